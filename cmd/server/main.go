@@ -51,7 +51,7 @@ func main() {
 		log.Fatal("cannot create server", zap.Error(err))
 	}
 
-	log.Info("Starting Vaultify server", zap.String("port", cfg.Port))
+	log.Info("Starting Vaultify server", zap.String("port", cfg.Port), zap.String("grpc_port", cfg.GRPCPort))
 
 	err = server.Start(":" + cfg.Port)
 	if err != nil {
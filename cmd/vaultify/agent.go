@@ -0,0 +1,482 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/pixperk/vaultify/internal/config"
+	"github.com/pixperk/vaultify/pkg/client"
+)
+
+const (
+	defaultAgentInterval = 5 * time.Minute
+	// reloadTimeout bounds how long a reload command may run
+	reloadTimeout = 30 * time.Second
+)
+
+// agentConfig is the JSON file read by "vaultify agent -config"
+type agentConfig struct {
+	// Server defaults to the server of the stored login
+	Server string `json:"server"`
+	// Email and PasswordFile let the agent log in on its own. Without them the token stored
+	// by "vaultify login" is used.
+	Email        string `json:"email"`
+	PasswordFile string `json:"password_file"`
+	// TokenFile caches the access token between restarts
+	TokenFile   string          `json:"token_file"`
+	Environment string          `json:"environment"`
+	Interval    string          `json:"interval"`
+	Templates   []agentTemplate `json:"templates"`
+}
+
+// agentTemplate renders Source to Destination and runs Command when the output changed
+type agentTemplate struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	// Perms is the octal mode of the rendered file, 0600 by default
+	Perms   string `json:"perms"`
+	User    string `json:"user"`
+	Group   string `json:"group"`
+	Command string `json:"command"`
+
+	mode     fs.FileMode
+	uid, gid int
+}
+
+func loadAgentConfig(path string) (agentConfig, error) {
+	var ac agentConfig
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ac, err
+	}
+	if err := json.Unmarshal(data, &ac); err != nil {
+		return ac, fmt.Errorf("invalid agent config %s: %w", path, err)
+	}
+	if len(ac.Templates) == 0 {
+		return ac, fmt.Errorf("agent config %s has no templates", path)
+	}
+
+	for i := range ac.Templates {
+		t := &ac.Templates[i]
+		if t.Source == "" || t.Destination == "" {
+			return ac, fmt.Errorf("template %d needs a source and a destination", i+1)
+		}
+		t.mode = 0o600
+		if t.Perms != "" {
+			mode, err := strconv.ParseUint(t.Perms, 8, 32)
+			if err != nil || mode > 0o777 {
+				return ac, fmt.Errorf("template %s: invalid perms %q", t.Destination, t.Perms)
+			}
+			t.mode = fs.FileMode(mode)
+		}
+		if t.uid, err = lookupID(t.User, user.Lookup, user.LookupId, func(u *user.User) string { return u.Uid }); err != nil {
+			return ac, fmt.Errorf("template %s: %w", t.Destination, err)
+		}
+		if t.gid, err = lookupID(t.Group, user.LookupGroup, user.LookupGroupId, func(g *user.Group) string { return g.Gid }); err != nil {
+			return ac, fmt.Errorf("template %s: %w", t.Destination, err)
+		}
+	}
+	return ac, nil
+}
+
+// lookupID resolves a user or group given by name or number, -1 when unset
+func lookupID[T any](name string, byName, byID func(string) (T, error), id func(T) string) (int, error) {
+	if name == "" {
+		return -1, nil
+	}
+	entry, err := byName(name)
+	if err != nil {
+		if entry, err = byID(name); err != nil {
+			return -1, err
+		}
+	}
+	return strconv.Atoi(id(entry))
+}
+
+// dependencies are the secrets a render read, so the agent knows what to watch
+type dependencies struct {
+	paths    map[string]bool
+	prefixes map[string]bool
+	envs     map[string]bool
+}
+
+func newDependencies() dependencies {
+	return dependencies{paths: map[string]bool{}, prefixes: map[string]bool{}, envs: map[string]bool{}}
+}
+
+func (d dependencies) equal(o dependencies) bool {
+	return maps.Equal(d.paths, o.paths) && maps.Equal(d.prefixes, o.prefixes) && maps.Equal(d.envs, o.envs)
+}
+
+// requests returns the watch requests covering the dependencies. Templates reading from more
+// than one environment watch all of them.
+func (d dependencies) requests() []client.WatchRequest {
+	var env string
+	if len(d.envs) == 1 {
+		for e := range d.envs {
+			env = e
+		}
+	}
+
+	var reqs []client.WatchRequest
+	if len(d.paths) > 0 {
+		reqs = append(reqs, client.WatchRequest{Paths: slices.Sorted(maps.Keys(d.paths)), Environment: env})
+	}
+	for _, prefix := range slices.Sorted(maps.Keys(d.prefixes)) {
+		reqs = append(reqs, client.WatchRequest{Prefix: prefix, Environment: env})
+	}
+	return reqs
+}
+
+// renderer renders the templates of one pass. Secrets used by several templates are read once.
+type renderer struct {
+	ctx    context.Context
+	client *client.Client
+	cfg    cliConfig
+	env    string
+
+	values  map[[2]string]string
+	folders map[[2]string]map[string]string
+	deps    dependencies
+}
+
+func (r *renderer) environment(env []string) (string, error) {
+	switch len(env) {
+	case 0:
+		return r.env, nil
+	case 1:
+		return env[0], nil
+	}
+	return "", errors.New("at most one environment may be given")
+}
+
+// secret is the template function {{ secret "db/password" }}, with an optional environment
+func (r *renderer) secret(path string, env ...string) (string, error) {
+	e, err := r.environment(env)
+	if err != nil {
+		return "", err
+	}
+	path = r.cfg.fullPath(path)
+	r.deps.paths[path], r.deps.envs[e] = true, true
+	return r.read(path, e)
+}
+
+// read returns the value of the secret at the full path, from the cache if this pass read it before
+func (r *renderer) read(path, e string) (string, error) {
+	key := [2]string{path, e}
+	if value, ok := r.values[key]; ok {
+		return value, nil
+	}
+	secret, err := r.client.GetSecret(r.ctx, client.GetSecretRequest{Path: path, Environment: e, Resolve: true})
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	r.values[key] = secret.Value
+	return secret.Value, nil
+}
+
+// secrets is the template function {{ range $name, $value := secrets "app" }}, which maps the
+// path of every secret under a folder, relative to the folder, to its value
+func (r *renderer) secrets(folder string, env ...string) (map[string]string, error) {
+	e, err := r.environment(env)
+	if err != nil {
+		return nil, err
+	}
+	prefix := r.cfg.fullPath(folder) + "/"
+	r.deps.prefixes[prefix], r.deps.envs[e] = true, true
+
+	key := [2]string{prefix, e}
+	if values, ok := r.folders[key]; ok {
+		return values, nil
+	}
+	listed, err := r.client.ListSecrets(r.ctx, prefix, e)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", prefix, err)
+	}
+	values := make(map[string]string, len(listed))
+	for _, s := range listed {
+		value, err := r.read(s.Path, e)
+		if err != nil {
+			return nil, err
+		}
+		values[strings.TrimPrefix(s.Path, prefix)] = value
+	}
+	r.folders[key] = values
+	return values, nil
+}
+
+// render executes the template and writes the output, reporting whether the file changed
+func (r *renderer) render(t agentTemplate) (bool, error) {
+	src, err := os.ReadFile(t.Source)
+	if err != nil {
+		return false, err
+	}
+	tmpl, err := template.New(filepath.Base(t.Source)).Option("missingkey=error").Funcs(template.FuncMap{
+		"secret":  r.secret,
+		"secrets": r.secrets,
+	}).Parse(string(src))
+	if err != nil {
+		return false, err
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, nil); err != nil {
+		return false, err
+	}
+	return writeFileAtomic(t.Destination, out.Bytes(), t.mode, t.uid, t.gid)
+}
+
+// writeFileAtomic replaces path with data through a rename, so readers never see a partial
+// file. It leaves an identical file alone and reports whether it wrote.
+func writeFileAtomic(path string, data []byte, mode fs.FileMode, uid, gid int) (bool, error) {
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, data) {
+		info, err := os.Stat(path)
+		if err == nil && info.Mode().Perm() == mode {
+			return false, nil
+		}
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return false, err
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return false, err
+	}
+	defer os.Remove(tmp.Name())
+
+	// restrict the file before any secret is written to it
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return false, err
+	}
+	if uid >= 0 || gid >= 0 {
+		if err := tmp.Chown(uid, gid); err != nil {
+			tmp.Close()
+			return false, err
+		}
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return false, err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return false, err
+	}
+	if err := tmp.Close(); err != nil {
+		return false, err
+	}
+	return true, os.Rename(tmp.Name(), path)
+}
+
+// agent renders templates with the secrets they use and keeps them up to date
+type agent struct {
+	client    *client.Client
+	cfg       cliConfig
+	config    agentConfig
+	tokenFile string
+}
+
+// renderAll renders every template, then runs the reload commands of those that changed, each
+// command once. A template that fails keeps its previous file; the first error is returned.
+func (a *agent) renderAll(ctx context.Context) (dependencies, error) {
+	r := &renderer{
+		ctx:     ctx,
+		client:  a.client,
+		cfg:     a.cfg,
+		env:     a.config.Environment,
+		values:  map[[2]string]string{},
+		folders: map[[2]string]map[string]string{},
+		deps:    newDependencies(),
+	}
+
+	var (
+		firstErr error
+		commands []string
+	)
+	for _, t := range a.config.Templates {
+		changed, err := r.render(t)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "vaultify: agent: %s: %v\n", t.Destination, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if changed {
+			fmt.Fprintf(os.Stderr, "vaultify: agent: rendered %s\n", t.Destination)
+			if t.Command != "" && !slices.Contains(commands, t.Command) {
+				commands = append(commands, t.Command)
+			}
+		}
+	}
+
+	for _, command := range commands {
+		if err := runReload(ctx, command); err != nil {
+			fmt.Fprintf(os.Stderr, "vaultify: agent: %q: %v\n", command, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	if err := a.saveToken(); err != nil {
+		fmt.Fprintf(os.Stderr, "vaultify: agent: failed to cache token: %v\n", err)
+	}
+	return r.deps, firstErr
+}
+
+func runReload(ctx context.Context, command string) error {
+	ctx, cancel := context.WithTimeout(ctx, reloadTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdout, cmd.Stderr = os.Stderr, os.Stderr
+	return cmd.Run()
+}
+
+// saveToken caches the current token when the agent logs in on its own
+func (a *agent) saveToken() error {
+	if a.tokenFile == "" {
+		return nil
+	}
+	token := a.client.Token()
+	if token == "" {
+		return nil
+	}
+	_, err := writeFileAtomic(a.tokenFile, []byte(token), 0o600, -1, -1)
+	return err
+}
+
+// newAgent builds the client: with the agent's own credentials and cached token if it has
+// them, else with the stored login
+func newAgent(ac agentConfig) (*agent, error) {
+	a := &agent{config: ac}
+	if ac.Email == "" {
+		c, cfg, err := newClient()
+		if err != nil {
+			return nil, err
+		}
+		if ac.Server != "" {
+			if c, err = client.New(ac.Server, client.WithToken(cfg.Token)); err != nil {
+				return nil, err
+			}
+		}
+		a.client, a.cfg = c, cfg
+		return a, nil
+	}
+
+	password := os.Getenv("VAULTIFY_PASSWORD")
+	if ac.PasswordFile != "" {
+		data, err := os.ReadFile(ac.PasswordFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read password file: %w", err)
+		}
+		password = strings.TrimRight(string(data), "\r\n")
+	}
+	if password == "" {
+		return nil, errors.New("the agent config has an email but no password_file, and VAULTIFY_PASSWORD is not set")
+	}
+
+	a.tokenFile = ac.TokenFile
+	if a.tokenFile == "" {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return nil, err
+		}
+		a.tokenFile = filepath.Join(dir, "vaultify", "agent-token")
+	}
+	// a missing or unreadable cache just means logging in again
+	token, _ := os.ReadFile(a.tokenFile)
+
+	server := ac.Server
+	if server == "" {
+		server = defaultServer
+	}
+	c, err := client.New(server, client.WithToken(strings.TrimSpace(string(token))), client.WithCredentials(ac.Email, password))
+	if err != nil {
+		return nil, err
+	}
+	a.client, a.cfg = c, cliConfig{Server: server, Email: ac.Email}
+	return a, nil
+}
+
+func runAgent(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("agent", flag.ExitOnError)
+	configFile := fs.String("config", "", "agent config file (required)")
+	once := fs.Bool("once", false, "render the templates once and exit")
+	if _, err := parseArgs(fs, args, 0, 0, "-config FILE [-once]"); err != nil {
+		return err
+	}
+	if *configFile == "" {
+		return errors.New("-config is required")
+	}
+
+	ac, err := loadAgentConfig(*configFile)
+	if err != nil {
+		return err
+	}
+	interval := defaultAgentInterval
+	if ac.Interval != "" {
+		if interval, err = config.ParseDuration(ac.Interval); err != nil || interval <= 0 {
+			return fmt.Errorf("invalid interval %q", ac.Interval)
+		}
+	}
+	a, err := newAgent(ac)
+	if err != nil {
+		return err
+	}
+
+	deps, err := a.renderAll(ctx)
+	if *once {
+		return err
+	}
+
+	// events trigger a render right away; the interval is a fallback for anything missed
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// the watches follow the secrets the templates read, which may change between renders
+	var stopWatch context.CancelFunc
+	watch := func(d dependencies) <-chan struct{} {
+		if stopWatch != nil {
+			stopWatch()
+		}
+		watchCtx, cancel := context.WithCancel(ctx)
+		stopWatch = cancel
+		return watchSecrets(watchCtx, a.client, d.requests()...)
+	}
+	changed := watch(deps)
+	defer func() { stopWatch() }()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+		case <-ticker.C:
+		}
+
+		fresh, _ := a.renderAll(ctx)
+		if !fresh.equal(deps) {
+			changed = watch(fresh)
+			deps = fresh
+		}
+	}
+}
@@ -0,0 +1,442 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pixperk/vaultify/internal/config"
+	"github.com/pixperk/vaultify/pkg/client"
+)
+
+// durationFlag accepts the same durations as the server config, including days such as 7d
+type durationFlag time.Duration
+
+func (d *durationFlag) String() string {
+	if time.Duration(*d) == 0 {
+		return ""
+	}
+	return time.Duration(*d).String()
+}
+
+func (d *durationFlag) Set(s string) error {
+	v, err := config.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = durationFlag(v)
+	return nil
+}
+
+// parseArgs parses the flags of a command and checks it got between min and max positional arguments
+func parseArgs(fs *flag.FlagSet, args []string, min, max int, usage string) ([]string, error) {
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: vaultify %s %s\n", fs.Name(), usage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() < min || fs.NArg() > max {
+		fs.Usage()
+		os.Exit(2)
+	}
+	return fs.Args(), nil
+}
+
+func runLogin(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("login", flag.ExitOnError)
+	server := fs.String("server", "", "server URL (default: the stored server, or "+defaultServer+")")
+	email := fs.String("email", "", "account email (prompted for if omitted)")
+	passwordStdin := fs.Bool("password-stdin", false, "read the password from stdin instead of prompting")
+	if _, err := parseArgs(fs, args, 0, 0, "[-server URL] [-email EMAIL] [-password-stdin]"); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if *server != "" {
+		cfg.Server = *server
+	}
+
+	in := bufio.NewReader(stdin)
+	if *email == "" {
+		if *passwordStdin {
+			return errors.New("-email is required with -password-stdin")
+		}
+		fmt.Fprint(os.Stderr, "Email: ")
+		line, err := readLine(in)
+		if err != nil {
+			return err
+		}
+		*email = strings.TrimSpace(string(line))
+	}
+
+	var password []byte
+	if *passwordStdin {
+		password, err = readLine(in)
+	} else {
+		fmt.Fprint(os.Stderr, "Password: ")
+		password, err = readPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if errors.Is(err, errNotTerminal) {
+			password, err = readLine(in)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read password: %w", err)
+	}
+
+	c, err := client.New(cfg.Server)
+	if err != nil {
+		return err
+	}
+	resp, err := c.Login(ctx, *email, string(password))
+	if err != nil {
+		return err
+	}
+
+	cfg.Email = resp.User.Email
+	cfg.Token = resp.AccessToken
+	if err := saveConfig(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	fmt.Fprintf(stdout, "Logged in to %s as %s\n", cfg.Server, cfg.Email)
+	return nil
+}
+
+func runGet(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("get", flag.ExitOnError)
+	env := fs.String("env", "", "environment (default: the server's default)")
+	version := fs.Int("version", 0, "version to read (default: latest)")
+	resolve := fs.Bool("resolve", false, "expand references to other secrets")
+	format := outputFlag(fs, formatRaw)
+	rest, err := parseArgs(fs, args, 1, 1, "[-env ENV] [-version N] [-resolve] [-o raw|table|json] PATH")
+	if err != nil {
+		return err
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	c, cfg, err := newClient()
+	if err != nil {
+		return err
+	}
+	secret, err := c.GetSecret(ctx, client.GetSecretRequest{
+		Path:        cfg.fullPath(rest[0]),
+		Environment: *env,
+		Version:     int32(*version),
+		Resolve:     *resolve,
+	})
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case formatJSON:
+		return printJSON(secret)
+	case formatTable:
+		return printTable([]string{"PATH", "ENV", "VERSION", "VALUE"}, [][]string{
+			{secret.Path, secret.Environment, strconv.Itoa(int(secret.Version)), secret.Value},
+		})
+	}
+	// raw is the value exactly as stored, so it can be piped or redirected to a file
+	_, err = fmt.Fprint(stdout, secret.Value)
+	return err
+}
+
+func runPut(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("put", flag.ExitOnError)
+	env := fs.String("env", "", "environment (default: the server's default)")
+	file := fs.String("file", "-", `read the value from this file ("-" for stdin)`)
+	var ttl durationFlag
+	fs.Var(&ttl, "ttl", "expire a new secret after this long, e.g. 24h or 7d")
+	format := outputFlag(fs, formatTable)
+	rest, err := parseArgs(fs, args, 1, 1, "[-env ENV] [-file FILE] [-ttl DURATION] [-o table|json] PATH")
+	if err != nil {
+		return err
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	value, err := readValue(*file)
+	if err != nil {
+		return err
+	}
+	c, cfg, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	path := cfg.fullPath(rest[0])
+	secret, err := c.UpdateSecret(ctx, client.UpdateSecretRequest{
+		Path:        path,
+		Environment: *env,
+		Value:       value,
+	})
+	// secrets are created relative to the caller's namespace, team secrets by full path;
+	// a missing secret in someone else's namespace can't be created
+	relative, own := strings.CutPrefix(path, strings.ToLower(cfg.Email)+"/")
+	if errors.Is(err, client.ErrNotFound) && (own || strings.HasPrefix(path, "org/")) {
+		secret, err = c.CreateSecret(ctx, client.CreateSecretRequest{
+			Path:        relative,
+			Value:       value,
+			TTL:         time.Duration(ttl),
+			Environment: *env,
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case formatJSON:
+		return printJSON(secret)
+	case formatRaw:
+		_, err = fmt.Fprintln(stdout, secret.Version)
+		return err
+	}
+	return printTable([]string{"PATH", "ENV", "VERSION"}, [][]string{
+		{secret.Path, secret.Environment, strconv.Itoa(int(secret.Version))},
+	})
+}
+
+func runList(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("ls", flag.ExitOnError)
+	env := fs.String("env", "", "environment (default: the server's default)")
+	format := outputFlag(fs, formatTable)
+	rest, err := parseArgs(fs, args, 0, 1, "[-env ENV] [-o table|json|raw] [FOLDER]")
+	if err != nil {
+		return err
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	c, cfg, err := newClient()
+	if err != nil {
+		return err
+	}
+	// without a folder the server lists the caller's own namespace
+	var prefix string
+	if len(rest) == 1 {
+		prefix = cfg.fullPath(rest[0]) + "/"
+	}
+	secrets, err := c.ListSecrets(ctx, prefix, *env)
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case formatJSON:
+		return printJSON(secrets)
+	case formatRaw:
+		for _, s := range secrets {
+			fmt.Fprintln(stdout, s.Path)
+		}
+		return nil
+	}
+	rows := make([][]string, 0, len(secrets))
+	for _, s := range secrets {
+		expires := "-"
+		if s.ExpiresAt != nil {
+			expires = formatTime(*s.ExpiresAt)
+		}
+		rows = append(rows, []string{s.Path, strconv.Itoa(int(s.Version)), formatTime(s.UpdatedAt), expires})
+	}
+	return printTable([]string{"PATH", "VERSION", "UPDATED", "EXPIRES"}, rows)
+}
+
+func runHistory(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	env := fs.String("env", "", "environment (default: the server's default)")
+	format := outputFlag(fs, formatTable)
+	rest, err := parseArgs(fs, args, 1, 1, "[-env ENV] [-o table|json|raw] PATH")
+	if err != nil {
+		return err
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	c, cfg, err := newClient()
+	if err != nil {
+		return err
+	}
+	versions, err := c.History(ctx, cfg.fullPath(rest[0]), *env)
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case formatJSON:
+		return printJSON(versions)
+	case formatRaw:
+		for _, v := range versions {
+			fmt.Fprintln(stdout, v.Version)
+		}
+		return nil
+	}
+	rows := make([][]string, 0, len(versions))
+	for _, v := range versions {
+		createdBy := v.CreatedBy
+		if createdBy == "" {
+			createdBy = "-"
+		}
+		rows = append(rows, []string{strconv.Itoa(int(v.Version)), formatTime(v.CreatedAt), createdBy})
+	}
+	return printTable([]string{"VERSION", "CREATED", "CREATED BY"}, rows)
+}
+
+func runRollback(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("rollback", flag.ExitOnError)
+	env := fs.String("env", "", "environment (default: the server's default)")
+	version := fs.Int("version", 0, "version to restore (required)")
+	format := outputFlag(fs, formatTable)
+	rest, err := parseArgs(fs, args, 1, 1, "[-env ENV] -version N [-o table|json] PATH")
+	if err != nil {
+		return err
+	}
+	if *version < 1 {
+		return errors.New("-version is required")
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	c, cfg, err := newClient()
+	if err != nil {
+		return err
+	}
+	rollback, err := c.RollbackSecret(ctx, client.RollbackSecretRequest{
+		Path:        cfg.fullPath(rest[0]),
+		Environment: *env,
+		Version:     int32(*version),
+	})
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case formatJSON:
+		return printJSON(rollback)
+	case formatRaw:
+		_, err = fmt.Fprintln(stdout, rollback.NewVersion)
+		return err
+	}
+	return printTable([]string{"PATH", "RESTORED", "NEW VERSION"}, [][]string{
+		{rollback.Path, strconv.Itoa(int(rollback.ToVersion)), strconv.Itoa(int(rollback.NewVersion))},
+	})
+}
+
+func runShare(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("share", flag.ExitOnError)
+	permission := fs.String("permission", "read", "read or write")
+	var ttl durationFlag
+	fs.Var(&ttl, "ttl", "revoke the share after this long, e.g. 24h or 7d")
+	format := outputFlag(fs, formatTable)
+	rest, err := parseArgs(fs, args, 2, 2, "[-permission read|write] [-ttl DURATION] [-o table|json] PATH|FOLDER/* EMAIL|group:SLUG")
+	if err != nil {
+		return err
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	c, cfg, err := newClient()
+	if err != nil {
+		return err
+	}
+	req := client.ShareSecretRequest{
+		Path:       cfg.fullPath(rest[0]),
+		Permission: *permission,
+		TTL:        time.Duration(ttl),
+	}
+	if group, ok := strings.CutPrefix(rest[1], "group:"); ok {
+		req.TargetGroup = group
+	} else {
+		req.TargetEmail = rest[1]
+	}
+	share, err := c.ShareSecret(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	if *format == formatJSON {
+		return printJSON(share)
+	}
+	return printTable([]string{"PATH", "SHARED WITH", "PERMISSION"}, [][]string{
+		{share.Path, sharedWith(*share), share.Permission},
+	})
+}
+
+// sharedWith names the user or group a share targets
+func sharedWith(share client.Share) string {
+	if share.TargetGroup != "" {
+		return "group:" + share.TargetGroup
+	}
+	return share.TargetEmail
+}
+
+func runAudit(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("audit", flag.ExitOnError)
+	action := fs.String("action", "", "only this action, e.g. read_secret")
+	path := fs.String("path", "", "only this secret path")
+	failed := fs.Bool("failed", false, "only failed attempts")
+	var since durationFlag
+	fs.Var(&since, "since", "only entries newer than this, e.g. 1h or 7d")
+	limit := fs.Int("limit", 50, "maximum number of entries")
+	format := outputFlag(fs, formatTable)
+	if _, err := parseArgs(fs, args, 0, 0, "[-action ACTION] [-path PATH] [-failed] [-since DURATION] [-limit N] [-o table|json]"); err != nil {
+		return err
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	c, cfg, err := newClient()
+	if err != nil {
+		return err
+	}
+	q := client.AuditQuery{Action: *action, Limit: *limit}
+	if *path != "" {
+		q.Path = cfg.fullPath(*path)
+	}
+	if *failed {
+		success := false
+		q.Success = &success
+	}
+	if since != 0 {
+		q.From = time.Now().Add(-time.Duration(since))
+	}
+	logs, err := c.AuditLogs(ctx, q)
+	if err != nil {
+		return err
+	}
+
+	if *format == formatJSON {
+		return printJSON(logs)
+	}
+	rows := make([][]string, 0, len(logs))
+	for _, l := range logs {
+		result := "ok"
+		if !l.Success {
+			result = "failed"
+			if l.Reason != nil {
+				result += ": " + *l.Reason
+			}
+		}
+		rows = append(rows, []string{formatTime(l.CreatedAt), l.Action, l.ResourcePath, strconv.Itoa(int(l.ResourceVersion)), result})
+	}
+	return printTable([]string{"TIME", "ACTION", "PATH", "VERSION", "RESULT"}, rows)
+}
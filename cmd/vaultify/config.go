@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pixperk/vaultify/pkg/client"
+)
+
+const defaultServer = "http://localhost:9090"
+
+// cliConfig is what login stores between runs
+type cliConfig struct {
+	Server string `json:"server"`
+	Email  string `json:"email"`
+	Token  string `json:"token"`
+}
+
+// configPath is $VAULTIFY_CONFIG, or vaultify/config.json in the user's config directory
+func configPath() (string, error) {
+	if path := os.Getenv("VAULTIFY_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "vaultify", "config.json"), nil
+}
+
+// loadConfig reads the stored config. VAULTIFY_ADDR and VAULTIFY_TOKEN override it.
+func loadConfig() (cliConfig, error) {
+	cfg := cliConfig{Server: defaultServer}
+
+	path, err := configPath()
+	if err != nil {
+		return cfg, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return cfg, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return cfg, fmt.Errorf("invalid config %s: %w", path, err)
+		}
+	}
+
+	if addr := os.Getenv("VAULTIFY_ADDR"); addr != "" {
+		cfg.Server = addr
+	}
+	if token := os.Getenv("VAULTIFY_TOKEN"); token != "" {
+		cfg.Token = token
+	}
+	return cfg, nil
+}
+
+// saveConfig writes the config readable only by the current user, since it holds the token
+func saveConfig(cfg cliConfig) error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// write and rename so a failed write never truncates the existing config
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".config-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// newClient returns a client for the stored server and token
+func newClient() (*client.Client, cliConfig, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, cfg, err
+	}
+	if cfg.Token == "" {
+		return nil, cfg, errors.New(`not logged in, run "vaultify login" first`)
+	}
+	c, err := client.New(cfg.Server, client.WithToken(cfg.Token))
+	return c, cfg, err
+}
+
+// fullPath puts paths given without a namespace into the user's own: "db/password" becomes
+// "alice@example.com/db/password". Team paths (org/...) and paths whose first segment is
+// an email are left alone.
+func (cfg cliConfig) fullPath(path string) string {
+	path = strings.Trim(path, "/")
+	first, _, _ := strings.Cut(path, "/")
+	if cfg.Email == "" || first == "org" || strings.Contains(first, "@") {
+		return path
+	}
+	return strings.ToLower(cfg.Email) + "/" + path
+}
@@ -0,0 +1,102 @@
+// Command vaultify is a command-line client for the Vaultify API.
+//
+//	vaultify login -server http://localhost:9090 -email alice@example.com
+//	echo -n s3cret | vaultify put db/password
+//	vaultify get db/password
+//	vaultify run -secret DB_PASSWORD=db/password -- ./server
+//
+// Secret values are read from stdin or files, never from arguments, so they stay out of
+// shell history. Paths without a namespace are taken to be in the logged-in user's own.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+
+	"github.com/pixperk/vaultify/pkg/client"
+)
+
+type command struct {
+	summary string
+	run     func(ctx context.Context, args []string) error
+}
+
+var commands = map[string]command{
+	"login":    {"Log in and store the access token", runLogin},
+	"get":      {"Print a secret's value", runGet},
+	"put":      {"Create a secret or write a new version from stdin or a file", runPut},
+	"ls":       {"List secrets under a folder", runList},
+	"history":  {"List the versions of a secret", runHistory},
+	"rollback": {"Restore an earlier version of a secret", runRollback},
+	"share":    {"Share a secret with another user", runShare},
+	"audit":    {"Show your audit log", runAudit},
+	"run":      {"Run a command with secrets in its environment", runRun},
+	"agent":    {"Render templates with secrets and keep them up to date", runAgent},
+}
+
+// exitCode ends vaultify with a status and no message, e.g. to pass on the status of a child
+type exitCode int
+
+func (e exitCode) Error() string {
+	return fmt.Sprintf("exit status %d", int(e))
+}
+
+// stdin and stdout are swapped out in tests
+var (
+	stdin  io.Reader = os.Stdin
+	stdout io.Writer = os.Stdout
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: vaultify <command> [flags] [args]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, `Run "vaultify <command> -h" for the flags of a command.`)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	name := os.Args[1]
+	if name == "-h" || name == "-help" || name == "--help" || name == "help" {
+		usage()
+		return
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "vaultify: unknown command %q\n\n", name)
+		usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := cmd.run(ctx, os.Args[2:]); err != nil {
+		var code exitCode
+		if errors.As(err, &code) {
+			os.Exit(int(code))
+		}
+		if errors.Is(err, client.ErrUnauthorized) {
+			err = fmt.Errorf("%w (run \"vaultify login\" to log in again)", err)
+		}
+		fmt.Fprintln(os.Stderr, "vaultify:", err)
+		os.Exit(1)
+	}
+}
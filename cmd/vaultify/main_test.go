@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFullPath(t *testing.T) {
+	cfg := cliConfig{Email: "Alice@Example.com"}
+
+	require.Equal(t, "alice@example.com/db/password", cfg.fullPath("db/password"))
+	require.Equal(t, "alice@example.com/db/password", cfg.fullPath("/db/password/"))
+	require.Equal(t, "bob@example.com/api-key", cfg.fullPath("bob@example.com/api-key"))
+	require.Equal(t, "org/acme/backend/db", cfg.fullPath("org/acme/backend/db"))
+	require.Equal(t, "db/password", cliConfig{}.fullPath("db/password"))
+}
+
+func TestSaveConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vaultify", "config.json")
+	t.Setenv("VAULTIFY_CONFIG", path)
+	t.Setenv("VAULTIFY_ADDR", "")
+	t.Setenv("VAULTIFY_TOKEN", "")
+
+	cfg := cliConfig{Server: "http://vault.internal:9090", Email: "alice@example.com", Token: "token"}
+	require.NoError(t, saveConfig(cfg))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	loaded, err := loadConfig()
+	require.NoError(t, err)
+	require.Equal(t, cfg, loaded)
+
+	t.Setenv("VAULTIFY_TOKEN", "override")
+	loaded, err = loadConfig()
+	require.NoError(t, err)
+	require.Equal(t, "override", loaded.Token)
+}
+
+func TestReadValue(t *testing.T) {
+	stdin = strings.NewReader("s3cret\n")
+	t.Cleanup(func() { stdin = os.Stdin })
+
+	value, err := readValue("-")
+	require.NoError(t, err)
+	require.Equal(t, "s3cret", value)
+
+	file := filepath.Join(t.TempDir(), "value")
+	require.NoError(t, os.WriteFile(file, []byte("line one\nline two\n"), 0o600))
+	value, err = readValue(file)
+	require.NoError(t, err)
+	require.Equal(t, "line one\nline two", value)
+
+	stdin = strings.NewReader("")
+	_, err = readValue("")
+	require.Error(t, err)
+}
+
+func TestReadLine(t *testing.T) {
+	r := strings.NewReader("alice@example.com\r\nrest")
+
+	line, err := readLine(r)
+	require.NoError(t, err)
+	require.Equal(t, "alice@example.com", string(line))
+
+	line, err = readLine(r)
+	require.NoError(t, err)
+	require.Equal(t, "rest", string(line))
+}
+
+func TestEnvName(t *testing.T) {
+	require.Equal(t, "DB_PASSWORD", envName("db/password"))
+	require.Equal(t, "API_KEY_V2", envName("api-key.v2"))
+	require.Equal(t, "_1PASSWORD", envName("1password"))
+}
+
+func TestSecretFlags(t *testing.T) {
+	flags := secretFlags{}
+	require.NoError(t, flags.Set("DB_PASSWORD=db/password"))
+	require.Equal(t, "db/password", flags["DB_PASSWORD"])
+
+	require.Error(t, flags.Set("DB_PASSWORD=db/other"))
+	require.Error(t, flags.Set("db-password=db/password"))
+	require.Error(t, flags.Set("API_KEY"))
+}
+
+func TestRun(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/list":
+			json.NewEncoder(w).Encode(map[string]any{"secrets": []map[string]any{
+				{"path": "alice@example.com/app/api-key", "version": 1},
+			}})
+		case "/api/v1/secrets/alice@example.com/db/password":
+			json.NewEncoder(w).Encode(map[string]any{"path": "alice@example.com/db/password", "version": 1, "decrypted_value": "s3cret"})
+		case "/api/v1/secrets/alice@example.com/app/api-key":
+			json.NewEncoder(w).Encode(map[string]any{"path": "alice@example.com/app/api-key", "version": 1, "decrypted_value": "k3y"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	t.Setenv("VAULTIFY_CONFIG", filepath.Join(t.TempDir(), "config.json"))
+	require.NoError(t, saveConfig(cliConfig{Email: "alice@example.com"}))
+	t.Setenv("VAULTIFY_ADDR", srv.URL)
+	t.Setenv("VAULTIFY_TOKEN", "token")
+
+	err := runRun(context.Background(), []string{
+		"-secret", "DB_PASSWORD=db/password", "-prefix", "app",
+		"--", "sh", "-c", `test "$DB_PASSWORD" = s3cret && test "$API_KEY" = k3y`,
+	})
+	require.NoError(t, err)
+
+	err = runRun(context.Background(), []string{"-secret", "DB_PASSWORD=db/password", "--", "sh", "-c", "exit 3"})
+	require.Equal(t, exitCode(3), err)
+}
+
+func TestAgentRender(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/v1/login" {
+			json.NewEncoder(w).Encode(map[string]any{"access_token": "agent-token", "user": map[string]any{"email": "alice@example.com"}})
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer agent-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v1/list":
+			json.NewEncoder(w).Encode(map[string]any{"secrets": []map[string]any{
+				{"path": "alice@example.com/app/api-key", "version": 1},
+			}})
+		case "/api/v1/secrets/alice@example.com/db/password":
+			json.NewEncoder(w).Encode(map[string]any{"decrypted_value": "s3cret"})
+		case "/api/v1/secrets/alice@example.com/app/api-key":
+			json.NewEncoder(w).Encode(map[string]any{"decrypted_value": "k3y"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+	source := write("app.conf.tmpl", "password={{ secret \"db/password\" }}\n{{ range $name, $value := secrets \"app\" }}{{ $name }}={{ $value }}\n{{ end }}")
+	reloaded := filepath.Join(dir, "reloaded")
+	configFile := write("agent.json", `{
+		"server": "`+srv.URL+`",
+		"email": "alice@example.com",
+		"password_file": "`+write("password", "hunter2\n")+`",
+		"token_file": "`+filepath.Join(dir, "cache", "token")+`",
+		"templates": [{
+			"source": "`+source+`",
+			"destination": "`+filepath.Join(dir, "out", "app.conf")+`",
+			"perms": "0640",
+			"command": "touch `+reloaded+`"
+		}]
+	}`)
+
+	ac, err := loadAgentConfig(configFile)
+	require.NoError(t, err)
+	a, err := newAgent(ac)
+	require.NoError(t, err)
+
+	deps, err := a.renderAll(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{"alice@example.com/db/password": true}, deps.paths)
+	require.Equal(t, map[string]bool{"alice@example.com/app/": true}, deps.prefixes)
+
+	out, err := os.ReadFile(filepath.Join(dir, "out", "app.conf"))
+	require.NoError(t, err)
+	require.Equal(t, "password=s3cret\napi-key=k3y\n", string(out))
+	info, err := os.Stat(filepath.Join(dir, "out", "app.conf"))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+	require.FileExists(t, reloaded)
+
+	token, err := os.ReadFile(filepath.Join(dir, "cache", "token"))
+	require.NoError(t, err)
+	require.Equal(t, "agent-token", string(token))
+
+	// nothing changed, so nothing is written or reloaded
+	require.NoError(t, os.Remove(reloaded))
+	_, err = a.renderAll(context.Background())
+	require.NoError(t, err)
+	require.NoFileExists(t, reloaded)
+}
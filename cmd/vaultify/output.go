@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+var errNotTerminal = errors.New("not a terminal")
+
+// output formats accepted by -o
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatRaw   = "raw"
+)
+
+// outputFlag registers -o on fs with the given default
+func outputFlag(fs *flag.FlagSet, def string) *string {
+	return fs.String("o", def, "output format: table, json or raw")
+}
+
+func checkFormat(format string) error {
+	switch format {
+	case formatTable, formatJSON, formatRaw:
+		return nil
+	}
+	return fmt.Errorf("unknown output format %q (want table, json or raw)", format)
+}
+
+func printJSON(v any) error {
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// printTable writes tab-separated rows under a header, aligned in columns
+func printTable(header []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format(time.DateTime)
+}
+
+// readLine reads up to the next newline one byte at a time, so nothing after it is consumed
+func readLine(r io.Reader) ([]byte, error) {
+	var line []byte
+	buf := make([]byte, 1)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if buf[0] == '\n' {
+				break
+			}
+			line = append(line, buf[0])
+		}
+		if err == io.EOF && len(line) > 0 {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return []byte(strings.TrimSuffix(string(line), "\r")), nil
+}
+
+// readValue reads a secret value from file, or from stdin when file is empty or "-".
+// A single trailing newline is dropped so "echo value | vaultify put" stores just the value.
+func readValue(file string) (string, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if file == "" || file == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return "", err
+	}
+	value := strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r")
+	if value == "" {
+		return "", errors.New("secret value is empty")
+	}
+	return value, nil
+}
@@ -0,0 +1,8 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package main
+
+// readPassword cannot turn off echo on this platform, so login reads the password as a plain line
+func readPassword(fd int) ([]byte, error) {
+	return nil, errNotTerminal
+}
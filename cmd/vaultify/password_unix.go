@@ -0,0 +1,32 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package main
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// readPassword reads a line from the terminal fd with echo turned off
+func readPassword(fd int) ([]byte, error) {
+	termios, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		return nil, errNotTerminal
+	}
+
+	noEcho := *termios
+	noEcho.Lflag &^= unix.ECHO
+	noEcho.Lflag |= unix.ICANON | unix.ISIG
+	noEcho.Iflag |= unix.ICRNL
+	if err := unix.IoctlSetTermios(fd, ioctlSetTermios, &noEcho); err != nil {
+		return nil, err
+	}
+	defer unix.IoctlSetTermios(fd, ioctlSetTermios, termios)
+
+	return readLine(fdReader(fd))
+}
+
+type fdReader int
+
+func (r fdReader) Read(p []byte) (int, error) {
+	return unix.Read(int(r), p)
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"os/signal"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pixperk/vaultify/pkg/client"
+)
+
+// watchRetryMax caps the wait before reconnecting a broken watch stream
+const watchRetryMax = 30 * time.Second
+
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// secretFlags collects repeated -secret NAME=PATH flags
+type secretFlags map[string]string
+
+func (s secretFlags) String() string {
+	return ""
+}
+
+func (s secretFlags) Set(v string) error {
+	name, path, ok := strings.Cut(v, "=")
+	if !ok || path == "" {
+		return fmt.Errorf("want NAME=PATH, got %q", v)
+	}
+	if !envNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid environment variable name %q", name)
+	}
+	if _, dup := s[name]; dup {
+		return fmt.Errorf("%s is given twice", name)
+	}
+	s[name] = path
+	return nil
+}
+
+// envName turns the path of a secret below a folder into a variable name: db/password becomes DB_PASSWORD
+func envName(relative string) string {
+	name := []byte(strings.ToUpper(relative))
+	for i, c := range name {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			name[i] = '_'
+		}
+	}
+	if len(name) > 0 && name[0] >= '0' && name[0] <= '9' {
+		return "_" + string(name)
+	}
+	return string(name)
+}
+
+// injection is the set of secrets a run puts into the environment of its child
+type injection struct {
+	env     string
+	secrets map[string]string // variable name to full path
+	prefix  string            // full folder path ending in "/", or empty
+}
+
+// fetch reads every secret of the injection, expanding references, and returns the variables
+func (in injection) fetch(ctx context.Context, c *client.Client) (map[string]string, error) {
+	paths := maps.Clone(in.secrets)
+	if in.prefix != "" {
+		listed, err := c.ListSecrets(ctx, in.prefix, in.env)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", in.prefix, err)
+		}
+		for _, s := range listed {
+			name := envName(strings.TrimPrefix(s.Path, in.prefix))
+			// explicit mappings win over names derived from the folder
+			if _, ok := in.secrets[name]; ok {
+				continue
+			}
+			if other, ok := paths[name]; ok {
+				return nil, fmt.Errorf("%s and %s both map to %s", other, s.Path, name)
+			}
+			paths[name] = s.Path
+		}
+	}
+
+	vars := make(map[string]string, len(paths))
+	for name, path := range paths {
+		secret, err := c.GetSecret(ctx, client.GetSecretRequest{Path: path, Environment: in.env, Resolve: true})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		vars[name] = secret.Value
+	}
+	return vars, nil
+}
+
+// watchRequest selects the secrets of the injection for watching
+func (in injection) watchRequest() client.WatchRequest {
+	req := client.WatchRequest{Prefix: in.prefix, Environment: in.env}
+	for _, path := range in.secrets {
+		req.Paths = append(req.Paths, path)
+	}
+	return req
+}
+
+// watchSecrets signals on the returned channel whenever a secret selected by one of reqs may
+// have changed, reconnecting with backoff until ctx is done. Bursts of events are coalesced.
+func watchSecrets(ctx context.Context, c *client.Client, reqs ...client.WatchRequest) <-chan struct{} {
+	changed := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+
+	for _, req := range reqs {
+		go func() {
+			wait := time.Second
+			for {
+				err := c.Watch(ctx, req, func(client.WatchEvent) error {
+					wait = time.Second
+					notify()
+					return nil
+				})
+				if ctx.Err() != nil {
+					return
+				}
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "vaultify: watch failed, retrying in %s: %v\n", wait, err)
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(wait):
+				}
+				wait = min(wait*2, watchRetryMax)
+				// events may have been missed while disconnected
+				notify()
+			}
+		}()
+	}
+	return changed
+}
+
+// child is a running command
+type child struct {
+	cmd  *exec.Cmd
+	done chan struct{}
+	err  error
+}
+
+func startChild(argv []string, vars map[string]string) (*child, error) {
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.Env = os.Environ()
+	for name, value := range vars {
+		cmd.Env = append(cmd.Env, name+"="+value)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	ch := &child{cmd: cmd, done: make(chan struct{})}
+	go func() {
+		ch.err = ch.cmd.Wait()
+		close(ch.done)
+	}()
+	return ch, nil
+}
+
+// stop asks the child to terminate and kills it if it is still running after grace
+func (ch *child) stop(grace time.Duration) {
+	if err := ch.cmd.Process.Signal(stopSignal); err != nil {
+		ch.cmd.Process.Kill()
+	}
+	select {
+	case <-ch.done:
+	case <-time.After(grace):
+		ch.cmd.Process.Kill()
+		<-ch.done
+	}
+}
+
+// exitStatus passes on how the child ended
+func (ch *child) exitStatus() error {
+	var exitErr *exec.ExitError
+	if errors.As(ch.err, &exitErr) {
+		if code := exitErr.ExitCode(); code > 0 {
+			return exitCode(code)
+		}
+		return exitCode(1)
+	}
+	return ch.err
+}
+
+func runRun(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	env := fs.String("env", "", "environment (default: the server's default)")
+	secrets := secretFlags{}
+	fs.Var(secrets, "secret", "set variable NAME to the secret at PATH (repeatable)")
+	prefix := fs.String("prefix", "", "set a variable for every secret under this folder, e.g. db/password as DB_PASSWORD")
+	restart := fs.Bool("restart", false, "restart the command when one of the secrets changes")
+	grace := fs.Duration("grace", 10*time.Second, "how long a restarted command may take to exit before it is killed")
+	argv, err := parseArgs(fs, args, 1, 1<<20, "[-env ENV] [-secret NAME=PATH]... [-prefix FOLDER] [-restart] -- COMMAND [ARGS...]")
+	if err != nil {
+		return err
+	}
+	if len(secrets) == 0 && *prefix == "" {
+		return errors.New("at least one -secret or a -prefix is required")
+	}
+
+	c, cfg, err := newClient()
+	if err != nil {
+		return err
+	}
+	in := injection{env: *env, secrets: make(map[string]string, len(secrets))}
+	for name, path := range secrets {
+		in.secrets[name] = cfg.fullPath(path)
+	}
+	if *prefix != "" {
+		in.prefix = cfg.fullPath(*prefix) + "/"
+	}
+
+	vars, err := in.fetch(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	// forward signals to the child instead of dying on them, so it can shut down cleanly
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, forwardedSignals...)
+	defer signal.Stop(signals)
+
+	var changed <-chan struct{}
+	if *restart {
+		watchCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		changed = watchSecrets(watchCtx, c, in.watchRequest())
+	}
+
+	ch, err := startChild(argv, vars)
+	if err != nil {
+		return err
+	}
+	for {
+		select {
+		case sig := <-signals:
+			ch.cmd.Process.Signal(sig)
+		case <-ch.done:
+			return ch.exitStatus()
+		case <-changed:
+			// a failed read keeps the command running on the values it has
+			fresh, err := in.fetch(ctx, c)
+			if err != nil {
+				if ctx.Err() == nil {
+					fmt.Fprintln(os.Stderr, "vaultify:", err)
+				}
+				continue
+			}
+			if maps.Equal(fresh, vars) {
+				continue
+			}
+			fmt.Fprintln(os.Stderr, "vaultify: secrets changed, restarting", argv[0])
+			ch.stop(*grace)
+			vars = fresh
+			if ch, err = startChild(argv, vars); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package main
+
+import "os"
+
+// forwardedSignals are passed on to the command started by run
+var forwardedSignals = []os.Signal{os.Interrupt}
+
+// stopSignal asks the command started by run to exit before a restart. Processes can't be
+// signalled here, so stopping falls back to killing them.
+var stopSignal os.Signal = os.Interrupt
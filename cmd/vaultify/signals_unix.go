@@ -0,0 +1,15 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package main
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// forwardedSignals are passed on to the command started by run
+var forwardedSignals = []os.Signal{unix.SIGINT, unix.SIGTERM, unix.SIGHUP, unix.SIGQUIT, unix.SIGUSR1, unix.SIGUSR2}
+
+// stopSignal asks the command started by run to exit before a restart
+var stopSignal os.Signal = unix.SIGTERM
@@ -18,59 +18,26 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/audit/logs": {
+        "/access-requests": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Fetch audit logs for the authenticated user with optional filters",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Lists the access requests made by the caller, newest first, optionally only those with a status.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Audit"
+                    "Access Requests"
                 ],
-                "summary": "Get audit logs",
+                "summary": "List my access requests",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Action filter (e.g., CREATE_SECRET)",
-                        "name": "action",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Resource path filter (e.g., /vault/secrets/foo)",
-                        "name": "path",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Resource version filter",
-                        "name": "version",
-                        "in": "query"
-                    },
-                    {
-                        "type": "boolean",
-                        "description": "Success status filter (true/false)",
-                        "name": "success",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Start date in RFC3339 or YYYY-MM-DD",
-                        "name": "from",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "End date in RFC3339 or YYYY-MM-DD",
-                        "name": "to",
+                        "description": "pending, approved, denied or cancelled",
+                        "name": "status",
                         "in": "query"
                     },
                     {
@@ -88,38 +55,32 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "List of audit logs",
+                        "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/api.getAuditLogsResponse"
-                            }
+                            "$ref": "#/definitions/api.listAccessRequestsResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid query parameter",
-                        "schema": {
-                            "$ref": "#/definitions/api.swaggerErrorResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "No logs found",
+                        "description": "Invalid status",
                         "schema": {
                             "$ref": "#/definitions/api.swaggerErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal server error",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/api.swaggerErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/login": {
+            },
             "post": {
-                "description": "Verify credentials and return access token",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Asks for read or write access to a secret, or with a path ending in /* to a folder, for a limited time. The justification is shown to the approvers: the owner or the maintainers of the owning team, and anyone a policy grants share on the path. They are notified and can approve or deny the request; approving creates a share that ends after duration_secs (at most 90 days). Audited as request_access.",
                 "consumes": [
                     "application/json"
                 ],
@@ -127,41 +88,41 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Access Requests"
                 ],
-                "summary": "Log in a user",
+                "summary": "Request access to a secret",
                 "parameters": [
                     {
-                        "description": "Login credentials",
-                        "name": "user",
+                        "description": "Access request",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.loginUserRequest"
+                            "$ref": "#/definitions/api.createAccessRequestRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/api.loginUserResponse"
+                            "$ref": "#/definitions/api.accessRequestResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid input",
                         "schema": {
                             "$ref": "#/definitions/api.swaggerErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "404": {
+                        "description": "Secret or folder owner not found",
                         "schema": {
                             "$ref": "#/definitions/api.swaggerErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "409": {
+                        "description": "Access already held or already requested",
                         "schema": {
                             "$ref": "#/definitions/api.swaggerErrorResponse"
                         }
@@ -175,58 +136,40 @@ const docTemplate = `{
                 }
             }
         },
-        "/secrets": {
-            "post": {
+        "/access-requests/pending": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Encrypts and stores a secret with optional TTL, linked to the authenticated user. The encrypted secret is signed with an HMAC signature to ensure integrity and prevent tampering.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Lists the pending access requests of other users that the caller can approve or deny, oldest first. Requests are looked up below the caller's namespace, the teams they maintain and the paths their policies grant share on, so a page may hold fewer than limit requests.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Secrets"
+                    "Access Requests"
                 ],
-                "summary": "Create a new secret",
+                "summary": "List access requests awaiting me",
                 "parameters": [
                     {
-                        "description": "Secret creation request",
-                        "name": "secret",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/api.createSecretRequest"
-                        }
+                        "type": "integer",
+                        "description": "Limit number of results (default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset for pagination (default 0)",
+                        "name": "offset",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.secretResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/api.swaggerErrorResponse"
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/api.swaggerErrorResponse"
-                        }
-                    },
-                    "403": {
-                        "description": "Forbidden",
-                        "schema": {
-                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                            "$ref": "#/definitions/api.listAccessRequestsResponse"
                         }
                     },
                     "500": {
@@ -238,74 +181,57 @@ const docTemplate = `{
                 }
             }
         },
-        "/secrets/share": {
-            "post": {
+        "/access-requests/{id}": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Allows a user to share their secret with another user, specifying access permission and optional TTL. Verifies ownership before proceeding.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Withdraws a pending access request made by the caller. Audited as cancel_access_request.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Secrets"
+                    "Access Requests"
                 ],
-                "summary": "Share a secret with another user",
+                "summary": "Cancel my access request",
                 "parameters": [
                     {
-                        "description": "Secret share request payload",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/api.shareSecretRequest"
-                        }
+                        "type": "string",
+                        "description": "Access request ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.shareSecretResponse"
+                            "$ref": "#/definitions/api.accessRequestResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid input or sharing with self",
-                        "schema": {
-                            "$ref": "#/definitions/api.swaggerErrorResponse"
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized: missing or invalid bearer token",
-                        "schema": {
-                            "$ref": "#/definitions/api.swaggerErrorResponse"
-                        }
-                    },
-                    "403": {
-                        "description": "Forbidden: not the secret owner",
+                        "description": "Invalid ID",
                         "schema": {
                             "$ref": "#/definitions/api.swaggerErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Secret or target user not found",
+                        "description": "Request not found",
                         "schema": {
                             "$ref": "#/definitions/api.swaggerErrorResponse"
                         }
                     },
                     "409": {
-                        "description": "Secret already shared with target user",
+                        "description": "Request already decided",
                         "schema": {
                             "$ref": "#/definitions/api.swaggerErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal server error during sharing",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/api.swaggerErrorResponse"
                         }
@@ -313,70 +239,89 @@ const docTemplate = `{
                 }
             }
         },
-        "/secrets/{path}": {
-            "get": {
+        "/access-requests/{id}/approve": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Fetches and decrypts the secret. If version is not specified, retrieves the latest. Verifies HMAC to ensure integrity.",
+                "description": "Shares the requested path with the requester for the requested duration, or a shorter duration_secs. The caller needs share on the path. Fails with 409 when the path is already shared with the requester; change that share instead. Audited as approve_access_request and share_secret, and the requester is notified.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Secrets"
+                    "Access Requests"
                 ],
-                "summary": "Retrieve a secret by path and optional version",
+                "summary": "Approve an access request",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Secret path",
-                        "name": "path",
+                        "description": "Access request ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Secret version (optional)",
-                        "name": "version",
-                        "in": "query"
+                        "description": "Reason and duration",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/api.decideAccessRequestRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.getSecretResponse"
+                            "$ref": "#/definitions/api.accessRequestResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized or HMAC verification failed",
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an approver of the path",
                         "schema": {
                             "$ref": "#/definitions/api.swaggerErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Secret not found",
+                        "description": "Request not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Request already decided or path already shared",
                         "schema": {
                             "$ref": "#/definitions/api.swaggerErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal server error",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/api.swaggerErrorResponse"
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/access-requests/{id}/deny": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Encrypts new secret value, verifies existing HMAC to prevent tampering, then creates a new secret version signed with a fresh HMAC.",
+                "description": "Rejects a pending access request, with an optional reason shown to the requester. The caller needs share on the path. Audited as deny_access_request, and the requester is notified.",
                 "consumes": [
                     "application/json"
                 ],
@@ -384,24 +329,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Secrets"
+                    "Access Requests"
                 ],
-                "summary": "Update an existing secret by creating a new version",
+                "summary": "Deny an access request",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Secret path",
-                        "name": "path",
+                        "description": "Access request ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "New secret value",
-                        "name": "updateSecretRequest",
+                        "description": "Reason",
+                        "name": "request",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.updateSecretRequest"
+                            "$ref": "#/definitions/api.decideAccessRequestRequest"
                         }
                     }
                 ],
@@ -409,7 +353,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.updateSecretResponse"
+                            "$ref": "#/definitions/api.accessRequestResponse"
                         }
                     },
                     "400": {
@@ -418,20 +362,26 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.swaggerErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized or HMAC verification failed",
+                    "403": {
+                        "description": "Not an approver of the path",
                         "schema": {
                             "$ref": "#/definitions/api.swaggerErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Secret not found",
+                        "description": "Request not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Request already decided",
                         "schema": {
                             "$ref": "#/definitions/api.swaggerErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal server error",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/api.swaggerErrorResponse"
                         }
@@ -439,24 +389,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/secrets/{path}/rollback": {
-            "post": {
+        "/access/{path}": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Reverts a secret to a previous version by duplicating the selected version with a new version number. Verifies HMAC before proceeding.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Lists everyone who can read or write a secret and why: its creator for a personal secret, the members of its team (organization owners and admins as maintainers) for a team secret, and the share that applies to each user or group, which may be the share of a folder above the secret. A user can appear once per source and again through their groups. Only the owner of the secret can see this.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Secrets"
                 ],
-                "summary": "Rollback secret to a previous version",
+                "summary": "List who has access to a secret",
                 "parameters": [
                     {
                         "type": "string",
@@ -466,42 +413,39 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Rollback secret request payload",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/api.rollbackSecretRequest"
-                        }
+                        "type": "string",
+                        "description": "Only entries with this permission (read or write)",
+                        "name": "permission",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.rollbackSecretResponse"
+                            "$ref": "#/definitions/api.secretAccessResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid input or bad version",
+                        "description": "Invalid path or permission",
                         "schema": {
                             "$ref": "#/definitions/api.swaggerErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized: invalid HMAC or missing token",
+                    "403": {
+                        "description": "Not the secret owner",
                         "schema": {
                             "$ref": "#/definitions/api.swaggerErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Secret version not found",
+                        "description": "Secret not found",
                         "schema": {
                             "$ref": "#/definitions/api.swaggerErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal server error during rollback",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/api.swaggerErrorResponse"
                         }
@@ -509,9 +453,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/sign-up": {
-            "post": {
-                "description": "Create a new user with hashed password",
+        "/audit/logs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Fetch audit logs for the authenticated user with optional filters",
                 "consumes": [
                     "application/json"
                 ],
@@ -519,29 +468,297 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Audit"
                 ],
-                "summary": "Register a new user",
+                "summary": "Get audit logs",
                 "parameters": [
                     {
-                        "description": "User registration info",
-                        "name": "user",
+                        "type": "string",
+                        "description": "Action filter (e.g., CREATE_SECRET)",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resource path filter (e.g., /vault/secrets/foo)",
+                        "name": "path",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Resource version filter",
+                        "name": "version",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Success status filter (true/false)",
+                        "name": "success",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only entries flagged (true) or not flagged (false) for break-glass review",
+                        "name": "flagged",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date in RFC3339 or YYYY-MM-DD",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date in RFC3339 or YYYY-MM-DD",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit number of results (default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset for pagination (default 0)",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of audit logs",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.getAuditLogsResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameter",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No logs found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/break-glass": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Grants the caller short-lived read access to a secret on a designated break-glass path, if they belong to its emergency group. The reason is mandatory. Access is a time-bound read share from the owner of the secret, or for team secrets from whoever set up the rule, and lasts for the maximum of the rule unless duration_secs asks for less. The owner of the secret (or the maintainers of its team) and every admin and auditor are notified at once. The use and everything the caller does on the secret until the access ends are audited with the session id, flagging them for post-incident review under /sys/break-glass. The two-person rule still applies to secrets under it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Break-Glass"
+                ],
+                "summary": "Break the glass",
+                "parameters": [
+                    {
+                        "description": "Secret and reason",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.createUserRequest"
+                            "$ref": "#/definitions/api.breakGlassRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/api.breakGlassSessionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not a break-glass path or not in its emergency group",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Secret not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The caller can already read the secret",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
                         }
                     }
+                }
+            }
+        },
+        "/break-glass/rules": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the designated break-glass paths the caller set up or can use as a member of their emergency group.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Break-Glass"
                 ],
+                "summary": "List break-glass rules",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.userResponse"
+                            "$ref": "#/definitions/api.listBreakGlassRulesResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/break-glass/rules/{path}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lets members of an emergency group open the secret, or with a trailing /* every secret below the folder, for themselves during an incident. Each use grants read access for at most max_duration_secs (default 1 hour, at most 4 hours). Needs share on the path; audited as set_break_glass_rule. Setting the rule again replaces the group and duration.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Break-Glass"
+                ],
+                "summary": "Designate a break-glass path",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret path, or folder ending in /*",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Rule",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.setBreakGlassRuleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.breakGlassRuleResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Access denied",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Secret or group not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stops the emergency group from opening the secret or folder. Access already granted runs until it ends. Needs share on the path; audited as remove_break_glass_rule.",
+                "tags": [
+                    "Break-Glass"
+                ],
+                "summary": "Remove a break-glass path",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret path, or folder ending in /*",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Rule removed"
+                    },
+                    "403": {
+                        "description": "Access denied",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No rule for the path",
                         "schema": {
                             "$ref": "#/definitions/api.swaggerErrorResponse"
                         }
@@ -554,170 +771,5064 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "api.auditLogResponse": {
+        },
+        "/break-glass/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the caller's uses of break-glass access with their review status, newest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Break-Glass"
+                ],
+                "summary": "List my break-glass sessions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Limit number of results (default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset for pagination (default 0)",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.listBreakGlassSessionsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/capabilities/{path}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists what the caller may do on a secret path: read, create, update, delete, list, rollback, share and history. Owners of a path, team roles and shares grant capabilities built in; policies attached to the caller or their groups add more, a matching deny rule removes everything, and a token issued for named policies keeps only what those grant. The path does not need to exist.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Secrets"
+                ],
+                "summary": "Check my capabilities on a path",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret path",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.capabilitiesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid path",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/environments": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the default environment and the promotion order (e.g. dev -\u003e staging -\u003e prod).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Environments"
+                ],
+                "summary": "List environments",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.listEnvironmentsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/environments/drift": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "For every readable secret under the prefix (the caller's own namespace by default), shows the latest version per environment and whether its value differs from the previous environment in the promotion order. Values are compared in memory and never returned; versions that fail the HMAC check or cannot be decrypted are reported as unreadable.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Environments"
+                ],
+                "summary": "Environment drift report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Path prefix",
+                        "name": "prefix",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.driftReportResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/expiring": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the readable secrets whose TTL ends within the window, and the shares granted by or to the caller that end within it. The window defaults to the longest configured expiry warning and accepts Go durations or whole days, e.g. 36h or 7d.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Secrets"
+                ],
+                "summary": "List secrets and shares expiring soon",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Window to look ahead, e.g. 7d",
+                        "name": "within",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.listExpiringResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid window",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the groups the caller belongs to, with the caller's role in each.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Groups"
+                ],
+                "summary": "List my groups",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.groupResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a group of users that secrets can be shared with; the caller becomes its owner.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Groups"
+                ],
+                "summary": "Create a group",
+                "parameters": [
+                    {
+                        "description": "Group",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.createGroupRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.groupResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid slug",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Slug already taken",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{group}/members": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Groups"
+                ],
+                "summary": "List group members",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group slug",
+                        "name": "group",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.memberResponse"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Group not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Adds a user to the group or changes their role. They immediately gain access to everything shared with the group. Requires group owner.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Groups"
+                ],
+                "summary": "Add or update a group member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group slug",
+                        "name": "group",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Member",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.addGroupMemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.memberResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Cannot demote the last owner",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not a group owner",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Group or user not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{group}/members/{email}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes a user from the group; they immediately lose access to everything shared with the group. Requires group owner, except that members can always leave.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Groups"
+                ],
+                "summary": "Remove a group member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group slug",
+                        "name": "group",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Member email",
+                        "name": "email",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Cannot remove the last owner",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not a group owner",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Group, user or membership not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/history/{path}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists every version of the secret in the environment, newest first, without values.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Secrets"
+                ],
+                "summary": "List the versions of a secret",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret path",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Environment (defaults to default)",
+                        "name": "env",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.secretHistoryResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Access denied",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Secret not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/list": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the secrets under a folder that the caller can read, with their latest version in the environment. Values are not included. The prefix defaults to the caller's own namespace.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Secrets"
+                ],
+                "summary": "List secrets under a prefix",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Folder to list, e.g. alice@example.com/db/",
+                        "name": "prefix",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Environment (defaults to default)",
+                        "name": "env",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.listSecretsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid prefix or environment",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/login": {
+            "post": {
+                "description": "Verify credentials and return access token. Naming policies issues a token limited to what they grant on top of the user's own access.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Log in a user",
+                "parameters": [
+                    {
+                        "description": "Login credentials",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.loginUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.loginUserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orgs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the organizations the caller belongs to, with the caller's role in each.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "List my organizations",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.organizationResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates an organization; the caller becomes its owner.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Create an organization",
+                "parameters": [
+                    {
+                        "description": "Organization",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.createOrganizationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.organizationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid slug",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Slug already taken",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orgs/{org}/members": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "List organization members",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization slug",
+                        "name": "org",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.memberResponse"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Adds a user to the organization or changes their role. Requires owner or admin; only owners can grant the owner role or change an owner's role.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Add or update an organization member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization slug",
+                        "name": "org",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Member",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.addOrgMemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.memberResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an owner or admin, or the change touches an owner and the caller is not one",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Organization or user not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orgs/{org}/members/{email}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes a user from the organization and from all of its teams. Requires owner or admin; only owners can remove an owner.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Remove an organization member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization slug",
+                        "name": "org",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Member email",
+                        "name": "email",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Cannot remove the last owner",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an owner or admin, or the member is an owner and the caller is not one",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Organization or user not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orgs/{org}/teams": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "List teams",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization slug",
+                        "name": "org",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.teamResponse"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a team inside the organization. Its secrets live under org/{org}/{team}/. Requires owner or admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Create a team",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization slug",
+                        "name": "org",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Team",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.createTeamRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.teamResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid slug",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an owner or admin",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Team already exists",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orgs/{org}/teams/{team}/members": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "List team members",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization slug",
+                        "name": "org",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Team slug",
+                        "name": "team",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.memberResponse"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Organization or team not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Adds an organization member to the team or changes their role. Requires org owner/admin or team maintainer.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Add or update a team member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization slug",
+                        "name": "org",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Team slug",
+                        "name": "team",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Member",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.addTeamMemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.memberResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "User is not an organization member",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to manage the team",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Organization, team or user not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orgs/{org}/teams/{team}/members/{email}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes a user from the team; they immediately lose access to the team's secrets. Requires org owner/admin or team maintainer.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Remove a team member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization slug",
+                        "name": "org",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Team slug",
+                        "name": "team",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Member email",
+                        "name": "email",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "403": {
+                        "description": "Not allowed to manage the team",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Organization, team or user not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/quorum-requests": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the caller's requests to read secrets under the two-person rule, newest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Two-Person Rule"
+                ],
+                "summary": "List my quorum requests",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Limit number of results (default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset for pagination (default 0)",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.listQuorumRequestsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/quorum-requests/pending": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the open requests of other users to read secrets the caller can read, which the caller can approve, oldest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Two-Person Rule"
+                ],
+                "summary": "List quorum requests awaiting me",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.listQuorumRequestsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/quorum-requests/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Shows the status and approvals of a request to read a secret under the two-person rule. Visible to the requester and to users who can read the secret.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Two-Person Rule"
+                ],
+                "summary": "Get a quorum request",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Quorum request ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.quorumRequestResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Request not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/quorum-requests/{id}/approve": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Adds the caller's approval to another user's request to read a secret under the two-person rule. The caller must be able to read the secret. Once the required number of distinct approvals is reached within the window, the requester may read the secret once before the read TTL runs out. Audited as approve_quorum_read; the requester is notified when the read is granted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Two-Person Rule"
+                ],
+                "summary": "Approve a quorum request",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Quorum request ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.quorumRequestResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Own request",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Request not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Already approved, granted or expired",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/quorum/{path}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns how many approvals a read of the secret needs, how long they are collected and how long the approved read stays usable.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Two-Person Rule"
+                ],
+                "summary": "Get the two-person rule of a secret",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret path",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.quorumResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Access denied",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Secret not found or not under the rule",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Makes every read of the secret, in every environment and by anyone including its owner, wait for approvals by that many other users who can read it. Approvals are collected for window_secs (default 1 hour, at most 7 days); the approved read is single-use and must happen within read_ttl_secs (default 15 minutes, at most 1 day). Needs share on the secret; audited as set_quorum.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Two-Person Rule"
+                ],
+                "summary": "Put a secret under the two-person rule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret path",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Rule",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.setQuorumRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.quorumResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Access denied",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Secret not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lets permitted users read the secret without approvals again. Needs share on the secret; audited as remove_quorum.",
+                "tags": [
+                    "Two-Person Rule"
+                ],
+                "summary": "Lift the two-person rule from a secret",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret path",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Rule removed"
+                    },
+                    "403": {
+                        "description": "Access denied",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Secret not found or not under the rule",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rotation/{path}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the rotation schedule, generator and last outcome for the secret in the given environment.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rotation"
+                ],
+                "summary": "Get the rotation policy of a secret",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret path",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Environment (defaults to default)",
+                        "name": "env",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.rotationPolicyResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Access denied",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Secret or policy not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Rotates the secret on a fixed interval or a cron schedule. The new value is either a generated password or the credential returned by a webhook (POST, JSON response with a value field) or an executable from the server's hooks directory. Only the secret's owner can set a policy.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rotation"
+                ],
+                "summary": "Set the rotation policy of a secret",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret path",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Environment (defaults to default)",
+                        "name": "env",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Rotation policy",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.setRotationPolicyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.rotationPolicyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid schedule, password policy or hook",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not the owner",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Secret not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Runs the secret's rotation policy immediately. If the generator or hook fails no version is created and the error is returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rotation"
+                ],
+                "summary": "Rotate a secret now",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret path",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Environment (defaults to default)",
+                        "name": "env",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.rotateSecretResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Access denied",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Secret or policy not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Rotation already in progress",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Rotation hook failed",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stops scheduled rotation of the secret in the given environment. Only the secret's owner can remove it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rotation"
+                ],
+                "summary": "Remove the rotation policy of a secret",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret path",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Environment (defaults to default)",
+                        "name": "env",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "403": {
+                        "description": "Not the owner",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Secret not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/secrets": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Encrypts and stores a secret with optional TTL, linked to the authenticated user, or to a team when the path is org/\u003corg\u003e/\u003cteam\u003e/\u003cname\u003e. The first value is stored in the given environment (default if omitted). The encrypted secret is signed with an HMAC signature to ensure integrity and prevent tampering.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Secrets"
+                ],
+                "summary": "Create a new secret",
+                "parameters": [
+                    {
+                        "description": "Secret creation request",
+                        "name": "secret",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.createSecretRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.secretResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/secrets/copy": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a new secret at the destination seeded with the current value of the source in every environment. History and sharing rules are not copied. Requires read access to the source and write access to the destination namespace. Secrets under the two-person rule cannot be copied.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Secrets"
+                ],
+                "summary": "Copy a secret",
+                "parameters": [
+                    {
+                        "description": "Source and destination paths",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.moveSecretRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.copySecretResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid path",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid HMAC on the source",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "No read access, destination not writable or source under the two-person rule",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Secret not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "A secret already exists at the destination",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/secrets/move": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Changes the path of a secret, keeping every version in every environment, its sharing rules, its break-glass rule and pending access requests for it. Moving needs read and delete on the secret. The secret keeps its owner; it can go to a team the caller can write to, and only its owner can move it into their own namespace.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Secrets"
+                ],
+                "summary": "Move or rename a secret",
+                "parameters": [
+                    {
+                        "description": "Source and destination paths",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.moveSecretRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.moveSecretResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid path",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "No read and delete on the secret or destination not writable",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Secret not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "A secret already exists at the destination",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/secrets/move-prefix": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Moves every secret under from_prefix to the same relative path under to_prefix in a single transaction. Folder shares, break-glass rules and pending access requests on the folder and below follow it. Fails as a whole if any secret is not owned by the caller or any destination is taken.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Secrets"
+                ],
+                "summary": "Move a folder of secrets",
+                "parameters": [
+                    {
+                        "description": "Source and destination prefixes",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.movePrefixRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.movePrefixResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid prefix",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not the owner of every secret or destination not writable",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No secrets under the prefix",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "A secret already exists at a destination",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/secrets/promote/{path}": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Copies a version (latest by default) of the secret from one environment into the next one in the promotion order, re-encrypting and re-signing it. The source HMAC is verified first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Environments"
+                ],
+                "summary": "Promote a secret version to the next environment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret path",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Promotion request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.promoteSecretRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.promoteSecretResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Unknown environment or invalid promotion",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid HMAC on the source version",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Access denied",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Source version not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/secrets/share": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Allows a user to share their secret with another user (target_email) or a group (target_group), specifying access permission and optional TTL. Verifies ownership before proceeding. A path ending in /* shares every secret below that folder, including secrets created later; it must be in the caller's own namespace or a team they maintain. The most specific rule wins, so a share of one secret overrides the share of its folder.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Secrets"
+                ],
+                "summary": "Share a secret with another user or a group",
+                "parameters": [
+                    {
+                        "description": "Secret share request payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.shareSecretRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.shareSecretResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input or sharing with self",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized: missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: not the secret or folder owner",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Secret or target user not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Secret or folder already shared with target user",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error during sharing",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/secrets/{path}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Fetches and decrypts the secret in the requested environment. If version is not specified, retrieves the latest. Verifies HMAC to ensure integrity. With resolve=true, references to other secrets are expanded subject to read access on each referenced path. A secret under the two-person rule is only returned once the caller holds an approved read, which the call uses up once the value has been read; until then the call opens or returns the pending quorum request with status 202.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Secrets"
+                ],
+                "summary": "Retrieve a secret by path and optional version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret path",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Environment (defaults to default)",
+                        "name": "env",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Secret version (optional)",
+                        "name": "version",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Expand references to other secrets in the value",
+                        "name": "resolve",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Block until the latest version is at least this (long-polling)",
+                        "name": "wait_for_version",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "How long to wait for wait_for_version, e.g. 30s (max 5m)",
+                        "name": "timeout",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.getSecretResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "The secret is under the two-person rule and the read awaits approvals",
+                        "schema": {
+                            "$ref": "#/definitions/api.quorumRequestResponse"
+                        }
+                    },
+                    "304": {
+                        "description": "wait_for_version was not reached before the timeout"
+                    },
+                    "401": {
+                        "description": "Unauthorized or HMAC verification failed",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Access denied to a referenced secret",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Secret not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The approved read was used by a concurrent request",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Reference cycle, depth limit or missing key",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Encrypts new secret value, verifies existing HMAC to prevent tampering, then creates a new secret version signed with a fresh HMAC. Writing to an environment the secret has no value in yet starts its version history there.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Secrets"
+                ],
+                "summary": "Update an existing secret by creating a new version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret path",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Environment (defaults to default)",
+                        "name": "env",
+                        "in": "query"
+                    },
+                    {
+                        "description": "New secret value",
+                        "name": "updateSecretRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.updateSecretRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.updateSecretResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized or HMAC verification failed",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Secret not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/secrets/{path}/rollback": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Reverts a secret to a previous version by duplicating the selected version with a new version number. Verifies HMAC before proceeding. Rollbacks stay within the requested environment.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Secrets"
+                ],
+                "summary": "Rollback secret to a previous version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret path",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Environment (defaults to default)",
+                        "name": "env",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Rollback secret request payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.rollbackSecretRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.rollbackSecretResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input or bad version",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized: invalid HMAC or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Secret version not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error during rollback",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/shared-with-me": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the active shares other users granted to the caller or to a group the caller is in, with owner, permission, expiry and creation time. Filter by path prefix and permission.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Secrets"
+                ],
+                "summary": "List secrets shared with me",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only shares of secrets under this folder",
+                        "name": "prefix",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only shares with this permission (read or write)",
+                        "name": "permission",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.sharedWithMeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid prefix or permission",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/shares/{path}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revokes the share of a secret with target_email or target_group, or with all=true every share of the secret. A path ending in /* revokes shares of that folder. Only the owner of the secret or folder can revoke its shares. Takes effect immediately.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Secrets"
+                ],
+                "summary": "Revoke shares",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret path",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User whose share to revoke",
+                        "name": "target_email",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Group whose share to revoke",
+                        "name": "target_group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Revoke every share of the secret",
+                        "name": "all",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.revokeSharesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Not exactly one of target_email, target_group and all=true given",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not the secret owner",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Secret or share not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Changes the permission and/or expiry of an active share. share_ttl_secs restarts the expiry from now and 0 removes it; leave it out to keep the current expiry. A path ending in /* changes the share of that folder. Only the owner of the secret or folder can change its shares. Takes effect immediately.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Secrets"
+                ],
+                "summary": "Change a share",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret path",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New permission and/or expiry",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.updateShareRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.shareRuleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input or nothing to change",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not the secret owner",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Secret or share not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sign-up": {
+            "post": {
+                "description": "Create a new user with hashed password",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Register a new user",
+                "parameters": [
+                    {
+                        "description": "User registration info",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.createUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.userResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sys/audit": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Audit logs of every user, with the filters of /audit plus user. Admins and security auditors only. Audit entries never contain secret values. Every search is itself audited as read_audit_logs.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "Search all audit logs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only entries of this user email",
+                        "name": "user",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Action filter (e.g., read_secret)",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resource path filter",
+                        "name": "path",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Resource version filter",
+                        "name": "version",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Success status filter (true/false)",
+                        "name": "success",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only entries flagged (true) or not flagged (false) for break-glass review",
+                        "name": "flagged",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date in RFC3339 or YYYY-MM-DD",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date in RFC3339 or YYYY-MM-DD",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit number of results (default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset for pagination (default 0)",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.getAuditLogsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameter",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an admin or auditor",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sys/break-glass": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists every use of break-glass access, newest first. With reviewed=false only those still awaiting post-incident review. Admins and auditors only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "List break-glass sessions",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Only reviewed (true) or unreviewed (false) sessions",
+                        "name": "reviewed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit number of results (default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset for pagination (default 0)",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.listBreakGlassSessionsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid reviewed value",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an admin or auditor",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sys/break-glass/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Shows a use of break-glass access with the audit entries flagged with it. Admins and auditors only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "Get a break-glass session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.breakGlassSessionDetailResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an admin or auditor",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sys/break-glass/{id}/review": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Signs off the post-incident review of a use of break-glass access with notes. Admins and auditors only, and never for their own sessions. Audited as review_break_glass.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "Review a break-glass session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Review notes",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.reviewBreakGlassRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.breakGlassSessionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an admin or auditor, or own session",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Already reviewed",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sys/cache/stats": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Hit, miss and eviction counts of the in-memory caches used for secret reads on this instance. Admins and auditors only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "Read cache statistics",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.cacheStatsResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an admin or auditor",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sys/hmac/rotate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replaces the active HMAC signing key right away instead of waiting for the daily rotation. Old keys keep verifying existing versions. Admins only; audited as rotate_hmac_key.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "Rotate the HMAC key",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.hmacKeyResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sys/status": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Counts of users, secrets, shares, groups, organizations and audit entries, the active HMAC key, configured environments, loaded policies and cache statistics of this instance. Admins only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "System status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.systemStatusResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sys/users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists every user with their system role. Admins only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "List users",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Limit number of results (default 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset for pagination (default 0)",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.listUsersResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sys/users/{email}/role": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Makes a user a regular user, a security auditor or an admin. The last admin cannot be demoted. Admins only; audited as set_user_role.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "Set a user's system role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User email",
+                        "name": "email",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.setUserRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.sysUserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid role or last admin",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/watch": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Opens a Server-Sent Events stream of version_changed, deleted and expired events for the given paths and/or every path under a prefix. Only events for secrets the caller can read at the time of the event are sent, and values are never included. A reset event means the watcher fell behind and events were lost; reconnect and re-read the secrets.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Secrets"
+                ],
+                "summary": "Watch secrets for changes",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Secret path to watch (repeatable)",
+                        "name": "path",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Watch every secret under this folder",
+                        "name": "prefix",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events for this environment",
+                        "name": "env",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/events.Event"
+                        }
+                    },
+                    "400": {
+                        "description": "No path or prefix, or an invalid one",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "List my webhook subscriptions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.webhookResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Registers a URL that receives a signed POST for every matching event on secrets under path_prefix (the caller's own namespace by default; team folders need read access). An empty events list subscribes to every type. Each request carries X-Vaultify-Signature: sha256=HMAC-SHA256(secret, timestamp + \".\" + body) and X-Vaultify-Timestamp. The signing secret is only returned in this response. URLs that resolve to loopback, private, link-local or other internal addresses are refused unless they are in WEBHOOK_ALLOWED_NETWORKS, and redirects are not followed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Subscribe to secret events",
+                "parameters": [
+                    {
+                        "description": "Receiver URL, path prefix and event types",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.createWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.createWebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid or internal URL, prefix or event type",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Prefix outside the caller's readable namespaces",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stops deliveries to the subscription and discards its delivery log.",
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Delete a webhook subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the most recent deliveries of the subscription with their status, attempts and last response.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Webhook delivery log",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of deliveries (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.webhookDeliveryResponse"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries/{delivery}/replay": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Queues a new delivery with the same payload, e.g. after a receiver outage. The payload keeps its event id so receivers can deduplicate. The caller must still be able to read the path the event is about.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Replay a webhook delivery",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Delivery ID",
+                        "name": "delivery",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/api.webhookDeliveryResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "No longer allowed to read the event's path",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook or delivery not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.swaggerErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.accessEntry": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "description": "Email is the user, or Group the group whose members have the access",
+                    "type": "string"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "permission": {
+                    "type": "string"
+                },
+                "role": {
+                    "description": "Role is the effective team role for team access",
+                    "type": "string"
+                },
+                "shared_path": {
+                    "description": "SharedPath is the folder share, e.g. org/acme/payments/*, that grants share access",
+                    "type": "string"
+                },
+                "shared_until": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.accessRequestResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "decided_at": {
+                    "type": "string"
+                },
+                "decided_by": {
+                    "type": "string"
+                },
+                "decision_reason": {
+                    "type": "string"
+                },
+                "duration_secs": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "justification": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "permission": {
+                    "type": "string"
+                },
+                "requester_email": {
+                    "type": "string"
+                },
+                "shared_until": {
+                    "description": "SharedUntil is when the share created by the approval ends",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.addGroupMemberRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "role"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "owner",
+                        "member"
+                    ]
+                }
+            }
+        },
+        "api.addOrgMemberRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "role"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "owner",
+                        "admin",
+                        "member"
+                    ]
+                }
+            }
+        },
+        "api.addTeamMemberRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "role"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "maintainer",
+                        "member",
+                        "viewer"
+                    ]
+                }
+            }
+        },
+        "api.auditLogResponse": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "break_glass_session_id": {
+                    "description": "BreakGlassSessionID flags entries written during break-glass access for review",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "pointer avoids issues with NullString",
+                    "type": "string"
+                },
+                "resource_path": {
+                    "type": "string"
+                },
+                "resource_version": {
+                    "type": "integer"
+                },
+                "success": {
+                    "type": "boolean"
+                },
+                "user_email": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.breakGlassRequest": {
+            "type": "object",
+            "required": [
+                "path",
+                "reason"
+            ],
+            "properties": {
+                "duration_secs": {
+                    "description": "DurationSecs shortens the access below the maximum of the rule",
+                    "type": "integer",
+                    "minimum": 60
+                },
+                "path": {
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "Reason is mandatory and sent to the owner and security",
+                    "type": "string"
+                }
+            }
+        },
+        "api.breakGlassRuleResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "max_duration_secs": {
+                    "type": "integer"
+                },
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.breakGlassSessionDetailResponse": {
+            "type": "object",
+            "properties": {
+                "audit_logs": {
+                    "description": "AuditLogs are the entries flagged with the session",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.auditLogResponse"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "review_notes": {
+                    "type": "string"
+                },
+                "reviewed_at": {
+                    "type": "string"
+                },
+                "reviewed_by": {
+                    "type": "string"
+                },
+                "user_email": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.breakGlassSessionResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "review_notes": {
+                    "type": "string"
+                },
+                "reviewed_at": {
+                    "type": "string"
+                },
+                "reviewed_by": {
+                    "type": "string"
+                },
+                "user_email": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.cacheStatsResponse": {
+            "type": "object",
+            "properties": {
+                "hmac_keys": {
+                    "$ref": "#/definitions/cache.Stats"
+                },
+                "read_access": {
+                    "$ref": "#/definitions/cache.Stats"
+                },
+                "secrets": {
+                    "$ref": "#/definitions/cache.Stats"
+                }
+            }
+        },
+        "api.capabilitiesResponse": {
+            "type": "object",
+            "properties": {
+                "capabilities": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "path": {
+                    "type": "string"
+                },
+                "policies": {
+                    "description": "Policies names the policies attached to the caller that have a rule matching the path",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "token_policies": {
+                    "description": "TokenPolicies are the policies the caller's token is limited to, if any",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api.copySecretResponse": {
+            "type": "object",
+            "properties": {
+                "environments": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "from": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.createAccessRequestRequest": {
+            "type": "object",
+            "required": [
+                "duration_secs",
+                "justification",
+                "path",
+                "permission"
+            ],
+            "properties": {
+                "duration_secs": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "justification": {
+                    "type": "string",
+                    "maxLength": 1000
+                },
+                "path": {
+                    "description": "Path is a secret, or a folder ending in /*",
+                    "type": "string"
+                },
+                "permission": {
+                    "type": "string",
+                    "enum": [
+                        "read",
+                        "write"
+                    ]
+                }
+            }
+        },
+        "api.createGroupRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "slug"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.createOrganizationRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "slug"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.createSecretRequest": {
+            "type": "object",
+            "required": [
+                "path",
+                "value"
+            ],
+            "properties": {
+                "environment": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "ttl_seconds": {
+                    "type": "integer"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.createTeamRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "slug"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.createUserRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "name",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string",
+                    "minLength": 6
+                }
+            }
+        },
+        "api.createWebhookRequest": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "path_prefix": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.createWebhookResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "path_prefix": {
+                    "type": "string"
+                },
+                "secret": {
+                    "description": "Secret signs every delivery. It is only returned once, when the subscription is created.",
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.decideAccessRequestRequest": {
+            "type": "object",
+            "properties": {
+                "duration_secs": {
+                    "description": "DurationSecs shortens the requested duration when approving. Omitted keeps it.",
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "reason": {
+                    "type": "string",
+                    "maxLength": 1000
+                }
+            }
+        },
+        "api.driftReportResponse": {
+            "type": "object",
+            "properties": {
+                "environments": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "secrets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.secretDrift"
+                    }
+                }
+            }
+        },
+        "api.environmentDrift": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.expiringSecretResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.expiringShareResponse": {
+            "type": "object",
+            "properties": {
+                "owner_email": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "permission": {
+                    "type": "string"
+                },
+                "shared_until": {
+                    "type": "string"
+                },
+                "target_email": {
+                    "type": "string"
+                },
+                "target_group": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.getAuditLogsResponse": {
+            "type": "object",
+            "properties": {
+                "logs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.auditLogResponse"
+                    }
+                }
+            }
+        },
+        "api.getSecretResponse": {
+            "type": "object",
+            "properties": {
+                "decrypted_value": {
+                    "type": "string"
+                },
+                "environment": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.groupResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.hmacKeyResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.listAccessRequestsResponse": {
+            "type": "object",
+            "properties": {
+                "requests": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.accessRequestResponse"
+                    }
+                }
+            }
+        },
+        "api.listBreakGlassRulesResponse": {
+            "type": "object",
+            "properties": {
+                "rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.breakGlassRuleResponse"
+                    }
+                }
+            }
+        },
+        "api.listBreakGlassSessionsResponse": {
+            "type": "object",
+            "properties": {
+                "sessions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.breakGlassSessionResponse"
+                    }
+                }
+            }
+        },
+        "api.listEnvironmentsResponse": {
+            "type": "object",
+            "properties": {
+                "default": {
+                    "type": "string"
+                },
+                "promotion_order": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api.listExpiringResponse": {
+            "type": "object",
+            "properties": {
+                "before": {
+                    "type": "string"
+                },
+                "secrets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.expiringSecretResponse"
+                    }
+                },
+                "shares": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.expiringShareResponse"
+                    }
+                }
+            }
+        },
+        "api.listQuorumRequestsResponse": {
+            "type": "object",
+            "properties": {
+                "requests": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.quorumRequestResponse"
+                    }
+                }
+            }
+        },
+        "api.listSecretsResponse": {
+            "type": "object",
+            "properties": {
+                "environment": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "secrets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.secretListItem"
+                    }
+                }
+            }
+        },
+        "api.listUsersResponse": {
+            "type": "object",
+            "properties": {
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.sysUserResponse"
+                    }
+                }
+            }
+        },
+        "api.loginUserRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string",
+                    "minLength": 6
+                },
+                "policies": {
+                    "description": "Policies limits the issued token to what the named policies grant, e.g. for CI jobs",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api.loginUserResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/api.userResponse"
+                }
+            }
+        },
+        "api.memberResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.movePrefixRequest": {
+            "type": "object",
+            "required": [
+                "from_prefix",
+                "to_prefix"
+            ],
+            "properties": {
+                "from_prefix": {
+                    "type": "string"
+                },
+                "to_prefix": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.movePrefixResponse": {
+            "type": "object",
+            "properties": {
+                "from_prefix": {
+                    "type": "string"
+                },
+                "moved": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.moveSecretResponse"
+                    }
+                },
+                "to_prefix": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.moveSecretRequest": {
+            "type": "object",
+            "required": [
+                "from",
+                "to"
+            ],
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.moveSecretResponse": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.organizationResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.promoteSecretRequest": {
+            "type": "object",
+            "required": [
+                "from"
+            ],
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.promoteSecretResponse": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "from_version": {
+                    "type": "integer"
+                },
+                "new_version": {
+                    "type": "integer"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.quorumRequestResponse": {
+            "type": "object",
+            "properties": {
+                "approvals": {
+                    "description": "Approvals lists who approved so far",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "consumed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "environment": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "granted_until": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "requester_email": {
+                    "type": "string"
+                },
+                "required_approvals": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.quorumResponse": {
+            "type": "object",
+            "properties": {
+                "approvals": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "read_ttl_secs": {
+                    "type": "integer"
+                },
+                "window_secs": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.reviewBreakGlassRequest": {
+            "type": "object",
+            "required": [
+                "notes"
+            ],
+            "properties": {
+                "notes": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.revokeSharesResponse": {
+            "type": "object",
+            "properties": {
+                "path": {
+                    "type": "string"
+                },
+                "revoked": {
+                    "description": "Revoked lists the users, and groups as group:\u003cslug\u003e, that lost their share",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api.rollbackSecretRequest": {
+            "type": "object",
+            "required": [
+                "version"
+            ],
+            "properties": {
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.rollbackSecretResponse": {
+            "type": "object",
+            "properties": {
+                "encrypted_value": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "existing_version": {
+                    "type": "integer"
+                },
+                "new_version": {
+                    "type": "integer"
+                },
+                "nonce": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "path": {
+                    "type": "string"
+                },
+                "to_version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.rotateSecretResponse": {
+            "type": "object",
+            "properties": {
+                "environment": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.rotationPolicyResponse": {
+            "type": "object",
+            "properties": {
+                "cron": {
+                    "type": "string"
+                },
+                "environment": {
+                    "type": "string"
+                },
+                "failure_count": {
+                    "type": "integer"
+                },
+                "generator": {
+                    "type": "string"
+                },
+                "hook_target": {
+                    "type": "string"
+                },
+                "interval_seconds": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_rotated_at": {
+                    "type": "string"
+                },
+                "next_rotation_at": {
+                    "type": "string"
+                },
+                "password_length": {
+                    "type": "integer"
+                },
+                "password_symbols": {
+                    "type": "boolean"
+                },
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.secretAccessResponse": {
+            "type": "object",
+            "properties": {
+                "access": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.accessEntry"
+                    }
+                },
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.secretDrift": {
+            "type": "object",
+            "properties": {
+                "environments": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/api.environmentDrift"
+                    }
+                },
+                "in_sync": {
+                    "type": "boolean"
+                },
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.secretHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "environment": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "versions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.secretVersionItem"
+                    }
+                }
+            }
+        },
+        "api.secretListItem": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.secretResponse": {
+            "type": "object",
+            "properties": {
+                "encrypted_value": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "environment": {
+                    "type": "string"
+                },
+                "nonce": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.secretVersionItem": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.setBreakGlassRuleRequest": {
+            "type": "object",
+            "required": [
+                "group"
+            ],
+            "properties": {
+                "group": {
+                    "description": "Group is the slug of the emergency group whose members may break the glass",
+                    "type": "string"
+                },
+                "max_duration_secs": {
+                    "description": "MaxDurationSecs caps the access of each use, 1 hour by default and at most 4 hours",
+                    "type": "integer",
+                    "minimum": 60
+                }
+            }
+        },
+        "api.setQuorumRequest": {
+            "type": "object",
+            "required": [
+                "approvals"
+            ],
+            "properties": {
+                "approvals": {
+                    "type": "integer",
+                    "maximum": 10,
+                    "minimum": 1
+                },
+                "read_ttl_secs": {
+                    "description": "ReadTTLSecs is how long the approved read stays usable, 15 minutes by default",
+                    "type": "integer",
+                    "minimum": 10
+                },
+                "window_secs": {
+                    "description": "WindowSecs is how long approvals are collected, 1 hour by default",
+                    "type": "integer",
+                    "minimum": 60
+                }
+            }
+        },
+        "api.setRotationPolicyRequest": {
+            "type": "object",
+            "required": [
+                "generator"
+            ],
+            "properties": {
+                "cron": {
+                    "type": "string"
+                },
+                "generator": {
+                    "type": "string",
+                    "enum": [
+                        "password",
+                        "webhook",
+                        "exec"
+                    ]
+                },
+                "hook_name": {
+                    "type": "string"
+                },
+                "hook_url": {
+                    "type": "string"
+                },
+                "interval_seconds": {
+                    "type": "integer"
+                },
+                "password_length": {
+                    "type": "integer"
+                },
+                "password_symbols": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "api.setUserRoleRequest": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "user",
+                        "auditor",
+                        "admin"
+                    ]
+                }
+            }
+        },
+        "api.shareRuleResponse": {
             "type": "object",
             "properties": {
-                "action": {
-                    "type": "string"
-                },
                 "created_at": {
                     "type": "string"
                 },
-                "id": {
+                "owner_email": {
                     "type": "string"
                 },
-                "reason": {
-                    "description": "pointer avoids issues with NullString",
+                "path": {
                     "type": "string"
                 },
-                "resource_path": {
+                "permission": {
                     "type": "string"
                 },
-                "resource_version": {
-                    "type": "integer"
+                "shared_until": {
+                    "type": "string"
                 },
-                "success": {
-                    "type": "boolean"
+                "target_email": {
+                    "type": "string"
                 },
-                "user_email": {
+                "target_group": {
                     "type": "string"
                 }
             }
         },
-        "api.createSecretRequest": {
+        "api.shareSecretRequest": {
             "type": "object",
             "required": [
                 "path",
-                "value"
+                "permission"
             ],
             "properties": {
                 "path": {
                     "type": "string"
                 },
-                "ttl_seconds": {
+                "permission": {
+                    "type": "string",
+                    "enum": [
+                        "read",
+                        "write"
+                    ]
+                },
+                "share_ttl_secs": {
                     "type": "integer"
                 },
-                "value": {
+                "target_email": {
+                    "description": "the share is for either a user or a group",
+                    "type": "string"
+                },
+                "target_group": {
                     "type": "string"
                 }
             }
         },
-        "api.createUserRequest": {
+        "api.shareSecretResponse": {
             "type": "object",
-            "required": [
-                "email",
-                "name",
-                "password"
-            ],
             "properties": {
-                "email": {
+                "owner_email": {
                     "type": "string"
                 },
-                "name": {
+                "path": {
                     "type": "string"
                 },
-                "password": {
-                    "type": "string",
-                    "minLength": 6
+                "permission": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                },
+                "target_email": {
+                    "type": "string"
+                },
+                "target_group": {
+                    "type": "string"
                 }
             }
         },
-        "api.getAuditLogsResponse": {
+        "api.sharedWithMeResponse": {
             "type": "object",
             "properties": {
-                "logs": {
+                "permission": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "shares": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/api.auditLogResponse"
+                        "$ref": "#/definitions/api.shareRuleResponse"
                     }
                 }
             }
         },
-        "api.getSecretResponse": {
+        "api.swaggerErrorResponse": {
             "type": "object",
             "properties": {
-                "decrypted_value": {
-                    "type": "string"
-                },
-                "path": {
+                "error": {
                     "type": "string"
-                },
-                "version": {
-                    "type": "integer"
                 }
             }
         },
-        "api.loginUserRequest": {
+        "api.sysUserResponse": {
             "type": "object",
-            "required": [
-                "email",
-                "password"
-            ],
             "properties": {
+                "created_at": {
+                    "type": "string"
+                },
                 "email": {
                     "type": "string"
                 },
-                "password": {
-                    "type": "string",
-                    "minLength": 6
-                }
-            }
-        },
-        "api.loginUserResponse": {
-            "type": "object",
-            "properties": {
-                "access_token": {
+                "name": {
                     "type": "string"
                 },
-                "user": {
-                    "$ref": "#/definitions/api.userResponse"
+                "role": {
+                    "type": "string"
                 }
             }
         },
-        "api.rollbackSecretRequest": {
+        "api.systemCounts": {
             "type": "object",
-            "required": [
-                "version"
-            ],
             "properties": {
-                "version": {
+                "audit_logs": {
+                    "type": "integer"
+                },
+                "groups": {
+                    "type": "integer"
+                },
+                "organizations": {
+                    "type": "integer"
+                },
+                "secret_versions": {
+                    "type": "integer"
+                },
+                "secrets": {
+                    "type": "integer"
+                },
+                "sharing_rules": {
+                    "type": "integer"
+                },
+                "users": {
                     "type": "integer"
                 }
             }
         },
-        "api.rollbackSecretResponse": {
+        "api.systemStatusResponse": {
             "type": "object",
             "properties": {
-                "encrypted_value": {
+                "cache": {
+                    "$ref": "#/definitions/api.cacheStatsResponse"
+                },
+                "counts": {
+                    "$ref": "#/definitions/api.systemCounts"
+                },
+                "environments": {
                     "type": "array",
                     "items": {
-                        "type": "integer"
+                        "type": "string"
                     }
                 },
-                "existing_version": {
-                    "type": "integer"
+                "grpc": {
+                    "type": "boolean"
                 },
-                "new_version": {
-                    "type": "integer"
+                "hmac_key": {
+                    "$ref": "#/definitions/api.hmacKeyResponse"
                 },
-                "nonce": {
+                "policies": {
                     "type": "array",
                     "items": {
-                        "type": "integer"
+                        "type": "string"
                     }
+                }
+            }
+        },
+        "api.teamResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
                 },
-                "path": {
+                "name": {
                     "type": "string"
                 },
-                "to_version": {
-                    "type": "integer"
+                "namespace": {
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
                 }
             }
         },
-        "api.secretResponse": {
+        "api.updateSecretRequest": {
+            "type": "object",
+            "required": [
+                "value"
+            ],
+            "properties": {
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.updateSecretResponse": {
             "type": "object",
             "properties": {
                 "encrypted_value": {
@@ -726,6 +5837,9 @@ const docTemplate = `{
                         "type": "integer"
                     }
                 },
+                "environment": {
+                    "type": "string"
+                },
                 "nonce": {
                     "type": "array",
                     "items": {
@@ -734,20 +5848,15 @@ const docTemplate = `{
                 },
                 "path": {
                     "type": "string"
+                },
+                "version": {
+                    "type": "integer"
                 }
             }
         },
-        "api.shareSecretRequest": {
+        "api.updateShareRequest": {
             "type": "object",
-            "required": [
-                "path",
-                "permission",
-                "target_email"
-            ],
             "properties": {
-                "path": {
-                    "type": "string"
-                },
                 "permission": {
                     "type": "string",
                     "enum": [
@@ -756,88 +5865,154 @@ const docTemplate = `{
                     ]
                 },
                 "share_ttl_secs": {
-                    "type": "integer"
+                    "description": "ShareTTLSecs restarts the expiry from now, 0 removes it. Omitted keeps the current expiry.",
+                    "type": "integer",
+                    "minimum": 0
                 },
                 "target_email": {
+                    "description": "the share to change is that of either a user or a group",
+                    "type": "string"
+                },
+                "target_group": {
                     "type": "string"
                 }
             }
         },
-        "api.shareSecretResponse": {
+        "api.userResponse": {
             "type": "object",
             "properties": {
-                "owner_email": {
+                "created_at": {
                     "type": "string"
                 },
-                "path": {
+                "email": {
                     "type": "string"
                 },
-                "permission": {
+                "name": {
                     "type": "string"
                 },
-                "success": {
-                    "type": "boolean"
-                },
-                "target_email": {
+                "role": {
                     "type": "string"
                 }
             }
         },
-        "api.swaggerErrorResponse": {
+        "api.webhookDeliveryResponse": {
             "type": "object",
             "properties": {
-                "error": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_status_code": {
+                    "type": "integer"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "status": {
                     "type": "string"
                 }
             }
         },
-        "api.updateSecretRequest": {
+        "api.webhookResponse": {
             "type": "object",
-            "required": [
-                "value"
-            ],
             "properties": {
-                "value": {
+                "created_at": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "path_prefix": {
+                    "type": "string"
+                },
+                "url": {
                     "type": "string"
                 }
             }
         },
-        "api.updateSecretResponse": {
+        "cache.Stats": {
             "type": "object",
             "properties": {
-                "encrypted_value": {
-                    "type": "array",
-                    "items": {
-                        "type": "integer"
-                    }
+                "capacity": {
+                    "type": "integer"
                 },
-                "nonce": {
-                    "type": "array",
-                    "items": {
-                        "type": "integer"
-                    }
+                "evictions": {
+                    "type": "integer"
                 },
-                "path": {
-                    "type": "string"
+                "hits": {
+                    "type": "integer"
                 },
-                "version": {
+                "misses": {
+                    "type": "integer"
+                },
+                "size": {
                     "type": "integer"
                 }
             }
         },
-        "api.userResponse": {
+        "events.Event": {
             "type": "object",
             "properties": {
-                "created_at": {
+                "at": {
                     "type": "string"
                 },
-                "email": {
+                "environment": {
                     "type": "string"
                 },
-                "name": {
+                "path": {
                     "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/events.Type"
+                },
+                "version": {
+                    "type": "integer"
                 }
             }
+        },
+        "events.Type": {
+            "type": "string",
+            "enum": [
+                "version_changed",
+                "deleted",
+                "expired",
+                "share_changed",
+                "membership_changed",
+                "hmac_key_rotated",
+                "reset"
+            ],
+            "x-enum-varnames": [
+                "VersionChanged",
+                "Deleted",
+                "Expired",
+                "ShareChanged",
+                "MembershipChanged",
+                "HMACKeyRotated",
+                "Reset"
+            ]
         }
     },
     "securityDefinitions": {
@@ -0,0 +1,718 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/pixperk/vaultify/internal/auth"
+	db "github.com/pixperk/vaultify/internal/db/sqlc"
+	"github.com/pixperk/vaultify/internal/notify"
+	"github.com/pixperk/vaultify/internal/policy"
+	"github.com/pixperk/vaultify/internal/secretpath"
+)
+
+// access request states
+const (
+	accessRequestPending   = "pending"
+	accessRequestApproved  = "approved"
+	accessRequestDenied    = "denied"
+	accessRequestCancelled = "cancelled"
+)
+
+// maxAccessDuration caps how long an approved request grants access
+const maxAccessDuration = 90 * 24 * time.Hour
+
+type createAccessRequestRequest struct {
+	// Path is a secret, or a folder ending in /*
+	Path          string `json:"path" binding:"required"`
+	Permission    string `json:"permission" binding:"required,oneof=read write"`
+	Justification string `json:"justification" binding:"required,max=1000"`
+	DurationSecs  int64  `json:"duration_secs" binding:"required,min=1"`
+}
+
+type decideAccessRequestRequest struct {
+	Reason string `json:"reason" binding:"max=1000"`
+	// DurationSecs shortens the requested duration when approving. Omitted keeps it.
+	DurationSecs int64 `json:"duration_secs" binding:"omitempty,min=1"`
+}
+
+type accessRequestResponse struct {
+	ID             uuid.UUID  `json:"id"`
+	RequesterEmail string     `json:"requester_email"`
+	Path           string     `json:"path"`
+	Permission     string     `json:"permission"`
+	Justification  string     `json:"justification"`
+	DurationSecs   int64      `json:"duration_secs"`
+	Status         string     `json:"status"`
+	DecidedBy      string     `json:"decided_by,omitempty"`
+	DecisionReason string     `json:"decision_reason,omitempty"`
+	DecidedAt      *time.Time `json:"decided_at,omitempty"`
+	// SharedUntil is when the share created by the approval ends
+	SharedUntil *time.Time `json:"shared_until,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type listAccessRequestsResponse struct {
+	Requests []accessRequestResponse `json:"requests"`
+}
+
+func newAccessRequestResponse(request db.AccessRequests) accessRequestResponse {
+	resp := accessRequestResponse{
+		ID:             request.ID,
+		RequesterEmail: request.RequesterEmail,
+		Path:           sharedPath(request.Path, request.IsPrefix),
+		Permission:     request.Permission,
+		Justification:  request.Justification,
+		DurationSecs:   request.DurationSecs,
+		Status:         request.Status,
+		DecidedBy:      request.DecidedBy.String,
+		DecisionReason: request.DecisionReason.String,
+		CreatedAt:      request.CreatedAt,
+	}
+	if request.DecidedAt.Valid {
+		resp.DecidedAt = &request.DecidedAt.Time
+		if request.Status == accessRequestApproved {
+			until := request.DecidedAt.Time.Add(time.Duration(request.DurationSecs) * time.Second)
+			resp.SharedUntil = &until
+		}
+	}
+	return resp
+}
+
+func newListAccessRequestsResponse(requests []db.AccessRequests) listAccessRequestsResponse {
+	resp := listAccessRequestsResponse{Requests: make([]accessRequestResponse, 0, len(requests))}
+	for _, request := range requests {
+		resp.Requests = append(resp.Requests, newAccessRequestResponse(request))
+	}
+	return resp
+}
+
+// requestTarget resolves rawPath to the secret, or with a trailing /* the folder, access is
+// requested to, along with who owns it
+func (s *Server) requestTarget(ctx context.Context, rawPath string) (path string, prefix bool, owner db.GetLatestSecretByPathRow, err error) {
+	if folder, ok := strings.CutSuffix(strings.TrimSpace(rawPath), folderShareSuffix); ok {
+		if path, err = secretpath.Normalize(folder); err != nil {
+			return "", false, owner, newStatusError(http.StatusBadRequest, "%s", err)
+		}
+		owner, err = s.namespaceOwnership(ctx, path)
+		return path, true, owner, err
+	}
+
+	if path, err = secretpath.Normalize(rawPath); err != nil {
+		return "", false, owner, newStatusError(http.StatusBadRequest, "%s", err)
+	}
+	owner, err = s.loadSecret(ctx, path, defaultEnvironment)
+	if err == sql.ErrNoRows {
+		return path, false, owner, newStatusError(http.StatusNotFound, "the secret does not exist")
+	}
+	return path, false, owner, err
+}
+
+// namespaceOwnership describes who owns the namespace folder is in: the team for team folders,
+// otherwise the user the namespace is named after. Folders nobody owns cannot be requested.
+func (s *Server) namespaceOwnership(ctx context.Context, folder string) (db.GetLatestSecretByPathRow, error) {
+	owner := db.GetLatestSecretByPathRow{Path: folder}
+	notFound := newStatusError(http.StatusNotFound, "nobody owns %s", folder)
+
+	if orgSlug, teamSlug, ok := teamPrefixNamespace(folder + "/"); ok {
+		team, err := s.store.GetTeamBySlugs(ctx, db.GetTeamBySlugsParams{Slug: orgSlug, Slug_2: teamSlug})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return owner, notFound
+			}
+			return owner, err
+		}
+		owner.TeamID = uuid.NullUUID{UUID: team.ID, Valid: true}
+		return owner, nil
+	}
+
+	namespace, _, _ := strings.Cut(folder, "/")
+	user, err := s.store.GetUserByEmail(ctx, namespace)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return owner, notFound
+		}
+		return owner, err
+	}
+	owner.UserID = user.ID
+	return owner, nil
+}
+
+// capabilityHolders lists the users who hold want on path: the owner, or the members of the
+// owning team whose role grants it, users and group members shared the path with a permission
+// that grants it, and those a policy grants it to. Deny rules and token scopes are not considered,
+// so the list is meant for notifications; decisions still go through the authorizer.
+func (s *Server) capabilityHolders(ctx context.Context, path string, owner db.GetLatestSecretByPathRow, want policy.Capability) ([]string, error) {
+	var emails []string
+	add := func(email string) {
+		if !slices.Contains(emails, email) {
+			emails = append(emails, email)
+		}
+	}
+	addGroup := func(groupID uuid.UUID) error {
+		members, err := s.store.ListGroupMembers(ctx, groupID)
+		if err != nil {
+			return err
+		}
+		for _, member := range members {
+			add(member.Email)
+		}
+		return nil
+	}
+
+	if owner.TeamID.Valid {
+		members, err := s.store.ListTeamAccess(ctx, owner.TeamID.UUID)
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range members {
+			if teamCapabilities(effectiveTeamRole(member.OrgRole, member.TeamRole)).Has(want) {
+				add(member.Email)
+			}
+		}
+	} else {
+		user, err := s.store.GetUserByID(ctx, owner.UserID)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+		if err == nil {
+			add(user.Email)
+		}
+	}
+
+	if shareCapabilities("write").Has(want) {
+		rules, err := s.store.GetSharedWith(ctx, db.GetSharedWithParams{
+			Path:    path,
+			Parents: secretpath.Parents(path),
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, rule := range rules {
+			if !shareCapabilities(rule.Permission).Has(want) {
+				continue
+			}
+			if rule.TargetGroupID.Valid {
+				if err := addGroup(rule.TargetGroupID.UUID); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			add(rule.TargetEmail)
+		}
+	}
+
+	users, groups := s.policies.Grantees(path, want)
+	for _, email := range users {
+		add(email)
+	}
+	for _, slug := range groups {
+		group, err := s.store.GetGroupBySlug(ctx, slug)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				continue
+			}
+			return nil, err
+		}
+		if err := addGroup(group.ID); err != nil {
+			return nil, err
+		}
+	}
+	return emails, nil
+}
+
+// @Summary      Request access to a secret
+// @Description  Asks for read or write access to a secret, or with a path ending in /* to a folder, for a limited time. The justification is shown to the approvers: the owner or the maintainers of the owning team, and anyone a policy grants share on the path. They are notified and can approve or deny the request; approving creates a share that ends after duration_secs (at most 90 days). Audited as request_access.
+// @Tags         Access Requests
+// @Accept       json
+// @Produce      json
+// @Param        request  body     createAccessRequestRequest  true  "Access request"
+// @Success      201      {object} accessRequestResponse
+// @Failure      400      {object} swaggerErrorResponse "Invalid input"
+// @Failure      404      {object} swaggerErrorResponse "Secret or folder owner not found"
+// @Failure      409      {object} swaggerErrorResponse "Access already held or already requested"
+// @Failure      500      {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /access-requests [post]
+func (s *Server) createAccessRequest(ctx *gin.Context) {
+	var req createAccessRequestRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if time.Duration(req.DurationSecs)*time.Second > maxAccessDuration {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("access can be requested for at most %s", maxAccessDuration)))
+		return
+	}
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	path, prefix, owner, err := s.requestTarget(ctx, req.Path)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+	caps, err := s.capabilities(ctx, authPayload, owner)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if caps.Has(shareCapabilities(req.Permission)) {
+		ctx.JSON(http.StatusConflict, errorResponse(fmt.Errorf("you already have %s access to %s", req.Permission, sharedPath(path, prefix))))
+		return
+	}
+
+	var request db.AccessRequests
+	err = s.store.ExecTx(ctx, func(q *db.Queries) error {
+		request, err = q.CreateAccessRequest(ctx, db.CreateAccessRequestParams{
+			RequesterID:    authPayload.UserID,
+			RequesterEmail: authPayload.Email,
+			Path:           path,
+			IsPrefix:       prefix,
+			Permission:     req.Permission,
+			Justification:  req.Justification,
+			DurationSecs:   req.DurationSecs,
+		})
+		if err != nil {
+			return err
+		}
+		detail := fmt.Sprintf("%s for %s: %s", req.Permission, time.Duration(req.DurationSecs)*time.Second, req.Justification)
+		if err := s.auditSvc.LogTx(ctx, q, authPayload.UserID, authPayload.Email, "request_access", sharedPath(path, prefix), owner.Version, true, &detail); err != nil {
+			return fmt.Errorf("failed to log action: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			ctx.JSON(http.StatusConflict, errorResponse(fmt.Errorf("you already have a pending request for %s", sharedPath(path, prefix))))
+			return
+		}
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	approvers, err := s.capabilityHolders(ctx, path, owner, policy.Share)
+	if err != nil {
+		log.Printf("Error listing approvers for %s: %v\n", path, err)
+	}
+	approvers = slices.DeleteFunc(approvers, func(email string) bool { return email == authPayload.Email })
+	s.notify(ctx, notify.Message{
+		Event:      "access_request.created",
+		Recipients: approvers,
+		Subject:    fmt.Sprintf("%s requests %s access to %s", authPayload.Email, req.Permission, sharedPath(path, prefix)),
+		Body: fmt.Sprintf("%s asks for %s access to %s for %s:\n\n%s\n\n"+
+			"Approve with POST /access-requests/%s/approve or deny with POST /access-requests/%s/deny.",
+			authPayload.Email, req.Permission, sharedPath(path, prefix), time.Duration(req.DurationSecs)*time.Second,
+			req.Justification, request.ID, request.ID),
+		Path: sharedPath(path, prefix),
+	})
+
+	ctx.JSON(http.StatusCreated, newAccessRequestResponse(request))
+}
+
+// @Summary      List my access requests
+// @Description  Lists the access requests made by the caller, newest first, optionally only those with a status.
+// @Tags         Access Requests
+// @Produce      json
+// @Param        status  query    string  false  "pending, approved, denied or cancelled"
+// @Param        limit   query    int     false  "Limit number of results (default 50)"
+// @Param        offset  query    int     false  "Offset for pagination (default 0)"
+// @Success      200     {object} listAccessRequestsResponse
+// @Failure      400     {object} swaggerErrorResponse "Invalid status"
+// @Failure      500     {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /access-requests [get]
+func (s *Server) listAccessRequests(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	status := ctx.Query("status")
+	switch status {
+	case "", accessRequestPending, accessRequestApproved, accessRequestDenied, accessRequestCancelled:
+	default:
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("invalid status %q", status)))
+		return
+	}
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 {
+		limit = 50
+	}
+	offset, err := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	requests, err := s.store.ListAccessRequestsByRequester(ctx, db.ListAccessRequestsByRequesterParams{
+		RequesterID: authPayload.UserID,
+		Status:      status,
+		Limit:       int32(limit),
+		Offset:      int32(offset),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, newListAccessRequestsResponse(requests))
+}
+
+// @Summary      List access requests awaiting me
+// @Description  Lists the pending access requests of other users that the caller can approve or deny, oldest first. Requests are looked up below the caller's namespace, the teams they maintain and the paths their policies grant share on, so a page may hold fewer than limit requests.
+// @Tags         Access Requests
+// @Produce      json
+// @Param        limit   query    int     false  "Limit number of results (default 50)"
+// @Param        offset  query    int     false  "Offset for pagination (default 0)"
+// @Success      200  {object} listAccessRequestsResponse
+// @Failure      500  {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /access-requests/pending [get]
+func (s *Server) listPendingAccessRequests(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 {
+		limit = 50
+	}
+	offset, err := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	sub, err := s.policySubject(ctx, authPayload)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	pending, err := s.store.ListPendingAccessRequestsForApprover(ctx, db.ListPendingAccessRequestsForApproverParams{
+		ApproverID: authPayload.UserID,
+		Namespace:  personalNamespace(authPayload.Email),
+		Prefixes:   s.policies.Prefixes(sub, policy.Share),
+		Limit:      int32(limit),
+		Offset:     int32(offset),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	var decidable []db.AccessRequests
+	for _, request := range pending {
+		if _, err := s.scopeForSharing(ctx, authPayload, sharedPath(request.Path, request.IsPrefix)); err != nil {
+			if errorStatus(err) == http.StatusInternalServerError {
+				ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+				return
+			}
+			continue
+		}
+		decidable = append(decidable, request)
+	}
+	ctx.JSON(http.StatusOK, newListAccessRequestsResponse(decidable))
+}
+
+// bindDecision binds the optional body of an approval or denial
+func bindDecision(ctx *gin.Context) (decideAccessRequestRequest, bool) {
+	var req decideAccessRequestRequest
+	if ctx.Request.ContentLength == 0 {
+		return req, true
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return req, false
+	}
+	return req, true
+}
+
+// accessRequest loads the request with the id rawID
+func (s *Server) accessRequest(ctx context.Context, rawID string) (db.AccessRequests, error) {
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		return db.AccessRequests{}, newStatusError(http.StatusBadRequest, "invalid access request id")
+	}
+	request, err := s.store.GetAccessRequest(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return request, newStatusError(http.StatusNotFound, "the access request does not exist")
+		}
+		return request, err
+	}
+	return request, nil
+}
+
+// checkPending fails for requests that were already decided or cancelled
+func checkPending(request db.AccessRequests) error {
+	if request.Status != accessRequestPending {
+		return newStatusError(http.StatusConflict, "the access request is already %s", request.Status)
+	}
+	return nil
+}
+
+// requestForDecision loads a pending request of another user and checks that the caller holds
+// share on its path, returning the scope the approval shares
+func (s *Server) requestForDecision(ctx context.Context, authPayload *auth.Payload, rawID string) (db.AccessRequests, shareScope, error) {
+	request, err := s.accessRequest(ctx, rawID)
+	if err != nil {
+		return request, shareScope{}, err
+	}
+	if request.RequesterID == authPayload.UserID {
+		return request, shareScope{}, newStatusError(http.StatusForbidden, "you cannot decide your own access request")
+	}
+	scope, err := s.scopeForSharing(ctx, authPayload, sharedPath(request.Path, request.IsPrefix))
+	if err != nil {
+		return request, scope, err
+	}
+	return request, scope, checkPending(request)
+}
+
+// requestOwnerEmail returns whom the share approving a request is granted on behalf of: the owner
+// of the secret or of the namespace the folder is in, like a break-glass share. Team paths are
+// shared by the approver, who acts for the team.
+func (s *Server) requestOwnerEmail(ctx context.Context, authPayload *auth.Payload, scope shareScope) (string, error) {
+	if scope.TeamID.Valid {
+		return authPayload.Email, nil
+	}
+
+	ownerID := scope.OwnerID
+	if scope.Prefix {
+		owner, err := s.namespaceOwnership(ctx, scope.Path)
+		if err != nil {
+			return "", err
+		}
+		ownerID = owner.UserID
+	}
+	owner, err := s.store.GetUserByID(ctx, ownerID)
+	if err != nil {
+		return "", err
+	}
+	return owner.Email, nil
+}
+
+// decideTx marks the request decided, failing when someone else decided it first
+func decideTx(ctx context.Context, q *db.Queries, arg db.DecideAccessRequestParams) (db.AccessRequests, error) {
+	request, err := q.DecideAccessRequest(ctx, arg)
+	if err == sql.ErrNoRows {
+		return request, newStatusError(http.StatusConflict, "the access request was decided in the meantime")
+	}
+	return request, err
+}
+
+// @Summary      Approve an access request
+// @Description  Shares the requested path with the requester for the requested duration, or a shorter duration_secs. The caller needs share on the path. Fails with 409 when the path is already shared with the requester; change that share instead. Audited as approve_access_request and share_secret, and the requester is notified.
+// @Tags         Access Requests
+// @Accept       json
+// @Produce      json
+// @Param        id       path     string                      true   "Access request ID"
+// @Param        request  body     decideAccessRequestRequest  false  "Reason and duration"
+// @Success      200      {object} accessRequestResponse
+// @Failure      400      {object} swaggerErrorResponse "Invalid input"
+// @Failure      403      {object} swaggerErrorResponse "Not an approver of the path"
+// @Failure      404      {object} swaggerErrorResponse "Request not found"
+// @Failure      409      {object} swaggerErrorResponse "Request already decided or path already shared"
+// @Failure      500      {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /access-requests/{id}/approve [post]
+func (s *Server) approveAccessRequest(ctx *gin.Context) {
+	req, ok := bindDecision(ctx)
+	if !ok {
+		return
+	}
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	request, scope, err := s.requestForDecision(ctx, authPayload, ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+	durationSecs := request.DurationSecs
+	if req.DurationSecs > 0 {
+		if req.DurationSecs > request.DurationSecs {
+			ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("the duration can only be shortened")))
+			return
+		}
+		durationSecs = req.DurationSecs
+	}
+	sharedUntil := time.Now().Add(time.Duration(durationSecs) * time.Second)
+
+	ownerEmail, err := s.requestOwnerEmail(ctx, authPayload, scope)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	var decided db.AccessRequests
+	err = s.store.ExecTx(ctx, func(q *db.Queries) error {
+		if err := checkNotSharedTx(ctx, q, scope, request.RequesterEmail); err != nil {
+			return err
+		}
+		rule, err := s.createShareTx(ctx, q, authPayload, scope, db.ShareSecretParams{
+			OwnerEmail:  ownerEmail,
+			TargetEmail: request.RequesterEmail,
+			Path:        request.Path,
+			Permission:  request.Permission,
+			SharedUntil: sql.NullTime{Time: sharedUntil, Valid: true},
+			IsPrefix:    request.IsPrefix,
+		})
+		if err != nil {
+			return err
+		}
+
+		decided, err = decideTx(ctx, q, db.DecideAccessRequestParams{
+			ID:             request.ID,
+			Status:         accessRequestApproved,
+			DecidedBy:      sql.NullString{String: authPayload.Email, Valid: true},
+			DecisionReason: sql.NullString{String: req.Reason, Valid: req.Reason != ""},
+			SharingRuleID:  uuid.NullUUID{UUID: rule.ID, Valid: true},
+			DurationSecs:   sql.NullInt64{Int64: durationSecs, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		detail := fmt.Sprintf("%s for %s until %s", request.Permission, request.RequesterEmail, sharedUntil.UTC().Format(time.RFC3339))
+		if err := s.auditSvc.LogTx(ctx, q, authPayload.UserID, authPayload.Email, "approve_access_request", scope.display(), scope.Version, true, &detail); err != nil {
+			return fmt.Errorf("failed to log action: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	s.notify(ctx, notify.Message{
+		Event:      "access_request.approved",
+		Recipients: []string{request.RequesterEmail},
+		Subject:    fmt.Sprintf("Access to %s approved", scope.display()),
+		Body: fmt.Sprintf("%s approved your request for %s access to %s. It ends at %s.",
+			authPayload.Email, request.Permission, scope.display(), sharedUntil.UTC().Format(time.RFC1123)),
+		Path:      scope.display(),
+		ExpiresAt: &sharedUntil,
+	})
+
+	ctx.JSON(http.StatusOK, newAccessRequestResponse(decided))
+}
+
+// @Summary      Deny an access request
+// @Description  Rejects a pending access request, with an optional reason shown to the requester. The caller needs share on the path. Audited as deny_access_request, and the requester is notified.
+// @Tags         Access Requests
+// @Accept       json
+// @Produce      json
+// @Param        id       path     string                      true   "Access request ID"
+// @Param        request  body     decideAccessRequestRequest  false  "Reason"
+// @Success      200      {object} accessRequestResponse
+// @Failure      400      {object} swaggerErrorResponse "Invalid input"
+// @Failure      403      {object} swaggerErrorResponse "Not an approver of the path"
+// @Failure      404      {object} swaggerErrorResponse "Request not found"
+// @Failure      409      {object} swaggerErrorResponse "Request already decided"
+// @Failure      500      {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /access-requests/{id}/deny [post]
+func (s *Server) denyAccessRequest(ctx *gin.Context) {
+	req, ok := bindDecision(ctx)
+	if !ok {
+		return
+	}
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	request, scope, err := s.requestForDecision(ctx, authPayload, ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	var decided db.AccessRequests
+	err = s.store.ExecTx(ctx, func(q *db.Queries) error {
+		decided, err = decideTx(ctx, q, db.DecideAccessRequestParams{
+			ID:             request.ID,
+			Status:         accessRequestDenied,
+			DecidedBy:      sql.NullString{String: authPayload.Email, Valid: true},
+			DecisionReason: sql.NullString{String: req.Reason, Valid: req.Reason != ""},
+		})
+		if err != nil {
+			return err
+		}
+
+		detail := fmt.Sprintf("%s for %s", request.Permission, request.RequesterEmail)
+		if req.Reason != "" {
+			detail += ": " + req.Reason
+		}
+		if err := s.auditSvc.LogTx(ctx, q, authPayload.UserID, authPayload.Email, "deny_access_request", scope.display(), scope.Version, true, &detail); err != nil {
+			return fmt.Errorf("failed to log action: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	body := fmt.Sprintf("%s denied your request for %s access to %s.", authPayload.Email, request.Permission, scope.display())
+	if req.Reason != "" {
+		body += "\n\nReason: " + req.Reason
+	}
+	s.notify(ctx, notify.Message{
+		Event:      "access_request.denied",
+		Recipients: []string{request.RequesterEmail},
+		Subject:    fmt.Sprintf("Access to %s denied", scope.display()),
+		Body:       body,
+		Path:       scope.display(),
+	})
+
+	ctx.JSON(http.StatusOK, newAccessRequestResponse(decided))
+}
+
+// @Summary      Cancel my access request
+// @Description  Withdraws a pending access request made by the caller. Audited as cancel_access_request.
+// @Tags         Access Requests
+// @Produce      json
+// @Param        id   path     string  true  "Access request ID"
+// @Success      200  {object} accessRequestResponse
+// @Failure      400  {object} swaggerErrorResponse "Invalid ID"
+// @Failure      404  {object} swaggerErrorResponse "Request not found"
+// @Failure      409  {object} swaggerErrorResponse "Request already decided"
+// @Failure      500  {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /access-requests/{id} [delete]
+func (s *Server) cancelAccessRequest(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	request, err := s.accessRequest(ctx, ctx.Param("id"))
+	if err == nil && request.RequesterID != authPayload.UserID {
+		// other users' requests are not revealed
+		err = newStatusError(http.StatusNotFound, "the access request does not exist")
+	}
+	if err == nil {
+		err = checkPending(request)
+	}
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	var cancelled db.AccessRequests
+	err = s.store.ExecTx(ctx, func(q *db.Queries) error {
+		cancelled, err = decideTx(ctx, q, db.DecideAccessRequestParams{
+			ID:        request.ID,
+			Status:    accessRequestCancelled,
+			DecidedBy: sql.NullString{String: authPayload.Email, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		if err := s.auditSvc.LogTx(ctx, q, authPayload.UserID, authPayload.Email, "cancel_access_request", sharedPath(request.Path, request.IsPrefix), 0, true, nil); err != nil {
+			return fmt.Errorf("failed to log action: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, newAccessRequestResponse(cancelled))
+}
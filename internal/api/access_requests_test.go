@@ -0,0 +1,98 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessRequests(t *testing.T) {
+	owner := newTestUser(t)
+	requester := newTestUser(t)
+	other := newTestUser(t)
+
+	secret := owner.createSecret("db/password", "s3cret")
+
+	require.Equal(t, http.StatusBadRequest, requester.call(http.MethodPost, "/access-requests", createAccessRequestRequest{Path: secret.Path, Permission: "read", Justification: "no duration"}, nil))
+	require.Equal(t, http.StatusConflict, owner.call(http.MethodPost, "/access-requests", createAccessRequestRequest{Path: secret.Path, Permission: "read", Justification: "mine", DurationSecs: 3600}, nil))
+
+	var request accessRequestResponse
+	require.Equal(t, http.StatusCreated, requester.call(http.MethodPost, "/access-requests", createAccessRequestRequest{
+		Path:          secret.Path,
+		Permission:    "read",
+		Justification: "debugging the payment outage",
+		DurationSecs:  3600,
+	}, &request))
+	require.Equal(t, accessRequestPending, request.Status)
+	require.Equal(t, http.StatusConflict, requester.call(http.MethodPost, "/access-requests", createAccessRequestRequest{Path: secret.Path, Permission: "write", Justification: "again", DurationSecs: 3600}, nil))
+
+	var pending listAccessRequestsResponse
+	require.Equal(t, http.StatusOK, owner.call(http.MethodGet, "/access-requests/pending", nil, &pending))
+	require.Contains(t, accessRequestIDs(pending.Requests), request.ID.String())
+	pending = listAccessRequestsResponse{}
+	require.Equal(t, http.StatusOK, other.call(http.MethodGet, "/access-requests/pending", nil, &pending))
+	require.NotContains(t, accessRequestIDs(pending.Requests), request.ID.String())
+
+	// only approvers decide, and never their own requests
+	approve := "/access-requests/" + request.ID.String() + "/approve"
+	require.Equal(t, http.StatusForbidden, other.call(http.MethodPost, approve, decideAccessRequestRequest{}, nil))
+	require.Equal(t, http.StatusForbidden, requester.call(http.MethodPost, approve, decideAccessRequestRequest{}, nil))
+	require.Equal(t, http.StatusBadRequest, owner.call(http.MethodPost, approve, decideAccessRequestRequest{DurationSecs: 7200}, nil))
+
+	var approved accessRequestResponse
+	require.Equal(t, http.StatusOK, owner.call(http.MethodPost, approve, decideAccessRequestRequest{Reason: "ok for the outage", DurationSecs: 1800}, &approved))
+	require.Equal(t, accessRequestApproved, approved.Status)
+	require.Equal(t, owner.email, approved.DecidedBy)
+	require.Equal(t, int64(1800), approved.DurationSecs)
+	require.NotNil(t, approved.SharedUntil)
+
+	got, code := requester.getSecret(secret.Path)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "s3cret", got.Decrypted)
+	var shared sharedWithMeResponse
+	require.Equal(t, http.StatusOK, requester.call(http.MethodGet, "/shared-with-me", nil, &shared))
+	require.Len(t, shared.Shares, 1)
+	require.Equal(t, owner.email, shared.Shares[0].OwnerEmail)
+	var access secretAccessResponse
+	require.Equal(t, http.StatusOK, owner.call(http.MethodGet, "/access/"+secret.Path, nil, &access))
+	require.Len(t, access.Access, 2)
+	require.Equal(t, requester.email, access.Access[1].Email)
+	require.NotNil(t, access.Access[1].SharedUntil)
+
+	require.Equal(t, http.StatusConflict, owner.call(http.MethodPost, "/access-requests/"+request.ID.String()+"/deny", decideAccessRequestRequest{Reason: "too late"}, nil))
+
+	// a denied request grants nothing
+	var denied accessRequestResponse
+	require.Equal(t, http.StatusCreated, requester.call(http.MethodPost, "/access-requests", createAccessRequestRequest{Path: secret.Path, Permission: "write", Justification: "rotate it", DurationSecs: 3600}, &denied))
+	require.Equal(t, http.StatusOK, owner.call(http.MethodPost, "/access-requests/"+denied.ID.String()+"/deny", decideAccessRequestRequest{Reason: "ask the on-call instead"}, &denied))
+	require.Equal(t, accessRequestDenied, denied.Status)
+	require.Equal(t, "ask the on-call instead", denied.DecisionReason)
+	require.Equal(t, http.StatusForbidden, requester.call(http.MethodPut, "/secrets/"+secret.Path, updateSecretRequest{Value: "changed"}, nil))
+
+	var cancelled accessRequestResponse
+	require.Equal(t, http.StatusCreated, other.call(http.MethodPost, "/access-requests", createAccessRequestRequest{Path: secret.Path, Permission: "read", Justification: "curious", DurationSecs: 60}, &cancelled))
+	require.Equal(t, http.StatusNotFound, requester.call(http.MethodDelete, "/access-requests/"+cancelled.ID.String(), nil, nil))
+	require.Equal(t, http.StatusOK, other.call(http.MethodDelete, "/access-requests/"+cancelled.ID.String(), nil, &cancelled))
+	require.Equal(t, accessRequestCancelled, cancelled.Status)
+
+	var mine listAccessRequestsResponse
+	require.Equal(t, http.StatusOK, requester.call(http.MethodGet, "/access-requests", nil, &mine))
+	require.Len(t, mine.Requests, 2)
+	require.Equal(t, accessRequestDenied, mine.Requests[0].Status)
+
+	var logs getAuditLogsResponse
+	require.Equal(t, http.StatusOK, owner.call(http.MethodGet, "/audit?action=approve_access_request", nil, &logs))
+	require.Len(t, logs.Logs, 1)
+	logs = getAuditLogsResponse{}
+	require.Equal(t, http.StatusOK, requester.call(http.MethodGet, "/audit?action=request_access", nil, &logs))
+	require.Len(t, logs.Logs, 2)
+}
+
+func accessRequestIDs(requests []accessRequestResponse) []string {
+	ids := make([]string, 0, len(requests))
+	for _, r := range requests {
+		ids = append(ids, r.ID.String())
+	}
+	return ids
+}
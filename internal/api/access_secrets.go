@@ -1,8 +1,10 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -14,9 +16,10 @@ import (
 )
 
 type getSecretResponse struct {
-	Path      string `json:"path"`
-	Version   int32  `json:"version"`
-	Decrypted string `json:"decrypted_value"`
+	Path        string `json:"path"`
+	Environment string `json:"environment"`
+	Version     int32  `json:"version"`
+	Decrypted   string `json:"decrypted_value"`
 }
 
 type updateSecretRequest struct {
@@ -24,10 +27,11 @@ type updateSecretRequest struct {
 }
 
 type updateSecretResponse struct {
-	Path      string `json:"path"`
-	Version   int32  `json:"version"`
-	Encrypted []byte `json:"encrypted_value"`
-	Nonce     []byte `json:"nonce"`
+	Path        string `json:"path"`
+	Environment string `json:"environment"`
+	Version     int32  `json:"version"`
+	Encrypted   []byte `json:"encrypted_value"`
+	Nonce       []byte `json:"nonce"`
 }
 
 // VerifySecretHMAC verifies the HMAC signature of the encrypted secret and nonce
@@ -37,78 +41,139 @@ func VerifySecretHMAC(secret db.GetLatestSecretByPathRow, key []byte) (bool, err
 }
 
 // @Summary      Retrieve a secret by path and optional version
-// @Description  Fetches and decrypts the secret. If version is not specified, retrieves the latest. Verifies HMAC to ensure integrity.
+// @Description  Fetches and decrypts the secret in the requested environment. If version is not specified, retrieves the latest. Verifies HMAC to ensure integrity. With resolve=true, references to other secrets are expanded subject to read access on each referenced path. A secret under the two-person rule is only returned once the caller holds an approved read, which the call uses up once the value has been read; until then the call opens or returns the pending quorum request with status 202.
 // @Tags         Secrets
 // @Produce      json
 // @Param        path     path      string true  "Secret path"
+// @Param        env      query     string false "Environment (defaults to default)"
 // @Param        version  query     int    false "Secret version (optional)"
+// @Param        resolve  query     bool   false "Expand references to other secrets in the value"
+// @Param        wait_for_version  query  int     false "Block until the latest version is at least this (long-polling)"
+// @Param        timeout           query  string  false "How long to wait for wait_for_version, e.g. 30s (max 5m)"
 // @Success      200      {object}  getSecretResponse
+// @Success      202      {object}  quorumRequestResponse "The secret is under the two-person rule and the read awaits approvals"
+// @Success      304      "wait_for_version was not reached before the timeout"
 // @Failure 401 {object} swaggerErrorResponse "Unauthorized or HMAC verification failed"
+// @Failure 403 {object} swaggerErrorResponse "Access denied to a referenced secret"
 // @Failure 404 {object} swaggerErrorResponse "Secret not found"
+// @Failure 409 {object} swaggerErrorResponse "The approved read was used by a concurrent request"
+// @Failure 422 {object} swaggerErrorResponse "Reference cycle, depth limit or missing key"
 // @Failure 500 {object} swaggerErrorResponse "Internal server error"
 // @Security     BearerAuth
 // @Router       /secrets/{path} [get]
 func (s *Server) getSecret(ctx *gin.Context) {
 
-	log := logger.New(s.config.Env)
-
 	secret := ctx.MustGet("secret").(db.GetLatestSecretByPathRow)
 
 	authorizationPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
 
-	//Get the HMAC key from the database associated with the secret
-	hmacKey, err := s.store.GetHMACKeyByID(ctx, secret.HmacKeyID.UUID)
+	pending, granted, err := s.quorumRead(ctx, authorizationPayload, secret)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
+	if pending != nil {
+		resp, err := s.quorumRequestResponse(ctx, *pending, secret.Path)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusAccepted, resp)
+		return
+	}
+
+	value, err := s.revealSecret(ctx, authorizationPayload, secret, granted)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	resolve, _ := strconv.ParseBool(ctx.Query("resolve"))
+	if resolve {
+		value, err = s.resolveSecretReferences(ctx, authorizationPayload, secret.Path, secret.Environment, value)
+		if err != nil {
+			ctx.JSON(referenceErrorStatus(err), errorResponse(err))
+			return
+		}
+	}
+
+	resp := getSecretResponse{
+		Path:        secret.Path,
+		Environment: secret.Environment,
+		Version:     secret.Version,
+		Decrypted:   value,
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+
+}
+
+// revealSecret verifies the HMAC of a secret the caller may read, decrypts it and audits the read.
+// With useGrant the caller's approved read under the two-person rule is used up together with the
+// audit entry, so a read that fails does not cost the grant.
+func (s *Server) revealSecret(ctx context.Context, authPayload *auth.Payload, secret db.GetLatestSecretByPathRow, useGrant bool) (string, error) {
+
+	log := logger.New(s.config.Env)
+
+	//Get the HMAC key associated with the secret
+	hmacKey, err := s.hmacKey(ctx, secret.HmacKeyID.UUID)
+	if err != nil {
+		return "", err
+	}
 
 	//Verify the hmac signature
 	isVerified, err := VerifySecretHMAC(secret, hmacKey.Key)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+		return "", err
 	}
 	if !isVerified {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(fmt.Errorf("invalid HMAC signature")))
 		failureReason := "invalid HMAC signature"
 		//Log the secret access in the database
-		err = s.auditSvc.Log(ctx, authorizationPayload.UserID, authorizationPayload.Email, "read_secret", secret.Path, secret.Version, false, &failureReason)
+		err = s.auditSvc.Log(ctx, authPayload.UserID, authPayload.Email, "read_secret", secret.Path, secret.Version, false, &failureReason)
 		if err != nil {
 			log.Error("failed to log secret access", zap.Error(err))
 		}
-		return
+		return "", newStatusError(http.StatusUnauthorized, "invalid HMAC signature")
 	}
 
 	// Decrypt the secret value
 	decryptedValue, err := s.encryptor.Decrypt(secret.EncryptedValue, secret.Nonce)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+		return "", err
+	}
+
+	if useGrant {
+		err = s.store.ExecTx(ctx, func(q *db.Queries) error {
+			if err := s.useQuorumGrantTx(ctx, q, authPayload, secret); err != nil {
+				return err
+			}
+			if err := s.auditSvc.LogTx(ctx, q, authPayload.UserID, authPayload.Email, "read_secret", secret.Path, secret.Version, true, nil); err != nil {
+				return fmt.Errorf("failed to log action: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+		return string(decryptedValue), nil
 	}
 
 	//Log the secret access in the database
-	err = s.auditSvc.Log(ctx, authorizationPayload.UserID, authorizationPayload.Email, "read_secret", secret.Path, secret.Version, true, nil)
+	err = s.auditSvc.Log(ctx, authPayload.UserID, authPayload.Email, "read_secret", secret.Path, secret.Version, true, nil)
 	if err != nil {
 		log.Error("failed to log secret access", zap.Error(err))
 	}
 
-	resp := getSecretResponse{
-		Path:      secret.Path,
-		Version:   secret.Version,
-		Decrypted: string(decryptedValue),
-	}
-
-	ctx.JSON(http.StatusOK, resp)
-
+	return string(decryptedValue), nil
 }
 
 // @Summary      Update an existing secret by creating a new version
-// @Description  Encrypts new secret value, verifies existing HMAC to prevent tampering, then creates a new secret version signed with a fresh HMAC.
+// @Description  Encrypts new secret value, verifies existing HMAC to prevent tampering, then creates a new secret version signed with a fresh HMAC. Writing to an environment the secret has no value in yet starts its version history there.
 // @Tags         Secrets
 // @Accept       json
 // @Produce      json
 // @Param        path     path      string true  "Secret path"
+// @Param        env      query     string false "Environment (defaults to default)"
 // @Param        updateSecretRequest  body      updateSecretRequest  true  "New secret value"
 // @Success      200                  {object}  updateSecretResponse
 // @Failure      400                  {object}  swaggerErrorResponse "Invalid input"
@@ -129,55 +194,76 @@ func (s *Server) updateSecret(ctx *gin.Context) {
 
 	authorizationPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
 
-	//Get the HMAC key from the database associated with the secret
-	secretHmacKey, err := s.store.GetHMACKeyByID(ctx, secret.HmacKeyID.UUID)
+	updatedSecret, err := s.writeSecretVersion(ctx, authorizationPayload, secret, req.Value)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
-	//Verify the hmac signature
-	isVerified, err := VerifySecretHMAC(secret, secretHmacKey.Key)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+	resp := updateSecretResponse{
+		Path:        secret.Path,
+		Environment: updatedSecret.Environment,
+		Version:     updatedSecret.Version,
+		Encrypted:   updatedSecret.EncryptedValue,
+		Nonce:       updatedSecret.Nonce,
 	}
-	if !isVerified {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(fmt.Errorf("invalid HMAC signature")))
-		failureReason := "invalid HMAC signature"
-		//Log the secret access in the database
-		err = s.auditSvc.Log(ctx, authorizationPayload.UserID, authorizationPayload.Email, "update_secret", secret.Path, secret.Version, false, &failureReason)
+
+	ctx.JSON(http.StatusOK, resp)
+
+}
+
+// writeSecretVersion verifies the latest version of a secret the caller may write and stores
+// value as its next version
+func (s *Server) writeSecretVersion(ctx context.Context, authPayload *auth.Payload, secret db.GetLatestSecretByPathRow, value string) (db.SecretVersions, error) {
+
+	var updatedSecret db.SecretVersions
+
+	// a secret with no value in this environment yet has nothing to verify
+	if secret.Version > 0 {
+		//Get the HMAC key from the database associated with the secret
+		secretHmacKey, err := s.store.GetHMACKeyByID(ctx, secret.HmacKeyID.UUID)
 		if err != nil {
-			logger.New(s.config.Env).Error("failed to log secret access", zap.Error(err))
+			return updatedSecret, err
+		}
+
+		//Verify the hmac signature
+		isVerified, err := VerifySecretHMAC(secret, secretHmacKey.Key)
+		if err != nil {
+			return updatedSecret, err
+		}
+		if !isVerified {
+			failureReason := "invalid HMAC signature"
+			//Log the secret access in the database
+			err = s.auditSvc.Log(ctx, authPayload.UserID, authPayload.Email, "update_secret", secret.Path, secret.Version, false, &failureReason)
+			if err != nil {
+				logger.New(s.config.Env).Error("failed to log secret access", zap.Error(err))
+			}
+			return updatedSecret, newStatusError(http.StatusUnauthorized, "invalid HMAC signature")
 		}
-		return
 	}
 
 	// Encrypt the new secret value
 
-	encryptedValue, nonce, err := s.encryptor.Encrypt([]byte(req.Value))
+	encryptedValue, nonce, err := s.encryptor.Encrypt([]byte(value))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+		return updatedSecret, err
 	}
 
 	// Create a new HMAC signature for the new secret value
 	hmacKey, err := s.store.GetActiveHMACKey(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(fmt.Errorf("failed to fetch active HMAC key")))
-		return
+		return updatedSecret, fmt.Errorf("failed to fetch active HMAC key")
 	}
 
 	hmacPayload := util.ComputeHMACPayload(encryptedValue, nonce)
 	hmacSig, err := util.GenerateHMACSignature(hmacPayload, hmacKey.Key)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(fmt.Errorf("failed to generate HMAC signature")))
-		return
+		return updatedSecret, fmt.Errorf("failed to generate HMAC signature")
 	}
 
 	args := db.CreateNewSecretVersionParams{
 		CreatedBy: uuid.NullUUID{
-			UUID:  authorizationPayload.UserID,
+			UUID:  authPayload.UserID,
 			Valid: true,
 		},
 		Path:           secret.Path,
@@ -188,29 +274,20 @@ func (s *Server) updateSecret(ctx *gin.Context) {
 			UUID:  hmacKey.ID,
 			Valid: true,
 		},
+		Environment: secret.Environment,
 	}
 
-	var updatedSecret db.SecretVersions
-	s.store.ExecTx(ctx, func(q *db.Queries) error {
+	err = s.store.ExecTx(ctx, func(q *db.Queries) error {
 		updatedSecret, err = q.CreateNewSecretVersion(ctx, args)
 		if err != nil {
 			return err
 		}
 
 		// Log the action
-		if err = s.auditSvc.LogTx(ctx, q, authorizationPayload.UserID, authorizationPayload.Email, "update_secret", secret.Path, updatedSecret.Version, true, nil); err != nil {
+		if err = s.auditSvc.LogTx(ctx, q, authPayload.UserID, authPayload.Email, "update_secret", secret.Path, updatedSecret.Version, true, nil); err != nil {
 			return fmt.Errorf("failed to log action: %w", err)
 		}
-		return nil
+		return s.publishTx(ctx, q, versionChanged(secret, updatedSecret.Environment, updatedSecret.Version))
 	})
-
-	resp := updateSecretResponse{
-		Path:      secret.Path,
-		Version:   updatedSecret.Version,
-		Encrypted: updatedSecret.EncryptedValue,
-		Nonce:     updatedSecret.Nonce,
-	}
-
-	ctx.JSON(http.StatusOK, resp)
-
+	return updatedSecret, err
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/pixperk/vaultify/internal/auth"
 	db "github.com/pixperk/vaultify/internal/db/sqlc"
+	"github.com/pixperk/vaultify/internal/secretpath"
 )
 
 type auditLogResponse struct {
@@ -21,6 +22,8 @@ type auditLogResponse struct {
 	Success         bool      `json:"success"`
 	Reason          *string   `json:"reason,omitempty"` // pointer avoids issues with NullString
 	CreatedAt       time.Time `json:"created_at"`
+	// BreakGlassSessionID flags entries written during break-glass access for review
+	BreakGlassSessionID *uuid.UUID `json:"break_glass_session_id,omitempty"`
 }
 
 type getAuditLogsResponse struct {
@@ -36,6 +39,7 @@ type getAuditLogsResponse struct {
 // @Param        path       query     string  false  "Resource path filter (e.g., /vault/secrets/foo)"
 // @Param        version    query     int     false  "Resource version filter"
 // @Param        success    query     bool    false  "Success status filter (true/false)"
+// @Param        flagged    query     bool    false  "Only entries flagged (true) or not flagged (false) for break-glass review"
 // @Param        from       query     string  false  "Start date in RFC3339 or YYYY-MM-DD"
 // @Param        to         query     string  false  "End date in RFC3339 or YYYY-MM-DD"
 // @Param        limit      query     int     false  "Limit number of results (default 50)"
@@ -48,8 +52,18 @@ type getAuditLogsResponse struct {
 // @Router       /audit/logs [get]
 func (s *Server) getAuditLogs(c *gin.Context) {
 	authPayload := c.MustGet(authorizationPayloadKey).(*auth.Payload)
-	email := authPayload.Email
 
+	params, ok := auditLogFilter(c)
+	if !ok {
+		return
+	}
+	params.UserEmail = authPayload.Email
+	s.respondAuditLogs(c, params)
+}
+
+// auditLogFilter parses the audit log query parameters shared by /audit/logs and /sys/audit. On
+// failure the error response has already been written.
+func auditLogFilter(c *gin.Context) (db.FilterAuditLogsParams, bool) {
 	// Parse query params
 	action := c.Query("action")
 	path := c.Query("path")
@@ -57,6 +71,17 @@ func (s *Server) getAuditLogs(c *gin.Context) {
 	fromStr := c.Query("from")
 	toStr := c.Query("to")
 	successStr := c.Query("success")
+	flaggedStr := c.Query("flagged")
+
+	// Optional path, compared in canonical form
+	if path != "" {
+		normalized, err := secretpath.Normalize(path)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse(err))
+			return db.FilterAuditLogsParams{}, false
+		}
+		path = normalized
+	}
 
 	// Optional int32 version
 	var version int32
@@ -64,20 +89,31 @@ func (s *Server) getAuditLogs(c *gin.Context) {
 		v, err := strconv.Atoi(versionStr)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid version"})
-			return
+			return db.FilterAuditLogsParams{}, false
 		}
 		version = int32(v)
 	}
 
 	// Optional success bool
-	var successBool bool
+	var successBool sql.NullBool
 	if successStr != "" {
 		success, err := strconv.ParseBool(successStr)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid success value"})
-			return
+			return db.FilterAuditLogsParams{}, false
+		}
+		successBool = sql.NullBool{Bool: success, Valid: true}
+	}
+
+	// Optional break-glass flag
+	var flagged sql.NullBool
+	if flaggedStr != "" {
+		f, err := strconv.ParseBool(flaggedStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid flagged value"})
+			return db.FilterAuditLogsParams{}, false
 		}
-		successBool = success
+		flagged = sql.NullBool{Bool: f, Valid: true}
 	}
 
 	// Optional time filters
@@ -88,7 +124,7 @@ func (s *Server) getAuditLogs(c *gin.Context) {
 			t, err = time.Parse("2006-01-02", fromStr)
 			if err != nil {
 				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from time"})
-				return
+				return db.FilterAuditLogsParams{}, false
 			}
 		}
 		fromTime = sql.NullTime{Time: t.UTC(), Valid: true}
@@ -101,7 +137,7 @@ func (s *Server) getAuditLogs(c *gin.Context) {
 			t, err = time.Parse("2006-01-02", toStr)
 			if err != nil {
 				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to time"})
-				return
+				return db.FilterAuditLogsParams{}, false
 			}
 			t = t.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
 		}
@@ -118,19 +154,21 @@ func (s *Server) getAuditLogs(c *gin.Context) {
 		offset = 0
 	}
 
-	// Build filter params
-	params := db.FilterAuditLogsParams{
-		UserEmail:       email,
+	return db.FilterAuditLogsParams{
 		Action:          action,
 		ResourcePath:    path,
 		ResourceVersion: version,
 		Success:         successBool,
+		Flagged:         flagged,
 		CreatedAt:       fromTime,
-		CreatedAt_2:     toTime,
+		CreatedAt2:      toTime,
 		Limit:           int32(limit),
 		Offset:          int32(offset),
-	}
+	}, true
+}
 
+// respondAuditLogs writes the audit logs matching params
+func (s *Server) respondAuditLogs(c *gin.Context, params db.FilterAuditLogsParams) {
 	// Fetch logs
 	logs, err := s.store.FilterAuditLogs(c.Request.Context(), params)
 	if err != nil {
@@ -144,21 +182,7 @@ func (s *Server) getAuditLogs(c *gin.Context) {
 
 	cleanLogs := make([]auditLogResponse, 0, len(logs))
 	for _, l := range logs {
-		var reason *string
-		if l.Reason.Valid {
-			reason = &l.Reason.String
-		}
-
-		cleanLogs = append(cleanLogs, auditLogResponse{
-			ID:              l.ID,
-			UserEmail:       l.UserEmail,
-			Action:          l.Action,
-			ResourcePath:    l.ResourcePath,
-			ResourceVersion: l.ResourceVersion,
-			Success:         l.Success,
-			Reason:          reason,
-			CreatedAt:       l.CreatedAt.Time, // .Time is valid since .Valid is true from db
-		})
+		cleanLogs = append(cleanLogs, newAuditLogResponse(l))
 	}
 
 	resp := getAuditLogsResponse{
@@ -167,3 +191,25 @@ func (s *Server) getAuditLogs(c *gin.Context) {
 
 	c.JSON(http.StatusOK, resp)
 }
+
+func newAuditLogResponse(l db.AuditLogs) auditLogResponse {
+	var reason *string
+	if l.Reason.Valid {
+		reason = &l.Reason.String
+	}
+
+	resp := auditLogResponse{
+		ID:              l.ID,
+		UserEmail:       l.UserEmail,
+		Action:          l.Action,
+		ResourcePath:    l.ResourcePath,
+		ResourceVersion: l.ResourceVersion,
+		Success:         l.Success,
+		Reason:          reason,
+		CreatedAt:       l.CreatedAt.Time, // .Time is valid since .Valid is true from db
+	}
+	if l.BreakGlassSessionID.Valid {
+		resp.BreakGlassSessionID = &l.BreakGlassSessionID.UUID
+	}
+	return resp
+}
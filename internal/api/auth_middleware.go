@@ -15,31 +15,29 @@ const (
 	authorizationPayloadKey = "authorization_payload"
 )
 
-func authMiddleware(tokenMaker auth.TokenMaker) gin.HandlerFunc {
-	return func(ctx *gin.Context) {
-		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
-		if len(authorizationHeader) == 0 {
-			err := errors.New("authorization header not provided")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
-			return
-		}
+// verifyAuthorization checks a "Bearer <token>" authorization value and returns the token's payload
+func verifyAuthorization(tokenMaker auth.TokenMaker, authorizationHeader string) (*auth.Payload, error) {
+	if len(authorizationHeader) == 0 {
+		return nil, errors.New("authorization header not provided")
+	}
 
-		fields := strings.Fields(authorizationHeader)
-		if len(fields) < 2 {
-			err := errors.New("invalid authorization header format")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
-			return
-		}
+	fields := strings.Fields(authorizationHeader)
+	if len(fields) < 2 {
+		return nil, errors.New("invalid authorization header format")
+	}
 
-		authorizationType := strings.ToLower(fields[0])
-		if authorizationType != authorizationTypeBearer {
-			err := errors.New("unsupported authorization type")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
-			return
-		}
+	authorizationType := strings.ToLower(fields[0])
+	if authorizationType != authorizationTypeBearer {
+		return nil, errors.New("unsupported authorization type")
+	}
 
-		accessToken := fields[1]
-		payload, err := tokenMaker.VerifyToken(accessToken)
+	accessToken := fields[1]
+	return tokenMaker.VerifyToken(accessToken)
+}
+
+func authMiddleware(tokenMaker auth.TokenMaker) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		payload, err := verifyAuthorization(tokenMaker, ctx.GetHeader(authorizationHeaderKey))
 		if err != nil {
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
 			return
@@ -0,0 +1,623 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pixperk/vaultify/internal/auth"
+	db "github.com/pixperk/vaultify/internal/db/sqlc"
+	"github.com/pixperk/vaultify/internal/notify"
+	"github.com/pixperk/vaultify/internal/policy"
+	"github.com/pixperk/vaultify/internal/secretpath"
+)
+
+const (
+	defaultBreakGlassDuration = time.Hour
+	maxBreakGlassDuration     = 4 * time.Hour
+)
+
+type setBreakGlassRuleRequest struct {
+	// Group is the slug of the emergency group whose members may break the glass
+	Group string `json:"group" binding:"required"`
+	// MaxDurationSecs caps the access of each use, 1 hour by default and at most 4 hours
+	MaxDurationSecs int64 `json:"max_duration_secs" binding:"omitempty,min=60"`
+}
+
+type breakGlassRuleResponse struct {
+	Path            string    `json:"path"`
+	Group           string    `json:"group"`
+	MaxDurationSecs int64     `json:"max_duration_secs"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type listBreakGlassRulesResponse struct {
+	Rules []breakGlassRuleResponse `json:"rules"`
+}
+
+type breakGlassRequest struct {
+	Path string `json:"path" binding:"required"`
+	// Reason is mandatory and sent to the owner and security
+	Reason string `json:"reason" binding:"required"`
+	// DurationSecs shortens the access below the maximum of the rule
+	DurationSecs int64 `json:"duration_secs" binding:"omitempty,min=60"`
+}
+
+type breakGlassSessionResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	UserEmail   string     `json:"user_email"`
+	Path        string     `json:"path"`
+	Reason      string     `json:"reason"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	ReviewedBy  string     `json:"reviewed_by,omitempty"`
+	ReviewNotes string     `json:"review_notes,omitempty"`
+	ReviewedAt  *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type listBreakGlassSessionsResponse struct {
+	Sessions []breakGlassSessionResponse `json:"sessions"`
+}
+
+type breakGlassSessionDetailResponse struct {
+	breakGlassSessionResponse
+	// AuditLogs are the entries flagged with the session
+	AuditLogs []auditLogResponse `json:"audit_logs"`
+}
+
+type reviewBreakGlassRequest struct {
+	Notes string `json:"notes" binding:"required"`
+}
+
+func newBreakGlassSessionResponse(session db.BreakGlassSessions) breakGlassSessionResponse {
+	resp := breakGlassSessionResponse{
+		ID:          session.ID,
+		UserEmail:   session.UserEmail,
+		Path:        session.Path,
+		Reason:      session.Reason,
+		ExpiresAt:   session.ExpiresAt,
+		ReviewedBy:  session.ReviewedBy.String,
+		ReviewNotes: session.ReviewNotes.String,
+		CreatedAt:   session.CreatedAt,
+	}
+	if session.ReviewedAt.Valid {
+		resp.ReviewedAt = &session.ReviewedAt.Time
+	}
+	return resp
+}
+
+// flagBreakGlassAudit is an audit hook linking entries a user writes on a path during their
+// break-glass session on it to the session, which flags them for its review
+func (s *Server) flagBreakGlassAudit(ctx context.Context, q *db.Queries, entry db.AuditLogs) error {
+	sessions, err := q.ListActiveBreakGlassSessions(ctx, entry.UserID)
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		if session.Path == entry.ResourcePath {
+			return q.FlagAuditLog(ctx, db.FlagAuditLogParams{
+				ID:                  entry.ID,
+				BreakGlassSessionID: uuid.NullUUID{UUID: session.ID, Valid: true},
+			})
+		}
+	}
+	return nil
+}
+
+// securityContacts lists the admins and auditors, who are told about every use of break-glass access
+func (s *Server) securityContacts(ctx context.Context) ([]string, error) {
+	return s.store.ListUserEmailsWithRoles(ctx, []string{systemRoleAdmin, systemRoleAuditor})
+}
+
+// @Summary      List break-glass rules
+// @Description  Lists the designated break-glass paths the caller set up or can use as a member of their emergency group.
+// @Tags         Break-Glass
+// @Produce      json
+// @Success      200  {object} listBreakGlassRulesResponse
+// @Failure      500  {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /break-glass/rules [get]
+func (s *Server) listBreakGlassRules(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	rules, err := s.store.ListBreakGlassRulesForUser(ctx, authPayload.UserID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	resp := listBreakGlassRulesResponse{Rules: make([]breakGlassRuleResponse, 0, len(rules))}
+	for _, rule := range rules {
+		resp.Rules = append(resp.Rules, breakGlassRuleResponse{
+			Path:            sharedPath(rule.Path, rule.IsPrefix),
+			Group:           rule.GroupSlug,
+			MaxDurationSecs: rule.MaxDurationSecs,
+			CreatedAt:       rule.CreatedAt,
+		})
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// @Summary      Designate a break-glass path
+// @Description  Lets members of an emergency group open the secret, or with a trailing /* every secret below the folder, for themselves during an incident. Each use grants read access for at most max_duration_secs (default 1 hour, at most 4 hours). Needs share on the path; audited as set_break_glass_rule. Setting the rule again replaces the group and duration.
+// @Tags         Break-Glass
+// @Accept       json
+// @Produce      json
+// @Param        path     path     string                    true  "Secret path, or folder ending in /*"
+// @Param        request  body     setBreakGlassRuleRequest  true  "Rule"
+// @Success      200      {object} breakGlassRuleResponse
+// @Failure      400      {object} swaggerErrorResponse "Invalid input"
+// @Failure      403      {object} swaggerErrorResponse "Access denied"
+// @Failure      404      {object} swaggerErrorResponse "Secret or group not found"
+// @Failure      500      {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /break-glass/rules/{path} [put]
+func (s *Server) setBreakGlassRule(ctx *gin.Context) {
+	var req setBreakGlassRuleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	maxDuration := defaultBreakGlassDuration
+	if req.MaxDurationSecs > 0 {
+		maxDuration = time.Duration(req.MaxDurationSecs) * time.Second
+	}
+	if maxDuration > maxBreakGlassDuration {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("break-glass access can last at most %s", maxBreakGlassDuration)))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+	scope, err := s.scopeForSharing(ctx, authPayload, ctx.Param("path"))
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	group, err := s.store.GetGroupBySlug(ctx, req.Group)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("group not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	var rule db.BreakGlassRules
+	err = s.store.ExecTx(ctx, func(q *db.Queries) error {
+		rule, err = q.UpsertBreakGlassRule(ctx, db.UpsertBreakGlassRuleParams{
+			Path:            scope.Path,
+			IsPrefix:        scope.Prefix,
+			GroupID:         group.ID,
+			MaxDurationSecs: int64(maxDuration / time.Second),
+			CreatedBy:       authPayload.UserID,
+		})
+		if err != nil {
+			return err
+		}
+		detail := fmt.Sprintf("group %s, up to %s", group.Slug, maxDuration)
+		if err := s.auditSvc.LogTx(ctx, q, authPayload.UserID, authPayload.Email, "set_break_glass_rule", scope.display(), scope.Version, true, &detail); err != nil {
+			return fmt.Errorf("failed to log action: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, breakGlassRuleResponse{
+		Path:            scope.display(),
+		Group:           group.Slug,
+		MaxDurationSecs: rule.MaxDurationSecs,
+		CreatedAt:       rule.CreatedAt,
+	})
+}
+
+// @Summary      Remove a break-glass path
+// @Description  Stops the emergency group from opening the secret or folder. Access already granted runs until it ends. Needs share on the path; audited as remove_break_glass_rule.
+// @Tags         Break-Glass
+// @Param        path  path  string  true  "Secret path, or folder ending in /*"
+// @Success      204   "Rule removed"
+// @Failure      403   {object} swaggerErrorResponse "Access denied"
+// @Failure      404   {object} swaggerErrorResponse "No rule for the path"
+// @Failure      500   {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /break-glass/rules/{path} [delete]
+func (s *Server) deleteBreakGlassRule(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+	scope, err := s.scopeForSharing(ctx, authPayload, ctx.Param("path"))
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	err = s.store.ExecTx(ctx, func(q *db.Queries) error {
+		deleted, err := q.DeleteBreakGlassRule(ctx, db.DeleteBreakGlassRuleParams{
+			Path:     scope.Path,
+			IsPrefix: scope.Prefix,
+		})
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return newStatusError(http.StatusNotFound, "%s is not a break-glass path", scope.display())
+		}
+		if err := s.auditSvc.LogTx(ctx, q, authPayload.UserID, authPayload.Email, "remove_break_glass_rule", scope.display(), scope.Version, true, nil); err != nil {
+			return fmt.Errorf("failed to log action: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// @Summary      Break the glass
+// @Description  Grants the caller short-lived read access to a secret on a designated break-glass path, if they belong to its emergency group. The reason is mandatory. Access is a time-bound read share from the owner of the secret, or for team secrets from whoever set up the rule, and lasts for the maximum of the rule unless duration_secs asks for less. The owner of the secret (or the maintainers of its team) and every admin and auditor are notified at once. The use and everything the caller does on the secret until the access ends are audited with the session id, flagging them for post-incident review under /sys/break-glass. The two-person rule still applies to secrets under it.
+// @Tags         Break-Glass
+// @Accept       json
+// @Produce      json
+// @Param        request  body     breakGlassRequest  true  "Secret and reason"
+// @Success      201      {object} breakGlassSessionResponse
+// @Failure      400      {object} swaggerErrorResponse "Invalid input"
+// @Failure      403      {object} swaggerErrorResponse "Not a break-glass path or not in its emergency group"
+// @Failure      404      {object} swaggerErrorResponse "Secret not found"
+// @Failure      409      {object} swaggerErrorResponse "The caller can already read the secret"
+// @Failure      500      {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /break-glass [post]
+func (s *Server) breakGlass(ctx *gin.Context) {
+	var req breakGlassRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("a reason is required")))
+		return
+	}
+	path, err := secretpath.Normalize(req.Path)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	secret, err := s.loadSecret(ctx, path, defaultEnvironment)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("the secret does not exist")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rule, err := s.store.GetBreakGlassRuleForPath(ctx, db.GetBreakGlassRuleForPathParams{
+		Path:    path,
+		Parents: secretpath.Parents(path),
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("%s is not a break-glass path", path)))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if _, err := s.store.GetGroupMemberRole(ctx, db.GetGroupMemberRoleParams{GroupID: rule.GroupID, UserID: authPayload.UserID}); err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("only members of the %s group can break the glass on %s", rule.GroupSlug, path)))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	canRead, err := s.can(ctx, authPayload, secret, policy.Read)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if canRead {
+		ctx.JSON(http.StatusConflict, errorResponse(fmt.Errorf("you can already read %s", path)))
+		return
+	}
+
+	durationSecs := rule.MaxDurationSecs
+	if req.DurationSecs > 0 {
+		if req.DurationSecs > rule.MaxDurationSecs {
+			ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("break-glass access to %s lasts at most %s", path, time.Duration(rule.MaxDurationSecs)*time.Second)))
+			return
+		}
+		durationSecs = req.DurationSecs
+	}
+	expiresAt := time.Now().Add(time.Duration(durationSecs) * time.Second)
+
+	// the share is granted on behalf of the owner; team secrets are shared by whoever set up the
+	// rule for the team, since whoever created the secret may no longer be part of it
+	ownerID := secret.UserID
+	if secret.TeamID.Valid {
+		ownerID = rule.CreatedBy
+	}
+	owner, err := s.store.GetUserByID(ctx, ownerID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	scope := shareScope{
+		Path:    secret.Path,
+		Version: secret.Version,
+		OwnerID: secret.UserID,
+		TeamID:  secret.TeamID,
+	}
+	var session db.BreakGlassSessions
+	err = s.store.ExecTx(ctx, func(q *db.Queries) error {
+		// the session comes first so that the audit hook flags the entries below with it
+		session, err = q.CreateBreakGlassSession(ctx, db.CreateBreakGlassSessionParams{
+			RuleID:    uuid.NullUUID{UUID: rule.ID, Valid: true},
+			UserID:    authPayload.UserID,
+			UserEmail: authPayload.Email,
+			Path:      path,
+			Reason:    reason,
+			ExpiresAt: expiresAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = s.createShareTx(ctx, q, authPayload, scope, db.ShareSecretParams{
+			OwnerEmail:  owner.Email,
+			TargetEmail: authPayload.Email,
+			Path:        path,
+			Permission:  "read",
+			SharedUntil: sql.NullTime{Time: expiresAt, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		detail := fmt.Sprintf("session %s until %s: %s", session.ID, expiresAt.UTC().Format(time.RFC3339), reason)
+		if err := s.auditSvc.LogTx(ctx, q, authPayload.UserID, authPayload.Email, "break_glass", path, secret.Version, true, &detail); err != nil {
+			return fmt.Errorf("failed to log action: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	owners, err := s.capabilityHolders(ctx, path, secret, policy.Share)
+	if err != nil {
+		log.Printf("Error listing owners of %s: %v\n", path, err)
+	}
+	security, err := s.securityContacts(ctx)
+	if err != nil {
+		log.Printf("Error listing security contacts: %v\n", err)
+	}
+	recipients := owners
+	for _, email := range security {
+		if !slices.Contains(recipients, email) {
+			recipients = append(recipients, email)
+		}
+	}
+	recipients = slices.DeleteFunc(recipients, func(email string) bool { return email == authPayload.Email })
+	s.notify(ctx, notify.Message{
+		Event:      "break_glass.used",
+		Recipients: recipients,
+		Subject:    fmt.Sprintf("Break-glass access to %s by %s", path, authPayload.Email),
+		Body: fmt.Sprintf("%s used break-glass access as a member of %s to read %s until %s.\n\nReason: %s\n\n"+
+			"Everything they do on the secret until then is flagged for review with session %s.",
+			authPayload.Email, rule.GroupSlug, path, expiresAt.UTC().Format(time.RFC1123), reason, session.ID),
+		Path:      path,
+		ExpiresAt: &expiresAt,
+	})
+
+	ctx.JSON(http.StatusCreated, newBreakGlassSessionResponse(session))
+}
+
+// @Summary      List my break-glass sessions
+// @Description  Lists the caller's uses of break-glass access with their review status, newest first.
+// @Tags         Break-Glass
+// @Produce      json
+// @Param        limit   query    int  false  "Limit number of results (default 50)"
+// @Param        offset  query    int  false  "Offset for pagination (default 0)"
+// @Success      200     {object} listBreakGlassSessionsResponse
+// @Failure      500     {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /break-glass/sessions [get]
+func (s *Server) listMyBreakGlassSessions(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 {
+		limit = 50
+	}
+	offset, err := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	sessions, err := s.store.ListBreakGlassSessionsByUser(ctx, db.ListBreakGlassSessionsByUserParams{
+		UserID: authPayload.UserID,
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, newListBreakGlassSessionsResponse(sessions))
+}
+
+// @Summary      List break-glass sessions
+// @Description  Lists every use of break-glass access, newest first. With reviewed=false only those still awaiting post-incident review. Admins and auditors only.
+// @Tags         System
+// @Produce      json
+// @Param        reviewed  query    bool  false  "Only reviewed (true) or unreviewed (false) sessions"
+// @Param        limit     query    int   false  "Limit number of results (default 50)"
+// @Param        offset    query    int   false  "Offset for pagination (default 0)"
+// @Success      200       {object} listBreakGlassSessionsResponse
+// @Failure      400       {object} swaggerErrorResponse "Invalid reviewed value"
+// @Failure      403       {object} swaggerErrorResponse "Not an admin or auditor"
+// @Failure      500       {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /sys/break-glass [get]
+func (s *Server) listBreakGlassSessions(ctx *gin.Context) {
+	var reviewed sql.NullBool
+	if raw := ctx.Query("reviewed"); raw != "" {
+		r, err := strconv.ParseBool(raw)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("invalid reviewed value")))
+			return
+		}
+		reviewed = sql.NullBool{Bool: r, Valid: true}
+	}
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 {
+		limit = 50
+	}
+	offset, err := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	sessions, err := s.store.ListBreakGlassSessions(ctx, db.ListBreakGlassSessionsParams{
+		Reviewed: reviewed,
+		Limit:    int32(limit),
+		Offset:   int32(offset),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, newListBreakGlassSessionsResponse(sessions))
+}
+
+func newListBreakGlassSessionsResponse(sessions []db.BreakGlassSessions) listBreakGlassSessionsResponse {
+	resp := listBreakGlassSessionsResponse{Sessions: make([]breakGlassSessionResponse, 0, len(sessions))}
+	for _, session := range sessions {
+		resp.Sessions = append(resp.Sessions, newBreakGlassSessionResponse(session))
+	}
+	return resp
+}
+
+// breakGlassSession loads the session in the :id parameter
+func (s *Server) breakGlassSession(ctx context.Context, rawID string) (db.BreakGlassSessions, error) {
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		return db.BreakGlassSessions{}, newStatusError(http.StatusBadRequest, "invalid session id")
+	}
+	session, err := s.store.GetBreakGlassSession(ctx, id)
+	if err == sql.ErrNoRows {
+		return session, newStatusError(http.StatusNotFound, "the break-glass session does not exist")
+	}
+	return session, err
+}
+
+// @Summary      Get a break-glass session
+// @Description  Shows a use of break-glass access with the audit entries flagged with it. Admins and auditors only.
+// @Tags         System
+// @Produce      json
+// @Param        id   path     string  true  "Session ID"
+// @Success      200  {object} breakGlassSessionDetailResponse
+// @Failure      400  {object} swaggerErrorResponse "Invalid ID"
+// @Failure      403  {object} swaggerErrorResponse "Not an admin or auditor"
+// @Failure      404  {object} swaggerErrorResponse "Session not found"
+// @Failure      500  {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /sys/break-glass/{id} [get]
+func (s *Server) getBreakGlassSession(ctx *gin.Context) {
+	session, err := s.breakGlassSession(ctx, ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	logs, err := s.store.ListAuditLogsForBreakGlassSession(ctx, uuid.NullUUID{UUID: session.ID, Valid: true})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	resp := breakGlassSessionDetailResponse{
+		breakGlassSessionResponse: newBreakGlassSessionResponse(session),
+		AuditLogs:                 make([]auditLogResponse, 0, len(logs)),
+	}
+	for _, l := range logs {
+		resp.AuditLogs = append(resp.AuditLogs, newAuditLogResponse(l))
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// @Summary      Review a break-glass session
+// @Description  Signs off the post-incident review of a use of break-glass access with notes. Admins and auditors only, and never for their own sessions. Audited as review_break_glass.
+// @Tags         System
+// @Accept       json
+// @Produce      json
+// @Param        id       path     string                   true  "Session ID"
+// @Param        request  body     reviewBreakGlassRequest  true  "Review notes"
+// @Success      200      {object} breakGlassSessionResponse
+// @Failure      400      {object} swaggerErrorResponse "Invalid input"
+// @Failure      403      {object} swaggerErrorResponse "Not an admin or auditor, or own session"
+// @Failure      404      {object} swaggerErrorResponse "Session not found"
+// @Failure      409      {object} swaggerErrorResponse "Already reviewed"
+// @Failure      500      {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /sys/break-glass/{id}/review [post]
+func (s *Server) reviewBreakGlassSession(ctx *gin.Context) {
+	var req reviewBreakGlassRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	session, err := s.breakGlassSession(ctx, ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+	if session.UserID == authPayload.UserID {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("you cannot review your own break-glass session")))
+		return
+	}
+
+	err = s.store.ExecTx(ctx, func(q *db.Queries) error {
+		session, err = q.ReviewBreakGlassSession(ctx, db.ReviewBreakGlassSessionParams{
+			ID:          session.ID,
+			ReviewedBy:  sql.NullString{String: authPayload.Email, Valid: true},
+			ReviewNotes: sql.NullString{String: req.Notes, Valid: true},
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return newStatusError(http.StatusConflict, "the break-glass session is already reviewed")
+			}
+			return err
+		}
+		detail := fmt.Sprintf("session %s by %s: %s", session.ID, session.UserEmail, req.Notes)
+		if err := s.auditSvc.LogTx(ctx, q, authPayload.UserID, authPayload.Email, "review_break_glass", session.Path, 0, true, &detail); err != nil {
+			return fmt.Errorf("failed to log action: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, newBreakGlassSessionResponse(session))
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	db "github.com/pixperk/vaultify/internal/db/sqlc"
+	"github.com/pixperk/vaultify/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBreakGlass(t *testing.T) {
+	owner := newTestUser(t)
+	oncall := newTestUser(t)
+	outsider := newTestUser(t)
+	security := newTestUser(t)
+
+	_, err := testStore.SetUserRole(context.Background(), db.SetUserRoleParams{Email: security.email, Role: systemRoleAuditor})
+	require.NoError(t, err)
+
+	secret := owner.createSecret("prod/db-password", "hunter2")
+
+	require.Equal(t, http.StatusForbidden, oncall.call(http.MethodPost, "/break-glass", breakGlassRequest{Path: secret.Path, Reason: "database down"}, nil))
+
+	var group groupResponse
+	require.Equal(t, http.StatusOK, owner.call(http.MethodPost, "/groups", createGroupRequest{Slug: util.RandomString(10), Name: "On-call"}, &group))
+	require.Equal(t, http.StatusOK, owner.call(http.MethodPost, "/groups/"+group.Slug+"/members", addGroupMemberRequest{Email: oncall.email, Role: "member"}, nil))
+
+	rules := "/break-glass/rules/" + secret.Path
+	require.Equal(t, http.StatusBadRequest, owner.call(http.MethodPut, rules, setBreakGlassRuleRequest{Group: group.Slug, MaxDurationSecs: 5 * 3600}, nil))
+	var rule breakGlassRuleResponse
+	require.Equal(t, http.StatusOK, owner.call(http.MethodPut, rules, setBreakGlassRuleRequest{Group: group.Slug, MaxDurationSecs: 1800}, &rule))
+	require.Equal(t, int64(1800), rule.MaxDurationSecs)
+	var listed listBreakGlassRulesResponse
+	require.Equal(t, http.StatusOK, oncall.call(http.MethodGet, "/break-glass/rules", nil, &listed))
+	require.Len(t, listed.Rules, 1)
+	require.Equal(t, secret.Path, listed.Rules[0].Path)
+
+	// only the emergency group, with a reason and within the rule's duration
+	require.Equal(t, http.StatusForbidden, outsider.call(http.MethodPost, "/break-glass", breakGlassRequest{Path: secret.Path, Reason: "curious"}, nil))
+	require.Equal(t, http.StatusBadRequest, oncall.call(http.MethodPost, "/break-glass", breakGlassRequest{Path: secret.Path, Reason: "  "}, nil))
+	require.Equal(t, http.StatusBadRequest, oncall.call(http.MethodPost, "/break-glass", breakGlassRequest{Path: secret.Path, Reason: "database down", DurationSecs: 3600}, nil))
+
+	var session breakGlassSessionResponse
+	require.Equal(t, http.StatusCreated, oncall.call(http.MethodPost, "/break-glass", breakGlassRequest{Path: secret.Path, Reason: "database down", DurationSecs: 600}, &session))
+	require.Equal(t, oncall.email, session.UserEmail)
+	require.WithinDuration(t, time.Now().Add(10*time.Minute), session.ExpiresAt, time.Minute)
+
+	got, code := oncall.getSecret(secret.Path)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "hunter2", got.Decrypted)
+	require.Equal(t, http.StatusConflict, oncall.call(http.MethodPost, "/break-glass", breakGlassRequest{Path: secret.Path, Reason: "again"}, nil))
+
+	// the share is recorded as granted by the secret's owner
+	var shared sharedWithMeResponse
+	require.Equal(t, http.StatusOK, oncall.call(http.MethodGet, "/shared-with-me", nil, &shared))
+	require.Len(t, shared.Shares, 1)
+	require.Equal(t, owner.email, shared.Shares[0].OwnerEmail)
+
+	// the use, the share it created and the read are flagged for review
+	var logs getAuditLogsResponse
+	require.Equal(t, http.StatusOK, oncall.call(http.MethodGet, "/audit?flagged=true", nil, &logs))
+	require.Len(t, logs.Logs, 3)
+	for _, l := range logs.Logs {
+		require.NotNil(t, l.BreakGlassSessionID)
+		require.Equal(t, session.ID, *l.BreakGlassSessionID)
+	}
+
+	require.Equal(t, http.StatusForbidden, oncall.call(http.MethodGet, "/sys/break-glass", nil, nil))
+	var unreviewed listBreakGlassSessionsResponse
+	require.Equal(t, http.StatusOK, security.call(http.MethodGet, "/sys/break-glass?reviewed=false", nil, &unreviewed))
+	require.NotEmpty(t, unreviewed.Sessions)
+	var detail breakGlassSessionDetailResponse
+	require.Equal(t, http.StatusOK, security.call(http.MethodGet, "/sys/break-glass/"+session.ID.String(), nil, &detail))
+	require.Len(t, detail.AuditLogs, 3)
+
+	review := "/sys/break-glass/" + session.ID.String() + "/review"
+	var done breakGlassSessionResponse
+	require.Equal(t, http.StatusOK, security.call(http.MethodPost, review, reviewBreakGlassRequest{Notes: "outage INC-42, justified"}, &done))
+	require.Equal(t, security.email, done.ReviewedBy)
+	require.NotNil(t, done.ReviewedAt)
+	require.Equal(t, http.StatusConflict, security.call(http.MethodPost, review, reviewBreakGlassRequest{Notes: "again"}, nil))
+
+	var mine listBreakGlassSessionsResponse
+	require.Equal(t, http.StatusOK, oncall.call(http.MethodGet, "/break-glass/sessions", nil, &mine))
+	require.Len(t, mine.Sessions, 1)
+	require.Equal(t, security.email, mine.Sessions[0].ReviewedBy)
+
+	require.Equal(t, http.StatusNoContent, owner.call(http.MethodDelete, rules, nil, nil))
+	require.Equal(t, http.StatusNotFound, owner.call(http.MethodDelete, rules, nil, nil))
+}
@@ -0,0 +1,214 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pixperk/vaultify/internal/auth"
+	"github.com/pixperk/vaultify/internal/cache"
+	db "github.com/pixperk/vaultify/internal/db/sqlc"
+	"github.com/pixperk/vaultify/internal/events"
+	"github.com/pixperk/vaultify/internal/policy"
+)
+
+// invalidationBuffer is how many events the cache may fall behind before it is purged
+const invalidationBuffer = 1024
+
+type secretRowKey struct {
+	path string
+	env  string
+}
+
+type readAccessKey struct {
+	userID uuid.UUID
+	path   string
+	// scope holds the policies a token is limited to, which change what it may do
+	scope string
+}
+
+// secretCache keeps what a secret read needs from the database: the latest encrypted row, the
+// HMAC key that signed it and what the reader may do with it. Values stay encrypted in the cache;
+// they are only decrypted per request. Entries are dropped when events announce a change and
+// expire after the TTL in any case, which bounds staleness if an event is missed.
+//
+// A value loaded from the database is only stored if no invalidation ran since the load began,
+// so a read racing a write cannot put back what the write just invalidated.
+type secretCache struct {
+	// mu is held exclusively while gen is bumped and entries are dropped, and shared while a
+	// loaded value is stored
+	mu  sync.RWMutex
+	gen uint64
+
+	rows     *cache.LRU[secretRowKey, db.GetLatestSecretByPathRow]
+	hmacKeys *cache.LRU[uuid.UUID, db.HmacKeys]
+	reads    *cache.LRU[readAccessKey, policy.Capability]
+}
+
+type cacheStatsResponse struct {
+	Secrets    cache.Stats `json:"secrets"`
+	HMACKeys   cache.Stats `json:"hmac_keys"`
+	ReadAccess cache.Stats `json:"read_access"`
+}
+
+func newSecretCache(size int, ttl time.Duration) *secretCache {
+	return &secretCache{
+		rows:     cache.NewLRU[secretRowKey, db.GetLatestSecretByPathRow](size, ttl),
+		hmacKeys: cache.NewLRU[uuid.UUID, db.HmacKeys](size, ttl),
+		reads:    cache.NewLRU[readAccessKey, policy.Capability](size, ttl),
+	}
+}
+
+func (c *secretCache) stats() cacheStatsResponse {
+	return cacheStatsResponse{
+		Secrets:    c.rows.Stats(),
+		HMACKeys:   c.hmacKeys.Stats(),
+		ReadAccess: c.reads.Stats(),
+	}
+}
+
+// generation is taken before loading a value and handed to store
+func (c *secretCache) generation() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.gen
+}
+
+// store runs set unless the cache was invalidated since gen was taken
+func (c *secretCache) store(gen uint64, set func()) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.gen == gen {
+		set()
+	}
+}
+
+func (c *secretCache) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	c.purgeLocked()
+}
+
+func (c *secretCache) purgeLocked() {
+	c.rows.Purge()
+	c.hmacKeys.Purge()
+	c.reads.Purge()
+}
+
+func (c *secretCache) invalidate(e events.Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+
+	switch e.Type {
+	case events.VersionChanged, events.Deleted, events.Expired:
+		c.rows.DeleteFunc(func(k secretRowKey) bool { return k.path == e.Path })
+		c.reads.DeleteFunc(func(k readAccessKey) bool { return k.path == e.Path })
+	case events.ShareChanged:
+		// folder shares carry the folder with a trailing slash and cover everything below it
+		if strings.HasSuffix(e.Path, "/") {
+			c.reads.DeleteFunc(func(k readAccessKey) bool { return strings.HasPrefix(k.path, e.Path) })
+			break
+		}
+		c.reads.DeleteFunc(func(k readAccessKey) bool { return k.path == e.Path })
+	case events.MembershipChanged:
+		c.reads.Purge()
+	case events.HMACKeyRotated:
+		c.hmacKeys.Purge()
+	case events.Reset:
+		c.purgeLocked()
+	}
+}
+
+// invalidateCache applies every event to the cache until ctx is done. If the cache falls
+// behind, it is purged, since it can no longer tell which entries are stale.
+func (s *Server) invalidateCache(ctx context.Context) {
+	go func() {
+		for {
+			sub := s.bus.Subscribe(invalidationBuffer, nil)
+			for open := true; open; {
+				select {
+				case e, ok := <-sub.C:
+					if !ok {
+						open = false
+						break
+					}
+					s.cache.invalidate(e)
+				case <-ctx.Done():
+					sub.Close()
+					return
+				}
+			}
+			s.cache.purge()
+		}
+	}()
+}
+
+// latestSecret is GetLatestSecretByPath through the cache
+func (s *Server) latestSecret(ctx context.Context, path, env string) (db.GetLatestSecretByPathRow, error) {
+	key := secretRowKey{path: path, env: env}
+	if secret, ok := s.cache.rows.Get(key); ok {
+		return secret, nil
+	}
+
+	gen := s.cache.generation()
+	secret, err := s.store.GetLatestSecretByPath(ctx, db.GetLatestSecretByPathParams{
+		Path:        path,
+		Environment: env,
+	})
+	if err != nil {
+		return secret, err
+	}
+	s.cache.store(gen, func() { s.cache.rows.Set(key, secret) })
+	return secret, nil
+}
+
+// hmacKey is GetHMACKeyByID through the cache
+func (s *Server) hmacKey(ctx context.Context, id uuid.UUID) (db.HmacKeys, error) {
+	if key, ok := s.cache.hmacKeys.Get(id); ok {
+		return key, nil
+	}
+
+	gen := s.cache.generation()
+	key, err := s.store.GetHMACKeyByID(ctx, id)
+	if err != nil {
+		return key, err
+	}
+	s.cache.store(gen, func() { s.cache.hmacKeys.Set(id, key) })
+	return key, nil
+}
+
+// cachedCapabilities is capabilities through the cache. Both grants and denials are cached;
+// shares, revocations and membership changes invalidate them. Policies only change on restart.
+// A grant resting on a time-bound share is not cached past the share's expiry.
+func (s *Server) cachedCapabilities(ctx context.Context, authPayload *auth.Payload, secret db.GetLatestSecretByPathRow) (policy.Capability, error) {
+	key := readAccessKey{userID: authPayload.UserID, path: secret.Path, scope: strings.Join(authPayload.Policies, ",")}
+	if caps, ok := s.cache.reads.Get(key); ok {
+		return caps, nil
+	}
+
+	gen := s.cache.generation()
+	caps, until, err := s.capabilitiesUntil(ctx, authPayload, secret)
+	if err != nil {
+		return 0, err
+	}
+	s.cache.store(gen, func() { s.cache.reads.SetUntil(key, caps, until) })
+	return caps, nil
+}
+
+// @Summary      Read cache statistics
+// @Description  Hit, miss and eviction counts of the in-memory caches used for secret reads on this instance. Admins and auditors only.
+// @Tags         System
+// @Produce      json
+// @Success      200  {object} cacheStatsResponse
+// @Failure      403  {object} swaggerErrorResponse "Not an admin or auditor"
+// @Security     BearerAuth
+// @Router       /sys/cache/stats [get]
+func (s *Server) getCacheStats(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, s.cache.stats())
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pixperk/vaultify/internal/auth"
+	db "github.com/pixperk/vaultify/internal/db/sqlc"
+	"github.com/pixperk/vaultify/internal/secretpath"
+)
+
+type capabilitiesResponse struct {
+	Path         string   `json:"path"`
+	Capabilities []string `json:"capabilities"`
+	// Policies names the policies attached to the caller that have a rule matching the path
+	Policies []string `json:"policies"`
+	// TokenPolicies are the policies the caller's token is limited to, if any
+	TokenPolicies []string `json:"token_policies,omitempty"`
+}
+
+// @Summary      Check my capabilities on a path
+// @Description  Lists what the caller may do on a secret path: read, create, update, delete, list, rollback, share and history. Owners of a path, team roles and shares grant capabilities built in; policies attached to the caller or their groups add more, a matching deny rule removes everything, and a token issued for named policies keeps only what those grant. The path does not need to exist.
+// @Tags         Secrets
+// @Produce      json
+// @Param        path  path     string  true  "Secret path"
+// @Success      200   {object} capabilitiesResponse
+// @Failure      400   {object} swaggerErrorResponse "Invalid path"
+// @Failure      500   {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /capabilities/{path} [get]
+func (s *Server) getCapabilities(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	resp, err := s.pathCapabilities(ctx, authPayload, ctx.Param("path"))
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// pathCapabilities runs the authorizer for the caller on rawPath, using the owner of the secret
+// there or, when there is none, whoever would own a secret created there
+func (s *Server) pathCapabilities(ctx context.Context, authPayload *auth.Payload, rawPath string) (capabilitiesResponse, error) {
+	path, err := secretpath.Normalize(rawPath)
+	if err != nil {
+		return capabilitiesResponse{}, newStatusError(http.StatusBadRequest, "%s", err)
+	}
+
+	var owner db.GetLatestSecretByPathRow
+	secret, err := s.store.GetSecretByPath(ctx, path)
+	switch {
+	case err == nil:
+		owner = secretOwnership(secret)
+	case err == sql.ErrNoRows:
+		if owner, err = s.pathOwnership(ctx, authPayload, path); err != nil {
+			return capabilitiesResponse{}, err
+		}
+	default:
+		return capabilitiesResponse{}, err
+	}
+
+	caps, err := s.capabilities(ctx, authPayload, owner)
+	if err != nil {
+		return capabilitiesResponse{}, err
+	}
+	sub, err := s.policySubject(ctx, authPayload)
+	if err != nil {
+		return capabilitiesResponse{}, err
+	}
+
+	resp := capabilitiesResponse{
+		Path:          path,
+		Capabilities:  caps.Names(),
+		Policies:      s.policies.Evaluate(sub, path).Policies,
+		TokenPolicies: authPayload.Policies,
+	}
+	if resp.Policies == nil {
+		resp.Policies = []string{}
+	}
+	return resp, nil
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicies(t *testing.T) {
+	owner := newTestUser(t)
+	reader := newTestUser(t)
+
+	shared := owner.createSecret("policy-shared/key", "k")
+	denied := reader.createSecret("policy-denied/key", "d")
+
+	_, code := reader.getSecret(shared.Path)
+	require.Equal(t, http.StatusForbidden, code)
+	var caps capabilitiesResponse
+	require.Equal(t, http.StatusOK, reader.call(http.MethodGet, "/capabilities/"+shared.Path, nil, &caps))
+	require.Empty(t, caps.Capabilities)
+
+	// joining the group attaches the policy
+	require.Equal(t, http.StatusOK, reader.call(http.MethodPost, "/groups", createGroupRequest{Slug: policyGroup, Name: "Policy readers"}, nil))
+
+	secret, code := reader.getSecret(shared.Path)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "k", secret.Decrypted)
+	require.Equal(t, http.StatusForbidden, reader.call(http.MethodPut, "/secrets/"+shared.Path, updateSecretRequest{Value: "changed"}, nil))
+
+	require.Equal(t, http.StatusOK, reader.call(http.MethodGet, "/capabilities/"+shared.Path, nil, &caps))
+	require.Equal(t, []string{"read", "list"}, caps.Capabilities)
+	require.Equal(t, []string{"test-policy"}, caps.Policies)
+
+	// a deny beats owning the secret, and covers paths that do not exist yet
+	_, code = reader.getSecret(denied.Path)
+	require.Equal(t, http.StatusForbidden, code)
+	require.Equal(t, http.StatusForbidden, reader.call(http.MethodPost, "/secrets/", createSecretRequest{Path: "policy-denied/other", Value: "x"}, nil))
+
+	caps = capabilitiesResponse{}
+	require.Equal(t, http.StatusOK, owner.call(http.MethodGet, "/capabilities/"+shared.Path, nil, &caps))
+	require.Equal(t, []string{"read", "create", "update", "delete", "list", "rollback", "share", "history"}, caps.Capabilities)
+	require.Empty(t, caps.Policies)
+}
+
+func TestScopedToken(t *testing.T) {
+	u := newTestUser(t)
+
+	require.Equal(t, http.StatusBadRequest, u.login("missing"))
+
+	own := u.createSecret("policy-shared/own", "v")
+	other := u.createSecret("app/key", "v")
+
+	// the scoped token keeps only what test-policy grants, even on the user's own secrets
+	require.Equal(t, http.StatusOK, u.login("test-policy"))
+	_, code := u.getSecret(own.Path)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, http.StatusForbidden, u.call(http.MethodPut, "/secrets/"+own.Path, updateSecretRequest{Value: "v2"}, nil))
+	_, code = u.getSecret(other.Path)
+	require.Equal(t, http.StatusForbidden, code)
+
+	var caps capabilitiesResponse
+	require.Equal(t, http.StatusOK, u.call(http.MethodGet, "/capabilities/"+own.Path, nil, &caps))
+	require.Equal(t, []string{"read", "list"}, caps.Capabilities)
+	require.Equal(t, []string{"test-policy"}, caps.TokenPolicies)
+}
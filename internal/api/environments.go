@@ -0,0 +1,430 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pixperk/vaultify/internal/auth"
+	db "github.com/pixperk/vaultify/internal/db/sqlc"
+	"github.com/pixperk/vaultify/internal/logger"
+	"github.com/pixperk/vaultify/internal/policy"
+	"github.com/pixperk/vaultify/internal/secretpath"
+	"github.com/pixperk/vaultify/internal/util"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultEnvironment holds values written without an explicit environment
+	defaultEnvironment = "default"
+
+	environmentKey = "environment"
+)
+
+// isKnownEnvironment reports whether env is the default environment or part of the promotion order
+func (s *Server) isKnownEnvironment(env string) bool {
+	if env == defaultEnvironment {
+		return true
+	}
+	for _, e := range s.config.PromotionOrder {
+		if e == env {
+			return true
+		}
+	}
+	return false
+}
+
+// environmentParam reads the env query parameter, falling back to the default environment
+func (s *Server) environmentParam(ctx *gin.Context) (string, error) {
+	env := ctx.DefaultQuery("env", defaultEnvironment)
+	if !s.isKnownEnvironment(env) {
+		return "", fmt.Errorf("unknown environment %q", env)
+	}
+	return env, nil
+}
+
+// promotionIndex returns the position of env in the promotion order, or -1
+func (s *Server) promotionIndex(env string) int {
+	for i, e := range s.config.PromotionOrder {
+		if e == env {
+			return i
+		}
+	}
+	return -1
+}
+
+// loadSecret returns the latest version of the secret in env. When the secret exists but has no
+// value in env yet, the returned row carries only the secret's ownership and has Version 0.
+func (s *Server) loadSecret(ctx context.Context, path, env string) (db.GetLatestSecretByPathRow, error) {
+	secret, err := s.store.GetLatestSecretByPath(ctx, db.GetLatestSecretByPathParams{
+		Path:        path,
+		Environment: env,
+	})
+	if err != sql.ErrNoRows {
+		return secret, err
+	}
+
+	owner, err := s.store.GetSecretByPath(ctx, path)
+	if err != nil {
+		return secret, err
+	}
+
+	secret = secretOwnership(owner)
+	secret.Environment = env
+	return secret, nil
+}
+
+type listEnvironmentsResponse struct {
+	Default        string   `json:"default"`
+	PromotionOrder []string `json:"promotion_order"`
+}
+
+type promoteSecretRequest struct {
+	From    string `json:"from" binding:"required"`
+	To      string `json:"to"`
+	Version int32  `json:"version"`
+}
+
+type promoteSecretResponse struct {
+	Path        string `json:"path"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	FromVersion int32  `json:"from_version"`
+	NewVersion  int32  `json:"new_version"`
+}
+
+type environmentDrift struct {
+	Version int32  `json:"version,omitempty"`
+	Status  string `json:"status"`
+}
+
+type secretDrift struct {
+	Path         string                      `json:"path"`
+	InSync       bool                        `json:"in_sync"`
+	Environments map[string]environmentDrift `json:"environments"`
+}
+
+type driftReportResponse struct {
+	Environments []string      `json:"environments"`
+	Secrets      []secretDrift `json:"secrets"`
+}
+
+// drift statuses for a single environment of a secret
+const (
+	driftMissing    = "missing"
+	driftSource     = "source"
+	driftInSync     = "in_sync"
+	driftDiffers    = "differs"
+	driftUnreadable = "unreadable"
+)
+
+// @Summary      List environments
+// @Description  Returns the default environment and the promotion order (e.g. dev -> staging -> prod).
+// @Tags         Environments
+// @Produce      json
+// @Success      200     {object} listEnvironmentsResponse
+// @Security     BearerAuth
+// @Router       /environments [get]
+func (s *Server) listEnvironments(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, listEnvironmentsResponse{
+		Default:        defaultEnvironment,
+		PromotionOrder: s.config.PromotionOrder,
+	})
+}
+
+// @Summary      Promote a secret version to the next environment
+// @Description  Copies a version (latest by default) of the secret from one environment into the next one in the promotion order, re-encrypting and re-signing it. The source HMAC is verified first.
+// @Tags         Environments
+// @Accept       json
+// @Produce      json
+// @Param        path    path     string                true  "Secret path"
+// @Param        request body     promoteSecretRequest  true  "Promotion request"
+// @Success      200     {object} promoteSecretResponse
+// @Failure      400     {object} swaggerErrorResponse "Unknown environment or invalid promotion"
+// @Failure      401     {object} swaggerErrorResponse "Invalid HMAC on the source version"
+// @Failure      403     {object} swaggerErrorResponse "Access denied"
+// @Failure      404     {object} swaggerErrorResponse "Source version not found"
+// @Failure      500     {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /secrets/promote/{path} [post]
+func (s *Server) promoteSecret(ctx *gin.Context) {
+	var req promoteSecretRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	secret := ctx.MustGet("secret").(db.GetLatestSecretByPathRow)
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	fromIdx := s.promotionIndex(req.From)
+	if fromIdx < 0 {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("unknown environment %q", req.From)))
+		return
+	}
+	if req.To == "" {
+		if fromIdx == len(s.config.PromotionOrder)-1 {
+			ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("%s is the last environment in the promotion order", req.From)))
+			return
+		}
+		req.To = s.config.PromotionOrder[fromIdx+1]
+	}
+	if toIdx := s.promotionIndex(req.To); toIdx <= fromIdx {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("secrets can only be promoted forward, from %s to a later environment", req.From)))
+		return
+	}
+
+	var source db.GetLatestSecretByPathRow
+	var err error
+	if req.Version == 0 {
+		source, err = s.store.GetLatestSecretByPath(ctx, db.GetLatestSecretByPathParams{
+			Path:        secret.Path,
+			Environment: req.From,
+		})
+	} else {
+		var versioned db.GetSecretVersionByPathAndVersionRow
+		versioned, err = s.store.GetSecretVersionByPathAndVersion(ctx, db.GetSecretVersionByPathAndVersionParams{
+			Path:        secret.Path,
+			Version:     req.Version,
+			Environment: req.From,
+		})
+		source = db.GetLatestSecretByPathRow(versioned)
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("no %s version to promote", req.From)))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	sourceHmacKey, err := s.store.GetHMACKeyByID(ctx, source.HmacKeyID.UUID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	isVerified, err := VerifySecretHMAC(source, sourceHmacKey.Key)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if !isVerified {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(fmt.Errorf("invalid HMAC signature")))
+		failureReason := "invalid HMAC signature"
+		err = s.auditSvc.Log(ctx, authPayload.UserID, authPayload.Email, "promote_secret", secret.Path, source.Version, false, &failureReason)
+		if err != nil {
+			logger.New(s.config.Env).Error("failed to log secret promotion", zap.Error(err))
+		}
+		return
+	}
+
+	decryptedValue, err := s.encryptor.Decrypt(source.EncryptedValue, source.Nonce)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	encryptedValue, nonce, err := s.encryptor.Encrypt(decryptedValue)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	hmacKey, err := s.store.GetActiveHMACKey(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(fmt.Errorf("failed to fetch active HMAC key")))
+		return
+	}
+
+	hmacPayload := util.ComputeHMACPayload(encryptedValue, nonce)
+	hmacSig, err := util.GenerateHMACSignature(hmacPayload, hmacKey.Key)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(fmt.Errorf("failed to generate HMAC signature")))
+		return
+	}
+
+	args := db.CreateNewSecretVersionParams{
+		CreatedBy: uuid.NullUUID{
+			UUID:  authPayload.UserID,
+			Valid: true,
+		},
+		Path:           secret.Path,
+		EncryptedValue: encryptedValue,
+		Nonce:          nonce,
+		HmacSignature:  hmacSig,
+		HmacKeyID: uuid.NullUUID{
+			UUID:  hmacKey.ID,
+			Valid: true,
+		},
+		Environment: req.To,
+	}
+
+	var promoted db.SecretVersions
+	err = s.store.ExecTx(ctx, func(q *db.Queries) error {
+		promoted, err = q.CreateNewSecretVersion(ctx, args)
+		if err != nil {
+			return err
+		}
+
+		reason := fmt.Sprintf("%s v%d -> %s v%d", req.From, source.Version, req.To, promoted.Version)
+		if err = s.auditSvc.LogTx(ctx, q, authPayload.UserID, authPayload.Email, "promote_secret", secret.Path, promoted.Version, true, &reason); err != nil {
+			return fmt.Errorf("failed to log action: %w", err)
+		}
+		return s.publishTx(ctx, q, versionChanged(secret, promoted.Environment, promoted.Version))
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, promoteSecretResponse{
+		Path:        secret.Path,
+		From:        req.From,
+		To:          req.To,
+		FromVersion: source.Version,
+		NewVersion:  promoted.Version,
+	})
+}
+
+// @Summary      Environment drift report
+// @Description  For every readable secret under the prefix (the caller's own namespace by default), shows the latest version per environment and whether its value differs from the previous environment in the promotion order. Values are compared in memory and never returned; versions that fail the HMAC check or cannot be decrypted are reported as unreadable.
+// @Tags         Environments
+// @Produce      json
+// @Param        prefix  query    string  false  "Path prefix"
+// @Success      200     {object} driftReportResponse
+// @Failure      500     {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /environments/drift [get]
+func (s *Server) getDriftReport(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	prefix, err := secretpath.NormalizePrefix(ctx.DefaultQuery("prefix", authPayload.Email+"/"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	rows, err := s.store.GetLatestEnvironmentVersionsByPrefix(ctx, prefix)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// group the latest version of each environment by secret path. The first row of a path, in
+	// query order, stands in for the secret when checking access.
+	byPath := make(map[string]map[string]db.GetLatestEnvironmentVersionsByPrefixRow)
+	owners := make(map[string]db.GetLatestEnvironmentVersionsByPrefixRow)
+	for _, row := range rows {
+		if byPath[row.Path] == nil {
+			byPath[row.Path] = make(map[string]db.GetLatestEnvironmentVersionsByPrefixRow)
+			owners[row.Path] = row
+		}
+		byPath[row.Path][row.Environment] = row
+	}
+
+	paths := make([]string, 0, len(byPath))
+	for path := range byPath {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	report := driftReportResponse{
+		Environments: s.config.PromotionOrder,
+		Secrets:      []secretDrift{},
+	}
+
+	for _, path := range paths {
+		envs := byPath[path]
+		owner := owners[path]
+
+		caps, err := s.cachedCapabilities(ctx, authPayload, db.GetLatestSecretByPathRow{
+			UserID: owner.UserID,
+			Path:   owner.Path,
+			TeamID: owner.TeamID,
+		})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		if !caps.Has(policy.Read) {
+			continue
+		}
+
+		entry := secretDrift{
+			Path:         path,
+			InSync:       true,
+			Environments: make(map[string]environmentDrift),
+		}
+
+		var previous []byte
+		for _, env := range s.config.PromotionOrder {
+			row, ok := envs[env]
+			if !ok {
+				entry.Environments[env] = environmentDrift{Status: driftMissing}
+				entry.InSync = false
+				continue
+			}
+
+			digest, ok, err := s.driftDigest(ctx, row)
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+				return
+			}
+			if !ok {
+				entry.Environments[env] = environmentDrift{Version: row.Version, Status: driftUnreadable}
+				entry.InSync = false
+				continue
+			}
+
+			status := driftSource
+			if previous != nil {
+				status = driftInSync
+				if string(previous) != string(digest[:]) {
+					status = driftDiffers
+					entry.InSync = false
+				}
+			}
+			previous = digest[:]
+
+			entry.Environments[env] = environmentDrift{Version: row.Version, Status: status}
+		}
+
+		report.Secrets = append(report.Secrets, entry)
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+// driftDigest hashes the plaintext of a version for comparison. Versions whose HMAC key is gone,
+// whose signature does not verify or that cannot be decrypted are reported as not ok.
+func (s *Server) driftDigest(ctx context.Context, row db.GetLatestEnvironmentVersionsByPrefixRow) ([]byte, bool, error) {
+	if !row.HmacKeyID.Valid {
+		return nil, false, nil
+	}
+	key, err := s.hmacKey(ctx, row.HmacKeyID.UUID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	verified, err := util.VerifyHMAC(util.ComputeHMACPayload(row.EncryptedValue, row.Nonce), row.HmacSignature, key.Key)
+	if err != nil || !verified {
+		return nil, false, nil
+	}
+
+	plain, err := s.encryptor.Decrypt(row.EncryptedValue, row.Nonce)
+	if err != nil {
+		return nil, false, nil
+	}
+	digest := sha256.Sum256(plain)
+	return digest[:], true, nil
+}
@@ -2,8 +2,14 @@ package api
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"time"
+
+	"github.com/google/uuid"
+	db "github.com/pixperk/vaultify/internal/db/sqlc"
+	"github.com/pixperk/vaultify/internal/events"
+	"github.com/pixperk/vaultify/internal/notify"
 )
 
 func (s *Server) cleanExpiredSecrets(interval time.Duration) {
@@ -12,19 +18,177 @@ func (s *Server) cleanExpiredSecrets(interval time.Duration) {
 		defer ticker.Stop()
 
 		for range ticker.C {
+			// warnings go out before the deletes so nothing expires unannounced within one tick
+			warnCtx, cancelWarn := context.WithTimeout(context.Background(), interval)
+			s.sendExpiryWarnings(warnCtx, time.Now())
+			cancelWarn()
+
 			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 
-			if err := s.store.DeleteExpiredSecretAndVersions(ctx); err != nil {
+			if err := s.expireSecrets(ctx); err != nil {
 				log.Printf("Error deleting expired secrets: %v\n", err)
 			}
 
-			if err := s.store.DeleteExpiredSharingRules(ctx); err != nil {
+			if err := s.expireSharingRules(ctx); err != nil {
 				log.Printf("Error deleting expired sharing rules: %v\n", err)
 			}
 
+			if err := s.store.DeleteStaleExpiryWarnings(ctx); err != nil {
+				log.Printf("Error deleting stale expiry warnings: %v\n", err)
+			}
+
 			cancel()
 
 			log.Println("Expired secrets and sharing rules cleaned up.")
 		}
 	}()
 }
+
+// expireSecrets deletes expired secrets and records an audit entry for each under its owner
+func (s *Server) expireSecrets(ctx context.Context) error {
+	reason := "expired"
+	return s.store.ExecTx(ctx, func(q *db.Queries) error {
+		deleted, err := q.DeleteExpiredSecretAndVersions(ctx)
+		if err != nil {
+			return err
+		}
+		for _, secret := range deleted {
+			if err := s.auditSvc.LogTx(ctx, q, secret.UserID, secret.OwnerEmail, "expire_secret", secret.Path, 0, true, &reason); err != nil {
+				return fmt.Errorf("failed to log action: %w", err)
+			}
+			if err := s.publishTx(ctx, q, events.Event{
+				Type:    events.Expired,
+				Path:    secret.Path,
+				OwnerID: secret.UserID,
+				TeamID:  secret.TeamID,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// expireSharingRules deletes expired shares and records an audit entry for each under the sharing user
+func (s *Server) expireSharingRules(ctx context.Context) error {
+	return s.store.ExecTx(ctx, func(q *db.Queries) error {
+		deleted, err := q.DeleteExpiredSharingRules(ctx)
+		if err != nil {
+			return err
+		}
+		for _, rule := range deleted {
+			owner, err := q.GetUserByEmail(ctx, rule.OwnerEmail)
+			if err != nil {
+				return fmt.Errorf("failed to load owner of share on %s: %w", rule.Path, err)
+			}
+			reason := fmt.Sprintf("%s access for %s expired", rule.Permission, rule.TargetEmail)
+			if err := s.auditSvc.LogTx(ctx, q, owner.ID, owner.Email, "expire_share", sharedPath(rule.Path, rule.IsPrefix), 0, true, &reason); err != nil {
+				return fmt.Errorf("failed to log action: %w", err)
+			}
+			if err := s.publishTx(ctx, q, events.Event{
+				Type:   events.ShareChanged,
+				Path:   shareEventPath(rule.Path, rule.IsPrefix),
+				Target: rule.TargetEmail,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// warningThreshold returns the shortest configured threshold the remaining time falls within.
+// Only that warning is sent, so a secret created with a short TTL gets one warning, not all of them.
+func (s *Server) warningThreshold(remaining time.Duration) (time.Duration, bool) {
+	thresholds := s.config.ExpiryWarningThresholds
+	for i := len(thresholds) - 1; i >= 0; i-- {
+		if remaining <= thresholds[i] {
+			return thresholds[i], true
+		}
+	}
+	return 0, false
+}
+
+// sendExpiryWarnings notifies owners of secrets and both sides of shares that are about to expire.
+// Each warning is claimed in the database first so restarts and other instances do not repeat it.
+func (s *Server) sendExpiryWarnings(ctx context.Context, now time.Time) {
+	if len(s.config.ExpiryWarningThresholds) == 0 {
+		return
+	}
+	horizon := now.Add(s.config.ExpiryWarningThresholds[0])
+
+	secrets, err := s.store.ListSecretsExpiringBefore(ctx, horizon)
+	if err != nil {
+		log.Printf("Error listing expiring secrets: %v\n", err)
+	} else {
+		for _, secret := range secrets {
+			expiresAt := secret.ExpiresAt.Time
+			s.sendExpiryWarning(ctx, "secret", secret.ID, expiresAt, now, notify.Message{
+				Event:      "secret.expiring",
+				Recipients: []string{secret.OwnerEmail},
+				Subject:    fmt.Sprintf("Secret %s expires soon", secret.Path),
+				Body: fmt.Sprintf("The secret %s expires at %s and will be deleted with all of its versions.\n"+
+					"Extend its TTL or move the value elsewhere before then.",
+					secret.Path, expiresAt.UTC().Format(time.RFC1123)),
+				Path:      secret.Path,
+				ExpiresAt: &expiresAt,
+			})
+		}
+	}
+
+	shares, err := s.store.ListSharesExpiringBefore(ctx, horizon)
+	if err != nil {
+		log.Printf("Error listing expiring sharing rules: %v\n", err)
+		return
+	}
+	for _, share := range shares {
+		expiresAt := share.SharedUntil.Time
+		path := sharedPath(share.Path, share.IsPrefix)
+		recipients := []string{share.OwnerEmail}
+		// shares with a group have no single recipient on the other side
+		if email, _ := splitPrincipal(share.TargetEmail); email != "" {
+			recipients = append(recipients, email)
+		}
+		s.sendExpiryWarning(ctx, "share", share.ID, expiresAt, now, notify.Message{
+			Event:      "share.expiring",
+			Recipients: recipients,
+			Subject:    fmt.Sprintf("Access to %s expires soon", path),
+			Body: fmt.Sprintf("%s access to %s shared by %s with %s ends at %s.\n"+
+				"Ask %s to share it again if access is still needed.",
+				share.Permission, path, share.OwnerEmail, share.TargetEmail,
+				expiresAt.UTC().Format(time.RFC1123), share.OwnerEmail),
+			Path:      path,
+			ExpiresAt: &expiresAt,
+		})
+	}
+}
+
+func (s *Server) sendExpiryWarning(ctx context.Context, resourceType string, resourceID uuid.UUID, expiresAt, now time.Time, msg notify.Message) {
+	threshold, ok := s.warningThreshold(expiresAt.Sub(now))
+	if !ok {
+		return
+	}
+
+	claim := db.ClaimExpiryWarningParams{
+		ResourceType:     resourceType,
+		ResourceID:       resourceID,
+		ThresholdSeconds: int64(threshold / time.Second),
+		ExpiresAt:        expiresAt,
+	}
+	claimed, err := s.store.ClaimExpiryWarning(ctx, claim)
+	if err != nil {
+		log.Printf("Error recording expiry warning for %s: %v\n", msg.Path, err)
+		return
+	}
+	if claimed == 0 {
+		return
+	}
+
+	if err := s.notifier.Notify(ctx, msg); err != nil {
+		log.Printf("Error sending expiry warning for %s: %v\n", msg.Path, err)
+		// release the claim so the next tick retries the delivery
+		if err := s.store.ReleaseExpiryWarning(ctx, db.ReleaseExpiryWarningParams(claim)); err != nil {
+			log.Printf("Error releasing expiry warning for %s: %v\n", msg.Path, err)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pixperk/vaultify/internal/auth"
+	"github.com/pixperk/vaultify/internal/config"
+	db "github.com/pixperk/vaultify/internal/db/sqlc"
+	"github.com/pixperk/vaultify/internal/policy"
+)
+
+type expiringSecretResponse struct {
+	Path      string    `json:"path"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type expiringShareResponse struct {
+	Path        string    `json:"path"`
+	OwnerEmail  string    `json:"owner_email"`
+	TargetEmail string    `json:"target_email,omitempty"`
+	TargetGroup string    `json:"target_group,omitempty"`
+	Permission  string    `json:"permission"`
+	SharedUntil time.Time `json:"shared_until"`
+}
+
+type listExpiringResponse struct {
+	Before  time.Time                `json:"before"`
+	Secrets []expiringSecretResponse `json:"secrets"`
+	Shares  []expiringShareResponse  `json:"shares"`
+}
+
+// @Summary      List secrets and shares expiring soon
+// @Description  Lists the readable secrets whose TTL ends within the window, and the shares granted by or to the caller that end within it. The window defaults to the longest configured expiry warning and accepts Go durations or whole days, e.g. 36h or 7d.
+// @Tags         Secrets
+// @Produce      json
+// @Param        within  query    string  false  "Window to look ahead, e.g. 7d"
+// @Success      200     {object} listExpiringResponse
+// @Failure      400     {object} swaggerErrorResponse "Invalid window"
+// @Failure      500     {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /expiring [get]
+func (s *Server) listExpiring(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	var within time.Duration
+	if len(s.config.ExpiryWarningThresholds) > 0 {
+		within = s.config.ExpiryWarningThresholds[0]
+	}
+	if param := ctx.Query("within"); param != "" {
+		d, err := config.ParseDuration(param)
+		if err != nil || d <= 0 {
+			ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("invalid within %q", param)))
+			return
+		}
+		within = d
+	}
+	before := time.Now().Add(within)
+
+	secrets, err := s.store.ListSecretsExpiringBefore(ctx, before)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	resp := listExpiringResponse{
+		Before:  before,
+		Secrets: []expiringSecretResponse{},
+		Shares:  []expiringShareResponse{},
+	}
+	for _, secret := range secrets {
+		allowed, err := s.can(ctx, authPayload, db.GetLatestSecretByPathRow{
+			UserID: secret.UserID,
+			Path:   secret.Path,
+			TeamID: secret.TeamID,
+		}, policy.Read)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		if allowed {
+			resp.Secrets = append(resp.Secrets, expiringSecretResponse{
+				Path:      secret.Path,
+				ExpiresAt: secret.ExpiresAt.Time,
+			})
+		}
+	}
+
+	shares, err := s.store.ListSharesExpiringBeforeForUser(ctx, db.ListSharesExpiringBeforeForUserParams{
+		Before: before,
+		Email:  authPayload.Email,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	for _, share := range shares {
+		item := expiringShareResponse{
+			Path:        sharedPath(share.Path, share.IsPrefix),
+			OwnerEmail:  share.OwnerEmail,
+			Permission:  share.Permission,
+			SharedUntil: share.SharedUntil.Time,
+		}
+		item.TargetEmail, item.TargetGroup = splitPrincipal(share.TargetEmail)
+		resp.Shares = append(resp.Shares, item)
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
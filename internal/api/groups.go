@@ -0,0 +1,361 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/pixperk/vaultify/internal/auth"
+	db "github.com/pixperk/vaultify/internal/db/sqlc"
+)
+
+const (
+	groupRoleOwner  = "owner"
+	groupRoleMember = "member"
+
+	// shares with a group store the group as target_email in the form group:<slug>
+	groupPrincipalPrefix = "group:"
+)
+
+// groupPrincipal is the share target standing for the group
+func groupPrincipal(slug string) string {
+	return groupPrincipalPrefix + slug
+}
+
+// splitPrincipal tells apart the share target of a user from that of a group
+func splitPrincipal(target string) (email, group string) {
+	if slug, ok := strings.CutPrefix(target, groupPrincipalPrefix); ok {
+		return "", slug
+	}
+	return target, ""
+}
+
+type createGroupRequest struct {
+	Slug string `json:"slug" binding:"required"`
+	Name string `json:"name" binding:"required"`
+}
+
+type groupResponse struct {
+	Slug      string    `json:"slug"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type addGroupMemberRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required,oneof=owner member"`
+}
+
+// groupMembership loads the group named in the route and the caller's role in it.
+// It writes the error response itself and returns ok=false when the caller is not a member.
+func (s *Server) groupMembership(ctx *gin.Context, authPayload *auth.Payload) (group db.Groups, role string, ok bool) {
+	group, err := s.store.GetGroupBySlug(ctx, ctx.Param("group"))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("group not found")))
+			return group, "", false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return group, "", false
+	}
+
+	role, err = s.store.GetGroupMemberRole(ctx, db.GetGroupMemberRoleParams{
+		GroupID: group.ID,
+		UserID:  authPayload.UserID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// don't reveal the members of groups the caller is not part of
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("group not found")))
+			return group, "", false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return group, "", false
+	}
+
+	return group, role, true
+}
+
+var errLastGroupOwner = fmt.Errorf("a group must keep at least one owner")
+
+// ensureGroupOwnerRemains rejects a change that would demote or remove the group's last owner.
+// The owners stay locked until the transaction ends.
+func (s *Server) ensureGroupOwnerRemains(ctx context.Context, q *db.Queries, groupID, userID uuid.UUID, newRole string) error {
+	if newRole == groupRoleOwner {
+		return nil
+	}
+
+	owners, err := q.LockGroupOwners(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(owners, userID) && len(owners) <= 1 {
+		return errLastGroupOwner
+	}
+	return nil
+}
+
+// @Summary      Create a group
+// @Description  Creates a group of users that secrets can be shared with; the caller becomes its owner.
+// @Tags         Groups
+// @Accept       json
+// @Produce      json
+// @Param        request body     createGroupRequest  true  "Group"
+// @Success      200     {object} groupResponse
+// @Failure      400     {object} swaggerErrorResponse "Invalid slug"
+// @Failure      409     {object} swaggerErrorResponse "Slug already taken"
+// @Failure      500     {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /groups [post]
+func (s *Server) createGroup(ctx *gin.Context) {
+	var req createGroupRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if !slugPattern.MatchString(req.Slug) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("slug must be lowercase letters, digits and dashes")))
+		return
+	}
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	var group db.Groups
+	err := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		var err error
+		group, err = q.CreateGroup(ctx, db.CreateGroupParams{
+			Slug:      req.Slug,
+			Name:      req.Name,
+			CreatedBy: authPayload.UserID,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = q.UpsertGroupMember(ctx, db.UpsertGroupMemberParams{
+			GroupID: group.ID,
+			UserID:  authPayload.UserID,
+			Role:    groupRoleOwner,
+		})
+		if err != nil {
+			return err
+		}
+
+		return s.logOrgAction(ctx, q, authPayload, "create_group", groupPrincipal(group.Slug), "created group "+group.Name)
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			ctx.JSON(http.StatusConflict, errorResponse(fmt.Errorf("group slug already taken")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, groupResponse{
+		Slug:      group.Slug,
+		Name:      group.Name,
+		Role:      groupRoleOwner,
+		CreatedAt: group.CreatedAt.Time,
+	})
+}
+
+// @Summary      List my groups
+// @Description  Lists the groups the caller belongs to, with the caller's role in each.
+// @Tags         Groups
+// @Produce      json
+// @Success      200     {array}  groupResponse
+// @Failure      500     {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /groups [get]
+func (s *Server) listGroups(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	groups, err := s.store.ListGroupsForUser(ctx, authPayload.UserID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	resp := make([]groupResponse, 0, len(groups))
+	for _, g := range groups {
+		resp = append(resp, groupResponse{
+			Slug:      g.Slug,
+			Name:      g.Name,
+			Role:      g.Role,
+			CreatedAt: g.CreatedAt.Time,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// @Summary      List group members
+// @Tags         Groups
+// @Produce      json
+// @Param        group   path     string  true  "Group slug"
+// @Success      200     {array}  memberResponse
+// @Failure      404     {object} swaggerErrorResponse "Group not found"
+// @Failure      500     {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /groups/{group}/members [get]
+func (s *Server) listGroupMembers(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+	group, _, ok := s.groupMembership(ctx, authPayload)
+	if !ok {
+		return
+	}
+
+	members, err := s.store.ListGroupMembers(ctx, group.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	resp := make([]memberResponse, 0, len(members))
+	for _, m := range members {
+		resp = append(resp, memberResponse{
+			Email:     m.Email,
+			Name:      m.Name,
+			Role:      m.Role,
+			CreatedAt: m.CreatedAt.Time,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// @Summary      Add or update a group member
+// @Description  Adds a user to the group or changes their role. They immediately gain access to everything shared with the group. Requires group owner.
+// @Tags         Groups
+// @Accept       json
+// @Produce      json
+// @Param        group   path     string                 true  "Group slug"
+// @Param        request body     addGroupMemberRequest  true  "Member"
+// @Success      200     {object} memberResponse
+// @Failure      400     {object} swaggerErrorResponse "Cannot demote the last owner"
+// @Failure      403     {object} swaggerErrorResponse "Not a group owner"
+// @Failure      404     {object} swaggerErrorResponse "Group or user not found"
+// @Failure      500     {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /groups/{group}/members [post]
+func (s *Server) addGroupMember(ctx *gin.Context) {
+	var req addGroupMemberRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	group, role, ok := s.groupMembership(ctx, authPayload)
+	if !ok {
+		return
+	}
+	if role != groupRoleOwner {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("you do not have permission to manage members of this group")))
+		return
+	}
+
+	user, ok := s.lookupMember(ctx, req.Email)
+	if !ok {
+		return
+	}
+
+	var member db.GroupMembers
+	err := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		if err := s.ensureGroupOwnerRemains(ctx, q, group.ID, user.ID, req.Role); err != nil {
+			return err
+		}
+
+		var err error
+		member, err = q.UpsertGroupMember(ctx, db.UpsertGroupMemberParams{
+			GroupID: group.ID,
+			UserID:  user.ID,
+			Role:    req.Role,
+		})
+		if err != nil {
+			return err
+		}
+
+		return s.logOrgAction(ctx, q, authPayload, "add_group_member", groupPrincipal(group.Slug), fmt.Sprintf("%s as %s", user.Email, req.Role))
+	})
+	if err != nil {
+		if err == errLastGroupOwner {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, memberResponse{
+		Email:     user.Email,
+		Name:      user.Name,
+		Role:      member.Role,
+		CreatedAt: member.CreatedAt.Time,
+	})
+}
+
+// @Summary      Remove a group member
+// @Description  Removes a user from the group; they immediately lose access to everything shared with the group. Requires group owner, except that members can always leave.
+// @Tags         Groups
+// @Produce      json
+// @Param        group   path     string  true  "Group slug"
+// @Param        email   path     string  true  "Member email"
+// @Success      204
+// @Failure      400     {object} swaggerErrorResponse "Cannot remove the last owner"
+// @Failure      403     {object} swaggerErrorResponse "Not a group owner"
+// @Failure      404     {object} swaggerErrorResponse "Group, user or membership not found"
+// @Failure      500     {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /groups/{group}/members/{email} [delete]
+func (s *Server) removeGroupMember(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	group, role, ok := s.groupMembership(ctx, authPayload)
+	if !ok {
+		return
+	}
+
+	user, ok := s.lookupMember(ctx, ctx.Param("email"))
+	if !ok {
+		return
+	}
+	if role != groupRoleOwner && user.ID != authPayload.UserID {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("you do not have permission to manage members of this group")))
+		return
+	}
+
+	err := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		if err := s.ensureGroupOwnerRemains(ctx, q, group.ID, user.ID, ""); err != nil {
+			return err
+		}
+
+		removed, err := q.RemoveGroupMember(ctx, db.RemoveGroupMemberParams{GroupID: group.ID, UserID: user.ID})
+		if err != nil {
+			return err
+		}
+		if removed == 0 {
+			return newStatusError(http.StatusNotFound, "the user is not a member of the group")
+		}
+
+		return s.logOrgAction(ctx, q, authPayload, "remove_group_member", groupPrincipal(group.Slug), user.Email)
+	})
+	if err != nil {
+		if err == errLastGroupOwner {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/pixperk/vaultify/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupSharing(t *testing.T) {
+	owner := newTestUser(t)
+	member := newTestUser(t)
+
+	var group groupResponse
+	require.Equal(t, http.StatusOK, owner.call(http.MethodPost, "/groups", createGroupRequest{Slug: util.RandomString(10), Name: "Payments"}, &group))
+	require.Equal(t, "owner", group.Role)
+
+	created := owner.createSecret("payments/key", "k")
+	var share shareSecretResponse
+	require.Equal(t, http.StatusOK, owner.call(http.MethodPost, "/secrets/share", shareSecretRequest{Path: created.Path, TargetGroup: group.Slug, Permission: "read"}, &share))
+	require.Equal(t, group.Slug, share.TargetGroup)
+	require.Empty(t, share.TargetEmail)
+
+	_, code := member.getSecret(created.Path)
+	require.Equal(t, http.StatusForbidden, code)
+
+	// only owners manage members
+	members := "/groups/" + group.Slug + "/members"
+	require.Equal(t, http.StatusNotFound, member.call(http.MethodPost, members, addGroupMemberRequest{Email: member.email, Role: "member"}, nil))
+	require.Equal(t, http.StatusOK, owner.call(http.MethodPost, members, addGroupMemberRequest{Email: member.email, Role: "member"}, nil))
+
+	secret, code := member.getSecret(created.Path)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "k", secret.Decrypted)
+	var shared sharedWithMeResponse
+	require.Equal(t, http.StatusOK, member.call(http.MethodGet, "/shared-with-me", nil, &shared))
+	require.Len(t, shared.Shares, 1)
+	require.Equal(t, group.Slug, shared.Shares[0].TargetGroup)
+
+	// the last owner cannot leave
+	require.Equal(t, http.StatusBadRequest, owner.call(http.MethodDelete, members+"/"+owner.email, nil, nil))
+
+	// removing the member ends their access right away
+	require.Equal(t, http.StatusNoContent, owner.call(http.MethodDelete, members+"/"+member.email, nil, nil))
+	_, code = member.getSecret(created.Path)
+	require.Equal(t, http.StatusForbidden, code)
+
+	require.Equal(t, http.StatusOK, owner.call(http.MethodDelete, "/shares/"+created.Path+"?target_group="+group.Slug, nil, nil))
+}
@@ -0,0 +1,508 @@
+package api
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/pixperk/vaultify/internal/auth"
+	db "github.com/pixperk/vaultify/internal/db/sqlc"
+	"github.com/pixperk/vaultify/internal/events"
+	"github.com/pixperk/vaultify/internal/policy"
+	"github.com/pixperk/vaultify/internal/secretpath"
+	vaultifyv1 "github.com/pixperk/vaultify/proto/vaultify/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// grpcPublicMethods can be called without an access token
+var grpcPublicMethods = map[string]bool{
+	vaultifyv1.Vaultify_SignUp_FullMethodName: true,
+	vaultifyv1.Vaultify_Login_FullMethodName:  true,
+}
+
+type grpcPayloadKey struct{}
+
+// grpcService implements the Vaultify gRPC service on top of the same Server logic as the REST handlers
+type grpcService struct {
+	vaultifyv1.UnimplementedVaultifyServer
+	server *Server
+}
+
+// NewGRPCServer returns a gRPC server exposing the Vaultify service. Callers authenticate with an
+// "authorization: Bearer <token>" metadata entry, as with the REST API.
+func (s *Server) NewGRPCServer() *grpc.Server {
+	g := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(s.grpcUnaryAuth, s.grpcUnaryRateLimit),
+		grpc.ChainStreamInterceptor(s.grpcStreamAuth, s.grpcStreamRateLimit),
+	)
+	vaultifyv1.RegisterVaultifyServer(g, &grpcService{server: s})
+	return g
+}
+
+// startGRPC serves the gRPC API on address in the background
+func (s *Server) startGRPC(address string) (*grpc.Server, error) {
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	g := s.NewGRPCServer()
+	go func() {
+		if err := g.Serve(lis); err != nil {
+			log.Printf("gRPC server stopped: %v", err)
+		}
+	}()
+	return g, nil
+}
+
+// stopGRPC lets in-flight calls finish, and closes the remaining ones, such as open Watch streams,
+// once ctx is done
+func stopGRPC(ctx context.Context, g *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		g.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		g.Stop()
+	}
+}
+
+// grpcAuthenticate verifies the access token in the incoming metadata
+func (s *Server) grpcAuthenticate(ctx context.Context, method string) (context.Context, error) {
+	if grpcPublicMethods[method] {
+		return ctx, nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	var header string
+	if values := md.Get(authorizationHeaderKey); len(values) > 0 {
+		header = values[0]
+	}
+	payload, err := verifyAuthorization(s.tokenMaker, header)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return context.WithValue(ctx, grpcPayloadKey{}, payload), nil
+}
+
+func (s *Server) grpcUnaryAuth(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := s.grpcAuthenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// authenticatedStream carries the verified token payload in the stream's context
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (a *authenticatedStream) Context() context.Context {
+	return a.ctx
+}
+
+func (s *Server) grpcStreamAuth(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.grpcAuthenticate(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+}
+
+// grpcRateLimit takes a token from the caller's bucket, the same one the REST routes use. Public
+// methods carry no token and are not limited, as with sign-up and login over REST.
+func (s *Server) grpcRateLimit(ctx context.Context, method string) error {
+	if grpcPublicMethods[method] {
+		return nil
+	}
+	allowed, err := s.rateLimiter.Allow(grpcPayload(ctx).UserID)
+	if err != nil {
+		log.Printf("Redis rate limiter error: %v", err)
+		return status.Error(codes.Internal, "internal error")
+	}
+	if !allowed {
+		return status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+	return nil
+}
+
+func (s *Server) grpcUnaryRateLimit(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := s.grpcRateLimit(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (s *Server) grpcStreamRateLimit(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := s.grpcRateLimit(stream.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, stream)
+}
+
+func grpcPayload(ctx context.Context) *auth.Payload {
+	return ctx.Value(grpcPayloadKey{}).(*auth.Payload)
+}
+
+// grpcError converts an error carrying an HTTP status into the matching gRPC status
+func grpcError(err error) error {
+	return grpcStatusError(errorStatus(err), err)
+}
+
+func grpcStatusError(httpStatus int, err error) error {
+	code := codes.Internal
+	switch httpStatus {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusUnauthorized:
+		code = codes.Unauthenticated
+	case http.StatusForbidden:
+		code = codes.PermissionDenied
+	case http.StatusNotFound:
+		code = codes.NotFound
+	case http.StatusConflict:
+		code = codes.AlreadyExists
+	case http.StatusUnprocessableEntity:
+		code = codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		code = codes.ResourceExhausted
+	}
+	return status.Error(code, err.Error())
+}
+
+// validate applies the binding rules of the REST request types
+func validate(req any) error {
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return nil
+}
+
+// grpcEnvironment defaults env and rejects unknown environments
+func (s *Server) grpcEnvironment(env string) (string, error) {
+	if env == "" {
+		return defaultEnvironment, nil
+	}
+	if !s.isKnownEnvironment(env) {
+		return "", newStatusError(http.StatusBadRequest, "unknown environment %q", env)
+	}
+	return env, nil
+}
+
+func newUserMessage(user userResponse) *vaultifyv1.User {
+	return &vaultifyv1.User{
+		Email:     user.Email,
+		Name:      user.Name,
+		CreatedAt: timestamppb.New(user.CreatedAt),
+	}
+}
+
+func (g *grpcService) SignUp(ctx context.Context, in *vaultifyv1.SignUpRequest) (*vaultifyv1.User, error) {
+	req := createUserRequest{Name: in.Name, Email: in.Email, Password: in.Password}
+	if err := validate(&req); err != nil {
+		return nil, err
+	}
+	user, err := g.server.registerUser(ctx, req)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return newUserMessage(newUserResponse(user)), nil
+}
+
+func (g *grpcService) Login(ctx context.Context, in *vaultifyv1.LoginRequest) (*vaultifyv1.LoginResponse, error) {
+	req := loginUserRequest{Email: in.Email, Password: in.Password, Policies: in.Policies}
+	if err := validate(&req); err != nil {
+		return nil, err
+	}
+	resp, err := g.server.authenticate(ctx, req)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &vaultifyv1.LoginResponse{
+		AccessToken: resp.AccessToken,
+		User:        newUserMessage(resp.User),
+	}, nil
+}
+
+func (g *grpcService) CreateSecret(ctx context.Context, in *vaultifyv1.CreateSecretRequest) (*vaultifyv1.Secret, error) {
+	req := createSecretRequest{
+		Path:        in.Path,
+		Value:       in.Value,
+		TTLSeconds:  in.TtlSeconds,
+		Environment: in.Environment,
+	}
+	if err := validate(&req); err != nil {
+		return nil, err
+	}
+	secret, path, err := g.server.storeNewSecret(ctx, grpcPayload(ctx), req)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &vaultifyv1.Secret{
+		Path:           path,
+		Environment:    secret.Environment,
+		Version:        secret.Version,
+		EncryptedValue: secret.EncryptedValue,
+		Nonce:          secret.Nonce,
+	}, nil
+}
+
+func (g *grpcService) GetSecret(ctx context.Context, in *vaultifyv1.GetSecretRequest) (*vaultifyv1.SecretValue, error) {
+	path, err := secretpath.Normalize(in.Path)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	env, err := g.server.grpcEnvironment(in.Environment)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	if in.Version < 0 {
+		return nil, status.Error(codes.NotFound, "Secret version not found")
+	}
+
+	authPayload := grpcPayload(ctx)
+	secret, err := g.server.secretForRead(ctx, authPayload, path, env, in.Version, policy.Read)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	pending, granted, err := g.server.quorumRead(ctx, authPayload, secret)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	if pending != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "the secret is under the two-person rule: quorum request %s awaits %d approvals", pending.ID, pending.RequiredApprovals)
+	}
+	value, err := g.server.revealSecret(ctx, authPayload, secret, granted)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	if in.Resolve {
+		value, err = g.server.resolveSecretReferences(ctx, authPayload, secret.Path, secret.Environment, value)
+		if err != nil {
+			return nil, grpcStatusError(referenceErrorStatus(err), err)
+		}
+	}
+
+	return &vaultifyv1.SecretValue{
+		Path:        secret.Path,
+		Environment: secret.Environment,
+		Version:     secret.Version,
+		Value:       value,
+	}, nil
+}
+
+func (g *grpcService) UpdateSecret(ctx context.Context, in *vaultifyv1.UpdateSecretRequest) (*vaultifyv1.Secret, error) {
+	req := updateSecretRequest{Value: in.Value}
+	if err := validate(&req); err != nil {
+		return nil, err
+	}
+	path, err := secretpath.Normalize(in.Path)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	env, err := g.server.grpcEnvironment(in.Environment)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	authPayload := grpcPayload(ctx)
+	secret, err := g.server.secretForWrite(ctx, authPayload, path, env, policy.Update)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	updated, err := g.server.writeSecretVersion(ctx, authPayload, secret, req.Value)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &vaultifyv1.Secret{
+		Path:           secret.Path,
+		Environment:    updated.Environment,
+		Version:        updated.Version,
+		EncryptedValue: updated.EncryptedValue,
+		Nonce:          updated.Nonce,
+	}, nil
+}
+
+func (g *grpcService) ShareSecret(ctx context.Context, in *vaultifyv1.ShareSecretRequest) (*vaultifyv1.Share, error) {
+	req := shareSecretRequest{
+		Path:         in.Path,
+		TargetEmail:  in.TargetEmail,
+		TargetGroup:  in.TargetGroup,
+		Permission:   in.Permission,
+		ShareTTLSecs: int(in.ShareTtlSeconds),
+	}
+	if err := validate(&req); err != nil {
+		return nil, err
+	}
+	share, err := g.server.grantShare(ctx, grpcPayload(ctx), req)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return grpcShare(share), nil
+}
+
+func grpcShare(share db.SharingRules) *vaultifyv1.Share {
+	resp := &vaultifyv1.Share{
+		Path:       sharedPath(share.Path, share.IsPrefix),
+		Permission: share.Permission,
+		OwnerEmail: share.OwnerEmail,
+	}
+	resp.TargetEmail, resp.TargetGroup = splitPrincipal(share.TargetEmail)
+	if share.SharedUntil.Valid {
+		resp.SharedUntil = timestamppb.New(share.SharedUntil.Time)
+	}
+	return resp
+}
+
+func (g *grpcService) UpdateShare(ctx context.Context, in *vaultifyv1.UpdateShareRequest) (*vaultifyv1.Share, error) {
+	req := updateShareRequest{
+		TargetEmail: in.TargetEmail,
+		TargetGroup: in.TargetGroup,
+		Permission:  in.Permission,
+	}
+	if in.ShareTtlSeconds != nil {
+		secs := int(*in.ShareTtlSeconds)
+		req.ShareTTLSecs = &secs
+	}
+	if err := validate(&req); err != nil {
+		return nil, err
+	}
+	share, err := g.server.changeShare(ctx, grpcPayload(ctx), in.Path, req)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return grpcShare(share), nil
+}
+
+func (g *grpcService) RevokeShares(ctx context.Context, in *vaultifyv1.RevokeSharesRequest) (*vaultifyv1.RevokeSharesResponse, error) {
+	var target string
+	if !in.All || in.TargetEmail != "" || in.TargetGroup != "" {
+		var err error
+		if target, err = sharePrincipal(in.TargetEmail, in.TargetGroup); err != nil || in.All {
+			return nil, status.Error(codes.InvalidArgument, "give either target_email, target_group or all")
+		}
+	}
+	revoked, err := g.server.revokeShares(ctx, grpcPayload(ctx), in.Path, target)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &vaultifyv1.RevokeSharesResponse{Path: revoked.Path, Revoked: revoked.Revoked}, nil
+}
+
+func (g *grpcService) ListAuditLogs(ctx context.Context, in *vaultifyv1.ListAuditLogsRequest) (*vaultifyv1.ListAuditLogsResponse, error) {
+	limit := in.Limit
+	if limit < 1 {
+		limit = 50
+	}
+	offset := max(in.Offset, 0)
+
+	path := in.Path
+	if path != "" {
+		var err error
+		if path, err = secretpath.Normalize(path); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	params := db.FilterAuditLogsParams{
+		UserEmail:       grpcPayload(ctx).Email,
+		Action:          in.Action,
+		ResourcePath:    path,
+		ResourceVersion: in.Version,
+		Limit:           limit,
+		Offset:          offset,
+	}
+	if in.Success != nil {
+		params.Success.Bool, params.Success.Valid = *in.Success, true
+	}
+	if in.From != nil {
+		params.CreatedAt.Time, params.CreatedAt.Valid = in.From.AsTime(), true
+	}
+	if in.To != nil {
+		params.CreatedAt2.Time, params.CreatedAt2.Valid = in.To.AsTime(), true
+	}
+
+	logs, err := g.server.store.FilterAuditLogs(ctx, params)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to fetch logs")
+	}
+
+	resp := &vaultifyv1.ListAuditLogsResponse{Logs: make([]*vaultifyv1.AuditLog, 0, len(logs))}
+	for _, l := range logs {
+		resp.Logs = append(resp.Logs, &vaultifyv1.AuditLog{
+			Id:              l.ID.String(),
+			UserEmail:       l.UserEmail,
+			Action:          l.Action,
+			ResourcePath:    l.ResourcePath,
+			ResourceVersion: l.ResourceVersion,
+			Success:         l.Success,
+			Reason:          l.Reason.String,
+			CreatedAt:       timestamppb.New(l.CreatedAt.Time),
+		})
+	}
+	return resp, nil
+}
+
+func newWatchEvent(e events.Event) *vaultifyv1.WatchEvent {
+	msg := &vaultifyv1.WatchEvent{
+		Type:        string(e.Type),
+		Path:        e.Path,
+		Environment: e.Environment,
+		Version:     e.Version,
+	}
+	if !e.At.IsZero() {
+		msg.At = timestamppb.New(e.At)
+	}
+	return msg
+}
+
+func (g *grpcService) Watch(in *vaultifyv1.WatchRequest, stream grpc.ServerStreamingServer[vaultifyv1.WatchEvent]) error {
+	ctx := stream.Context()
+	authPayload := grpcPayload(ctx)
+
+	filter, err := g.server.watchFilter(in.Paths, in.Prefix, in.Environment)
+	if err != nil {
+		return grpcError(err)
+	}
+	sub := g.server.bus.Subscribe(watchBuffer, filter)
+	defer sub.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e, ok := <-sub.C:
+			if !ok {
+				// the watcher fell behind; the client reconnects and re-reads
+				return stream.Send(&vaultifyv1.WatchEvent{Type: string(events.Reset)})
+			}
+			if e.Type == events.Reset {
+				if err := stream.Send(newWatchEvent(e)); err != nil {
+					return err
+				}
+				continue
+			}
+			allowed, err := g.server.canReadEvent(ctx, authPayload, e)
+			if err != nil {
+				log.Printf("Error checking access for watch event on %s: %v\n", e.Path, err)
+				return status.Error(codes.Internal, "permission check failed")
+			}
+			if !allowed {
+				continue
+			}
+			if err := stream.Send(newWatchEvent(e)); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/pixperk/vaultify/internal/util"
+	vaultifyv1 "github.com/pixperk/vaultify/proto/vaultify/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newGRPCClient serves the gRPC API of testServer over an in-memory listener
+func newGRPCClient(t *testing.T) vaultifyv1.VaultifyClient {
+	lis := bufconn.Listen(1 << 20)
+	g := testServer.NewGRPCServer()
+	go g.Serve(lis)
+	t.Cleanup(g.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return vaultifyv1.NewVaultifyClient(conn)
+}
+
+// grpcContext authenticates calls as u
+func (u *testUser) grpcContext() context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), authorizationHeaderKey, "Bearer "+u.token)
+}
+
+func TestGRPCAuthorization(t *testing.T) {
+	owner := newTestUser(t)
+	reader := newTestUser(t)
+	outsider := newTestUser(t)
+	client := newGRPCClient(t)
+
+	secret := owner.createSecret("grpc/token", "t0k3n")
+
+	_, err := client.GetSecret(context.Background(), &vaultifyv1.GetSecretRequest{Path: secret.Path})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	got, err := client.GetSecret(owner.grpcContext(), &vaultifyv1.GetSecretRequest{Path: secret.Path})
+	require.NoError(t, err)
+	require.Equal(t, "t0k3n", got.Value)
+
+	_, err = client.GetSecret(outsider.grpcContext(), &vaultifyv1.GetSecretRequest{Path: secret.Path})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// reads under the two-person rule wait for approvals, as over REST
+	require.Equal(t, http.StatusOK, owner.share(shareSecretRequest{Path: secret.Path, TargetEmail: reader.email, Permission: "read"}))
+	require.Equal(t, http.StatusOK, owner.call(http.MethodPut, "/quorum/"+secret.Path, setQuorumRequest{Approvals: 2}, nil))
+	_, err = client.GetSecret(reader.grpcContext(), &vaultifyv1.GetSecretRequest{Path: secret.Path})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	var mine listQuorumRequestsResponse
+	require.Equal(t, http.StatusOK, reader.call(http.MethodGet, "/quorum-requests", nil, &mine))
+	require.Len(t, mine.Requests, 1)
+	require.Equal(t, quorumPending, mine.Requests[0].Status)
+}
+
+func TestGRPCRateLimit(t *testing.T) {
+	user := newTestUser(t)
+	client := newGRPCClient(t)
+
+	// a bucket of one token that does not refill during the test
+	limiter := testServer.rateLimiter
+	testServer.rateLimiter = util.NewRateLimiter(testServer.config.RedisAddr, 1, 0.0001)
+	t.Cleanup(func() { testServer.rateLimiter = limiter })
+
+	_, err := client.GetSecret(user.grpcContext(), &vaultifyv1.GetSecretRequest{Path: user.email + "/missing"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+	_, err = client.GetSecret(user.grpcContext(), &vaultifyv1.GetSecretRequest{Path: user.email + "/missing"})
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// sign-up and login are not limited
+	_, err = client.Login(context.Background(), &vaultifyv1.LoginRequest{Email: user.email, Password: user.password})
+	require.NoError(t, err)
+}
@@ -0,0 +1,155 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pixperk/vaultify/internal/auth"
+	db "github.com/pixperk/vaultify/internal/db/sqlc"
+	"github.com/pixperk/vaultify/internal/policy"
+	"github.com/pixperk/vaultify/internal/secretpath"
+)
+
+type secretListItem struct {
+	Path      string     `json:"path"`
+	Version   int32      `json:"version"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type listSecretsResponse struct {
+	Prefix      string           `json:"prefix"`
+	Environment string           `json:"environment"`
+	Secrets     []secretListItem `json:"secrets"`
+}
+
+type secretVersionItem struct {
+	Version   int32      `json:"version"`
+	CreatedAt time.Time  `json:"created_at"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
+}
+
+type secretHistoryResponse struct {
+	Path        string              `json:"path"`
+	Environment string              `json:"environment"`
+	Versions    []secretVersionItem `json:"versions"`
+}
+
+// @Summary      List secrets under a prefix
+// @Description  Lists the secrets under a folder that the caller can read, with their latest version in the environment. Values are not included. The prefix defaults to the caller's own namespace.
+// @Tags         Secrets
+// @Produce      json
+// @Param        prefix  query    string  false  "Folder to list, e.g. alice@example.com/db/"
+// @Param        env     query    string  false  "Environment (defaults to default)"
+// @Success      200     {object} listSecretsResponse
+// @Failure      400     {object} swaggerErrorResponse "Invalid prefix or environment"
+// @Failure      500     {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /list [get]
+func (s *Server) listSecrets(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	env, err := s.environmentParam(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	prefix := personalNamespace(authPayload.Email)
+	if raw := ctx.Query("prefix"); raw != "" {
+		if prefix, err = secretpath.NormalizePrefix(raw); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+	}
+
+	rows, err := s.store.ListLatestSecretsByPrefix(ctx, db.ListLatestSecretsByPrefixParams{
+		Prefix:      prefix,
+		Environment: env,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	resp := listSecretsResponse{
+		Prefix:      prefix,
+		Environment: env,
+		Secrets:     []secretListItem{},
+	}
+	for _, row := range rows {
+		caps, err := s.cachedCapabilities(ctx, authPayload, db.GetLatestSecretByPathRow{
+			UserID: row.UserID,
+			Path:   row.Path,
+			TeamID: row.TeamID,
+		})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		if !caps.Has(policy.List) {
+			continue
+		}
+
+		item := secretListItem{
+			Path:      row.Path,
+			Version:   row.Version,
+			UpdatedAt: row.UpdatedAt.Time,
+		}
+		if row.ExpiresAt.Valid {
+			item.ExpiresAt = &row.ExpiresAt.Time
+		}
+		resp.Secrets = append(resp.Secrets, item)
+	}
+	sort.Slice(resp.Secrets, func(i, j int) bool {
+		return resp.Secrets[i].Path < resp.Secrets[j].Path
+	})
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// @Summary      List the versions of a secret
+// @Description  Lists every version of the secret in the environment, newest first, without values.
+// @Tags         Secrets
+// @Produce      json
+// @Param        path  path     string  true   "Secret path"
+// @Param        env   query    string  false  "Environment (defaults to default)"
+// @Success      200   {object} secretHistoryResponse
+// @Failure      403   {object} swaggerErrorResponse "Access denied"
+// @Failure      404   {object} swaggerErrorResponse "Secret not found"
+// @Failure      500   {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /history/{path} [get]
+func (s *Server) getSecretHistory(ctx *gin.Context) {
+	secret := ctx.MustGet("secret").(db.GetLatestSecretByPathRow)
+
+	versions, err := s.store.GetAllSecretVersionsByPath(ctx, db.GetAllSecretVersionsByPathParams{
+		Path:        secret.Path,
+		Environment: secret.Environment,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	resp := secretHistoryResponse{
+		Path:        secret.Path,
+		Environment: secret.Environment,
+		Versions:    make([]secretVersionItem, 0, len(versions)),
+	}
+	for _, v := range versions {
+		item := secretVersionItem{
+			Version:   v.Version,
+			CreatedAt: v.CreatedAt.Time,
+		}
+		if v.CreatedBy.Valid {
+			item.CreatedBy = &v.CreatedBy.UUID
+		}
+		resp.Versions = append(resp.Versions, item)
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
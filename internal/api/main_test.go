@@ -0,0 +1,193 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/pixperk/vaultify/internal/audit"
+	"github.com/pixperk/vaultify/internal/config"
+	db "github.com/pixperk/vaultify/internal/db/sqlc"
+	"github.com/pixperk/vaultify/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+// testServer is an in-process Vaultify server backed by the database and Redis from app.env.
+// Tests that need it are skipped when the database is unreachable.
+var testServer *Server
+
+// testStore is the database behind testServer, for setup the API cannot do
+var testStore *db.Store
+
+// policyGroup is the group the test policy is attached to, deleteGroup the one of the delete
+// policy; their slugs are fresh for every run
+var (
+	policyGroup = "policy-" + util.RandomString(10)
+	deleteGroup = "delete-" + util.RandomString(10)
+)
+
+// testPolicy lets members of policyGroup read everyone's policy-shared folder and denies them
+// everything in their policy-denied folder
+func testPolicy() string {
+	return `
+		name   = "test-policy"
+		groups = ["` + policyGroup + `"]
+
+		path "*/policy-shared/**" {
+			capabilities = ["read", "list"]
+		}
+		path "*/policy-denied/**" {
+			capabilities = ["deny"]
+		}
+	`
+}
+
+// deletePolicy lets members of deleteGroup delete, and nothing else, in everyone's
+// policy-delete folder
+func deletePolicy() string {
+	return `
+		name   = "delete-policy"
+		groups = ["` + deleteGroup + `"]
+
+		path "*/policy-delete/**" {
+			capabilities = ["delete"]
+		}
+	`
+}
+
+func TestMain(m *testing.M) {
+	os.Exit(runTests(m))
+}
+
+func runTests(m *testing.M) int {
+	// the rate limiter loads its script relative to the repository root
+	if err := os.Chdir("../.."); err != nil {
+		log.Fatal("cannot change to repository root:", err)
+	}
+
+	cfg, err := config.LoadConfig(".")
+	if err != nil {
+		log.Println("skipping server tests, cannot load config:", err)
+		return m.Run()
+	}
+	conn, err := sql.Open("postgres", cfg.DBSource)
+	if err == nil {
+		err = conn.Ping()
+	}
+	if err != nil {
+		log.Println("skipping server tests, cannot connect to db:", err)
+		return m.Run()
+	}
+
+	store := db.NewStore(conn)
+	testStore = store
+	if err := store.RotateHmacKey(context.Background(), 24*time.Hour); err != nil {
+		log.Fatal("cannot create HMAC key:", err)
+	}
+	// the cache is kept fresh by the event listener, which the tests do not start
+	cfg.CacheSize = -1
+	cfg.PolicyDir, err = os.MkdirTemp("", "vaultify-policies")
+	if err != nil {
+		log.Fatal("cannot create policy dir:", err)
+	}
+	defer os.RemoveAll(cfg.PolicyDir)
+	if err := os.WriteFile(filepath.Join(cfg.PolicyDir, "test.hcl"), []byte(testPolicy()), 0o600); err != nil {
+		log.Fatal("cannot write policy:", err)
+	}
+	if err := os.WriteFile(filepath.Join(cfg.PolicyDir, "delete.hcl"), []byte(deletePolicy()), 0o600); err != nil {
+		log.Fatal("cannot write policy:", err)
+	}
+	auditSvc := audit.NewAuditService(*store, cfg.Env)
+	testServer, err = NewServer(&cfg, *store, *auditSvc)
+	if err != nil {
+		log.Fatal("cannot create server:", err)
+	}
+	return m.Run()
+}
+
+// testUser is a signed-up user calling the REST API of testServer
+type testUser struct {
+	t        *testing.T
+	email    string
+	password string
+	token    string
+}
+
+// newTestUser signs up a random user and logs them in
+func newTestUser(t *testing.T) *testUser {
+	if testServer == nil {
+		t.Skip("database not available")
+	}
+	u := &testUser{t: t, email: util.RandomEmail(), password: util.RandomString(12)}
+	code := u.call(http.MethodPost, "/sign-up", createUserRequest{Name: util.RandomName(), Email: u.email, Password: u.password}, nil)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, http.StatusOK, u.login())
+	return u
+}
+
+// login replaces the user's token with one limited to policies, or a full one without any
+func (u *testUser) login(policies ...string) int {
+	var resp loginUserResponse
+	code := u.call(http.MethodPost, "/login", loginUserRequest{Email: u.email, Password: u.password, Policies: policies}, &resp)
+	if code == http.StatusOK {
+		u.token = resp.AccessToken
+	}
+	return code
+}
+
+// call sends body as JSON to the API path target and decodes a successful response into out.
+// It returns the status code.
+func (u *testUser) call(method, target string, body, out any) int {
+	u.t.Helper()
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		require.NoError(u.t, err)
+		reader = bytes.NewReader(data)
+	}
+
+	req := httptest.NewRequest(method, "/api/v1"+target, reader)
+	req.Header.Set("Content-Type", "application/json")
+	if u.token != "" {
+		req.Header.Set(authorizationHeaderKey, "Bearer "+u.token)
+	}
+	rec := httptest.NewRecorder()
+	testServer.router.ServeHTTP(rec, req)
+
+	if out != nil && rec.Code < http.StatusMultipleChoices {
+		require.NoError(u.t, json.Unmarshal(rec.Body.Bytes(), out))
+	}
+	return rec.Code
+}
+
+// createSecret stores value at path, relative to the user's namespace unless it names another
+func (u *testUser) createSecret(path, value string) secretResponse {
+	u.t.Helper()
+	var secret secretResponse
+	require.Equal(u.t, http.StatusOK, u.call(http.MethodPost, "/secrets/", createSecretRequest{Path: path, Value: value}, &secret))
+	return secret
+}
+
+// getSecret reads the latest default version of the secret at path
+func (u *testUser) getSecret(path string) (getSecretResponse, int) {
+	u.t.Helper()
+	var secret getSecretResponse
+	code := u.call(http.MethodGet, "/secrets/"+path, nil, &secret)
+	return secret, code
+}
+
+// share shares path with the user or group target
+func (u *testUser) share(req shareSecretRequest) int {
+	u.t.Helper()
+	return u.call(http.MethodPost, "/secrets/share", req, nil)
+}
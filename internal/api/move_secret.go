@@ -0,0 +1,519 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pixperk/vaultify/internal/auth"
+	db "github.com/pixperk/vaultify/internal/db/sqlc"
+	"github.com/pixperk/vaultify/internal/events"
+	"github.com/pixperk/vaultify/internal/policy"
+	"github.com/pixperk/vaultify/internal/secretpath"
+	"github.com/pixperk/vaultify/internal/util"
+)
+
+type moveSecretRequest struct {
+	From string `json:"from" binding:"required"`
+	To   string `json:"to" binding:"required"`
+}
+
+type moveSecretResponse struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type copySecretResponse struct {
+	From         string   `json:"from"`
+	To           string   `json:"to"`
+	Environments []string `json:"environments"`
+}
+
+type movePrefixRequest struct {
+	FromPrefix string `json:"from_prefix" binding:"required"`
+	ToPrefix   string `json:"to_prefix" binding:"required"`
+}
+
+type movePrefixResponse struct {
+	FromPrefix string               `json:"from_prefix"`
+	ToPrefix   string               `json:"to_prefix"`
+	Moved      []moveSecretResponse `json:"moved"`
+}
+
+// secretOwnership returns the ownership fields of secret in the shape used by the permission checks
+func secretOwnership(secret db.Secrets) db.GetLatestSecretByPathRow {
+	return db.GetLatestSecretByPathRow{
+		SecretID:   secret.ID,
+		SecretID_2: secret.ID,
+		UserID:     secret.UserID,
+		Path:       secret.Path,
+		TeamID:     secret.TeamID,
+	}
+}
+
+// personalNamespace is the path prefix of the user's own secrets
+func personalNamespace(email string) string {
+	return strings.ToLower(email) + "/"
+}
+
+// destinationOwner checks that path lies in a namespace the user can write to (their own or a team's)
+// and returns the user and team that will own a secret stored there
+func (s *Server) destinationOwner(ctx context.Context, authPayload *auth.Payload, path string, creator uuid.UUID) (uuid.UUID, uuid.NullUUID, error) {
+	if strings.HasPrefix(path, orgNamespacePrefix) {
+		teamID, err := s.teamForWrite(ctx, authPayload, path)
+		return creator, teamID, err
+	}
+	if !strings.HasPrefix(path, personalNamespace(authPayload.Email)) {
+		return uuid.Nil, uuid.NullUUID{}, newStatusError(http.StatusForbidden, "%s is outside your namespace; secrets can only be placed under %s or a team you can write to", path, personalNamespace(authPayload.Email))
+	}
+	if err := s.checkPersonalCreate(ctx, authPayload, path); err != nil {
+		return uuid.Nil, uuid.NullUUID{}, err
+	}
+	return authPayload.UserID, uuid.NullUUID{}, nil
+}
+
+// moveOne renames a single secret inside q, carrying its versions and sharing rules along
+func (s *Server) moveOne(ctx context.Context, q *db.Queries, authPayload *auth.Payload, secret db.Secrets, to string) error {
+	// moving takes the secret away from its path, and the mover sees it at the new one
+	canMove, err := s.can(ctx, authPayload, secretOwnership(secret), policy.Read|policy.Delete)
+	if err != nil {
+		return err
+	}
+	if !canMove {
+		return newStatusError(http.StatusForbidden, "you do not have permission to move %s", secret.Path)
+	}
+
+	// the secret keeps its owner, so moving it does not make it yours. Nobody else's secret can
+	// be placed in your own namespace.
+	_, teamID, err := s.destinationOwner(ctx, authPayload, to, secret.UserID)
+	if err != nil {
+		return err
+	}
+	if !teamID.Valid && secret.UserID != authPayload.UserID {
+		return newStatusError(http.StatusForbidden, "only the owner of %s can move it into their namespace", secret.Path)
+	}
+	userID := secret.UserID
+
+	if _, err = q.MoveSecret(ctx, db.MoveSecretParams{
+		OldPath: secret.Path,
+		NewPath: to,
+		UserID:  userID,
+		TeamID:  teamID,
+	}); err != nil {
+		return err
+	}
+
+	if _, err = q.MoveSharingRules(ctx, db.MoveSharingRulesParams{
+		OldPath: secret.Path,
+		NewPath: to,
+	}); err != nil {
+		return err
+	}
+	if _, err = q.MoveBreakGlassRule(ctx, db.MoveBreakGlassRuleParams{
+		OldPath: secret.Path,
+		NewPath: to,
+	}); err != nil {
+		return err
+	}
+	if _, err = q.MovePendingAccessRequests(ctx, db.MovePendingAccessRequestsParams{
+		OldPath: secret.Path,
+		NewPath: to,
+	}); err != nil {
+		return err
+	}
+
+	// record the move under both paths so either history shows where the secret went
+	movedTo := fmt.Sprintf("moved to %s", to)
+	if err = s.auditSvc.LogTx(ctx, q, authPayload.UserID, authPayload.Email, "move_secret", secret.Path, 0, true, &movedTo); err != nil {
+		return fmt.Errorf("failed to log action: %w", err)
+	}
+	movedFrom := fmt.Sprintf("moved from %s", secret.Path)
+	if err = s.auditSvc.LogTx(ctx, q, authPayload.UserID, authPayload.Email, "move_secret", to, 0, true, &movedFrom); err != nil {
+		return fmt.Errorf("failed to log action: %w", err)
+	}
+
+	// watchers of the old path see it go away, watchers of the new one see every environment appear
+	versions, err := q.GetLatestVersionsBySecretID(ctx, secret.ID)
+	if err != nil {
+		return err
+	}
+	changes := []events.Event{{
+		Type:    events.Deleted,
+		Path:    secret.Path,
+		OwnerID: secret.UserID,
+		TeamID:  secret.TeamID,
+	}}
+	for _, v := range versions {
+		changes = append(changes, events.Event{
+			Type:        events.VersionChanged,
+			Path:        to,
+			Environment: v.Environment,
+			Version:     v.Version,
+			OwnerID:     userID,
+			TeamID:      teamID,
+		})
+	}
+	return s.publishTx(ctx, q, changes...)
+}
+
+// @Summary      Move or rename a secret
+// @Description  Changes the path of a secret, keeping every version in every environment, its sharing rules, its break-glass rule and pending access requests for it. Moving needs read and delete on the secret. The secret keeps its owner; it can go to a team the caller can write to, and only its owner can move it into their own namespace.
+// @Tags         Secrets
+// @Accept       json
+// @Produce      json
+// @Param        request body     moveSecretRequest  true  "Source and destination paths"
+// @Success      200     {object} moveSecretResponse
+// @Failure      400     {object} swaggerErrorResponse "Invalid path"
+// @Failure      403     {object} swaggerErrorResponse "No read and delete on the secret or destination not writable"
+// @Failure      404     {object} swaggerErrorResponse "Secret not found"
+// @Failure      409     {object} swaggerErrorResponse "A secret already exists at the destination"
+// @Failure      500     {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /secrets/move [post]
+func (s *Server) moveSecret(ctx *gin.Context) {
+	var req moveSecretRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	from, err := secretpath.Normalize(req.From)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	to, err := secretpath.Normalize(req.To)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if from == to {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("source and destination are the same")))
+		return
+	}
+
+	err = s.store.ExecTx(ctx, func(q *db.Queries) error {
+		secret, err := q.GetSecretByPathForUpdate(ctx, from)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return newStatusError(http.StatusNotFound, "secret not found")
+			}
+			return err
+		}
+		return s.moveOne(ctx, q, authPayload, secret, to)
+	})
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, moveSecretResponse{From: from, To: to})
+}
+
+// @Summary      Move a folder of secrets
+// @Description  Moves every secret under from_prefix to the same relative path under to_prefix in a single transaction. Folder shares, break-glass rules and pending access requests on the folder and below follow it. Fails as a whole if any secret is not owned by the caller or any destination is taken.
+// @Tags         Secrets
+// @Accept       json
+// @Produce      json
+// @Param        request body     movePrefixRequest  true  "Source and destination prefixes"
+// @Success      200     {object} movePrefixResponse
+// @Failure      400     {object} swaggerErrorResponse "Invalid prefix"
+// @Failure      403     {object} swaggerErrorResponse "Not the owner of every secret or destination not writable"
+// @Failure      404     {object} swaggerErrorResponse "No secrets under the prefix"
+// @Failure      409     {object} swaggerErrorResponse "A secret already exists at a destination"
+// @Failure      500     {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /secrets/move-prefix [post]
+func (s *Server) moveSecretPrefix(ctx *gin.Context) {
+	var req movePrefixRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	// prefixes are folders: "team/db" moves team/db/x but not team/dbx
+	from, err := secretpath.Normalize(req.FromPrefix)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	to, err := secretpath.Normalize(req.ToPrefix)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	from, to = from+"/", to+"/"
+	if strings.HasPrefix(to, from) || strings.HasPrefix(from, to) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("prefixes must not contain each other")))
+		return
+	}
+
+	resp := movePrefixResponse{
+		FromPrefix: from,
+		ToPrefix:   to,
+		Moved:      []moveSecretResponse{},
+	}
+
+	err = s.store.ExecTx(ctx, func(q *db.Queries) error {
+		secrets, err := q.ListSecretsByPrefixForUpdate(ctx, from)
+		if err != nil {
+			return err
+		}
+		if len(secrets) == 0 {
+			return newStatusError(http.StatusNotFound, "no secrets under %s", from)
+		}
+
+		for _, secret := range secrets {
+			dest, err := secretpath.Join(to, strings.TrimPrefix(secret.Path, from))
+			if err != nil {
+				return newStatusError(http.StatusBadRequest, "%s: %v", secret.Path, err)
+			}
+			if err = s.moveOne(ctx, q, authPayload, secret, dest); err != nil {
+				return err
+			}
+			resp.Moved = append(resp.Moved, moveSecretResponse{From: secret.Path, To: dest})
+		}
+		return s.moveFolderRules(ctx, q, strings.TrimSuffix(from, "/"), strings.TrimSuffix(to, "/"))
+	})
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// moveFolderRules moves the folder shares, break-glass rules and pending access requests on the
+// folder from and on every folder below it to the same place under to
+func (s *Server) moveFolderRules(ctx context.Context, q *db.Queries, from, to string) error {
+	shares, err := q.MovePrefixSharingRules(ctx, db.MovePrefixSharingRulesParams{OldPrefix: from, NewPrefix: to})
+	if err != nil {
+		return err
+	}
+	if _, err := q.MovePrefixBreakGlassRules(ctx, db.MovePrefixBreakGlassRulesParams{OldPrefix: from, NewPrefix: to}); err != nil {
+		return err
+	}
+	if _, err := q.MovePendingPrefixAccessRequests(ctx, db.MovePendingPrefixAccessRequestsParams{OldPrefix: from, NewPrefix: to}); err != nil {
+		return err
+	}
+	if shares == 0 {
+		return nil
+	}
+
+	// the moved shares now cover whatever else is already under the destination
+	return s.publishTx(ctx, q,
+		events.Event{Type: events.ShareChanged, Path: shareEventPath(from, true)},
+		events.Event{Type: events.ShareChanged, Path: shareEventPath(to, true)},
+	)
+}
+
+// @Summary      Copy a secret
+// @Description  Creates a new secret at the destination seeded with the current value of the source in every environment. History and sharing rules are not copied. Requires read access to the source and write access to the destination namespace. Secrets under the two-person rule cannot be copied.
+// @Tags         Secrets
+// @Accept       json
+// @Produce      json
+// @Param        request body     moveSecretRequest  true  "Source and destination paths"
+// @Success      200     {object} copySecretResponse
+// @Failure      400     {object} swaggerErrorResponse "Invalid path"
+// @Failure      401     {object} swaggerErrorResponse "Invalid HMAC on the source"
+// @Failure      403     {object} swaggerErrorResponse "No read access, destination not writable or source under the two-person rule"
+// @Failure      404     {object} swaggerErrorResponse "Secret not found"
+// @Failure      409     {object} swaggerErrorResponse "A secret already exists at the destination"
+// @Failure      500     {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /secrets/copy [post]
+func (s *Server) copySecret(ctx *gin.Context) {
+	var req moveSecretRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	from, err := secretpath.Normalize(req.From)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	to, err := secretpath.Normalize(req.To)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	source, err := s.store.GetSecretByPath(ctx, from)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("secret not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	canRead, err := s.can(ctx, authPayload, secretOwnership(source), policy.Read)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if !canRead {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("access denied")))
+		return
+	}
+	quorum, err := s.underQuorum(ctx, source.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if quorum {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("secrets under the two-person rule cannot be copied")))
+		return
+	}
+
+	userID, teamID, err := s.destinationOwner(ctx, authPayload, to, authPayload.UserID)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	versions, err := s.store.GetLatestVersionsBySecretID(ctx, source.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if len(versions) == 0 {
+		ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("secret has no versions to copy")))
+		return
+	}
+
+	hmacKey, err := s.store.GetActiveHMACKey(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(fmt.Errorf("failed to fetch active HMAC key")))
+		return
+	}
+
+	// verify and re-encrypt every environment's current value before writing anything
+	type copiedValue struct {
+		environment    string
+		encryptedValue []byte
+		nonce          []byte
+		hmacSignature  []byte
+	}
+	copies := make([]copiedValue, 0, len(versions))
+	for _, version := range versions {
+		sourceKey, err := s.store.GetHMACKeyByID(ctx, version.HmacKeyID.UUID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		isVerified, err := util.VerifyHMAC(util.ComputeHMACPayload(version.EncryptedValue, version.Nonce), version.HmacSignature, sourceKey.Key)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		if !isVerified {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(fmt.Errorf("invalid HMAC signature on %s version %d", version.Environment, version.Version)))
+			return
+		}
+
+		plaintext, err := s.encryptor.Decrypt(version.EncryptedValue, version.Nonce)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		encryptedValue, nonce, err := s.encryptor.Encrypt(plaintext)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		hmacSig, err := util.GenerateHMACSignature(util.ComputeHMACPayload(encryptedValue, nonce), hmacKey.Key)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(fmt.Errorf("failed to generate HMAC signature")))
+			return
+		}
+		copies = append(copies, copiedValue{
+			environment:    version.Environment,
+			encryptedValue: encryptedValue,
+			nonce:          nonce,
+			hmacSignature:  hmacSig,
+		})
+	}
+
+	createdBy := uuid.NullUUID{UUID: authPayload.UserID, Valid: true}
+	hmacKeyID := uuid.NullUUID{UUID: hmacKey.ID, Valid: true}
+	resp := copySecretResponse{From: from, To: to, Environments: []string{}}
+
+	var changes []events.Event
+	err = s.store.ExecTx(ctx, func(q *db.Queries) error {
+		first := copies[0]
+		created, err := q.CreateSecretWithVersion(ctx, db.CreateSecretWithVersionParams{
+			CreatedBy:      uuid.NullUUID{UUID: userID, Valid: true},
+			Path:           to,
+			EncryptedValue: first.encryptedValue,
+			Nonce:          first.nonce,
+			ExpiresAt:      source.ExpiresAt,
+			HmacSignature:  first.hmacSignature,
+			HmacKeyID:      hmacKeyID,
+			TeamID:         teamID,
+			Environment:    first.environment,
+		})
+		if err != nil {
+			return err
+		}
+		resp.Environments = append(resp.Environments, first.environment)
+		changes = append(changes, events.Event{
+			Type:        events.VersionChanged,
+			Path:        to,
+			Environment: created.Environment,
+			Version:     created.Version,
+			OwnerID:     userID,
+			TeamID:      teamID,
+		})
+
+		for _, c := range copies[1:] {
+			copied, err := q.CreateNewSecretVersion(ctx, db.CreateNewSecretVersionParams{
+				Path:           to,
+				EncryptedValue: c.encryptedValue,
+				Nonce:          c.nonce,
+				CreatedBy:      createdBy,
+				HmacSignature:  c.hmacSignature,
+				HmacKeyID:      hmacKeyID,
+				Environment:    c.environment,
+			})
+			if err != nil {
+				return err
+			}
+			resp.Environments = append(resp.Environments, c.environment)
+			changes = append(changes, events.Event{
+				Type:        events.VersionChanged,
+				Path:        to,
+				Environment: copied.Environment,
+				Version:     copied.Version,
+				OwnerID:     userID,
+				TeamID:      teamID,
+			})
+		}
+
+		copiedTo := fmt.Sprintf("copied to %s", to)
+		if err = s.auditSvc.LogTx(ctx, q, authPayload.UserID, authPayload.Email, "copy_secret", from, 0, true, &copiedTo); err != nil {
+			return fmt.Errorf("failed to log action: %w", err)
+		}
+		copiedFrom := fmt.Sprintf("copied from %s", from)
+		if err = s.auditSvc.LogTx(ctx, q, authPayload.UserID, authPayload.Email, "copy_secret", to, 1, true, &copiedFrom); err != nil {
+			return fmt.Errorf("failed to log action: %w", err)
+		}
+		return s.publishTx(ctx, q, changes...)
+	})
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
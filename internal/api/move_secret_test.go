@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMoveSecret(t *testing.T) {
+	owner := newTestUser(t)
+	reader := newTestUser(t)
+
+	secret := owner.createSecret("db/password", "s3cret")
+	require.Equal(t, http.StatusOK, owner.share(shareSecretRequest{Path: secret.Path, TargetEmail: reader.email, Permission: "read"}))
+
+	moved := owner.email + "/db/primary-password"
+	var resp moveSecretResponse
+	require.Equal(t, http.StatusOK, owner.call(http.MethodPost, "/secrets/move", moveSecretRequest{From: secret.Path, To: moved}, &resp))
+	require.Equal(t, moved, resp.To)
+
+	// the share follows the secret
+	_, code := owner.getSecret(secret.Path)
+	require.Equal(t, http.StatusNotFound, code)
+	got, code := reader.getSecret(moved)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "s3cret", got.Decrypted)
+
+	// a share does not allow moving
+	require.Equal(t, http.StatusForbidden, reader.call(http.MethodPost, "/secrets/move", moveSecretRequest{From: moved, To: reader.email + "/db/password"}, nil))
+}
+
+func TestMoveSecretWithDeleteOnly(t *testing.T) {
+	owner := newTestUser(t)
+	mover := newTestUser(t)
+
+	secret := owner.createSecret("policy-delete/key", "k")
+
+	// delete-policy grants delete on the secret, but not read
+	require.Equal(t, http.StatusOK, mover.call(http.MethodPost, "/groups", createGroupRequest{Slug: deleteGroup, Name: "Deleters"}, nil))
+	var caps capabilitiesResponse
+	require.Equal(t, http.StatusOK, mover.call(http.MethodGet, "/capabilities/"+secret.Path, nil, &caps))
+	require.Equal(t, []string{"delete"}, caps.Capabilities)
+
+	require.Equal(t, http.StatusForbidden, mover.call(http.MethodPost, "/secrets/move", moveSecretRequest{From: secret.Path, To: mover.email + "/stolen"}, nil))
+	_, code := mover.getSecret(mover.email + "/stolen")
+	require.Equal(t, http.StatusNotFound, code)
+
+	got, code := owner.getSecret(secret.Path)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "k", got.Decrypted)
+}
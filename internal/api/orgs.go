@@ -0,0 +1,778 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/pixperk/vaultify/internal/auth"
+	db "github.com/pixperk/vaultify/internal/db/sqlc"
+	"github.com/pixperk/vaultify/internal/events"
+	"github.com/pixperk/vaultify/internal/policy"
+)
+
+const (
+	orgRoleOwner = "owner"
+	orgRoleAdmin = "admin"
+
+	teamRoleMaintainer = "maintainer"
+	teamRoleMember     = "member"
+	teamRoleViewer     = "viewer"
+
+	// team owned secrets live under org/<org>/<team>/<name>
+	orgNamespacePrefix = "org/"
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+func teamRoleCanRead(role string) bool {
+	return role == teamRoleMaintainer || role == teamRoleMember || role == teamRoleViewer
+}
+
+func teamRoleCanWrite(role string) bool {
+	return role == teamRoleMaintainer || role == teamRoleMember
+}
+
+// teamCapabilities is what a team role grants on the team's secrets. Maintainers own them.
+func teamCapabilities(role string) policy.Capability {
+	switch role {
+	case teamRoleMaintainer:
+		return policy.All
+	case teamRoleMember:
+		return policy.Read | policy.List | policy.History | policy.Create | policy.Update | policy.Rollback
+	case teamRoleViewer:
+		return policy.Read | policy.List | policy.History
+	}
+	return 0
+}
+
+func orgRoleCanManage(role string) bool {
+	return role == orgRoleOwner || role == orgRoleAdmin
+}
+
+// teamNamespace splits org/<org>/<team>/<name> into its org and team slugs
+func teamNamespace(path string) (orgSlug, teamSlug string, ok bool) {
+	if !strings.HasPrefix(path, orgNamespacePrefix) {
+		return "", "", false
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(path, orgNamespacePrefix), "/", 3)
+	if len(parts) < 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// teamPrefixNamespace reports whether prefix lies inside a team namespace, org/<org>/<team>/...
+func teamPrefixNamespace(prefix string) (orgSlug, teamSlug string, ok bool) {
+	if !strings.HasPrefix(prefix, orgNamespacePrefix) {
+		return "", "", false
+	}
+	parts := strings.SplitN(strings.TrimPrefix(prefix, orgNamespacePrefix), "/", 3)
+	if len(parts) < 3 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+type createOrganizationRequest struct {
+	Slug string `json:"slug" binding:"required"`
+	Name string `json:"name" binding:"required"`
+}
+
+type organizationResponse struct {
+	Slug      string    `json:"slug"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type createTeamRequest struct {
+	Slug string `json:"slug" binding:"required"`
+	Name string `json:"name" binding:"required"`
+}
+
+type teamResponse struct {
+	Slug      string    `json:"slug"`
+	Name      string    `json:"name"`
+	Namespace string    `json:"namespace"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type addOrgMemberRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required,oneof=owner admin member"`
+}
+
+type addTeamMemberRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required,oneof=maintainer member viewer"`
+}
+
+type memberResponse struct {
+	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newTeamResponse(org string, team db.Teams) teamResponse {
+	return teamResponse{
+		Slug:      team.Slug,
+		Name:      team.Name,
+		Namespace: fmt.Sprintf("%s%s/%s/", orgNamespacePrefix, org, team.Slug),
+		CreatedAt: team.CreatedAt.Time,
+	}
+}
+
+// orgMembership loads the organization named in the route and the caller's role in it.
+// It writes the error response itself and returns ok=false when the caller is not a member.
+func (s *Server) orgMembership(ctx *gin.Context, authPayload *auth.Payload) (org db.Organizations, role string, ok bool) {
+	org, err := s.store.GetOrganizationBySlug(ctx, ctx.Param("org"))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("organization not found")))
+			return org, "", false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return org, "", false
+	}
+
+	role, err = s.store.GetOrgMemberRole(ctx, db.GetOrgMemberRoleParams{
+		OrgID:  org.ID,
+		UserID: authPayload.UserID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// don't reveal organizations the caller is not part of
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("organization not found")))
+			return org, "", false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return org, "", false
+	}
+
+	return org, role, true
+}
+
+// orgTeam loads the team named in the route, which must belong to org
+func (s *Server) orgTeam(ctx *gin.Context, org db.Organizations) (db.Teams, bool) {
+	team, err := s.store.GetTeamBySlugs(ctx, db.GetTeamBySlugsParams{
+		Slug:   org.Slug,
+		Slug_2: ctx.Param("team"),
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("team not found")))
+			return team, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return team, false
+	}
+	return team, true
+}
+
+// teamForWrite resolves the team owning an org/<org>/<team>/<name> path and checks that the
+// user can create secrets at path. Client errors are returned as *statusError.
+func (s *Server) teamForWrite(ctx context.Context, authPayload *auth.Payload, path string) (uuid.NullUUID, error) {
+	orgSlug, teamSlug, ok := teamNamespace(path)
+	if !ok {
+		return uuid.NullUUID{}, newStatusError(http.StatusBadRequest, "team secret paths must look like org/<org>/<team>/<name>")
+	}
+
+	team, err := s.store.GetTeamBySlugs(ctx, db.GetTeamBySlugsParams{Slug: orgSlug, Slug_2: teamSlug})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return uuid.NullUUID{}, newStatusError(http.StatusNotFound, "team not found")
+		}
+		return uuid.NullUUID{}, err
+	}
+
+	teamID := uuid.NullUUID{UUID: team.ID, Valid: true}
+	canCreate, err := s.can(ctx, authPayload, db.GetLatestSecretByPathRow{Path: path, TeamID: teamID}, policy.Create)
+	if err != nil {
+		return uuid.NullUUID{}, err
+	}
+	if !canCreate {
+		return uuid.NullUUID{}, newStatusError(http.StatusForbidden, "you do not have write access to this team's secrets")
+	}
+	return teamID, nil
+}
+
+// writableTeam is teamForWrite for handlers: on failure the error response has already been written
+func (s *Server) writableTeam(ctx *gin.Context, authPayload *auth.Payload, path string) (uuid.NullUUID, bool) {
+	teamID, err := s.teamForWrite(ctx, authPayload, path)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return teamID, false
+	}
+	return teamID, true
+}
+
+func (s *Server) lookupMember(ctx *gin.Context, email string) (db.Users, bool) {
+	user, err := s.store.GetUserByEmail(ctx, email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("the user does not exist")))
+			return user, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return user, false
+	}
+	return user, true
+}
+
+// logOrgAction audits a change to an organization, team or group and announces it, since any
+// such change can alter who may read team secrets or secrets shared with a group
+func (s *Server) logOrgAction(ctx context.Context, q *db.Queries, authPayload *auth.Payload, action, resource, detail string) error {
+	if err := s.auditSvc.LogTx(ctx, q, authPayload.UserID, authPayload.Email, action, resource, 0, true, &detail); err != nil {
+		return fmt.Errorf("failed to log action: %w", err)
+	}
+	return s.publishTx(ctx, q, events.Event{Type: events.MembershipChanged, Path: resource})
+}
+
+// @Summary      Create an organization
+// @Description  Creates an organization; the caller becomes its owner.
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Param        request body     createOrganizationRequest  true  "Organization"
+// @Success      200     {object} organizationResponse
+// @Failure      400     {object} swaggerErrorResponse "Invalid slug"
+// @Failure      409     {object} swaggerErrorResponse "Slug already taken"
+// @Failure      500     {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /orgs [post]
+func (s *Server) createOrganization(ctx *gin.Context) {
+	var req createOrganizationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if !slugPattern.MatchString(req.Slug) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("slug must be lowercase letters, digits and dashes")))
+		return
+	}
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	var org db.Organizations
+	err := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		var err error
+		org, err = q.CreateOrganization(ctx, db.CreateOrganizationParams{
+			Slug:      req.Slug,
+			Name:      req.Name,
+			CreatedBy: authPayload.UserID,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = q.UpsertOrgMember(ctx, db.UpsertOrgMemberParams{
+			OrgID:  org.ID,
+			UserID: authPayload.UserID,
+			Role:   orgRoleOwner,
+		})
+		if err != nil {
+			return err
+		}
+
+		return s.logOrgAction(ctx, q, authPayload, "create_org", orgNamespacePrefix+org.Slug, "created organization "+org.Name)
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			ctx.JSON(http.StatusConflict, errorResponse(fmt.Errorf("organization slug already taken")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, organizationResponse{
+		Slug:      org.Slug,
+		Name:      org.Name,
+		Role:      orgRoleOwner,
+		CreatedAt: org.CreatedAt.Time,
+	})
+}
+
+// @Summary      List my organizations
+// @Description  Lists the organizations the caller belongs to, with the caller's role in each.
+// @Tags         Organizations
+// @Produce      json
+// @Success      200     {array}  organizationResponse
+// @Failure      500     {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /orgs [get]
+func (s *Server) listOrganizations(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	orgs, err := s.store.ListOrganizationsForUser(ctx, authPayload.UserID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	resp := make([]organizationResponse, 0, len(orgs))
+	for _, o := range orgs {
+		resp = append(resp, organizationResponse{
+			Slug:      o.Slug,
+			Name:      o.Name,
+			Role:      o.Role,
+			CreatedAt: o.CreatedAt.Time,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// @Summary      List organization members
+// @Tags         Organizations
+// @Produce      json
+// @Param        org     path     string  true  "Organization slug"
+// @Success      200     {array}  memberResponse
+// @Failure      404     {object} swaggerErrorResponse "Organization not found"
+// @Failure      500     {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /orgs/{org}/members [get]
+func (s *Server) listOrgMembers(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+	org, _, ok := s.orgMembership(ctx, authPayload)
+	if !ok {
+		return
+	}
+
+	members, err := s.store.ListOrgMembers(ctx, org.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	resp := make([]memberResponse, 0, len(members))
+	for _, m := range members {
+		resp = append(resp, memberResponse{
+			Email:     m.Email,
+			Name:      m.Name,
+			Role:      m.Role,
+			CreatedAt: m.CreatedAt.Time,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// @Summary      Add or update an organization member
+// @Description  Adds a user to the organization or changes their role. Requires owner or admin; only owners can grant the owner role or change an owner's role.
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Param        org     path     string               true  "Organization slug"
+// @Param        request body     addOrgMemberRequest  true  "Member"
+// @Success      200     {object} memberResponse
+// @Failure      400     {object} swaggerErrorResponse
+// @Failure      403     {object} swaggerErrorResponse "Not an owner or admin, or the change touches an owner and the caller is not one"
+// @Failure      404     {object} swaggerErrorResponse "Organization or user not found"
+// @Failure      500     {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /orgs/{org}/members [post]
+func (s *Server) addOrgMember(ctx *gin.Context) {
+	var req addOrgMemberRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	org, role, ok := s.orgMembership(ctx, authPayload)
+	if !ok {
+		return
+	}
+	if !orgRoleCanManage(role) || (req.Role == orgRoleOwner && role != orgRoleOwner) {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("you do not have permission to manage members of this organization")))
+		return
+	}
+
+	user, ok := s.lookupMember(ctx, req.Email)
+	if !ok {
+		return
+	}
+
+	var member db.OrgMembers
+	err := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		if err := checkOwnerChange(ctx, q, org.ID, role, user.ID, req.Role); err != nil {
+			return err
+		}
+
+		var err error
+		member, err = q.UpsertOrgMember(ctx, db.UpsertOrgMemberParams{
+			OrgID:  org.ID,
+			UserID: user.ID,
+			Role:   req.Role,
+		})
+		if err != nil {
+			return err
+		}
+
+		return s.logOrgAction(ctx, q, authPayload, "add_org_member", orgNamespacePrefix+org.Slug, fmt.Sprintf("%s as %s", user.Email, req.Role))
+	})
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, memberResponse{
+		Email:     user.Email,
+		Name:      user.Name,
+		Role:      member.Role,
+		CreatedAt: member.CreatedAt.Time,
+	})
+}
+
+var errLastOwner = newStatusError(http.StatusBadRequest, "an organization must keep at least one owner")
+
+// checkOwnerChange rejects giving userID newRole, or removing them when newRole is empty, when
+// the change touches an owner and callerRole is not owner, or when it would demote or remove
+// the organization's last owner. The owners stay locked until the transaction ends.
+func checkOwnerChange(ctx context.Context, q *db.Queries, orgID uuid.UUID, callerRole string, userID uuid.UUID, newRole string) error {
+	owners, err := q.LockOrgOwners(ctx, orgID)
+	if err != nil {
+		return err
+	}
+
+	isOwner := slices.Contains(owners, userID)
+	if (isOwner || newRole == orgRoleOwner) && callerRole != orgRoleOwner {
+		return newStatusError(http.StatusForbidden, "only owners can change or remove an owner")
+	}
+	if isOwner && newRole != orgRoleOwner && len(owners) <= 1 {
+		return errLastOwner
+	}
+	return nil
+}
+
+// @Summary      Remove an organization member
+// @Description  Removes a user from the organization and from all of its teams. Requires owner or admin; only owners can remove an owner.
+// @Tags         Organizations
+// @Produce      json
+// @Param        org     path     string  true  "Organization slug"
+// @Param        email   path     string  true  "Member email"
+// @Success      204
+// @Failure      400     {object} swaggerErrorResponse "Cannot remove the last owner"
+// @Failure      403     {object} swaggerErrorResponse "Not an owner or admin, or the member is an owner and the caller is not one"
+// @Failure      404     {object} swaggerErrorResponse "Organization or user not found"
+// @Failure      500     {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /orgs/{org}/members/{email} [delete]
+func (s *Server) removeOrgMember(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	org, role, ok := s.orgMembership(ctx, authPayload)
+	if !ok {
+		return
+	}
+	if !orgRoleCanManage(role) {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("you do not have permission to manage members of this organization")))
+		return
+	}
+
+	user, ok := s.lookupMember(ctx, ctx.Param("email"))
+	if !ok {
+		return
+	}
+
+	err := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		if err := checkOwnerChange(ctx, q, org.ID, role, user.ID, ""); err != nil {
+			return err
+		}
+
+		if err := q.RemoveUserFromOrgTeams(ctx, db.RemoveUserFromOrgTeamsParams{OrgID: org.ID, UserID: user.ID}); err != nil {
+			return err
+		}
+		if err := q.RemoveOrgMember(ctx, db.RemoveOrgMemberParams{OrgID: org.ID, UserID: user.ID}); err != nil {
+			return err
+		}
+
+		return s.logOrgAction(ctx, q, authPayload, "remove_org_member", orgNamespacePrefix+org.Slug, user.Email)
+	})
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// @Summary      Create a team
+// @Description  Creates a team inside the organization. Its secrets live under org/{org}/{team}/. Requires owner or admin.
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Param        org     path     string             true  "Organization slug"
+// @Param        request body     createTeamRequest  true  "Team"
+// @Success      200     {object} teamResponse
+// @Failure      400     {object} swaggerErrorResponse "Invalid slug"
+// @Failure      403     {object} swaggerErrorResponse "Not an owner or admin"
+// @Failure      404     {object} swaggerErrorResponse "Organization not found"
+// @Failure      409     {object} swaggerErrorResponse "Team already exists"
+// @Failure      500     {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /orgs/{org}/teams [post]
+func (s *Server) createTeam(ctx *gin.Context) {
+	var req createTeamRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if !slugPattern.MatchString(req.Slug) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("slug must be lowercase letters, digits and dashes")))
+		return
+	}
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	org, role, ok := s.orgMembership(ctx, authPayload)
+	if !ok {
+		return
+	}
+	if !orgRoleCanManage(role) {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("you do not have permission to create teams in this organization")))
+		return
+	}
+
+	var team db.Teams
+	err := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		var err error
+		team, err = q.CreateTeam(ctx, db.CreateTeamParams{
+			OrgID: org.ID,
+			Slug:  req.Slug,
+			Name:  req.Name,
+		})
+		if err != nil {
+			return err
+		}
+
+		return s.logOrgAction(ctx, q, authPayload, "create_team", fmt.Sprintf("%s%s/%s", orgNamespacePrefix, org.Slug, team.Slug), "created team "+team.Name)
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			ctx.JSON(http.StatusConflict, errorResponse(fmt.Errorf("team already exists")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newTeamResponse(org.Slug, team))
+}
+
+// @Summary      List teams
+// @Tags         Organizations
+// @Produce      json
+// @Param        org     path     string  true  "Organization slug"
+// @Success      200     {array}  teamResponse
+// @Failure      404     {object} swaggerErrorResponse "Organization not found"
+// @Failure      500     {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /orgs/{org}/teams [get]
+func (s *Server) listTeams(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+	org, _, ok := s.orgMembership(ctx, authPayload)
+	if !ok {
+		return
+	}
+
+	teams, err := s.store.ListTeamsForOrg(ctx, org.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	resp := make([]teamResponse, 0, len(teams))
+	for _, t := range teams {
+		resp = append(resp, newTeamResponse(org.Slug, t))
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// @Summary      List team members
+// @Tags         Organizations
+// @Produce      json
+// @Param        org     path     string  true  "Organization slug"
+// @Param        team    path     string  true  "Team slug"
+// @Success      200     {array}  memberResponse
+// @Failure      404     {object} swaggerErrorResponse "Organization or team not found"
+// @Failure      500     {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /orgs/{org}/teams/{team}/members [get]
+func (s *Server) listTeamMembers(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+	org, _, ok := s.orgMembership(ctx, authPayload)
+	if !ok {
+		return
+	}
+	team, ok := s.orgTeam(ctx, org)
+	if !ok {
+		return
+	}
+
+	members, err := s.store.ListTeamMembers(ctx, team.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	resp := make([]memberResponse, 0, len(members))
+	for _, m := range members {
+		resp = append(resp, memberResponse{
+			Email:     m.Email,
+			Name:      m.Name,
+			Role:      m.Role,
+			CreatedAt: m.CreatedAt.Time,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// @Summary      Add or update a team member
+// @Description  Adds an organization member to the team or changes their role. Requires org owner/admin or team maintainer.
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Param        org     path     string                true  "Organization slug"
+// @Param        team    path     string                true  "Team slug"
+// @Param        request body     addTeamMemberRequest  true  "Member"
+// @Success      200     {object} memberResponse
+// @Failure      400     {object} swaggerErrorResponse "User is not an organization member"
+// @Failure      403     {object} swaggerErrorResponse "Not allowed to manage the team"
+// @Failure      404     {object} swaggerErrorResponse "Organization, team or user not found"
+// @Failure      500     {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /orgs/{org}/teams/{team}/members [post]
+func (s *Server) addTeamMember(ctx *gin.Context) {
+	var req addTeamMemberRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	org, _, ok := s.orgMembership(ctx, authPayload)
+	if !ok {
+		return
+	}
+	team, ok := s.orgTeam(ctx, org)
+	if !ok {
+		return
+	}
+	if !s.canManageTeam(ctx, authPayload, team) {
+		return
+	}
+
+	user, ok := s.lookupMember(ctx, req.Email)
+	if !ok {
+		return
+	}
+	if _, err := s.store.GetOrgMemberRole(ctx, db.GetOrgMemberRoleParams{OrgID: org.ID, UserID: user.ID}); err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("the user must be a member of the organization first")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	var member db.TeamMembers
+	err := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		var err error
+		member, err = q.UpsertTeamMember(ctx, db.UpsertTeamMemberParams{
+			TeamID: team.ID,
+			UserID: user.ID,
+			Role:   req.Role,
+		})
+		if err != nil {
+			return err
+		}
+
+		return s.logOrgAction(ctx, q, authPayload, "add_team_member", fmt.Sprintf("%s%s/%s", orgNamespacePrefix, org.Slug, team.Slug), fmt.Sprintf("%s as %s", user.Email, req.Role))
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, memberResponse{
+		Email:     user.Email,
+		Name:      user.Name,
+		Role:      member.Role,
+		CreatedAt: member.CreatedAt.Time,
+	})
+}
+
+// @Summary      Remove a team member
+// @Description  Removes a user from the team; they immediately lose access to the team's secrets. Requires org owner/admin or team maintainer.
+// @Tags         Organizations
+// @Produce      json
+// @Param        org     path     string  true  "Organization slug"
+// @Param        team    path     string  true  "Team slug"
+// @Param        email   path     string  true  "Member email"
+// @Success      204
+// @Failure      403     {object} swaggerErrorResponse "Not allowed to manage the team"
+// @Failure      404     {object} swaggerErrorResponse "Organization, team or user not found"
+// @Failure      500     {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /orgs/{org}/teams/{team}/members/{email} [delete]
+func (s *Server) removeTeamMember(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	org, _, ok := s.orgMembership(ctx, authPayload)
+	if !ok {
+		return
+	}
+	team, ok := s.orgTeam(ctx, org)
+	if !ok {
+		return
+	}
+	if !s.canManageTeam(ctx, authPayload, team) {
+		return
+	}
+
+	user, ok := s.lookupMember(ctx, ctx.Param("email"))
+	if !ok {
+		return
+	}
+
+	err := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		if err := q.RemoveTeamMember(ctx, db.RemoveTeamMemberParams{TeamID: team.ID, UserID: user.ID}); err != nil {
+			return err
+		}
+
+		return s.logOrgAction(ctx, q, authPayload, "remove_team_member", fmt.Sprintf("%s%s/%s", orgNamespacePrefix, org.Slug, team.Slug), user.Email)
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// canManageTeam checks the caller is an org owner/admin or a maintainer of the team
+func (s *Server) canManageTeam(ctx *gin.Context, authPayload *auth.Payload, team db.Teams) bool {
+	role, err := s.teamRole(ctx, team.ID, authPayload.UserID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+	if role != teamRoleMaintainer {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("you do not have permission to manage this team")))
+		return false
+	}
+	return true
+}
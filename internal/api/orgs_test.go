@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/pixperk/vaultify/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrgOwners(t *testing.T) {
+	owner := newTestUser(t)
+	second := newTestUser(t)
+	admin := newTestUser(t)
+
+	var org organizationResponse
+	require.Equal(t, http.StatusOK, owner.call(http.MethodPost, "/orgs", createOrganizationRequest{Slug: util.RandomString(10), Name: "Acme"}, &org))
+	members := "/orgs/" + org.Slug + "/members"
+
+	// the last owner can neither step down nor leave
+	require.Equal(t, http.StatusBadRequest, owner.call(http.MethodPost, members, addOrgMemberRequest{Email: owner.email, Role: "admin"}, nil))
+	require.Equal(t, http.StatusBadRequest, owner.call(http.MethodDelete, members+"/"+owner.email, nil, nil))
+
+	require.Equal(t, http.StatusOK, owner.call(http.MethodPost, members, addOrgMemberRequest{Email: second.email, Role: "owner"}, nil))
+	require.Equal(t, http.StatusOK, owner.call(http.MethodPost, members, addOrgMemberRequest{Email: admin.email, Role: "admin"}, nil))
+
+	// admins manage members but not owners
+	require.Equal(t, http.StatusForbidden, admin.call(http.MethodPost, members, addOrgMemberRequest{Email: admin.email, Role: "owner"}, nil))
+	require.Equal(t, http.StatusForbidden, admin.call(http.MethodPost, members, addOrgMemberRequest{Email: second.email, Role: "member"}, nil))
+	require.Equal(t, http.StatusForbidden, admin.call(http.MethodDelete, members+"/"+second.email, nil, nil))
+
+	var listed []memberResponse
+	require.Equal(t, http.StatusOK, owner.call(http.MethodGet, members, nil, &listed))
+	for _, m := range listed {
+		if m.Email == second.email {
+			require.Equal(t, "owner", m.Role)
+		}
+	}
+
+	// with another owner left, an owner can go
+	require.Equal(t, http.StatusNoContent, second.call(http.MethodDelete, members+"/"+owner.email, nil, nil))
+	require.Equal(t, http.StatusBadRequest, second.call(http.MethodPost, members, addOrgMemberRequest{Email: second.email, Role: "member"}, nil))
+}
@@ -1,131 +1,306 @@
 package api
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/pixperk/vaultify/internal/auth"
 	db "github.com/pixperk/vaultify/internal/db/sqlc"
+	"github.com/pixperk/vaultify/internal/policy"
+	"github.com/pixperk/vaultify/internal/secretpath"
 )
 
-func (s *Server) RequireReadAccess() gin.HandlerFunc {
-	return func(ctx *gin.Context) {
-		rawPath := ctx.Param("path")
-		path := strings.TrimPrefix(rawPath, "/") // remove leading slash
-		authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
-		var err error
-		var secret db.GetLatestSecretByPathRow
-		//Get the query params for version
-		version := ctx.Query("version")
-		if version == "" {
-			secret, err = s.store.GetLatestSecretByPath(ctx, path)
-		} else {
-			// Parse version string to int32
-			var versionInt int32
-			if _, err := fmt.Sscanf(version, "%d", &versionInt); err != nil {
-				ctx.AbortWithStatusJSON(400, gin.H{"error": "Invalid version format"})
-				return
-			}
+// teamRole returns the user's effective role on a team. Owners and admins of the
+// team's organization act as maintainers of every team in it.
+func (s *Server) teamRole(ctx context.Context, teamID, userID uuid.UUID) (string, error) {
+	access, err := s.store.GetTeamAccessForUser(ctx, db.GetTeamAccessForUserParams{
+		ID:     teamID,
+		UserID: userID,
+	})
+	if err != nil {
+		return "", err
+	}
 
-			// Get specific version
-			versionSecret, err := s.store.GetSecretVersionByPathAndVersion(ctx, db.GetSecretVersionByPathAndVersionParams{
-				Path:    path,
-				Version: versionInt,
-			})
-			if err != nil {
-				if err == sql.ErrNoRows {
-					ctx.AbortWithStatusJSON(404, gin.H{"error": "Secret version not found"})
-				} else {
-					ctx.AbortWithStatusJSON(500, gin.H{"error": "Error fetching secret version"})
-				}
-				return
-			}
+	return effectiveTeamRole(access.OrgRole, access.TeamRole), nil
+}
 
-			// Convert to GetLatestSecretByPathRow type using type conversion
-			secret = db.GetLatestSecretByPathRow(versionSecret)
-		}
+// effectiveTeamRole combines a user's organization and team roles into the team role that counts
+func effectiveTeamRole(orgRole, teamRole string) string {
+	if orgRole == orgRoleOwner || orgRole == orgRoleAdmin {
+		return teamRoleMaintainer
+	}
+	// team roles only count while the user is still part of the organization
+	if orgRole == "" {
+		return ""
+	}
+	return teamRole
+}
 
+// builtinCapabilities are the capabilities that follow from owning the secret, the caller's
+// role on its team and the share that applies to them. until is when the share that applies
+// runs out, zero when it does not.
+func (s *Server) builtinCapabilities(ctx context.Context, authPayload *auth.Payload, secret db.GetLatestSecretByPathRow) (caps policy.Capability, until time.Time, err error) {
+	if secret.TeamID.Valid {
+		role, err := s.teamRole(ctx, secret.TeamID.UUID, authPayload.UserID)
 		if err != nil {
-			if err == sql.ErrNoRows {
-				ctx.AbortWithStatusJSON(404, gin.H{"error": "Secret not found"})
-			} else {
-				ctx.AbortWithStatusJSON(500, gin.H{"error": "Error fetching secret"})
-			}
-			return
+			return 0, until, err
 		}
+		caps = teamCapabilities(role)
+	} else if secret.UserID == authPayload.UserID {
+		return policy.All, until, nil
+	}
+	if caps == policy.All {
+		return caps, until, nil
+	}
 
-		// Check if the user is owner
-		if secret.UserID == authPayload.UserID {
-			ctx.Set("secret", secret)
-			ctx.Next()
-			return
-		}
+	// Check if shared, directly or through a folder
+	permission, sharedUntil, err := s.sharedPermission(ctx, secret.Path, authPayload)
+	if err != nil {
+		return 0, until, err
+	}
+	if sharedUntil.Valid {
+		until = sharedUntil.Time
+	}
+	return caps | shareCapabilities(permission), until, nil
+}
 
-		// Check if shared
-		canRead, err := s.store.CheckIfShared(ctx, db.CheckIfSharedParams{
-			Path:        path,
-			TargetEmail: authPayload.Email,
-		})
+// shareCapabilities is what a read or write share grants. Only owners can share.
+func shareCapabilities(permission string) policy.Capability {
+	switch permission {
+	case "read":
+		return policy.Read | policy.List | policy.History
+	case "write":
+		return policy.Read | policy.List | policy.History | policy.Update | policy.Rollback
+	}
+	return 0
+}
 
-		if err != nil {
-			ctx.AbortWithStatusJSON(500, gin.H{"error": "Permission check failed"})
-			return
-		}
+// policySubject is who policies are evaluated for. Groups are only looked up when some
+// policy is attached to a group.
+func (s *Server) policySubject(ctx context.Context, authPayload *auth.Payload) (policy.Subject, error) {
+	sub := policy.Subject{Email: strings.ToLower(authPayload.Email)}
+	if !s.policies.UsesGroups() {
+		return sub, nil
+	}
+	groups, err := s.store.ListGroupsForUser(ctx, authPayload.UserID)
+	if err != nil {
+		return sub, err
+	}
+	for _, group := range groups {
+		sub.Groups = append(sub.Groups, group.Slug)
+	}
+	return sub, nil
+}
 
-		if canRead {
-			ctx.Set("secret", secret)
-			ctx.Next()
-			return
+// capabilities is the single authorizer for secret paths. The built-in grants are extended by
+// the policies attached to the caller, and a matching deny rule takes everything away. A token
+// issued for named policies is further limited to what those policies grant. Anything not
+// granted is denied.
+func (s *Server) capabilities(ctx context.Context, authPayload *auth.Payload, secret db.GetLatestSecretByPathRow) (policy.Capability, error) {
+	caps, _, err := s.capabilitiesUntil(ctx, authPayload, secret)
+	return caps, err
+}
+
+// capabilitiesUntil is capabilities together with when they may change without any event: the
+// expiry of the time-bound share they rest on, or zero when there is none
+func (s *Server) capabilitiesUntil(ctx context.Context, authPayload *auth.Payload, secret db.GetLatestSecretByPathRow) (policy.Capability, time.Time, error) {
+	caps, until, err := s.builtinCapabilities(ctx, authPayload, secret)
+	if err != nil {
+		return 0, until, err
+	}
+
+	sub, err := s.policySubject(ctx, authPayload)
+	if err != nil {
+		return 0, until, err
+	}
+	caps = s.policies.Evaluate(sub, secret.Path).Allowed(caps)
+
+	if len(authPayload.Policies) > 0 {
+		caps &= s.policies.EvaluateNamed(authPayload.Policies, secret.Path).Allowed(0)
+	}
+	return caps, until, nil
+}
+
+// can reports whether the caller holds every capability of want on the secret
+func (s *Server) can(ctx context.Context, authPayload *auth.Payload, secret db.GetLatestSecretByPathRow, want policy.Capability) (bool, error) {
+	caps, err := s.capabilities(ctx, authPayload, secret)
+	if err != nil {
+		return false, err
+	}
+	return caps.Has(want), nil
+}
+
+// pathOwnership describes who would own a secret at path that does not exist yet, so the
+// authorizer can be asked about it: the caller in their own namespace, or the team whose
+// namespace it is in
+func (s *Server) pathOwnership(ctx context.Context, authPayload *auth.Payload, path string) (db.GetLatestSecretByPathRow, error) {
+	secret := db.GetLatestSecretByPathRow{Path: path}
+	if strings.HasPrefix(path+"/", personalNamespace(authPayload.Email)) {
+		secret.UserID = authPayload.UserID
+		return secret, nil
+	}
+
+	orgSlug, teamSlug, ok := teamPrefixNamespace(path + "/")
+	if !ok {
+		return secret, nil
+	}
+	team, err := s.store.GetTeamBySlugs(ctx, db.GetTeamBySlugsParams{Slug: orgSlug, Slug_2: teamSlug})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return secret, nil
 		}
+		return secret, err
+	}
+	secret.TeamID = uuid.NullUUID{UUID: team.ID, Valid: true}
+	return secret, nil
+}
 
-		ctx.AbortWithStatusJSON(403, gin.H{"error": "Access denied"})
+// sharedPermission returns the permission of the sharing rule that applies to the user on
+// path, or "" when nothing is shared, and when that rule runs out. Rules for the user and for
+// their groups count. The most specific rule wins: a share of the secret itself, then the share
+// of the closest folder above it.
+func (s *Server) sharedPermission(ctx context.Context, path string, authPayload *auth.Payload) (string, sql.NullTime, error) {
+	rule, err := s.store.GetEffectiveShare(ctx, db.GetEffectiveShareParams{
+		TargetEmail: authPayload.Email,
+		UserID:      authPayload.UserID,
+		Path:        path,
+		Parents:     secretpath.Parents(path),
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", sql.NullTime{}, nil
+		}
+		return "", sql.NullTime{}, err
 	}
+	return rule.Permission, rule.SharedUntil, nil
 }
 
-func (s *Server) RequireWriteAccess() gin.HandlerFunc {
-	return func(ctx *gin.Context) {
-		rawPath := ctx.Param("path")
-		path := strings.TrimPrefix(rawPath, "/") // remove leading slash
-		authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+// checkPersonalCreate checks that neither a policy nor the token scope keeps the caller from
+// creating path in their own namespace. Client errors are returned as *statusError.
+func (s *Server) checkPersonalCreate(ctx context.Context, authPayload *auth.Payload, path string) error {
+	canCreate, err := s.can(ctx, authPayload, db.GetLatestSecretByPathRow{Path: path, UserID: authPayload.UserID}, policy.Create)
+	if err != nil {
+		return err
+	}
+	if !canCreate {
+		return newStatusError(http.StatusForbidden, "you are not allowed to create %s", path)
+	}
+	return nil
+}
 
-		secret, err := s.store.GetLatestSecretByPath(ctx, path)
+// secretForRead loads a version of the secret, the latest when version is 0, and checks
+// that the caller holds want on it, usually read or history
+func (s *Server) secretForRead(ctx context.Context, authPayload *auth.Payload, path, env string, version int32, want policy.Capability) (db.GetLatestSecretByPathRow, error) {
+	var secret db.GetLatestSecretByPathRow
+	var err error
+	if version == 0 {
+		secret, err = s.latestSecret(ctx, path, env)
+	} else {
+		// Get specific version
+		versionSecret, err := s.store.GetSecretVersionByPathAndVersion(ctx, db.GetSecretVersionByPathAndVersionParams{
+			Path:        path,
+			Version:     version,
+			Environment: env,
+		})
 		if err != nil {
 			if err == sql.ErrNoRows {
-				ctx.AbortWithStatusJSON(404, gin.H{"error": "Secret not found"})
-			} else {
-				ctx.AbortWithStatusJSON(500, gin.H{"error": "Error fetching secret"})
+				return secret, newStatusError(http.StatusNotFound, "Secret version not found")
 			}
-			return
+			return secret, newStatusError(http.StatusInternalServerError, "Error fetching secret version")
 		}
 
-		// Check if the user is owner
-		if secret.UserID == authPayload.UserID {
-			ctx.Set("secret", secret)
-			ctx.Next()
-			return
+		// Convert to GetLatestSecretByPathRow type using type conversion
+		secret = db.GetLatestSecretByPathRow(versionSecret)
+	}
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return secret, newStatusError(http.StatusNotFound, "Secret not found")
 		}
+		return secret, newStatusError(http.StatusInternalServerError, "Error fetching secret")
+	}
 
-		// Check if shared
-		permissions, err := s.store.GetPermissions(ctx, db.GetPermissionsParams{
-			Path:        path,
-			TargetEmail: authPayload.Email,
-		})
+	caps, err := s.cachedCapabilities(ctx, authPayload, secret)
+	if err != nil {
+		return secret, newStatusError(http.StatusInternalServerError, "Permission check failed")
+	}
+	if !caps.Has(want) {
+		return secret, newStatusError(http.StatusForbidden, "Access denied")
+	}
+	return secret, nil
+}
 
-		if err != nil {
+// secretForWrite loads the latest version of the secret in env and checks that the caller holds
+// want on it. Writes may target an environment the secret has no value in yet.
+func (s *Server) secretForWrite(ctx context.Context, authPayload *auth.Payload, path, env string, want policy.Capability) (db.GetLatestSecretByPathRow, error) {
+	secret, err := s.loadSecret(ctx, path, env)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return secret, newStatusError(http.StatusNotFound, "Secret not found")
+		}
+		return secret, newStatusError(http.StatusInternalServerError, "Error fetching secret")
+	}
+
+	canWrite, err := s.can(ctx, authPayload, secret, want)
+	if err != nil {
+		return secret, newStatusError(http.StatusInternalServerError, "Permission check failed")
+	}
+	if !canWrite {
+		return secret, newStatusError(http.StatusForbidden, "Access denied")
+	}
+	return secret, nil
+}
 
-			ctx.AbortWithStatusJSON(403, gin.H{"error": "Access denied"})
+// RequireCapability loads the secret named by the path parameter and aborts unless the caller
+// holds want on it. Reads and history may pick a version; everything else acts on the latest
+// version and may target an environment the secret has no value in yet.
+func (s *Server) RequireCapability(want policy.Capability) gin.HandlerFunc {
+	reading := want == policy.Read || want == policy.History
+	return func(ctx *gin.Context) {
+		path, err := secretpath.Normalize(ctx.Param("path"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+		env, err := s.environmentParam(ctx)
+		if err != nil {
+			ctx.AbortWithStatusJSON(400, gin.H{"error": err.Error()})
 			return
 		}
 
-		// Check if the user has write access
-		if permissions == "write" {
-			ctx.Set("secret", secret)
-			ctx.Next()
+		var secret db.GetLatestSecretByPathRow
+		if reading {
+			//Get the query params for version
+			var versionInt int32
+			if version := ctx.Query("version"); version != "" {
+				// Parse version string to int32
+				if _, err := fmt.Sscanf(version, "%d", &versionInt); err != nil {
+					ctx.AbortWithStatusJSON(400, gin.H{"error": "Invalid version format"})
+					return
+				}
+				if versionInt < 1 {
+					ctx.AbortWithStatusJSON(404, gin.H{"error": "Secret version not found"})
+					return
+				}
+			}
+			secret, err = s.secretForRead(ctx, authPayload, path, env, versionInt, want)
+		} else {
+			secret, err = s.secretForWrite(ctx, authPayload, path, env, want)
+		}
+		if err != nil {
+			ctx.AbortWithStatusJSON(errorStatus(err), gin.H{"error": err.Error()})
 			return
 		}
 
-		ctx.AbortWithStatusJSON(403, gin.H{"error": "Access denied"})
+		ctx.Set("secret", secret)
+		ctx.Set(environmentKey, env)
+		ctx.Next()
 	}
 }
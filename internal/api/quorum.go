@@ -0,0 +1,618 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/pixperk/vaultify/internal/auth"
+	db "github.com/pixperk/vaultify/internal/db/sqlc"
+	"github.com/pixperk/vaultify/internal/notify"
+	"github.com/pixperk/vaultify/internal/policy"
+)
+
+// quorum request states; pending and granted requests past their deadline are reported as expired
+const (
+	quorumPending  = "pending"
+	quorumGranted  = "granted"
+	quorumConsumed = "consumed"
+	quorumExpired  = "expired"
+)
+
+const (
+	defaultQuorumWindow  = time.Hour
+	maxQuorumWindow      = 7 * 24 * time.Hour
+	defaultQuorumReadTTL = 15 * time.Minute
+	maxQuorumReadTTL     = 24 * time.Hour
+)
+
+type setQuorumRequest struct {
+	Approvals int32 `json:"approvals" binding:"required,min=1,max=10"`
+	// WindowSecs is how long approvals are collected, 1 hour by default
+	WindowSecs int64 `json:"window_secs" binding:"omitempty,min=60"`
+	// ReadTTLSecs is how long the approved read stays usable, 15 minutes by default
+	ReadTTLSecs int64 `json:"read_ttl_secs" binding:"omitempty,min=10"`
+}
+
+type quorumResponse struct {
+	Path        string    `json:"path"`
+	Approvals   int32     `json:"approvals"`
+	WindowSecs  int64     `json:"window_secs"`
+	ReadTTLSecs int64     `json:"read_ttl_secs"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type quorumRequestResponse struct {
+	ID                uuid.UUID `json:"id"`
+	Path              string    `json:"path"`
+	Environment       string    `json:"environment"`
+	RequesterEmail    string    `json:"requester_email"`
+	RequiredApprovals int32     `json:"required_approvals"`
+	// Approvals lists who approved so far
+	Approvals    []string   `json:"approvals"`
+	Status       string     `json:"status"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	GrantedUntil *time.Time `json:"granted_until,omitempty"`
+	ConsumedAt   *time.Time `json:"consumed_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+type listQuorumRequestsResponse struct {
+	Requests []quorumRequestResponse `json:"requests"`
+}
+
+func newQuorumResponse(path string, quorum db.SecretQuorums) quorumResponse {
+	return quorumResponse{
+		Path:        path,
+		Approvals:   quorum.RequiredApprovals,
+		WindowSecs:  quorum.WindowSecs,
+		ReadTTLSecs: quorum.ReadTtlSecs,
+		CreatedAt:   quorum.CreatedAt,
+	}
+}
+
+// quorumStatus reports requests whose approval window or granted read ran out as expired
+func quorumStatus(request db.QuorumRequests, now time.Time) string {
+	switch {
+	case request.Status == quorumPending && !now.Before(request.ExpiresAt):
+		return quorumExpired
+	case request.Status == quorumGranted && !now.Before(request.GrantedUntil.Time):
+		return quorumExpired
+	}
+	return request.Status
+}
+
+// quorumRequestResponse describes request on the secret at path, with its approvals
+func (s *Server) quorumRequestResponse(ctx context.Context, request db.QuorumRequests, path string) (quorumRequestResponse, error) {
+	resp := quorumRequestResponse{
+		ID:                request.ID,
+		Path:              path,
+		Environment:       request.Environment,
+		RequesterEmail:    request.RequesterEmail,
+		RequiredApprovals: request.RequiredApprovals,
+		Approvals:         []string{},
+		Status:            quorumStatus(request, time.Now()),
+		ExpiresAt:         request.ExpiresAt,
+		CreatedAt:         request.CreatedAt,
+	}
+	if request.GrantedUntil.Valid {
+		resp.GrantedUntil = &request.GrantedUntil.Time
+	}
+	if request.ConsumedAt.Valid {
+		resp.ConsumedAt = &request.ConsumedAt.Time
+	}
+
+	approvals, err := s.store.ListQuorumApprovals(ctx, request.ID)
+	if err != nil {
+		return resp, err
+	}
+	for _, approval := range approvals {
+		resp.Approvals = append(resp.Approvals, approval.ApproverEmail)
+	}
+	return resp, nil
+}
+
+// underQuorum reports whether reads of the secret need approvals
+func (s *Server) underQuorum(ctx context.Context, secretID uuid.UUID) (bool, error) {
+	_, err := s.store.GetSecretQuorum(ctx, secretID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// quorumRead applies the two-person rule to a read of secret by a caller who may read it. It
+// returns a nil request when the read can go ahead: the secret is not under the rule, or the
+// caller holds an approved read, in which case granted is set and revealSecret must use it up.
+// Otherwise it returns the request the read waits on, opening one and notifying the possible
+// approvers if the caller has none pending.
+func (s *Server) quorumRead(ctx context.Context, authPayload *auth.Payload, secret db.GetLatestSecretByPathRow) (pending *db.QuorumRequests, granted bool, err error) {
+	quorum, err := s.store.GetSecretQuorum(ctx, secret.SecretID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	granted, err = s.store.HasQuorumGrant(ctx, db.HasQuorumGrantParams{
+		RequesterID: authPayload.UserID,
+		SecretID:    secret.SecretID,
+		Environment: secret.Environment,
+	})
+	if err != nil || granted {
+		return nil, granted, err
+	}
+
+	open, err := s.store.GetOpenQuorumRequest(ctx, db.GetOpenQuorumRequestParams{
+		RequesterID: authPayload.UserID,
+		SecretID:    secret.SecretID,
+		Environment: secret.Environment,
+	})
+	if err == nil {
+		return &open, false, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, false, err
+	}
+
+	window := time.Duration(quorum.WindowSecs) * time.Second
+	var request db.QuorumRequests
+	err = s.store.ExecTx(ctx, func(q *db.Queries) error {
+		request, err = q.CreateQuorumRequest(ctx, db.CreateQuorumRequestParams{
+			SecretID:          secret.SecretID,
+			Environment:       secret.Environment,
+			RequesterID:       authPayload.UserID,
+			RequesterEmail:    authPayload.Email,
+			RequiredApprovals: quorum.RequiredApprovals,
+			ReadTtlSecs:       quorum.ReadTtlSecs,
+			ExpiresAt:         time.Now().Add(window),
+		})
+		if err != nil {
+			return err
+		}
+		detail := fmt.Sprintf("quorum request %s needs %d approvals within %s", request.ID, quorum.RequiredApprovals, window)
+		if err := s.auditSvc.LogTx(ctx, q, authPayload.UserID, authPayload.Email, "request_quorum_read", secret.Path, secret.Version, true, &detail); err != nil {
+			return fmt.Errorf("failed to log action: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	approvers, err := s.capabilityHolders(ctx, secret.Path, secret, policy.Read)
+	if err != nil {
+		log.Printf("Error listing approvers for %s: %v\n", secret.Path, err)
+	}
+	approvers = slices.DeleteFunc(approvers, func(email string) bool { return email == authPayload.Email })
+	s.notify(ctx, notify.Message{
+		Event:      "quorum_request.created",
+		Recipients: approvers,
+		Subject:    fmt.Sprintf("%s needs %d approvals to read %s", authPayload.Email, quorum.RequiredApprovals, secret.Path),
+		Body: fmt.Sprintf("%s wants to read %s (%s), which is under the two-person rule. %d other users who can read it must approve before %s.\n\n"+
+			"Approve with POST /quorum-requests/%s/approve.",
+			authPayload.Email, secret.Path, secret.Environment, quorum.RequiredApprovals,
+			request.ExpiresAt.UTC().Format(time.RFC1123), request.ID),
+		Path:      secret.Path,
+		ExpiresAt: &request.ExpiresAt,
+	})
+	return &request, false, nil
+}
+
+// useQuorumGrantTx uses up the caller's approved read of secret. A grant used by a concurrent
+// read in the meantime is a conflict.
+func (s *Server) useQuorumGrantTx(ctx context.Context, q *db.Queries, authPayload *auth.Payload, secret db.GetLatestSecretByPathRow) error {
+	grant, err := q.ConsumeQuorumGrant(ctx, db.ConsumeQuorumGrantParams{
+		RequesterID: authPayload.UserID,
+		SecretID:    secret.SecretID,
+		Environment: secret.Environment,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return newStatusError(http.StatusConflict, "the approved read of %s was used by another request", secret.Path)
+		}
+		return err
+	}
+
+	detail := "quorum request " + grant.ID.String()
+	if err := s.auditSvc.LogTx(ctx, q, authPayload.UserID, authPayload.Email, "use_quorum_grant", secret.Path, secret.Version, true, &detail); err != nil {
+		return fmt.Errorf("failed to log action: %w", err)
+	}
+	return nil
+}
+
+// @Summary      Get the two-person rule of a secret
+// @Description  Returns how many approvals a read of the secret needs, how long they are collected and how long the approved read stays usable.
+// @Tags         Two-Person Rule
+// @Produce      json
+// @Param        path  path     string  true  "Secret path"
+// @Success      200   {object} quorumResponse
+// @Failure      403   {object} swaggerErrorResponse "Access denied"
+// @Failure      404   {object} swaggerErrorResponse "Secret not found or not under the rule"
+// @Failure      500   {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /quorum/{path} [get]
+func (s *Server) getQuorum(ctx *gin.Context) {
+	secret := ctx.MustGet("secret").(db.GetLatestSecretByPathRow)
+
+	quorum, err := s.store.GetSecretQuorum(ctx, secret.SecretID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("the secret is not under the two-person rule")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, newQuorumResponse(secret.Path, quorum))
+}
+
+// @Summary      Put a secret under the two-person rule
+// @Description  Makes every read of the secret, in every environment and by anyone including its owner, wait for approvals by that many other users who can read it. Approvals are collected for window_secs (default 1 hour, at most 7 days); the approved read is single-use and must happen within read_ttl_secs (default 15 minutes, at most 1 day). Needs share on the secret; audited as set_quorum.
+// @Tags         Two-Person Rule
+// @Accept       json
+// @Produce      json
+// @Param        path     path     string            true  "Secret path"
+// @Param        request  body     setQuorumRequest  true  "Rule"
+// @Success      200      {object} quorumResponse
+// @Failure      400      {object} swaggerErrorResponse "Invalid input"
+// @Failure      403      {object} swaggerErrorResponse "Access denied"
+// @Failure      404      {object} swaggerErrorResponse "Secret not found"
+// @Failure      500      {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /quorum/{path} [put]
+func (s *Server) setQuorum(ctx *gin.Context) {
+	var req setQuorumRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	window, readTTL := defaultQuorumWindow, defaultQuorumReadTTL
+	if req.WindowSecs > 0 {
+		window = time.Duration(req.WindowSecs) * time.Second
+	}
+	if req.ReadTTLSecs > 0 {
+		readTTL = time.Duration(req.ReadTTLSecs) * time.Second
+	}
+	if window > maxQuorumWindow || readTTL > maxQuorumReadTTL {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("the window can be at most %s and the read TTL at most %s", maxQuorumWindow, maxQuorumReadTTL)))
+		return
+	}
+
+	secret := ctx.MustGet("secret").(db.GetLatestSecretByPathRow)
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	var quorum db.SecretQuorums
+	err := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		var err error
+		quorum, err = q.UpsertSecretQuorum(ctx, db.UpsertSecretQuorumParams{
+			SecretID:          secret.SecretID,
+			RequiredApprovals: req.Approvals,
+			WindowSecs:        int64(window / time.Second),
+			ReadTtlSecs:       int64(readTTL / time.Second),
+			CreatedBy:         authPayload.UserID,
+		})
+		if err != nil {
+			return err
+		}
+		detail := fmt.Sprintf("%d approvals within %s, read within %s", req.Approvals, window, readTTL)
+		if err := s.auditSvc.LogTx(ctx, q, authPayload.UserID, authPayload.Email, "set_quorum", secret.Path, secret.Version, true, &detail); err != nil {
+			return fmt.Errorf("failed to log action: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, newQuorumResponse(secret.Path, quorum))
+}
+
+// @Summary      Lift the two-person rule from a secret
+// @Description  Lets permitted users read the secret without approvals again. Needs share on the secret; audited as remove_quorum.
+// @Tags         Two-Person Rule
+// @Param        path  path  string  true  "Secret path"
+// @Success      204   "Rule removed"
+// @Failure      403   {object} swaggerErrorResponse "Access denied"
+// @Failure      404   {object} swaggerErrorResponse "Secret not found or not under the rule"
+// @Failure      500   {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /quorum/{path} [delete]
+func (s *Server) deleteQuorum(ctx *gin.Context) {
+	secret := ctx.MustGet("secret").(db.GetLatestSecretByPathRow)
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	err := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		deleted, err := q.DeleteSecretQuorum(ctx, secret.SecretID)
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return newStatusError(http.StatusNotFound, "the secret is not under the two-person rule")
+		}
+		if err := s.auditSvc.LogTx(ctx, q, authPayload.UserID, authPayload.Email, "remove_quorum", secret.Path, secret.Version, true, nil); err != nil {
+			return fmt.Errorf("failed to log action: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// quorumSecret loads the secret a request is for, in the request's environment
+func (s *Server) quorumSecret(ctx context.Context, request db.QuorumRequests) (db.GetLatestSecretByPathRow, error) {
+	secret, err := s.store.GetSecretByID(ctx, request.SecretID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return db.GetLatestSecretByPathRow{}, newStatusError(http.StatusNotFound, "the secret no longer exists")
+		}
+		return db.GetLatestSecretByPathRow{}, err
+	}
+	latest, err := s.loadSecret(ctx, secret.Path, request.Environment)
+	if err == sql.ErrNoRows {
+		return latest, newStatusError(http.StatusNotFound, "the secret no longer exists")
+	}
+	return latest, err
+}
+
+// quorumRequestFor loads the request in the :id parameter and its secret, checking that the
+// caller is the requester or can read the secret
+func (s *Server) quorumRequestFor(ctx context.Context, authPayload *auth.Payload, rawID string) (db.QuorumRequests, db.GetLatestSecretByPathRow, error) {
+	var secret db.GetLatestSecretByPathRow
+	notFound := newStatusError(http.StatusNotFound, "the quorum request does not exist")
+
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		return db.QuorumRequests{}, secret, newStatusError(http.StatusBadRequest, "invalid quorum request id")
+	}
+	request, err := s.store.GetQuorumRequest(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return request, secret, notFound
+		}
+		return request, secret, err
+	}
+	if secret, err = s.quorumSecret(ctx, request); err != nil {
+		return request, secret, err
+	}
+	if request.RequesterID == authPayload.UserID {
+		return request, secret, nil
+	}
+	canRead, err := s.can(ctx, authPayload, secret, policy.Read)
+	if err != nil {
+		return request, secret, err
+	}
+	if !canRead {
+		return request, secret, notFound
+	}
+	return request, secret, nil
+}
+
+// @Summary      List my quorum requests
+// @Description  Lists the caller's requests to read secrets under the two-person rule, newest first.
+// @Tags         Two-Person Rule
+// @Produce      json
+// @Param        limit   query    int  false  "Limit number of results (default 50)"
+// @Param        offset  query    int  false  "Offset for pagination (default 0)"
+// @Success      200     {object} listQuorumRequestsResponse
+// @Failure      500     {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /quorum-requests [get]
+func (s *Server) listQuorumRequests(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 {
+		limit = 50
+	}
+	offset, err := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	requests, err := s.store.ListQuorumRequestsByRequester(ctx, db.ListQuorumRequestsByRequesterParams{
+		RequesterID: authPayload.UserID,
+		Limit:       int32(limit),
+		Offset:      int32(offset),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	resp := listQuorumRequestsResponse{Requests: make([]quorumRequestResponse, 0, len(requests))}
+	for _, request := range requests {
+		path := ""
+		if secret, err := s.store.GetSecretByID(ctx, request.SecretID); err == nil {
+			path = secret.Path
+		}
+		item, err := s.quorumRequestResponse(ctx, request, path)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		resp.Requests = append(resp.Requests, item)
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// @Summary      List quorum requests awaiting me
+// @Description  Lists the open requests of other users to read secrets the caller can read, which the caller can approve, oldest first.
+// @Tags         Two-Person Rule
+// @Produce      json
+// @Success      200  {object} listQuorumRequestsResponse
+// @Failure      500  {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /quorum-requests/pending [get]
+func (s *Server) listPendingQuorumRequests(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	open, err := s.store.ListOpenQuorumRequests(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	resp := listQuorumRequestsResponse{Requests: []quorumRequestResponse{}}
+	for _, request := range open {
+		if request.RequesterID == authPayload.UserID {
+			continue
+		}
+		secret, err := s.quorumSecret(ctx, request)
+		if err != nil {
+			if errorStatus(err) == http.StatusNotFound {
+				continue
+			}
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		canRead, err := s.can(ctx, authPayload, secret, policy.Read)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		if !canRead {
+			continue
+		}
+		item, err := s.quorumRequestResponse(ctx, request, secret.Path)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		if slices.Contains(item.Approvals, authPayload.Email) {
+			continue
+		}
+		resp.Requests = append(resp.Requests, item)
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// @Summary      Get a quorum request
+// @Description  Shows the status and approvals of a request to read a secret under the two-person rule. Visible to the requester and to users who can read the secret.
+// @Tags         Two-Person Rule
+// @Produce      json
+// @Param        id   path     string  true  "Quorum request ID"
+// @Success      200  {object} quorumRequestResponse
+// @Failure      400  {object} swaggerErrorResponse "Invalid ID"
+// @Failure      404  {object} swaggerErrorResponse "Request not found"
+// @Failure      500  {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /quorum-requests/{id} [get]
+func (s *Server) getQuorumRequest(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	request, secret, err := s.quorumRequestFor(ctx, authPayload, ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+	resp, err := s.quorumRequestResponse(ctx, request, secret.Path)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// @Summary      Approve a quorum request
+// @Description  Adds the caller's approval to another user's request to read a secret under the two-person rule. The caller must be able to read the secret. Once the required number of distinct approvals is reached within the window, the requester may read the secret once before the read TTL runs out. Audited as approve_quorum_read; the requester is notified when the read is granted.
+// @Tags         Two-Person Rule
+// @Produce      json
+// @Param        id   path     string  true  "Quorum request ID"
+// @Success      200  {object} quorumRequestResponse
+// @Failure      400  {object} swaggerErrorResponse "Invalid ID"
+// @Failure      403  {object} swaggerErrorResponse "Own request"
+// @Failure      404  {object} swaggerErrorResponse "Request not found"
+// @Failure      409  {object} swaggerErrorResponse "Already approved, granted or expired"
+// @Failure      500  {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /quorum-requests/{id}/approve [post]
+func (s *Server) approveQuorumRequest(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	request, secret, err := s.quorumRequestFor(ctx, authPayload, ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+	if request.RequesterID == authPayload.UserID {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("you cannot approve your own read")))
+		return
+	}
+
+	var approvals int
+	err = s.store.ExecTx(ctx, func(q *db.Queries) error {
+		// the lock orders concurrent approvals, so exactly one of them grants the read
+		request, err = q.GetQuorumRequestForUpdate(ctx, request.ID)
+		if err != nil {
+			return err
+		}
+		if status := quorumStatus(request, time.Now()); status != quorumPending {
+			return newStatusError(http.StatusConflict, "the quorum request is already %s", status)
+		}
+
+		_, err = q.AddQuorumApproval(ctx, db.AddQuorumApprovalParams{
+			RequestID:     request.ID,
+			ApproverID:    authPayload.UserID,
+			ApproverEmail: authPayload.Email,
+		})
+		if err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+				return newStatusError(http.StatusConflict, "you already approved this request")
+			}
+			return err
+		}
+		all, err := q.ListQuorumApprovals(ctx, request.ID)
+		if err != nil {
+			return err
+		}
+		approvals = len(all)
+
+		detail := fmt.Sprintf("quorum request %s by %s: %d of %d approvals", request.ID, request.RequesterEmail, approvals, request.RequiredApprovals)
+		if approvals >= int(request.RequiredApprovals) {
+			if request, err = q.GrantQuorumRequest(ctx, request.ID); err != nil {
+				return err
+			}
+			detail += ", read granted until " + request.GrantedUntil.Time.UTC().Format(time.RFC3339)
+		}
+		if err := s.auditSvc.LogTx(ctx, q, authPayload.UserID, authPayload.Email, "approve_quorum_read", secret.Path, secret.Version, true, &detail); err != nil {
+			return fmt.Errorf("failed to log action: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	if request.Status == quorumGranted {
+		s.notify(ctx, notify.Message{
+			Event:      "quorum_request.granted",
+			Recipients: []string{request.RequesterEmail},
+			Subject:    fmt.Sprintf("Read of %s approved", secret.Path),
+			Body: fmt.Sprintf("Your read of %s (%s) was approved by %d users. Read it once before %s.",
+				secret.Path, request.Environment, approvals, request.GrantedUntil.Time.UTC().Format(time.RFC1123)),
+			Path:      secret.Path,
+			ExpiresAt: &request.GrantedUntil.Time,
+		})
+	}
+
+	resp, err := s.quorumRequestResponse(ctx, request, secret.Path)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuorum(t *testing.T) {
+	owner := newTestUser(t)
+	reader := newTestUser(t)
+	second := newTestUser(t)
+	outsider := newTestUser(t)
+
+	secret := owner.createSecret("prod/root-key", "k3y")
+	for _, u := range []*testUser{reader, second} {
+		require.Equal(t, http.StatusOK, owner.share(shareSecretRequest{Path: secret.Path, TargetEmail: u.email, Permission: "read"}))
+	}
+
+	require.Equal(t, http.StatusForbidden, reader.call(http.MethodPut, "/quorum/"+secret.Path, setQuorumRequest{Approvals: 2}, nil))
+	var quorum quorumResponse
+	require.Equal(t, http.StatusOK, owner.call(http.MethodPut, "/quorum/"+secret.Path, setQuorumRequest{Approvals: 2, ReadTTLSecs: 60}, &quorum))
+	require.Equal(t, int32(2), quorum.Approvals)
+	require.Equal(t, int64(3600), quorum.WindowSecs)
+
+	// the read waits for approvals, and asking again returns the same request
+	var request quorumRequestResponse
+	require.Equal(t, http.StatusAccepted, reader.call(http.MethodGet, "/secrets/"+secret.Path, nil, &request))
+	require.Equal(t, quorumPending, request.Status)
+	require.Equal(t, reader.email, request.RequesterEmail)
+	var again quorumRequestResponse
+	require.Equal(t, http.StatusAccepted, reader.call(http.MethodGet, "/secrets/"+secret.Path, nil, &again))
+	require.Equal(t, request.ID, again.ID)
+
+	var pending listQuorumRequestsResponse
+	require.Equal(t, http.StatusOK, second.call(http.MethodGet, "/quorum-requests/pending", nil, &pending))
+	require.Contains(t, quorumRequestIDs(pending.Requests), request.ID.String())
+
+	approve := "/quorum-requests/" + request.ID.String() + "/approve"
+	require.Equal(t, http.StatusForbidden, reader.call(http.MethodPost, approve, nil, nil))
+	require.Equal(t, http.StatusNotFound, outsider.call(http.MethodPost, approve, nil, nil))
+
+	var approved quorumRequestResponse
+	require.Equal(t, http.StatusOK, owner.call(http.MethodPost, approve, nil, &approved))
+	require.Equal(t, quorumPending, approved.Status)
+	require.Equal(t, []string{owner.email}, approved.Approvals)
+	require.Equal(t, http.StatusConflict, owner.call(http.MethodPost, approve, nil, nil))
+
+	require.Equal(t, http.StatusOK, second.call(http.MethodPost, approve, nil, &approved))
+	require.Equal(t, quorumGranted, approved.Status)
+	require.NotNil(t, approved.GrantedUntil)
+
+	// the approved read works once
+	got, code := reader.getSecret(secret.Path)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "k3y", got.Decrypted)
+	_, code = reader.getSecret(secret.Path)
+	require.Equal(t, http.StatusAccepted, code)
+
+	var mine listQuorumRequestsResponse
+	require.Equal(t, http.StatusOK, reader.call(http.MethodGet, "/quorum-requests", nil, &mine))
+	require.Len(t, mine.Requests, 2)
+	require.Equal(t, quorumConsumed, mine.Requests[1].Status)
+
+	// the owner is bound by the rule too
+	_, code = owner.getSecret(secret.Path)
+	require.Equal(t, http.StatusAccepted, code)
+
+	require.Equal(t, http.StatusNoContent, owner.call(http.MethodDelete, "/quorum/"+secret.Path, nil, nil))
+	require.Equal(t, http.StatusNotFound, owner.call(http.MethodDelete, "/quorum/"+secret.Path, nil, nil))
+	got, code = reader.getSecret(secret.Path)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "k3y", got.Decrypted)
+
+	var logs getAuditLogsResponse
+	require.Equal(t, http.StatusOK, second.call(http.MethodGet, "/audit?action=approve_quorum_read", nil, &logs))
+	require.Len(t, logs.Logs, 1)
+}
+
+func quorumRequestIDs(requests []quorumRequestResponse) []string {
+	ids := make([]string, 0, len(requests))
+	for _, r := range requests {
+		ids = append(ids, r.ID.String())
+	}
+	return ids
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/pixperk/vaultify/internal/auth"
+	db "github.com/pixperk/vaultify/internal/db/sqlc"
+	"github.com/pixperk/vaultify/internal/logger"
+	"github.com/pixperk/vaultify/internal/policy"
+	"github.com/pixperk/vaultify/internal/secretpath"
+	"github.com/pixperk/vaultify/internal/secrets"
+	"go.uber.org/zap"
+)
+
+// maxReferenceDepth bounds how many secrets a chain of references may traverse
+const maxReferenceDepth = 5
+
+var (
+	errReferenceNotFound  = errors.New("referenced secret not found")
+	errReferenceForbidden = errors.New("access denied to referenced secret")
+	errReferenceTampered  = errors.New("invalid HMAC signature on referenced secret")
+	errReferenceQuorum    = errors.New("referenced secret is under the two-person rule")
+)
+
+// resolveSecretReferences expands {{ secret "path" "key" }} references in value, enforcing
+// the caller's read access on every referenced path. References resolve within the same environment.
+func (s *Server) resolveSecretReferences(ctx context.Context, authPayload *auth.Payload, path, env, value string) (string, error) {
+	resolver := secrets.NewReferenceResolver(func(refPath string) (string, error) {
+		return s.readReferencedSecret(ctx, authPayload, path, refPath, env)
+	}, maxReferenceDepth)
+
+	return resolver.Resolve(path, value)
+}
+
+func (s *Server) readReferencedSecret(ctx context.Context, authPayload *auth.Payload, fromPath, refPath, env string) (string, error) {
+	secret, err := s.store.GetLatestSecretByPath(ctx, db.GetLatestSecretByPathParams{
+		Path:        refPath,
+		Environment: env,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("%w: %s", errReferenceNotFound, refPath)
+		}
+		return "", err
+	}
+
+	canRead, err := s.can(ctx, authPayload, secret, policy.Read)
+	if err != nil {
+		return "", err
+	}
+
+	reason := fmt.Sprintf("referenced from %s", fromPath)
+	if !canRead {
+		s.logReferenceAccess(ctx, authPayload, refPath, secret.Version, false, reason)
+		return "", fmt.Errorf("%w: %s", errReferenceForbidden, refPath)
+	}
+
+	// approved reads are single-use and cannot be spent through a reference
+	quorum, err := s.underQuorum(ctx, secret.SecretID)
+	if err != nil {
+		return "", err
+	}
+	if quorum {
+		s.logReferenceAccess(ctx, authPayload, refPath, secret.Version, false, "under the two-person rule")
+		return "", fmt.Errorf("%w: %s", errReferenceQuorum, refPath)
+	}
+
+	hmacKey, err := s.store.GetHMACKeyByID(ctx, secret.HmacKeyID.UUID)
+	if err != nil {
+		return "", err
+	}
+
+	isVerified, err := VerifySecretHMAC(secret, hmacKey.Key)
+	if err != nil {
+		return "", err
+	}
+	if !isVerified {
+		s.logReferenceAccess(ctx, authPayload, refPath, secret.Version, false, "invalid HMAC signature")
+		return "", fmt.Errorf("%w: %s", errReferenceTampered, refPath)
+	}
+
+	decryptedValue, err := s.encryptor.Decrypt(secret.EncryptedValue, secret.Nonce)
+	if err != nil {
+		return "", err
+	}
+
+	s.logReferenceAccess(ctx, authPayload, refPath, secret.Version, true, reason)
+	return string(decryptedValue), nil
+}
+
+func (s *Server) logReferenceAccess(ctx context.Context, authPayload *auth.Payload, path string, version int32, success bool, reason string) {
+	err := s.auditSvc.Log(ctx, authPayload.UserID, authPayload.Email, "resolve_secret_reference", path, version, success, &reason)
+	if err != nil {
+		logger.New(s.config.Env).Error("failed to log secret reference access", zap.Error(err))
+	}
+}
+
+// referenceErrorStatus maps a resolution failure to the HTTP status returned to the caller
+func referenceErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, errReferenceForbidden), errors.Is(err, errReferenceQuorum):
+		return http.StatusForbidden
+	case errors.Is(err, errReferenceNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, errReferenceTampered):
+		return http.StatusUnauthorized
+	case errors.Is(err, secrets.ErrReferenceCycle),
+		errors.Is(err, secrets.ErrReferenceDepth),
+		errors.Is(err, secrets.ErrReferenceKey),
+		errors.Is(err, secrets.ErrReferenceNotAJSON),
+		errors.Is(err, secretpath.ErrInvalidPath):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
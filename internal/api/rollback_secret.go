@@ -29,11 +29,12 @@ type rollbackSecretResponse struct {
 
 // rollbackSecret godoc
 // @Summary      Rollback secret to a previous version
-// @Description  Reverts a secret to a previous version by duplicating the selected version with a new version number. Verifies HMAC before proceeding.
+// @Description  Reverts a secret to a previous version by duplicating the selected version with a new version number. Verifies HMAC before proceeding. Rollbacks stay within the requested environment.
 // @Tags         Secrets
 // @Accept       json
 // @Produce      json
 // @Param        path    path     string                  true  "Secret path"
+// @Param        env     query    string                  false "Environment (defaults to default)"
 // @Param        request body     rollbackSecretRequest   true  "Rollback secret request payload"
 // @Success      200     {object} rollbackSecretResponse
 // @Failure      400     {object} swaggerErrorResponse "Invalid input or bad version"
@@ -53,6 +54,11 @@ func (s *Server) rollbackSecret(ctx *gin.Context) {
 
 	authorizationPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
 
+	if secret.Version == 0 {
+		ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("secret has no versions in %s", secret.Environment)))
+		return
+	}
+
 	//Get the HMAC key from the database associated with the secret
 	secretHmacKey, err := s.store.GetHMACKeyByID(ctx, secret.HmacKeyID.UUID)
 	if err != nil {
@@ -82,8 +88,9 @@ func (s *Server) rollbackSecret(ctx *gin.Context) {
 	}
 
 	rollbackToSecret, err := s.store.GetSecretVersionByPathAndVersion(ctx, db.GetSecretVersionByPathAndVersionParams{
-		Path:    secret.Path,
-		Version: req.Version,
+		Path:        secret.Path,
+		Version:     req.Version,
+		Environment: secret.Environment,
 	},
 	)
 	if err != nil {
@@ -132,12 +139,13 @@ func (s *Server) rollbackSecret(ctx *gin.Context) {
 			UUID:  hmacKey.ID,
 			Valid: true,
 		},
+		Environment: rollbackToSecret.Environment,
 	}
 
 	var mirroredSecret db.SecretVersions
 
-	s.store.ExecTx(ctx, func(q *db.Queries) error {
-		mirroredSecret, err = s.store.CreateNewSecretVersion(ctx, args)
+	err = s.store.ExecTx(ctx, func(q *db.Queries) error {
+		mirroredSecret, err = q.CreateNewSecretVersion(ctx, args)
 		if err != nil {
 			return err
 		}
@@ -146,8 +154,13 @@ func (s *Server) rollbackSecret(ctx *gin.Context) {
 		if err = s.auditSvc.LogTx(ctx, q, authorizationPayload.UserID, authorizationPayload.Email, "rollback_secret", secret.Path, mirroredSecret.Version, true, nil); err != nil {
 			return fmt.Errorf("failed to log action: %w", err)
 		}
-		return nil
+		return s.publishTx(ctx, q, versionChanged(secret, mirroredSecret.Environment, mirroredSecret.Version))
 	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
 	resp := rollbackSecretResponse{
 		Path:            secret.Path,
 		ExistingVersion: secret.Version,
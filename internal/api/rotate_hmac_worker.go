@@ -2,8 +2,11 @@ package api
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"time"
+
+	"github.com/pixperk/vaultify/internal/events"
 )
 
 func (s *Server) StartHMACRotationLoop(ctx context.Context, interval time.Duration, staleDuration time.Duration) {
@@ -14,8 +17,8 @@ func (s *Server) StartHMACRotationLoop(ctx context.Context, interval time.Durati
 		for {
 			select {
 			case <-ticker.C:
-				if err := s.store.RotateHmacKey(ctx, staleDuration); err != nil {
-					log.Printf("HMAC key rotation failed: %v", err)
+				if err := s.rotateHMACKey(ctx, staleDuration); err != nil {
+					log.Println(err)
 				}
 			case <-ctx.Done():
 				log.Println("Shutting down HMAC rotation loop...")
@@ -24,3 +27,25 @@ func (s *Server) StartHMACRotationLoop(ctx context.Context, interval time.Durati
 		}
 	}()
 }
+
+// rotateHMACKey rotates a stale key and tells every instance when a new key became active
+func (s *Server) rotateHMACKey(ctx context.Context, staleDuration time.Duration) error {
+	before, _ := s.store.GetActiveHMACKey(ctx)
+
+	if err := s.store.RotateHmacKey(ctx, staleDuration); err != nil {
+		return fmt.Errorf("HMAC key rotation failed: %w", err)
+	}
+
+	after, err := s.store.GetActiveHMACKey(ctx)
+	if err != nil || after.ID == before.ID {
+		return nil
+	}
+	payload, err := events.Encode(events.Event{Type: events.HMACKeyRotated})
+	if err == nil {
+		err = s.store.NotifyEvent(ctx, payload)
+	}
+	if err != nil {
+		return fmt.Errorf("announcing HMAC key rotation failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,508 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pixperk/vaultify/internal/auth"
+	db "github.com/pixperk/vaultify/internal/db/sqlc"
+	"github.com/pixperk/vaultify/internal/rotation"
+	"github.com/pixperk/vaultify/internal/util"
+)
+
+// rotation generators
+const (
+	generatorPassword = "password"
+	generatorWebhook  = "webhook"
+	generatorExec     = "exec"
+)
+
+// rotation triggers recorded in the audit log
+const (
+	rotationScheduled = "scheduled"
+	rotationManual    = "manual"
+)
+
+const (
+	// maxRotationBackoff caps the retry delay after repeated failures
+	maxRotationBackoff = time.Hour
+	// rotationBatchSize is how many due policies a single tick picks up
+	rotationBatchSize = 50
+	// rotationLeaseMargin is how long a lease outlives the hook timeout, to write the version
+	rotationLeaseMargin = time.Minute
+)
+
+// errRotationSkipped means another rotation holds the policy or it is no longer due
+var errRotationSkipped = errors.New("rotation skipped")
+
+type setRotationPolicyRequest struct {
+	IntervalSeconds int64  `json:"interval_seconds"`
+	Cron            string `json:"cron"`
+	Generator       string `json:"generator" binding:"required,oneof=password webhook exec"`
+	PasswordLength  int32  `json:"password_length"`
+	PasswordSymbols bool   `json:"password_symbols"`
+	HookURL         string `json:"hook_url"`
+	HookName        string `json:"hook_name"`
+}
+
+type rotationPolicyResponse struct {
+	Path            string     `json:"path"`
+	Environment     string     `json:"environment"`
+	IntervalSeconds int64      `json:"interval_seconds,omitempty"`
+	Cron            string     `json:"cron,omitempty"`
+	Generator       string     `json:"generator"`
+	PasswordLength  int32      `json:"password_length,omitempty"`
+	PasswordSymbols bool       `json:"password_symbols,omitempty"`
+	HookTarget      string     `json:"hook_target,omitempty"`
+	NextRotationAt  time.Time  `json:"next_rotation_at"`
+	LastRotatedAt   *time.Time `json:"last_rotated_at,omitempty"`
+	LastError       string     `json:"last_error,omitempty"`
+	FailureCount    int32      `json:"failure_count"`
+}
+
+type rotateSecretResponse struct {
+	Path        string `json:"path"`
+	Environment string `json:"environment"`
+	Version     int32  `json:"version"`
+}
+
+func newRotationPolicyResponse(path string, policy db.RotationPolicies) rotationPolicyResponse {
+	resp := rotationPolicyResponse{
+		Path:            path,
+		Environment:     policy.Environment,
+		IntervalSeconds: policy.IntervalSeconds.Int64,
+		Cron:            policy.CronExpr.String,
+		Generator:       policy.Generator,
+		HookTarget:      policy.HookTarget.String,
+		NextRotationAt:  policy.NextRotationAt,
+		LastError:       policy.LastError.String,
+		FailureCount:    policy.FailureCount,
+	}
+	if policy.Generator == generatorPassword {
+		resp.PasswordLength = policy.PasswordLength
+		resp.PasswordSymbols = policy.PasswordSymbols
+	}
+	if policy.LastRotatedAt.Valid {
+		resp.LastRotatedAt = &policy.LastRotatedAt.Time
+	}
+	return resp
+}
+
+func policySchedule(intervalSeconds sql.NullInt64, cronExpr sql.NullString) (rotation.Schedule, error) {
+	return rotation.ParseSchedule(time.Duration(intervalSeconds.Int64)*time.Second, cronExpr.String)
+}
+
+// rotatedValue produces the next value of the secret according to the policy
+func (s *Server) rotatedValue(ctx context.Context, policy db.LeaseRotationPolicyRow, currentVersion int32) (string, error) {
+	req := rotation.Request{
+		Path:           policy.Path,
+		Environment:    policy.Environment,
+		CurrentVersion: currentVersion,
+	}
+
+	hookCtx, cancel := context.WithTimeout(ctx, s.config.RotationHookTimeout)
+	defer cancel()
+
+	switch policy.Generator {
+	case generatorPassword:
+		return rotation.GeneratePassword(rotation.PasswordPolicy{
+			Length:  int(policy.PasswordLength),
+			Symbols: policy.PasswordSymbols,
+		})
+	case generatorWebhook:
+		return rotation.WebhookHook{URL: policy.HookTarget.String, Client: s.webhooks}.Rotate(hookCtx, req)
+	case generatorExec:
+		return rotation.ExecHook{Dir: s.config.RotationHooksDir, Name: policy.HookTarget.String}.Rotate(hookCtx, req)
+	default:
+		return "", fmt.Errorf("unknown rotation generator %q", policy.Generator)
+	}
+}
+
+// rotateByPolicy creates a new version of the policy's secret. The policy is leased while the value
+// is generated, so no transaction or row lock is held during a slow hook. The version is then written
+// in a short transaction, only if the lease is still held; if the generator or hook fails no version
+// is written and the failure is recorded with a backoff. A nil actor means the policy creator.
+func (s *Server) rotateByPolicy(ctx context.Context, policyID uuid.UUID, trigger string, actor *auth.Payload) (db.SecretVersions, error) {
+	policy, err := s.store.LeaseRotationPolicy(ctx, db.LeaseRotationPolicyParams{
+		ID:           policyID,
+		LeaseSeconds: int32((s.config.RotationHookTimeout + rotationLeaseMargin) / time.Second),
+		DueOnly:      trigger == rotationScheduled,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return db.SecretVersions{}, errRotationSkipped
+		}
+		return db.SecretVersions{}, err
+	}
+
+	rotated, err := s.rotateLeased(ctx, policy, trigger, actor)
+	if err == nil || errors.Is(err, errRotationSkipped) {
+		return rotated, err
+	}
+
+	// no version was written; record the failure and retry with a backoff
+	backoff := time.Minute << min(policy.FailureCount, 6)
+	if backoff > maxRotationBackoff {
+		backoff = maxRotationBackoff
+	}
+	if _, markErr := s.store.MarkRotationFailed(ctx, db.MarkRotationFailedParams{
+		ID:             policy.ID,
+		LeaseID:        policy.LeaseID,
+		LastError:      sql.NullString{String: err.Error(), Valid: true},
+		NextRotationAt: time.Now().Add(backoff),
+	}); markErr != nil {
+		return db.SecretVersions{}, fmt.Errorf("%w (recording the failure also failed: %v)", err, markErr)
+	}
+
+	userID, email := rotationActor(policy, actor)
+	reason := fmt.Sprintf("%s: %v", trigger, err)
+	if logErr := s.auditSvc.Log(ctx, userID, email, "rotate_secret", policy.Path, 0, false, &reason); logErr != nil {
+		return db.SecretVersions{}, fmt.Errorf("%w (audit log failed: %v)", err, logErr)
+	}
+	return db.SecretVersions{}, err
+}
+
+// rotateLeased generates the value outside of any transaction and writes it as a new version
+func (s *Server) rotateLeased(ctx context.Context, policy db.LeaseRotationPolicyRow, trigger string, actor *auth.Payload) (db.SecretVersions, error) {
+	var rotated db.SecretVersions
+
+	schedule, err := policySchedule(policy.IntervalSeconds, policy.CronExpr)
+	if err != nil {
+		return rotated, err
+	}
+
+	var currentVersion int32
+	current, err := s.store.GetLatestSecretByPath(ctx, db.GetLatestSecretByPathParams{
+		Path:        policy.Path,
+		Environment: policy.Environment,
+	})
+	if err == nil {
+		currentVersion = current.Version
+	} else if err != sql.ErrNoRows {
+		return rotated, err
+	}
+
+	value, err := s.rotatedValue(ctx, policy, currentVersion)
+	if err != nil {
+		return rotated, err
+	}
+
+	encryptedValue, nonce, err := s.encryptor.Encrypt([]byte(value))
+	if err != nil {
+		return rotated, err
+	}
+
+	err = s.store.ExecTx(ctx, func(q *db.Queries) error {
+		// the policy row stays locked for the rest of this transaction
+		held, err := q.MarkRotationSucceeded(ctx, db.MarkRotationSucceededParams{
+			ID:             policy.ID,
+			LeaseID:        policy.LeaseID,
+			NextRotationAt: schedule.Next(time.Now()),
+		})
+		if err != nil {
+			return err
+		}
+		if held == 0 {
+			return fmt.Errorf("%w: the policy changed or its lease ran out", errRotationSkipped)
+		}
+
+		hmacKey, err := q.GetActiveHMACKey(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to fetch active HMAC key: %w", err)
+		}
+
+		hmacSig, err := util.GenerateHMACSignature(util.ComputeHMACPayload(encryptedValue, nonce), hmacKey.Key)
+		if err != nil {
+			return fmt.Errorf("failed to generate HMAC signature: %w", err)
+		}
+
+		rotated, err = q.CreateNewSecretVersion(ctx, db.CreateNewSecretVersionParams{
+			Path:           policy.Path,
+			EncryptedValue: encryptedValue,
+			Nonce:          nonce,
+			CreatedBy:      uuid.NullUUID{UUID: policy.CreatedBy, Valid: true},
+			HmacSignature:  hmacSig,
+			HmacKeyID:      uuid.NullUUID{UUID: hmacKey.ID, Valid: true},
+			Environment:    policy.Environment,
+		})
+		if err != nil {
+			return err
+		}
+
+		userID, email := rotationActor(policy, actor)
+		if err = s.auditSvc.LogTx(ctx, q, userID, email, "rotate_secret", policy.Path, rotated.Version, true, &trigger); err != nil {
+			return fmt.Errorf("failed to log action: %w", err)
+		}
+
+		secret, err := q.GetSecretByPath(ctx, policy.Path)
+		if err != nil {
+			return err
+		}
+		return s.publishTx(ctx, q, versionChanged(secretOwnership(secret), rotated.Environment, rotated.Version))
+	})
+	if err != nil {
+		return db.SecretVersions{}, err
+	}
+	return rotated, nil
+}
+
+func rotationActor(policy db.LeaseRotationPolicyRow, actor *auth.Payload) (uuid.UUID, string) {
+	if actor != nil {
+		return actor.UserID, actor.Email
+	}
+	return policy.CreatedBy, policy.OwnerEmail
+}
+
+// StartRotationLoop periodically rotates every secret whose policy is due
+func (s *Server) StartRotationLoop(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.rotateDueSecrets(ctx)
+			case <-ctx.Done():
+				log.Println("Shutting down secret rotation loop...")
+				return
+			}
+		}
+	}()
+}
+
+func (s *Server) rotateDueSecrets(ctx context.Context) {
+	due, err := s.store.ListDueRotationPolicies(ctx, rotationBatchSize)
+	if err != nil {
+		log.Printf("Listing due rotation policies failed: %v", err)
+		return
+	}
+
+	for _, policyID := range due {
+		if _, err := s.rotateByPolicy(ctx, policyID, rotationScheduled, nil); err != nil && !errors.Is(err, errRotationSkipped) {
+			log.Printf("Rotation of policy %s failed: %v", policyID, err)
+		}
+	}
+}
+
+// @Summary      Get the rotation policy of a secret
+// @Description  Returns the rotation schedule, generator and last outcome for the secret in the given environment.
+// @Tags         Rotation
+// @Produce      json
+// @Param        path    path     string  true   "Secret path"
+// @Param        env     query    string  false  "Environment (defaults to default)"
+// @Success      200     {object} rotationPolicyResponse
+// @Failure      403     {object} swaggerErrorResponse "Access denied"
+// @Failure      404     {object} swaggerErrorResponse "Secret or policy not found"
+// @Failure      500     {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /rotation/{path} [get]
+func (s *Server) getRotationPolicy(ctx *gin.Context) {
+	secret := ctx.MustGet("secret").(db.GetLatestSecretByPathRow)
+
+	policy, err := s.store.GetRotationPolicy(ctx, db.GetRotationPolicyParams{
+		SecretID:    secret.SecretID,
+		Environment: ctx.MustGet(environmentKey).(string),
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("no rotation policy for this secret")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newRotationPolicyResponse(secret.Path, policy))
+}
+
+// @Summary      Set the rotation policy of a secret
+// @Description  Rotates the secret on a fixed interval or a cron schedule. The new value is either a generated password or the credential returned by a webhook (POST, JSON response with a value field) or an executable from the server's hooks directory. Only the secret's owner can set a policy.
+// @Tags         Rotation
+// @Accept       json
+// @Produce      json
+// @Param        path    path     string                    true   "Secret path"
+// @Param        env     query    string                    false  "Environment (defaults to default)"
+// @Param        request body     setRotationPolicyRequest  true   "Rotation policy"
+// @Success      200     {object} rotationPolicyResponse
+// @Failure      400     {object} swaggerErrorResponse "Invalid schedule, password policy or hook"
+// @Failure      403     {object} swaggerErrorResponse "Not the owner"
+// @Failure      404     {object} swaggerErrorResponse "Secret not found"
+// @Failure      500     {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /rotation/{path} [put]
+func (s *Server) setRotationPolicy(ctx *gin.Context) {
+	var req setRotationPolicyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	secret := ctx.MustGet("secret").(db.GetLatestSecretByPathRow)
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+	env := ctx.MustGet(environmentKey).(string)
+
+	schedule, err := rotation.ParseSchedule(time.Duration(req.IntervalSeconds)*time.Second, req.Cron)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.UpsertRotationPolicyParams{
+		SecretID:       secret.SecretID,
+		Environment:    env,
+		Generator:      req.Generator,
+		PasswordLength: 32,
+		NextRotationAt: schedule.Next(time.Now()),
+		CreatedBy:      authPayload.UserID,
+	}
+	if req.IntervalSeconds > 0 {
+		arg.IntervalSeconds = sql.NullInt64{Int64: req.IntervalSeconds, Valid: true}
+	} else {
+		arg.CronExpr = sql.NullString{String: req.Cron, Valid: true}
+	}
+
+	switch req.Generator {
+	case generatorPassword:
+		if req.PasswordLength != 0 {
+			arg.PasswordLength = req.PasswordLength
+		}
+		arg.PasswordSymbols = req.PasswordSymbols
+		if err := (rotation.PasswordPolicy{Length: int(arg.PasswordLength)}).Validate(); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+	case generatorWebhook:
+		if err := s.outbound.CheckURL(ctx, req.HookURL); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("hook_url: %w", err)))
+			return
+		}
+		arg.HookTarget = sql.NullString{String: req.HookURL, Valid: true}
+	case generatorExec:
+		if s.config.RotationHooksDir == "" {
+			ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("executable hooks are disabled on this server")))
+			return
+		}
+		if err := rotation.ValidateHookName(req.HookName); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		arg.HookTarget = sql.NullString{String: req.HookName, Valid: true}
+	}
+
+	var policy db.RotationPolicies
+	err = s.store.ExecTx(ctx, func(q *db.Queries) error {
+		policy, err = q.UpsertRotationPolicy(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		detail := fmt.Sprintf("%s via %s", env, req.Generator)
+		if err = s.auditSvc.LogTx(ctx, q, authPayload.UserID, authPayload.Email, "set_rotation_policy", secret.Path, secret.Version, true, &detail); err != nil {
+			return fmt.Errorf("failed to log action: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newRotationPolicyResponse(secret.Path, policy))
+}
+
+// @Summary      Remove the rotation policy of a secret
+// @Description  Stops scheduled rotation of the secret in the given environment. Only the secret's owner can remove it.
+// @Tags         Rotation
+// @Produce      json
+// @Param        path    path     string  true   "Secret path"
+// @Param        env     query    string  false  "Environment (defaults to default)"
+// @Success      204
+// @Failure      403     {object} swaggerErrorResponse "Not the owner"
+// @Failure      404     {object} swaggerErrorResponse "Secret not found"
+// @Failure      500     {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /rotation/{path} [delete]
+func (s *Server) deleteRotationPolicy(ctx *gin.Context) {
+	secret := ctx.MustGet("secret").(db.GetLatestSecretByPathRow)
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+	env := ctx.MustGet(environmentKey).(string)
+
+	err := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		if err := q.DeleteRotationPolicy(ctx, db.DeleteRotationPolicyParams{
+			SecretID:    secret.SecretID,
+			Environment: env,
+		}); err != nil {
+			return err
+		}
+		if err := s.auditSvc.LogTx(ctx, q, authPayload.UserID, authPayload.Email, "delete_rotation_policy", secret.Path, secret.Version, true, &env); err != nil {
+			return fmt.Errorf("failed to log action: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// @Summary      Rotate a secret now
+// @Description  Runs the secret's rotation policy immediately. If the generator or hook fails no version is created and the error is returned.
+// @Tags         Rotation
+// @Produce      json
+// @Param        path    path     string  true   "Secret path"
+// @Param        env     query    string  false  "Environment (defaults to default)"
+// @Success      200     {object} rotateSecretResponse
+// @Failure      403     {object} swaggerErrorResponse "Access denied"
+// @Failure      404     {object} swaggerErrorResponse "Secret or policy not found"
+// @Failure      409     {object} swaggerErrorResponse "Rotation already in progress"
+// @Failure      502     {object} swaggerErrorResponse "Rotation hook failed"
+// @Failure      500     {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /rotation/{path} [post]
+func (s *Server) rotateSecretNow(ctx *gin.Context) {
+	secret := ctx.MustGet("secret").(db.GetLatestSecretByPathRow)
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+	env := ctx.MustGet(environmentKey).(string)
+
+	policy, err := s.store.GetRotationPolicy(ctx, db.GetRotationPolicyParams{
+		SecretID:    secret.SecretID,
+		Environment: env,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("no rotation policy for this secret")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rotated, err := s.rotateByPolicy(ctx, policy.ID, rotationManual, authPayload)
+	if err != nil {
+		switch {
+		case errors.Is(err, errRotationSkipped):
+			ctx.JSON(http.StatusConflict, errorResponse(fmt.Errorf("a rotation of this secret is already in progress")))
+		case errors.Is(err, rotation.ErrHookFailed):
+			ctx.JSON(http.StatusBadGateway, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rotateSecretResponse{
+		Path:        secret.Path,
+		Environment: rotated.Environment,
+		Version:     rotated.Version,
+	})
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"net/http"
@@ -12,23 +13,27 @@ import (
 	"github.com/lib/pq"
 	"github.com/pixperk/vaultify/internal/auth"
 	db "github.com/pixperk/vaultify/internal/db/sqlc"
+	"github.com/pixperk/vaultify/internal/events"
+	"github.com/pixperk/vaultify/internal/secretpath"
 	"github.com/pixperk/vaultify/internal/util"
 )
 
 type createSecretRequest struct {
-	Path       string `json:"path" binding:"required"`
-	Value      string `json:"value" binding:"required"`
-	TTLSeconds int64  `json:"ttl_seconds"`
+	Path        string `json:"path" binding:"required"`
+	Value       string `json:"value" binding:"required"`
+	TTLSeconds  int64  `json:"ttl_seconds"`
+	Environment string `json:"environment"`
 }
 
 type secretResponse struct {
-	Path      string `json:"path"`
-	Encrypted []byte `json:"encrypted_value"`
-	Nonce     []byte `json:"nonce"`
+	Path        string `json:"path"`
+	Environment string `json:"environment"`
+	Encrypted   []byte `json:"encrypted_value"`
+	Nonce       []byte `json:"nonce"`
 }
 
 // @Summary      Create a new secret
-// @Description  Encrypts and stores a secret with optional TTL, linked to the authenticated user. The encrypted secret is signed with an HMAC signature to ensure integrity and prevent tampering.
+// @Description  Encrypts and stores a secret with optional TTL, linked to the authenticated user, or to a team when the path is org/<org>/<team>/<name>. The first value is stored in the given environment (default if omitted). The encrypted secret is signed with an HMAC signature to ensure integrity and prevent tampering.
 // @Tags         Secrets
 // @Accept       json
 // @Produce      json
@@ -52,11 +57,38 @@ func (s *Server) createSecret(ctx *gin.Context) {
 		ctx.JSON(http.StatusUnauthorized, errorResponse(fmt.Errorf("unauthorized")))
 		return
 	}
+
+	secret, path, err := s.storeNewSecret(ctx, authPayload, req)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+	resp := secretResponse{
+		Path:        path,
+		Environment: secret.Environment,
+		Encrypted:   secret.EncryptedValue,
+		Nonce:       secret.Nonce,
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+
+}
+
+// storeNewSecret encrypts, signs and stores the first version of a secret and returns it with
+// the secret's full path
+func (s *Server) storeNewSecret(ctx context.Context, authPayload *auth.Payload, req createSecretRequest) (db.SecretVersions, string, error) {
+	var secret db.SecretVersions
+
+	if req.Environment == "" {
+		req.Environment = defaultEnvironment
+	}
+	if !s.isKnownEnvironment(req.Environment) {
+		return secret, "", newStatusError(http.StatusBadRequest, "unknown environment %q", req.Environment)
+	}
 	// Encrypt the secret value
 	encryptedValue, nonce, err := s.encryptor.Encrypt([]byte(req.Value))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(fmt.Errorf("failed to encrypt secret")))
-		return
+		return secret, "", fmt.Errorf("failed to encrypt secret")
 	}
 
 	var expiresAt sql.NullTime
@@ -67,26 +99,35 @@ func (s *Server) createSecret(ctx *gin.Context) {
 		}
 	}
 
-	//make an array of path string words separated by space
-	pathWords := strings.Fields(req.Path)
-	var path string
-	if len(pathWords) < 2 {
-		path = fmt.Sprintf("%s/%s", authPayload.Email, req.Path)
+	path, err := secretpath.Normalize(req.Path)
+	if err != nil {
+		return secret, "", newStatusError(http.StatusBadRequest, "%s", err)
+	}
+
+	var teamID uuid.NullUUID
+	if strings.HasPrefix(path, orgNamespacePrefix) {
+		// team secrets are owned by the team and are not prefixed with the creator's email
+		if teamID, err = s.teamForWrite(ctx, authPayload, path); err != nil {
+			return secret, "", err
+		}
 	} else {
-		//join the path words with a -
-		path = fmt.Sprintf("%s/%s", authPayload.Email, strings.Join(pathWords, "-"))
+		path, err = secretpath.Join(authPayload.Email, path)
+		if err != nil {
+			return secret, "", newStatusError(http.StatusBadRequest, "%s", err)
+		}
+		if err = s.checkPersonalCreate(ctx, authPayload, path); err != nil {
+			return secret, "", err
+		}
 	}
 	hmacKey, err := s.store.GetActiveHMACKey(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(fmt.Errorf("failed to fetch active HMAC key")))
-		return
+		return secret, "", fmt.Errorf("failed to fetch active HMAC key")
 	}
 
 	hmacPayload := util.ComputeHMACPayload(encryptedValue, nonce)
 	hmacSig, err := util.GenerateHMACSignature(hmacPayload, hmacKey.Key)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(fmt.Errorf("failed to generate HMAC signature")))
-		return
+		return secret, "", fmt.Errorf("failed to generate HMAC signature")
 	}
 
 	arg := db.CreateSecretWithVersionParams{
@@ -103,10 +144,11 @@ func (s *Server) createSecret(ctx *gin.Context) {
 			UUID:  hmacKey.ID,
 			Valid: true,
 		},
+		TeamID:      teamID,
+		Environment: req.Environment,
 	}
-	var secret db.SecretVersions
 
-	s.store.ExecTx(ctx, func(q *db.Queries) error {
+	err = s.store.ExecTx(ctx, func(q *db.Queries) error {
 		secret, err = q.CreateSecretWithVersion(ctx, arg)
 		if err != nil {
 			return err
@@ -116,26 +158,24 @@ func (s *Server) createSecret(ctx *gin.Context) {
 		if err = s.auditSvc.LogTx(ctx, q, authPayload.UserID, authPayload.Email, "create_secret", path, 1, true, nil); err != nil {
 			return fmt.Errorf("failed to log action: %w", err)
 		}
-		return nil
+		return s.publishTx(ctx, q, events.Event{
+			Type:        events.VersionChanged,
+			Path:        path,
+			Environment: secret.Environment,
+			Version:     secret.Version,
+			OwnerID:     authPayload.UserID,
+			TeamID:      teamID,
+		})
 	})
 
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok {
 			switch pqErr.Code.Name() {
 			case "unique_violation":
-				ctx.JSON(http.StatusForbidden, errorResponse(err))
-				return
+				return secret, "", newStatusError(http.StatusForbidden, "%s", err)
 			}
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
-	}
-	resp := secretResponse{
-		Path:      arg.Path,
-		Encrypted: secret.EncryptedValue,
-		Nonce:     secret.Nonce,
+		return secret, "", err
 	}
-
-	ctx.JSON(http.StatusOK, resp)
-
+	return secret, path, nil
 }
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretLifecycle(t *testing.T) {
+	u := newTestUser(t)
+
+	created := u.createSecret("db/password", "v1")
+	require.Equal(t, u.email+"/db/password", created.Path)
+
+	secret, code := u.getSecret(created.Path)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "v1", secret.Decrypted)
+	require.EqualValues(t, 1, secret.Version)
+
+	var updated updateSecretResponse
+	require.Equal(t, http.StatusOK, u.call(http.MethodPut, "/secrets/"+created.Path, updateSecretRequest{Value: "v2"}, &updated))
+	require.EqualValues(t, 2, updated.Version)
+
+	var rollback rollbackSecretResponse
+	require.Equal(t, http.StatusOK, u.call(http.MethodPost, "/secrets/rollback/"+created.Path, rollbackSecretRequest{Version: 1}, &rollback))
+	require.EqualValues(t, 3, rollback.NewVersion)
+
+	secret, code = u.getSecret(created.Path)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "v1", secret.Decrypted)
+	require.EqualValues(t, 3, secret.Version)
+
+	secret, code = u.getSecret(created.Path + "?version=2")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "v2", secret.Decrypted)
+
+	var history secretHistoryResponse
+	require.Equal(t, http.StatusOK, u.call(http.MethodGet, "/history/"+created.Path, nil, &history))
+	require.Len(t, history.Versions, 3)
+	require.EqualValues(t, 3, history.Versions[0].Version)
+
+	var listed listSecretsResponse
+	require.Equal(t, http.StatusOK, u.call(http.MethodGet, "/list", nil, &listed))
+	require.Len(t, listed.Secrets, 1)
+	require.Equal(t, created.Path, listed.Secrets[0].Path)
+	require.EqualValues(t, 3, listed.Secrets[0].Version)
+
+	var logs getAuditLogsResponse
+	require.Equal(t, http.StatusOK, u.call(http.MethodGet, "/audit?action=create_secret&success=true", nil, &logs))
+	require.Len(t, logs.Logs, 1)
+	require.Equal(t, created.Path, logs.Logs[0].ResourcePath)
+}
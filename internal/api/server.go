@@ -2,19 +2,30 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
 	"github.com/pixperk/vaultify/internal/audit"
 	"github.com/pixperk/vaultify/internal/auth"
 	"github.com/pixperk/vaultify/internal/config"
 	db "github.com/pixperk/vaultify/internal/db/sqlc"
+	"github.com/pixperk/vaultify/internal/events"
+	"github.com/pixperk/vaultify/internal/notify"
+	"github.com/pixperk/vaultify/internal/policy"
 	"github.com/pixperk/vaultify/internal/secrets"
 	"github.com/pixperk/vaultify/internal/util"
+	"github.com/pixperk/vaultify/internal/webhook"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"google.golang.org/grpc"
 )
 
 type Server struct {
@@ -24,6 +35,15 @@ type Server struct {
 	encryptor  *secrets.Encryptor
 	router     *gin.Engine
 	auditSvc   audit.Service
+	notifier   notify.Notifier
+	bus        *events.Bus
+	cache      *secretCache
+	policies   *policy.Set
+	// shared by the REST routes and the gRPC interceptors, so both count against one bucket
+	rateLimiter *util.RateLimiter
+	// outbound requests to user-supplied URLs go through the guard
+	outbound *webhook.Guard
+	webhooks *http.Client
 }
 
 func NewServer(config *config.Config, store db.Store, auditSvc audit.Service) (*Server, error) {
@@ -38,20 +58,86 @@ func NewServer(config *config.Config, store db.Store, auditSvc audit.Service) (*
 		return nil, fmt.Errorf("cannot create encryptor : %w", err)
 	}
 
+	policies, err := loadPolicies(config.PolicyDir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load policies : %w", err)
+	}
+
+	outbound, err := webhook.NewGuard(config.WebhookAllowedNetworks)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse WEBHOOK_ALLOWED_NETWORKS : %w", err)
+	}
+
 	server := &Server{
-		config:     config,
-		store:      store,
-		tokenMaker: tokenMaker,
-		encryptor:  encryptor,
-		auditSvc:   auditSvc,
+		config:      config,
+		store:       store,
+		tokenMaker:  tokenMaker,
+		encryptor:   encryptor,
+		auditSvc:    auditSvc,
+		notifier:    newNotifier(config),
+		bus:         events.NewBus(),
+		cache:       newSecretCache(config.CacheSize, config.CacheTTL),
+		policies:    policies,
+		rateLimiter: util.NewRateLimiter(config.RedisAddr, config.RateLimitTokens, config.RateLimitRefill),
+		outbound:    outbound,
+		webhooks:    outbound.Client(),
 	}
 
+	server.auditSvc.AddHook(server.flagBreakGlassAudit)
+	server.auditSvc.AddHook(server.enqueueWebhookDeliveries)
+
 	r := server.setupRouter()
 	server.router = r
 
 	return server, nil
 }
 
+// loadPolicies reads the policy documents in dir. Without a directory only the built-in
+// grants apply.
+func loadPolicies(dir string) (*policy.Set, error) {
+	if dir == "" {
+		return policy.NewSet()
+	}
+	policies, err := policy.LoadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	return policy.NewSet(policies...)
+}
+
+// newNotifier combines the notifiers enabled in the config
+func newNotifier(config *config.Config) notify.Notifier {
+	var notifiers notify.Multi
+	if config.NotifySMTPAddr != "" {
+		notifiers = append(notifiers, notify.SMTPNotifier{
+			Addr:     config.NotifySMTPAddr,
+			From:     config.NotifySMTPFrom,
+			Username: config.NotifySMTPUsername,
+			Password: config.NotifySMTPPassword,
+		})
+	}
+	if config.NotifyWebhookURL != "" {
+		notifiers = append(notifiers, notify.WebhookNotifier{
+			URL:    config.NotifyWebhookURL,
+			Client: &http.Client{Timeout: 10 * time.Second},
+		})
+	}
+	if len(notifiers) == 0 {
+		return notify.Discard{}
+	}
+	return notifiers
+}
+
+// notify sends msg, logging instead of failing the request when it cannot be delivered
+func (s *Server) notify(ctx context.Context, msg notify.Message) {
+	if len(msg.Recipients) == 0 {
+		return
+	}
+	if err := s.notifier.Notify(ctx, msg); err != nil {
+		log.Printf("Error sending %s notification for %s: %v\n", msg.Event, msg.Path, err)
+	}
+}
+
 func (s *Server) setupRouter() *gin.Engine {
 	r := gin.New()
 
@@ -65,27 +151,210 @@ func (s *Server) setupRouter() *gin.Engine {
 	api.POST("/sign-up", s.createUser)
 	api.POST("/login", s.loginUser)
 
-	rl := util.NewRateLimiter(s.config.RedisAddr, s.config.RateLimitTokens, s.config.RateLimitRefill)
+	rl := s.rateLimiter
 
 	api.GET("/audit", authMiddleware(s.tokenMaker), rl.Middleware(), s.getAuditLogs)
 
 	authRoutes := api.Group("/secrets").Use(authMiddleware(s.tokenMaker)).Use(rl.Middleware())
 
 	authRoutes.POST("/", s.createSecret)
-	authRoutes.GET("/*path", s.RequireReadAccess(), s.getSecret)
-	authRoutes.PUT("/*path", s.RequireWriteAccess(), s.updateSecret)
-	authRoutes.POST("/rollback/*path", s.RequireWriteAccess(), s.rollbackSecret)
+	authRoutes.GET("/*path", s.WaitForVersion(), s.RequireCapability(policy.Read), s.getSecret)
+	authRoutes.PUT("/*path", s.RequireCapability(policy.Update), s.updateSecret)
+	authRoutes.POST("/rollback/*path", s.RequireCapability(policy.Rollback), s.rollbackSecret)
 	authRoutes.POST("/share", s.shareSecret)
+	authRoutes.POST("/promote/*path", s.RequireCapability(policy.Update), s.promoteSecret)
+	authRoutes.POST("/move", s.moveSecret)
+	authRoutes.POST("/move-prefix", s.moveSecretPrefix)
+	authRoutes.POST("/copy", s.copySecret)
+
+	shareRoutes := api.Group("/shares").Use(authMiddleware(s.tokenMaker)).Use(rl.Middleware())
+
+	shareRoutes.PATCH("/*path", s.updateShare)
+	shareRoutes.DELETE("/*path", s.deleteShare)
+
+	rotationRoutes := api.Group("/rotation").Use(authMiddleware(s.tokenMaker)).Use(rl.Middleware())
+
+	rotationRoutes.GET("/*path", s.RequireCapability(policy.Read), s.getRotationPolicy)
+	rotationRoutes.PUT("/*path", s.RequireCapability(policy.Share), s.setRotationPolicy)
+	rotationRoutes.DELETE("/*path", s.RequireCapability(policy.Share), s.deleteRotationPolicy)
+	rotationRoutes.POST("/*path", s.RequireCapability(policy.Update), s.rotateSecretNow)
+
+	quorumRoutes := api.Group("/quorum").Use(authMiddleware(s.tokenMaker)).Use(rl.Middleware())
+
+	quorumRoutes.GET("/*path", s.RequireCapability(policy.Read), s.getQuorum)
+	quorumRoutes.PUT("/*path", s.RequireCapability(policy.Share), s.setQuorum)
+	quorumRoutes.DELETE("/*path", s.RequireCapability(policy.Share), s.deleteQuorum)
+
+	quorumRequestRoutes := api.Group("/quorum-requests").Use(authMiddleware(s.tokenMaker)).Use(rl.Middleware())
+
+	quorumRequestRoutes.GET("", s.listQuorumRequests)
+	quorumRequestRoutes.GET("/pending", s.listPendingQuorumRequests)
+	quorumRequestRoutes.GET("/:id", s.getQuorumRequest)
+	quorumRequestRoutes.POST("/:id/approve", s.approveQuorumRequest)
+
+	api.GET("/list", authMiddleware(s.tokenMaker), rl.Middleware(), s.listSecrets)
+	api.GET("/history/*path", authMiddleware(s.tokenMaker), rl.Middleware(), s.RequireCapability(policy.History), s.getSecretHistory)
+	api.GET("/watch", authMiddleware(s.tokenMaker), rl.Middleware(), s.watchSecrets)
+	api.GET("/expiring", authMiddleware(s.tokenMaker), rl.Middleware(), s.listExpiring)
+	api.GET("/shared-with-me", authMiddleware(s.tokenMaker), rl.Middleware(), s.listSharedWithMe)
+	api.GET("/access/*path", authMiddleware(s.tokenMaker), rl.Middleware(), s.getSecretAccess)
+	api.GET("/capabilities/*path", authMiddleware(s.tokenMaker), rl.Middleware(), s.getCapabilities)
+
+	envRoutes := api.Group("/environments").Use(authMiddleware(s.tokenMaker)).Use(rl.Middleware())
+
+	envRoutes.GET("", s.listEnvironments)
+	envRoutes.GET("/drift", s.getDriftReport)
+
+	webhookRoutes := api.Group("/webhooks").Use(authMiddleware(s.tokenMaker)).Use(rl.Middleware())
+
+	webhookRoutes.POST("", s.createWebhook)
+	webhookRoutes.GET("", s.listWebhooks)
+	webhookRoutes.DELETE("/:id", s.deleteWebhook)
+	webhookRoutes.GET("/:id/deliveries", s.listWebhookDeliveries)
+	webhookRoutes.POST("/:id/deliveries/:delivery/replay", s.replayWebhookDelivery)
+
+	groupRoutes := api.Group("/groups").Use(authMiddleware(s.tokenMaker)).Use(rl.Middleware())
+
+	groupRoutes.POST("", s.createGroup)
+	groupRoutes.GET("", s.listGroups)
+	groupRoutes.GET("/:group/members", s.listGroupMembers)
+	groupRoutes.POST("/:group/members", s.addGroupMember)
+	groupRoutes.DELETE("/:group/members/:email", s.removeGroupMember)
+
+	accessRoutes := api.Group("/access-requests").Use(authMiddleware(s.tokenMaker)).Use(rl.Middleware())
+
+	accessRoutes.POST("", s.createAccessRequest)
+	accessRoutes.GET("", s.listAccessRequests)
+	accessRoutes.GET("/pending", s.listPendingAccessRequests)
+	accessRoutes.POST("/:id/approve", s.approveAccessRequest)
+	accessRoutes.POST("/:id/deny", s.denyAccessRequest)
+	accessRoutes.DELETE("/:id", s.cancelAccessRequest)
+
+	breakGlassRoutes := api.Group("/break-glass").Use(authMiddleware(s.tokenMaker)).Use(rl.Middleware())
+
+	breakGlassRoutes.POST("", s.breakGlass)
+	breakGlassRoutes.GET("/sessions", s.listMyBreakGlassSessions)
+	breakGlassRoutes.GET("/rules", s.listBreakGlassRules)
+	breakGlassRoutes.PUT("/rules/*path", s.setBreakGlassRule)
+	breakGlassRoutes.DELETE("/rules/*path", s.deleteBreakGlassRule)
+
+	sysRoutes := api.Group("/sys").Use(authMiddleware(s.tokenMaker)).Use(rl.Middleware())
+	{
+		admin := s.requireSystemRole(systemRoleAdmin)
+		sysRoutes.GET("/users", admin, s.listUsers)
+		sysRoutes.PUT("/users/:email/role", admin, s.setUserRole)
+		sysRoutes.POST("/hmac/rotate", admin, s.rotateHMACKeyNow)
+		sysRoutes.GET("/status", admin, s.getSystemStatus)
+		security := s.requireSystemRole(systemRoleAdmin, systemRoleAuditor)
+		sysRoutes.GET("/audit", security, s.searchAuditLogs)
+		sysRoutes.GET("/cache/stats", security, s.getCacheStats)
+		sysRoutes.GET("/break-glass", security, s.listBreakGlassSessions)
+		sysRoutes.GET("/break-glass/:id", security, s.getBreakGlassSession)
+		sysRoutes.POST("/break-glass/:id/review", security, s.reviewBreakGlassSession)
+	}
+
+	orgRoutes := api.Group("/orgs").Use(authMiddleware(s.tokenMaker)).Use(rl.Middleware())
+
+	orgRoutes.POST("", s.createOrganization)
+	orgRoutes.GET("", s.listOrganizations)
+	orgRoutes.GET("/:org/members", s.listOrgMembers)
+	orgRoutes.POST("/:org/members", s.addOrgMember)
+	orgRoutes.DELETE("/:org/members/:email", s.removeOrgMember)
+	orgRoutes.POST("/:org/teams", s.createTeam)
+	orgRoutes.GET("/:org/teams", s.listTeams)
+	orgRoutes.GET("/:org/teams/:team/members", s.listTeamMembers)
+	orgRoutes.POST("/:org/teams/:team/members", s.addTeamMember)
+	orgRoutes.DELETE("/:org/teams/:team/members/:email", s.removeTeamMember)
 
 	return r
 }
 
+// Handler returns the HTTP handler serving the REST API, without starting the background workers
+func (s *Server) Handler() http.Handler {
+	return s.router
+}
+
+// shutdownTimeout bounds how long Start waits for in-flight requests and streams on SIGINT or SIGTERM
+const shutdownTimeout = 10 * time.Second
+
+// Start serves the REST API, and the gRPC API when GRPC_PORT is set, until the process receives
+// SIGINT or SIGTERM. Both servers then stop accepting requests and drain the ones in flight.
 func (s *Server) Start(address string) error {
+	s.invalidateCache(context.Background())
+	if err := s.bootstrapAdmin(context.Background()); err != nil {
+		log.Printf("Bootstrapping the first admin failed: %v", err)
+	}
+	go func() {
+		if err := events.Listen(context.Background(), s.config.DBSource, s.bus); err != nil {
+			log.Printf("Event listener stopped: %v", err)
+		}
+	}()
 	s.StartHMACRotationLoop(context.Background(), 1*time.Hour, 24*time.Hour)
+	s.StartRotationLoop(context.Background(), s.config.RotationCheckInterval)
+	s.StartWebhookLoop(context.Background(), s.config.WebhookDeliveryInterval)
 	s.cleanExpiredSecrets(s.config.ExpirationCheckInterval)
-	return http.ListenAndServe(address, s.router)
+
+	var grpcServer *grpc.Server
+	if s.config.GRPCPort != "" {
+		var err error
+		if grpcServer, err = s.startGRPC(":" + s.config.GRPCPort); err != nil {
+			return fmt.Errorf("cannot start gRPC server : %w", err)
+		}
+	}
+
+	httpServer := &http.Server{Addr: address, Handler: s.router}
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		<-ctx.Done()
+
+		log.Println("Shutting down")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if grpcServer != nil {
+			stopGRPC(shutdownCtx, grpcServer)
+		}
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			log.Printf("HTTP server shutdown: %v", err)
+		}
+	}()
+
+	if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	<-stopped
+	return nil
 }
 
 func errorResponse(err error) gin.H {
 	return gin.H{"error": err.Error()}
 }
+
+// statusError carries the HTTP status for errors raised below the handler, e.g. inside a transaction
+type statusError struct {
+	status int
+	msg    string
+}
+
+func (e *statusError) Error() string {
+	return e.msg
+}
+
+func newStatusError(status int, format string, args ...any) error {
+	return &statusError{status: status, msg: fmt.Sprintf(format, args...)}
+}
+
+// errorStatus maps err to the HTTP status returned to the caller
+func errorStatus(err error) int {
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.status
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
@@ -1,19 +1,32 @@
 package api
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/pixperk/vaultify/internal/auth"
 	db "github.com/pixperk/vaultify/internal/db/sqlc"
+	"github.com/pixperk/vaultify/internal/events"
+	"github.com/pixperk/vaultify/internal/policy"
+	"github.com/pixperk/vaultify/internal/secretpath"
 )
 
+// folderShareSuffix marks a share of every secret below a folder, e.g. org/acme/payments/*
+const folderShareSuffix = "/*"
+
 type shareSecretRequest struct {
-	Path         string `json:"path" binding:"required"`
-	TargetEmail  string `json:"target_email" binding:"email,required"`
+	Path string `json:"path" binding:"required"`
+	// the share is for either a user or a group
+	TargetEmail  string `json:"target_email" binding:"omitempty,email"`
+	TargetGroup  string `json:"target_group"`
 	Permission   string `json:"permission" binding:"required,oneof=read write"`
 	ShareTTLSecs int    `json:"share_ttl_secs"`
 }
@@ -23,11 +36,24 @@ type shareSecretResponse struct {
 	Path        string `json:"path"`
 	Permission  string `json:"permission"`
 	OwnerEmail  string `json:"owner_email"`
-	TargetEmail string `json:"target_email"`
+	TargetEmail string `json:"target_email,omitempty"`
+	TargetGroup string `json:"target_group,omitempty"`
+}
+
+// sharePrincipal returns the target_email of the rule for a share with a user or a group,
+// exactly one of which must be given
+func sharePrincipal(email, group string) (string, error) {
+	if (email == "") == (group == "") {
+		return "", newStatusError(http.StatusBadRequest, "give either target_email or target_group")
+	}
+	if group != "" {
+		return groupPrincipal(group), nil
+	}
+	return email, nil
 }
 
-// @Summary      Share a secret with another user
-// @Description  Allows a user to share their secret with another user, specifying access permission and optional TTL. Verifies ownership before proceeding.
+// @Summary      Share a secret with another user or a group
+// @Description  Allows a user to share their secret with another user (target_email) or a group (target_group), specifying access permission and optional TTL. Verifies ownership before proceeding. A path ending in /* shares every secret below that folder, including secrets created later; it must be in the caller's own namespace or a team they maintain. The most specific rule wins, so a share of one secret overrides the share of its folder.
 // @Tags         Secrets
 // @Accept       json
 // @Produce      json
@@ -35,9 +61,9 @@ type shareSecretResponse struct {
 // @Success      200     {object} shareSecretResponse
 // @Failure      400     {object} swaggerErrorResponse "Invalid input or sharing with self"
 // @Failure      401     {object} swaggerErrorResponse "Unauthorized: missing or invalid bearer token"
-// @Failure      403     {object} swaggerErrorResponse "Forbidden: not the secret owner"
+// @Failure      403     {object} swaggerErrorResponse "Forbidden: not the secret or folder owner"
 // @Failure      404     {object} swaggerErrorResponse "Secret or target user not found"
-// @Failure      409     {object} swaggerErrorResponse "Secret already shared with target user"
+// @Failure      409     {object} swaggerErrorResponse "Secret or folder already shared with target user"
 // @Failure      500     {object} swaggerErrorResponse "Internal server error during sharing"
 // @Security     BearerAuth
 // @Router       /secrets/share [post]
@@ -47,48 +73,66 @@ func (s *Server) shareSecret(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
+
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
-	ownerEmail := authPayload.Email // Check if the secret exists
-	secret, err := s.store.GetLatestSecretByPath(ctx, req.Path)
+	sharedSecret, err := s.grantShare(ctx, authPayload, req)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("the secret does not exist")))
-			return
-		}
-	}
-
-	// Check if the user is the owner of the secret
-	if secret.UserID != authPayload.UserID {
-		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("you do not have permission to share this secret")))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
-	if ownerEmail == req.TargetEmail {
-		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("you cannot share a secret with yourself")))
-		return
+
+	resp := shareSecretResponse{
+		Success:    true,
+		Path:       sharedPath(sharedSecret.Path, sharedSecret.IsPrefix),
+		Permission: sharedSecret.Permission,
+		OwnerEmail: sharedSecret.OwnerEmail,
 	}
+	resp.TargetEmail, resp.TargetGroup = splitPrincipal(sharedSecret.TargetEmail)
+
+	ctx.JSON(http.StatusOK, resp)
 
-	// Check if the target user exists
-	_, err = s.store.GetUserByEmail(ctx, req.TargetEmail)
+}
+
+// grantShare shares a secret or folder the caller owns with the target user or group
+func (s *Server) grantShare(ctx context.Context, authPayload *auth.Payload, req shareSecretRequest) (db.SharingRules, error) {
+	var sharedSecret db.SharingRules
+
+	target, err := sharePrincipal(req.TargetEmail, req.TargetGroup)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("the target user does not exist")))
-			return
-		}
+		return sharedSecret, err
 	}
-	// Check if the target user is already shared
-	isAlreadyShared, err := s.store.CheckIfShared(ctx, db.CheckIfSharedParams{
-		Path:        req.Path,
-		TargetEmail: req.TargetEmail,
-	})
+	scope, err := s.scopeForSharing(ctx, authPayload, req.Path)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(fmt.Errorf("failed to check if the secret is already shared")))
-		return
-	}
-	if isAlreadyShared {
-		ctx.JSON(http.StatusConflict, errorResponse(fmt.Errorf("the secret is already shared with the target user")))
-		return
+		return sharedSecret, err
 	}
+	req.Path = scope.Path
 
+	ownerEmail := authPayload.Email
+	var targetGroupID uuid.NullUUID
+	if req.TargetGroup != "" {
+		// Check if the target group exists
+		group, err := s.store.GetGroupBySlug(ctx, req.TargetGroup)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return sharedSecret, newStatusError(http.StatusNotFound, "the target group does not exist")
+			}
+			return sharedSecret, err
+		}
+		targetGroupID = uuid.NullUUID{UUID: group.ID, Valid: true}
+	} else {
+		if ownerEmail == req.TargetEmail {
+			return sharedSecret, newStatusError(http.StatusBadRequest, "you cannot share a secret with yourself")
+		}
+
+		// Check if the target user exists
+		_, err = s.store.GetUserByEmail(ctx, req.TargetEmail)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return sharedSecret, newStatusError(http.StatusNotFound, "the target user does not exist")
+			}
+			return sharedSecret, err
+		}
+	}
 	var sharedUntil sql.NullTime
 	if req.ShareTTLSecs > 0 {
 		sharedUntil = sql.NullTime{
@@ -98,39 +142,392 @@ func (s *Server) shareSecret(ctx *gin.Context) {
 	}
 
 	args := db.ShareSecretParams{
-		OwnerEmail:  ownerEmail,
-		TargetEmail: req.TargetEmail,
-		Path:        req.Path,
-		Permission:  req.Permission,
-		SharedUntil: sharedUntil,
+		OwnerEmail:    ownerEmail,
+		TargetEmail:   target,
+		Path:          req.Path,
+		Permission:    req.Permission,
+		SharedUntil:   sharedUntil,
+		IsPrefix:      scope.Prefix,
+		TargetGroupID: targetGroupID,
 	}
-	var sharedSecret db.SharingRules
-	s.store.ExecTx(ctx, func(q *db.Queries) error {
-		sharedSecret, err = s.store.ShareSecret(ctx, args)
+	err = s.store.ExecTx(ctx, func(q *db.Queries) error {
+		if err := checkNotSharedTx(ctx, q, scope, target); err != nil {
+			return err
+		}
+		sharedSecret, err = s.createShareTx(ctx, q, authPayload, scope, args)
+		return err
+	})
+
+	if errorStatus(err) == http.StatusConflict {
+		return sharedSecret, err
+	}
+	if err != nil {
+		return sharedSecret, fmt.Errorf("failed to share the secret")
+	}
+	return sharedSecret, nil
+}
+
+// checkNotSharedTx fails with 409 if the path is already shared with the target. The lock it
+// takes is held until the transaction ends, so a concurrent share cannot slip in before the
+// rule is created.
+func checkNotSharedTx(ctx context.Context, q *db.Queries, scope shareScope, target string) error {
+	if err := q.LockShare(ctx, db.LockShareParams{Path: scope.Path, TargetEmail: target}); err != nil {
+		return err
+	}
+	shared, err := q.CheckIfShared(ctx, db.CheckIfSharedParams{
+		Path:        scope.Path,
+		TargetEmail: target,
+		IsPrefix:    scope.Prefix,
+	})
+	if err != nil {
+		return err
+	}
+	if shared {
+		return newStatusError(http.StatusConflict, "%s is already shared with %s, update the share instead", scope.display(), target)
+	}
+	return nil
+}
+
+// createShareTx inserts the sharing rule, audits it as share_secret and announces it
+func (s *Server) createShareTx(ctx context.Context, q *db.Queries, authPayload *auth.Payload, scope shareScope, args db.ShareSecretParams) (db.SharingRules, error) {
+	rule, err := q.ShareSecret(ctx, args)
+	if err != nil {
+		return rule, err
+	}
+
+	// Log the action
+	if err = s.auditSvc.LogTx(ctx, q, authPayload.UserID, authPayload.Email, "share_secret", scope.display(), scope.Version, true, nil); err != nil {
+		return rule, fmt.Errorf("failed to log action: %w", err)
+	}
+	return rule, s.publishTx(ctx, q, scope.event(rule.TargetEmail))
+}
+
+// shareScope is what a sharing rule applies to: one secret, or every secret below a folder
+type shareScope struct {
+	Path   string
+	Prefix bool
+	// Version is the latest version of the secret, 0 for folders
+	Version int32
+	OwnerID uuid.UUID
+	TeamID  uuid.NullUUID
+}
+
+// display returns the path the way users write it, with the wildcard for folders
+func (sc shareScope) display() string {
+	return sharedPath(sc.Path, sc.Prefix)
+}
+
+// event announces a change to the rule of target on the scope
+func (sc shareScope) event(target string) events.Event {
+	return events.Event{
+		Type:    events.ShareChanged,
+		Path:    shareEventPath(sc.Path, sc.Prefix),
+		OwnerID: sc.OwnerID,
+		TeamID:  sc.TeamID,
+		Target:  target,
+	}
+}
+
+// sharedPath returns the path of a sharing rule the way users write it
+func sharedPath(path string, prefix bool) string {
+	if prefix {
+		return path + folderShareSuffix
+	}
+	return path
+}
+
+// shareEventPath is the Path of a ShareChanged event. Folder rules end in a slash so that
+// subscribers can tell them apart and match every secret below them.
+func shareEventPath(path string, prefix bool) string {
+	if prefix {
+		return path + "/"
+	}
+	return path
+}
+
+// scopeForSharing resolves rawPath to the secret, or with a trailing /* the folder, whose
+// sharing rules the caller wants to change, and checks that the caller owns it
+func (s *Server) scopeForSharing(ctx context.Context, authPayload *auth.Payload, rawPath string) (shareScope, error) {
+	if folder, ok := strings.CutSuffix(strings.TrimSpace(rawPath), folderShareSuffix); ok {
+		return s.folderForSharing(ctx, authPayload, folder)
+	}
+
+	secret, err := s.secretForSharing(ctx, authPayload, rawPath)
+	if err != nil {
+		return shareScope{}, err
+	}
+	return shareScope{
+		Path:    secret.Path,
+		Version: secret.Version,
+		OwnerID: secret.UserID,
+		TeamID:  secret.TeamID,
+	}, nil
+}
+
+// folderForSharing checks that the caller holds share on the folder, which they do in their own
+// namespace, in a team they maintain or through a policy. The folder does not need to contain
+// any secrets yet.
+func (s *Server) folderForSharing(ctx context.Context, authPayload *auth.Payload, rawFolder string) (shareScope, error) {
+	folder, err := secretpath.Normalize(rawFolder)
+	if err != nil {
+		return shareScope{}, newStatusError(http.StatusBadRequest, "%s", err)
+	}
+	scope := shareScope{Path: folder, Prefix: true}
+
+	owner, err := s.pathOwnership(ctx, authPayload, folder)
+	if err != nil {
+		return scope, err
+	}
+	canShare, err := s.can(ctx, authPayload, owner, policy.Share)
+	if err != nil {
+		return scope, fmt.Errorf("failed to check folder ownership")
+	}
+	if !canShare {
+		return scope, newStatusError(http.StatusForbidden, "you can only share folders in your own namespace or a team you maintain")
+	}
+	scope.OwnerID = authPayload.UserID
+	scope.TeamID = owner.TeamID
+	return scope, nil
+}
+
+// secretForSharing loads the secret at rawPath and checks that the caller holds share on it,
+// which every change to its sharing rules requires
+func (s *Server) secretForSharing(ctx context.Context, authPayload *auth.Payload, rawPath string) (db.GetLatestSecretByPathRow, error) {
+	var secret db.GetLatestSecretByPathRow
+
+	path, err := secretpath.Normalize(rawPath)
+	if err != nil {
+		return secret, newStatusError(http.StatusBadRequest, "%s", err)
+	}
+
+	// Check if the secret exists
+	secret, err = s.loadSecret(ctx, path, defaultEnvironment)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return secret, newStatusError(http.StatusNotFound, "the secret does not exist")
+		}
+		return secret, err
+	}
+
+	canShare, err := s.can(ctx, authPayload, secret, policy.Share)
+	if err != nil {
+		return secret, fmt.Errorf("failed to check secret ownership")
+	}
+	if !canShare {
+		return secret, newStatusError(http.StatusForbidden, "you do not have permission to share this secret")
+	}
+	return secret, nil
+}
+
+type updateShareRequest struct {
+	// the share to change is that of either a user or a group
+	TargetEmail string `json:"target_email" binding:"omitempty,email"`
+	TargetGroup string `json:"target_group"`
+	Permission  string `json:"permission" binding:"omitempty,oneof=read write"`
+	// ShareTTLSecs restarts the expiry from now, 0 removes it. Omitted keeps the current expiry.
+	ShareTTLSecs *int `json:"share_ttl_secs" binding:"omitempty,min=0"`
+}
+
+type shareRuleResponse struct {
+	Path        string     `json:"path"`
+	OwnerEmail  string     `json:"owner_email"`
+	TargetEmail string     `json:"target_email,omitempty"`
+	TargetGroup string     `json:"target_group,omitempty"`
+	Permission  string     `json:"permission"`
+	SharedUntil *time.Time `json:"shared_until,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type revokeSharesResponse struct {
+	Path string `json:"path"`
+	// Revoked lists the users, and groups as group:<slug>, that lost their share
+	Revoked []string `json:"revoked"`
+}
+
+func newShareRuleResponse(rule db.SharingRules) shareRuleResponse {
+	resp := shareRuleResponse{
+		Path:       sharedPath(rule.Path, rule.IsPrefix),
+		OwnerEmail: rule.OwnerEmail,
+		Permission: rule.Permission,
+		CreatedAt:  rule.CreatedAt.Time,
+	}
+	resp.TargetEmail, resp.TargetGroup = splitPrincipal(rule.TargetEmail)
+	if rule.SharedUntil.Valid {
+		resp.SharedUntil = &rule.SharedUntil.Time
+	}
+	return resp
+}
+
+// @Summary      Change a share
+// @Description  Changes the permission and/or expiry of an active share. share_ttl_secs restarts the expiry from now and 0 removes it; leave it out to keep the current expiry. A path ending in /* changes the share of that folder. Only the owner of the secret or folder can change its shares. Takes effect immediately.
+// @Tags         Secrets
+// @Accept       json
+// @Produce      json
+// @Param        path     path     string              true  "Secret path"
+// @Param        request  body     updateShareRequest  true  "New permission and/or expiry"
+// @Success      200      {object} shareRuleResponse
+// @Failure      400      {object} swaggerErrorResponse "Invalid input or nothing to change"
+// @Failure      403      {object} swaggerErrorResponse "Not the secret owner"
+// @Failure      404      {object} swaggerErrorResponse "Secret or share not found"
+// @Failure      500      {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /shares/{path} [patch]
+func (s *Server) updateShare(ctx *gin.Context) {
+	var req updateShareRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+	rule, err := s.changeShare(ctx, authPayload, ctx.Param("path"), req)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newShareRuleResponse(rule))
+}
+
+// changeShare updates the active share of a secret or folder the caller owns with the target user or group
+func (s *Server) changeShare(ctx context.Context, authPayload *auth.Payload, rawPath string, req updateShareRequest) (db.SharingRules, error) {
+	var rule db.SharingRules
+
+	target, err := sharePrincipal(req.TargetEmail, req.TargetGroup)
+	if err != nil {
+		return rule, err
+	}
+	if req.Permission == "" && req.ShareTTLSecs == nil {
+		return rule, newStatusError(http.StatusBadRequest, "nothing to change, give a permission or share_ttl_secs")
+	}
+	scope, err := s.scopeForSharing(ctx, authPayload, rawPath)
+	if err != nil {
+		return rule, err
+	}
+
+	args := db.UpdateSharingRuleParams{
+		Path:        scope.Path,
+		TargetEmail: target,
+		IsPrefix:    scope.Prefix,
+	}
+	if req.Permission != "" {
+		args.Permission = sql.NullString{String: req.Permission, Valid: true}
+	}
+	if req.ShareTTLSecs != nil {
+		args.SetSharedUntil = true
+		if *req.ShareTTLSecs > 0 {
+			args.SharedUntil = sql.NullTime{
+				Time:  time.Now().Add(time.Duration(*req.ShareTTLSecs) * time.Second),
+				Valid: true,
+			}
+		}
+	}
+
+	err = s.store.ExecTx(ctx, func(q *db.Queries) error {
+		updated, err := q.UpdateSharingRule(ctx, args)
 		if err != nil {
 			return err
 		}
+		if len(updated) == 0 {
+			return newStatusError(http.StatusNotFound, "the secret is not shared with the target")
+		}
+		rule = updated[0]
 
-		// Log the action
-		if err = s.auditSvc.LogTx(ctx, q, authPayload.UserID, authPayload.Email, "share_secret", secret.Path, secret.Version, true, nil); err != nil {
+		expiry := "no expiry"
+		if rule.SharedUntil.Valid {
+			expiry = "until " + rule.SharedUntil.Time.UTC().Format(time.RFC3339)
+		}
+		reason := fmt.Sprintf("%s access for %s, %s", rule.Permission, rule.TargetEmail, expiry)
+		if err := s.auditSvc.LogTx(ctx, q, authPayload.UserID, authPayload.Email, "update_share", scope.display(), scope.Version, true, &reason); err != nil {
 			return fmt.Errorf("failed to log action: %w", err)
 		}
-		return nil
+		return s.publishTx(ctx, q, scope.event(rule.TargetEmail))
 	})
+	return rule, err
+}
 
+// @Summary      Revoke shares
+// @Description  Revokes the share of a secret with target_email or target_group, or with all=true every share of the secret. A path ending in /* revokes shares of that folder. Only the owner of the secret or folder can revoke its shares. Takes effect immediately.
+// @Tags         Secrets
+// @Produce      json
+// @Param        path          path     string  true   "Secret path"
+// @Param        target_email  query    string  false  "User whose share to revoke"
+// @Param        target_group  query    string  false  "Group whose share to revoke"
+// @Param        all           query    bool    false  "Revoke every share of the secret"
+// @Success      200           {object} revokeSharesResponse
+// @Failure      400           {object} swaggerErrorResponse "Not exactly one of target_email, target_group and all=true given"
+// @Failure      403           {object} swaggerErrorResponse "Not the secret owner"
+// @Failure      404           {object} swaggerErrorResponse "Secret or share not found"
+// @Failure      500           {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /shares/{path} [delete]
+func (s *Server) deleteShare(ctx *gin.Context) {
+	email, group := ctx.Query("target_email"), ctx.Query("target_group")
+	all, _ := strconv.ParseBool(ctx.Query("all"))
+	var target string
+	if !all || email != "" || group != "" {
+		var err error
+		if target, err = sharePrincipal(email, group); err != nil || all {
+			ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("give either target_email, target_group or all=true")))
+			return
+		}
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+	resp, err := s.revokeShares(ctx, authPayload, ctx.Param("path"), target)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(fmt.Errorf("failed to share the secret")))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
-	resp := shareSecretResponse{
-		Success:     true,
-		Path:        sharedSecret.Path,
-		Permission:  sharedSecret.Permission,
-		OwnerEmail:  sharedSecret.OwnerEmail,
-		TargetEmail: sharedSecret.TargetEmail,
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// revokeShares deletes the shares of a secret or folder the caller owns with target, a user
+// or group:<slug>, or all of them when target is empty
+func (s *Server) revokeShares(ctx context.Context, authPayload *auth.Payload, rawPath, target string) (revokeSharesResponse, error) {
+	resp := revokeSharesResponse{Revoked: []string{}}
+
+	scope, err := s.scopeForSharing(ctx, authPayload, rawPath)
+	if err != nil {
+		return resp, err
 	}
+	resp.Path = scope.display()
 
-	ctx.JSON(http.StatusOK, resp)
+	err = s.store.ExecTx(ctx, func(q *db.Queries) error {
+		var deleted []db.SharingRules
+		if target == "" {
+			deleted, err = q.DeleteSharingRulesByPath(ctx, db.DeleteSharingRulesByPathParams{
+				Path:     scope.Path,
+				IsPrefix: scope.Prefix,
+			})
+		} else {
+			deleted, err = q.DeleteSharingRule(ctx, db.DeleteSharingRuleParams{
+				Path:        scope.Path,
+				TargetEmail: target,
+				IsPrefix:    scope.Prefix,
+			})
+		}
+		if err != nil {
+			return err
+		}
+		if target != "" && len(deleted) == 0 {
+			return newStatusError(http.StatusNotFound, "the secret is not shared with the target")
+		}
 
+		for _, rule := range deleted {
+			reason := fmt.Sprintf("%s access for %s revoked", rule.Permission, rule.TargetEmail)
+			if err := s.auditSvc.LogTx(ctx, q, authPayload.UserID, authPayload.Email, "revoke_share", scope.display(), scope.Version, true, &reason); err != nil {
+				return fmt.Errorf("failed to log action: %w", err)
+			}
+			if err := s.publishTx(ctx, q, scope.event(rule.TargetEmail)); err != nil {
+				return err
+			}
+			if !slices.Contains(resp.Revoked, rule.TargetEmail) {
+				resp.Revoked = append(resp.Revoked, rule.TargetEmail)
+			}
+		}
+		return nil
+	})
+	return resp, err
 }
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSharing(t *testing.T) {
+	owner := newTestUser(t)
+	reader := newTestUser(t)
+	stranger := newTestUser(t)
+
+	created := owner.createSecret("api/key", "k")
+
+	_, code := reader.getSecret(created.Path)
+	require.Equal(t, http.StatusForbidden, code)
+
+	var share shareSecretResponse
+	require.Equal(t, http.StatusOK, owner.call(http.MethodPost, "/secrets/share", shareSecretRequest{Path: created.Path, TargetEmail: reader.email, Permission: "read"}, &share))
+	require.Equal(t, reader.email, share.TargetEmail)
+
+	require.Equal(t, http.StatusConflict, owner.share(shareSecretRequest{Path: created.Path, TargetEmail: reader.email, Permission: "read"}))
+
+	secret, code := reader.getSecret(created.Path)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "k", secret.Decrypted)
+
+	require.Equal(t, http.StatusForbidden, reader.call(http.MethodPut, "/secrets/"+created.Path, updateSecretRequest{Value: "x"}, nil))
+
+	_, code = stranger.getSecret(created.Path)
+	require.Equal(t, http.StatusForbidden, code)
+	_, code = owner.getSecret(created.Path + "-missing")
+	require.Equal(t, http.StatusNotFound, code)
+
+	// only the owner can change shares
+	require.Equal(t, http.StatusForbidden, reader.call(http.MethodPatch, "/shares/"+created.Path, updateShareRequest{TargetEmail: reader.email, Permission: "write"}, nil))
+
+	ttl := 3600
+	var rule shareRuleResponse
+	require.Equal(t, http.StatusOK, owner.call(http.MethodPatch, "/shares/"+created.Path, updateShareRequest{TargetEmail: reader.email, Permission: "write", ShareTTLSecs: &ttl}, &rule))
+	require.Equal(t, "write", rule.Permission)
+	require.NotNil(t, rule.SharedUntil)
+
+	require.Equal(t, http.StatusOK, reader.call(http.MethodPut, "/secrets/"+created.Path, updateSecretRequest{Value: "x"}, nil))
+
+	var shared sharedWithMeResponse
+	require.Equal(t, http.StatusOK, reader.call(http.MethodGet, "/shared-with-me?permission=write", nil, &shared))
+	require.Len(t, shared.Shares, 1)
+	require.Equal(t, created.Path, shared.Shares[0].Path)
+	require.Equal(t, owner.email, shared.Shares[0].OwnerEmail)
+	require.NotNil(t, shared.Shares[0].SharedUntil)
+	shared = sharedWithMeResponse{}
+	require.Equal(t, http.StatusOK, reader.call(http.MethodGet, "/shared-with-me?permission=read", nil, &shared))
+	require.Empty(t, shared.Shares)
+
+	var access secretAccessResponse
+	require.Equal(t, http.StatusOK, owner.call(http.MethodGet, "/access/"+created.Path, nil, &access))
+	require.Len(t, access.Access, 2)
+	require.Equal(t, accessSourceOwner, access.Access[0].Source)
+	require.Equal(t, accessSourceShare, access.Access[1].Source)
+	require.Equal(t, reader.email, access.Access[1].Email)
+	require.Equal(t, http.StatusForbidden, reader.call(http.MethodGet, "/access/"+created.Path, nil, nil))
+
+	require.Equal(t, http.StatusOK, owner.call(http.MethodDelete, "/shares/"+created.Path+"?target_email="+reader.email, nil, nil))
+	_, code = reader.getSecret(created.Path)
+	require.Equal(t, http.StatusForbidden, code)
+	require.Equal(t, http.StatusNotFound, owner.call(http.MethodDelete, "/shares/"+created.Path+"?target_email="+reader.email, nil, nil))
+
+	require.Equal(t, http.StatusOK, owner.share(shareSecretRequest{Path: created.Path, TargetEmail: reader.email, Permission: "read"}))
+	var revoked revokeSharesResponse
+	require.Equal(t, http.StatusOK, owner.call(http.MethodDelete, "/shares/"+created.Path+"?all=true", nil, &revoked))
+	require.Equal(t, []string{reader.email}, revoked.Revoked)
+}
+
+func TestFolderSharing(t *testing.T) {
+	owner := newTestUser(t)
+	reader := newTestUser(t)
+
+	key := owner.createSecret("payments/api-key", "k")
+
+	var share shareSecretResponse
+	require.Equal(t, http.StatusOK, owner.call(http.MethodPost, "/secrets/share", shareSecretRequest{Path: owner.email + "/payments/*", TargetEmail: reader.email, Permission: "write"}, &share))
+	require.Equal(t, owner.email+"/payments/*", share.Path)
+
+	// secrets created after the share are covered too
+	password := owner.createSecret("payments/db", "d")
+	for _, path := range []string{key.Path, password.Path} {
+		secret, code := reader.getSecret(path)
+		require.Equal(t, http.StatusOK, code)
+		require.NotEmpty(t, secret.Decrypted)
+	}
+
+	// a share of one secret overrides the share of its folder
+	require.Equal(t, http.StatusOK, owner.share(shareSecretRequest{Path: password.Path, TargetEmail: reader.email, Permission: "read"}))
+	require.Equal(t, http.StatusForbidden, reader.call(http.MethodPut, "/secrets/"+password.Path, updateSecretRequest{Value: "x"}, nil))
+	require.Equal(t, http.StatusOK, reader.call(http.MethodPut, "/secrets/"+key.Path, updateSecretRequest{Value: "x"}, nil))
+
+	// only the owner of the namespace can share its folders
+	require.Equal(t, http.StatusForbidden, reader.share(shareSecretRequest{Path: owner.email + "/*", TargetEmail: reader.email, Permission: "read"}))
+
+	var revoked revokeSharesResponse
+	require.Equal(t, http.StatusOK, owner.call(http.MethodDelete, "/shares/"+owner.email+"/payments/*?all=true", nil, &revoked))
+	require.Equal(t, []string{reader.email}, revoked.Revoked)
+	_, code := reader.getSecret(key.Path)
+	require.Equal(t, http.StatusForbidden, code)
+}
@@ -0,0 +1,211 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pixperk/vaultify/internal/auth"
+	db "github.com/pixperk/vaultify/internal/db/sqlc"
+	"github.com/pixperk/vaultify/internal/secretpath"
+)
+
+// Where an entry of the access list comes from
+const (
+	accessSourceOwner = "owner"
+	accessSourceTeam  = "team"
+	accessSourceShare = "share"
+)
+
+type sharedWithMeResponse struct {
+	Prefix     string              `json:"prefix,omitempty"`
+	Permission string              `json:"permission,omitempty"`
+	Shares     []shareRuleResponse `json:"shares"`
+}
+
+type accessEntry struct {
+	// Email is the user, or Group the group whose members have the access
+	Email      string `json:"email,omitempty"`
+	Group      string `json:"group,omitempty"`
+	Permission string `json:"permission"`
+	Source     string `json:"source"`
+	// Role is the effective team role for team access
+	Role string `json:"role,omitempty"`
+	// SharedPath is the folder share, e.g. org/acme/payments/*, that grants share access
+	SharedPath  string     `json:"shared_path,omitempty"`
+	SharedUntil *time.Time `json:"shared_until,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+}
+
+type secretAccessResponse struct {
+	Path   string        `json:"path"`
+	Access []accessEntry `json:"access"`
+}
+
+// permissionParam reads the optional permission filter from the query
+func permissionParam(ctx *gin.Context) (string, error) {
+	permission := ctx.Query("permission")
+	if permission != "" && permission != "read" && permission != "write" {
+		return "", fmt.Errorf("permission must be read or write, got %q", permission)
+	}
+	return permission, nil
+}
+
+// @Summary      List secrets shared with me
+// @Description  Lists the active shares other users granted to the caller or to a group the caller is in, with owner, permission, expiry and creation time. Filter by path prefix and permission.
+// @Tags         Secrets
+// @Produce      json
+// @Param        prefix      query    string  false  "Only shares of secrets under this folder"
+// @Param        permission  query    string  false  "Only shares with this permission (read or write)"
+// @Success      200         {object} sharedWithMeResponse
+// @Failure      400         {object} swaggerErrorResponse "Invalid prefix or permission"
+// @Failure      500         {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /shared-with-me [get]
+func (s *Server) listSharedWithMe(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	permission, err := permissionParam(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var prefix string
+	if raw := ctx.Query("prefix"); raw != "" {
+		if prefix, err = secretpath.NormalizePrefix(raw); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+	}
+
+	rules, err := s.store.GetSecretsSharedWithMe(ctx, db.GetSecretsSharedWithMeParams{
+		TargetEmail: authPayload.Email,
+		UserID:      authPayload.UserID,
+		Prefix:      prefix,
+		Permission:  permission,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	resp := sharedWithMeResponse{
+		Prefix:     prefix,
+		Permission: permission,
+		Shares:     make([]shareRuleResponse, 0, len(rules)),
+	}
+	for _, rule := range rules {
+		resp.Shares = append(resp.Shares, newShareRuleResponse(rule))
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// @Summary      List who has access to a secret
+// @Description  Lists everyone who can read or write a secret and why: its creator for a personal secret, the members of its team (organization owners and admins as maintainers) for a team secret, and the share that applies to each user or group, which may be the share of a folder above the secret. A user can appear once per source and again through their groups. Only the owner of the secret can see this.
+// @Tags         Secrets
+// @Produce      json
+// @Param        path        path     string  true   "Secret path"
+// @Param        permission  query    string  false  "Only entries with this permission (read or write)"
+// @Success      200         {object} secretAccessResponse
+// @Failure      400         {object} swaggerErrorResponse "Invalid path or permission"
+// @Failure      403         {object} swaggerErrorResponse "Not the secret owner"
+// @Failure      404         {object} swaggerErrorResponse "Secret not found"
+// @Failure      500         {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /access/{path} [get]
+func (s *Server) getSecretAccess(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	permission, err := permissionParam(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	resp, err := s.secretAccess(ctx, authPayload, ctx.Param("path"), permission)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// secretAccess lists the grants on a secret the caller owns, only those with permission
+// when it is set
+func (s *Server) secretAccess(ctx context.Context, authPayload *auth.Payload, rawPath, permission string) (secretAccessResponse, error) {
+	resp := secretAccessResponse{Access: []accessEntry{}}
+
+	secret, err := s.secretForSharing(ctx, authPayload, rawPath)
+	if err != nil {
+		return resp, err
+	}
+	resp.Path = secret.Path
+
+	add := func(entry accessEntry) {
+		if permission == "" || entry.Permission == permission {
+			resp.Access = append(resp.Access, entry)
+		}
+	}
+
+	if secret.TeamID.Valid {
+		members, err := s.store.ListTeamAccess(ctx, secret.TeamID.UUID)
+		if err != nil {
+			return resp, err
+		}
+		for _, member := range members {
+			role := effectiveTeamRole(member.OrgRole, member.TeamRole)
+			if !teamRoleCanRead(role) {
+				continue
+			}
+			entry := accessEntry{
+				Email:      member.Email,
+				Permission: "read",
+				Source:     accessSourceTeam,
+				Role:       role,
+			}
+			if teamRoleCanWrite(role) {
+				entry.Permission = "write"
+			}
+			if member.CreatedAt.Valid {
+				entry.CreatedAt = &member.CreatedAt.Time
+			}
+			add(entry)
+		}
+	} else {
+		owner, err := s.store.GetUserByID(ctx, secret.UserID)
+		if err != nil && err != sql.ErrNoRows {
+			return resp, err
+		}
+		if err == nil {
+			add(accessEntry{Email: owner.Email, Permission: "write", Source: accessSourceOwner})
+		}
+	}
+
+	rules, err := s.store.GetSharedWith(ctx, db.GetSharedWithParams{
+		Path:       secret.Path,
+		Parents:    secretpath.Parents(secret.Path),
+		Permission: permission,
+	})
+	if err != nil {
+		return resp, err
+	}
+	for _, rule := range rules {
+		share := newShareRuleResponse(rule)
+		entry := accessEntry{
+			Email:       share.TargetEmail,
+			Group:       share.TargetGroup,
+			Permission:  rule.Permission,
+			Source:      accessSourceShare,
+			SharedUntil: share.SharedUntil,
+			CreatedAt:   &share.CreatedAt,
+		}
+		if rule.IsPrefix {
+			entry.SharedPath = share.Path
+		}
+		resp.Access = append(resp.Access, entry)
+	}
+	return resp, nil
+}
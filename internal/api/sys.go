@@ -0,0 +1,313 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pixperk/vaultify/internal/auth"
+	db "github.com/pixperk/vaultify/internal/db/sqlc"
+)
+
+// System roles are held on users and gate the /sys routes. They grant nothing on secrets:
+// neither admins nor auditors can read a value they could not read as a regular user.
+const (
+	systemRoleUser    = "user"
+	systemRoleAuditor = "auditor"
+	systemRoleAdmin   = "admin"
+)
+
+// forceRotation is a stale duration every key has exceeded
+const forceRotation = time.Nanosecond
+
+type sysUserResponse struct {
+	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type listUsersResponse struct {
+	Users []sysUserResponse `json:"users"`
+}
+
+type setUserRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=user auditor admin"`
+}
+
+type hmacKeyResponse struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type systemCounts struct {
+	Users          int64 `json:"users"`
+	Secrets        int64 `json:"secrets"`
+	SecretVersions int64 `json:"secret_versions"`
+	SharingRules   int64 `json:"sharing_rules"`
+	Groups         int64 `json:"groups"`
+	Organizations  int64 `json:"organizations"`
+	AuditLogs      int64 `json:"audit_logs"`
+}
+
+type systemStatusResponse struct {
+	Counts       systemCounts       `json:"counts"`
+	HMACKey      *hmacKeyResponse   `json:"hmac_key,omitempty"`
+	Environments []string           `json:"environments"`
+	Policies     []string           `json:"policies"`
+	Cache        cacheStatsResponse `json:"cache"`
+	GRPC         bool               `json:"grpc"`
+}
+
+func newSysUserResponse(user db.Users) sysUserResponse {
+	return sysUserResponse{
+		Email:     user.Email,
+		Name:      user.Name,
+		Role:      user.Role,
+		CreatedAt: user.CreatedAt.Time,
+	}
+}
+
+// requireSystemRole lets the request through only for users holding one of roles. The role is
+// read from the database on every request, so a demotion takes effect immediately.
+func (s *Server) requireSystemRole(roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+		user, err := s.store.GetUserByID(ctx, authPayload.UserID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(fmt.Errorf("the user no longer exists")))
+				return
+			}
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		if !slices.Contains(roles, user.Role) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(fmt.Errorf("this requires the %s role", roles[0])))
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// bootstrapAdmin makes the existing account of BOOTSTRAP_ADMIN_EMAIL an admin while there is none
+// yet, so the first admin needs no database access. It only runs at startup: promoting on sign-up
+// would hand the role to whoever registers the address first.
+func (s *Server) bootstrapAdmin(ctx context.Context) error {
+	if s.config.BootstrapAdminEmail == "" {
+		return nil
+	}
+	user, err := s.store.PromoteBootstrapAdmin(ctx, s.config.BootstrapAdminEmail)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	}
+
+	reason := "bootstrap admin"
+	return s.auditSvc.Log(ctx, user.ID, user.Email, "bootstrap_admin", "user:"+user.Email, 0, true, &reason)
+}
+
+// @Summary      List users
+// @Description  Lists every user with their system role. Admins only.
+// @Tags         System
+// @Produce      json
+// @Param        limit   query    int  false  "Limit number of results (default 100)"
+// @Param        offset  query    int  false  "Offset for pagination (default 0)"
+// @Success      200     {object} listUsersResponse
+// @Failure      403     {object} swaggerErrorResponse "Not an admin"
+// @Failure      500     {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /sys/users [get]
+func (s *Server) listUsers(ctx *gin.Context) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "100"))
+	if err != nil || limit < 1 {
+		limit = 100
+	}
+	offset, err := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	users, err := s.store.ListUsers(ctx, db.ListUsersParams{Limit: int32(limit), Offset: int32(offset)})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	resp := listUsersResponse{Users: make([]sysUserResponse, 0, len(users))}
+	for _, user := range users {
+		resp.Users = append(resp.Users, newSysUserResponse(user))
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// @Summary      Set a user's system role
+// @Description  Makes a user a regular user, a security auditor or an admin. The last admin cannot be demoted. Admins only; audited as set_user_role.
+// @Tags         System
+// @Accept       json
+// @Produce      json
+// @Param        email    path     string              true  "User email"
+// @Param        request  body     setUserRoleRequest  true  "Role"
+// @Success      200      {object} sysUserResponse
+// @Failure      400      {object} swaggerErrorResponse "Invalid role or last admin"
+// @Failure      403      {object} swaggerErrorResponse "Not an admin"
+// @Failure      404      {object} swaggerErrorResponse "User not found"
+// @Failure      500      {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /sys/users/{email}/role [put]
+func (s *Server) setUserRole(ctx *gin.Context) {
+	var req setUserRoleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+	email := ctx.Param("email")
+
+	var updated db.Users
+	err := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		user, err := q.GetUserByEmail(ctx, email)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return newStatusError(http.StatusNotFound, "the user does not exist")
+			}
+			return err
+		}
+
+		if user.Role == systemRoleAdmin && req.Role != systemRoleAdmin {
+			// holding the admin rows until commit keeps two demotions from both passing the check
+			admins, err := q.LockAdmins(ctx)
+			if err != nil {
+				return err
+			}
+			if len(admins) <= 1 && slices.Contains(admins, user.ID) {
+				return newStatusError(http.StatusBadRequest, "the last admin cannot be demoted")
+			}
+		}
+
+		if updated, err = q.SetUserRole(ctx, db.SetUserRoleParams{Email: email, Role: req.Role}); err != nil {
+			return err
+		}
+		detail := fmt.Sprintf("%s -> %s", user.Role, req.Role)
+		if err := s.auditSvc.LogTx(ctx, q, authPayload.UserID, authPayload.Email, "set_user_role", "user:"+email, 0, true, &detail); err != nil {
+			return fmt.Errorf("failed to log action: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newSysUserResponse(updated))
+}
+
+// @Summary      Rotate the HMAC key
+// @Description  Replaces the active HMAC signing key right away instead of waiting for the daily rotation. Old keys keep verifying existing versions. Admins only; audited as rotate_hmac_key.
+// @Tags         System
+// @Produce      json
+// @Success      200  {object} hmacKeyResponse
+// @Failure      403  {object} swaggerErrorResponse "Not an admin"
+// @Failure      500  {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /sys/hmac/rotate [post]
+func (s *Server) rotateHMACKeyNow(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	if err := s.rotateHMACKey(ctx, forceRotation); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	key, err := s.store.GetActiveHMACKey(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	detail := key.ID.String()
+	if err := s.auditSvc.Log(ctx, authPayload.UserID, authPayload.Email, "rotate_hmac_key", "sys:hmac", 0, true, &detail); err != nil {
+		log.Printf("failed to log HMAC key rotation: %v", err)
+	}
+	ctx.JSON(http.StatusOK, hmacKeyResponse{ID: key.ID, CreatedAt: key.CreatedAt.Time})
+}
+
+// @Summary      System status
+// @Description  Counts of users, secrets, shares, groups, organizations and audit entries, the active HMAC key, configured environments, loaded policies and cache statistics of this instance. Admins only.
+// @Tags         System
+// @Produce      json
+// @Success      200  {object} systemStatusResponse
+// @Failure      403  {object} swaggerErrorResponse "Not an admin"
+// @Failure      500  {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /sys/status [get]
+func (s *Server) getSystemStatus(ctx *gin.Context) {
+	stats, err := s.store.GetSystemStats(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	resp := systemStatusResponse{
+		Counts:       systemCounts(stats),
+		Environments: s.config.PromotionOrder,
+		Policies:     s.policies.Names(),
+		Cache:        s.cache.stats(),
+		GRPC:         s.config.GRPCPort != "",
+	}
+
+	key, err := s.store.GetActiveHMACKey(ctx)
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if err == nil {
+		resp.HMACKey = &hmacKeyResponse{ID: key.ID, CreatedAt: key.CreatedAt.Time}
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// @Summary      Search all audit logs
+// @Description  Audit logs of every user, with the filters of /audit plus user. Admins and security auditors only. Audit entries never contain secret values. Every search is itself audited as read_audit_logs.
+// @Tags         System
+// @Produce      json
+// @Param        user       query     string  false  "Only entries of this user email"
+// @Param        action     query     string  false  "Action filter (e.g., read_secret)"
+// @Param        path       query     string  false  "Resource path filter"
+// @Param        version    query     int     false  "Resource version filter"
+// @Param        success    query     bool    false  "Success status filter (true/false)"
+// @Param        flagged    query     bool    false  "Only entries flagged (true) or not flagged (false) for break-glass review"
+// @Param        from       query     string  false  "Start date in RFC3339 or YYYY-MM-DD"
+// @Param        to         query     string  false  "End date in RFC3339 or YYYY-MM-DD"
+// @Param        limit      query     int     false  "Limit number of results (default 50)"
+// @Param        offset     query     int     false  "Offset for pagination (default 0)"
+// @Success      200  {object}  getAuditLogsResponse
+// @Failure      400  {object}  swaggerErrorResponse "Invalid query parameter"
+// @Failure      403  {object}  swaggerErrorResponse "Not an admin or auditor"
+// @Failure      500  {object}  swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /sys/audit [get]
+func (s *Server) searchAuditLogs(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	params, ok := auditLogFilter(ctx)
+	if !ok {
+		return
+	}
+	params.UserEmail = ctx.Query("user")
+
+	query := ctx.Request.URL.RawQuery
+	if err := s.auditSvc.Log(ctx, authPayload.UserID, authPayload.Email, "read_audit_logs", "sys:audit", 0, true, &query); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	s.respondAuditLogs(ctx, params)
+}
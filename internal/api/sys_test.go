@@ -0,0 +1,56 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	db "github.com/pixperk/vaultify/internal/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystemRoles(t *testing.T) {
+	admin := newTestUser(t)
+	auditor := newTestUser(t)
+	user := newTestUser(t)
+
+	_, err := testStore.SetUserRole(context.Background(), db.SetUserRoleParams{Email: admin.email, Role: systemRoleAdmin})
+	require.NoError(t, err)
+
+	require.Equal(t, http.StatusForbidden, user.call(http.MethodGet, "/sys/users?limit=10", nil, nil))
+	require.Equal(t, http.StatusForbidden, user.call(http.MethodGet, "/sys/audit", nil, nil))
+	require.Equal(t, http.StatusForbidden, user.call(http.MethodGet, "/sys/cache/stats", nil, nil))
+
+	var promoted sysUserResponse
+	require.Equal(t, http.StatusOK, admin.call(http.MethodPut, "/sys/users/"+auditor.email+"/role", setUserRoleRequest{Role: systemRoleAuditor}, &promoted))
+	require.Equal(t, systemRoleAuditor, promoted.Role)
+	require.Equal(t, http.StatusBadRequest, admin.call(http.MethodPut, "/sys/users/"+user.email+"/role", setUserRoleRequest{Role: "root"}, nil))
+
+	// auditors search every user's audit log but cannot administer
+	user.createSecret("audited", "v")
+	var logs getAuditLogsResponse
+	require.Equal(t, http.StatusOK, auditor.call(http.MethodGet, "/sys/audit?user="+user.email+"&action=create_secret", nil, &logs))
+	require.Len(t, logs.Logs, 1)
+	require.Equal(t, user.email, logs.Logs[0].UserEmail)
+	require.Equal(t, http.StatusForbidden, auditor.call(http.MethodGet, "/sys/status", nil, nil))
+	require.Equal(t, http.StatusOK, auditor.call(http.MethodGet, "/sys/cache/stats", nil, nil))
+	require.Equal(t, http.StatusForbidden, auditor.call(http.MethodPost, "/sys/hmac/rotate", nil, nil))
+
+	var status systemStatusResponse
+	require.Equal(t, http.StatusOK, admin.call(http.MethodGet, "/sys/status", nil, &status))
+	require.NotZero(t, status.Counts.Users)
+	require.NotNil(t, status.HMACKey)
+	var rotated hmacKeyResponse
+	require.Equal(t, http.StatusOK, admin.call(http.MethodPost, "/sys/hmac/rotate", nil, &rotated))
+	require.NotEqual(t, status.HMACKey.ID, rotated.ID)
+
+	// system roles grant nothing on secrets
+	secret := user.createSecret("private", "v")
+	_, code := admin.getSecret(secret.Path)
+	require.Equal(t, http.StatusForbidden, code)
+
+	// the searches themselves are audited
+	logs = getAuditLogsResponse{}
+	require.Equal(t, http.StatusOK, admin.call(http.MethodGet, "/sys/audit?user="+auditor.email+"&action=read_audit_logs", nil, &logs))
+	require.NotEmpty(t, logs.Logs)
+}
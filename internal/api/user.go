@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"database/sql"
 	"net/http"
 	"time"
@@ -23,12 +24,15 @@ type createUserRequest struct {
 type userResponse struct {
 	Email     string    `json:"email"`
 	Name      string    `json:"name"`
+	Role      string    `json:"role"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
 type loginUserRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=6"`
+	// Policies limits the issued token to what the named policies grant, e.g. for CI jobs
+	Policies []string `json:"policies"`
 }
 
 type loginUserResponse struct {
@@ -40,6 +44,7 @@ func newUserResponse(user db.Users) userResponse {
 	return userResponse{
 		Name:      user.Name,
 		Email:     user.Email,
+		Role:      user.Role,
 		CreatedAt: user.CreatedAt.Time,
 	}
 }
@@ -61,28 +66,32 @@ func (s *Server) createUser(ctx *gin.Context) {
 		return
 	}
 
-	hashedPassword, err := util.HashPassword(req.Password)
+	user, err := s.registerUser(ctx, req)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
+
+// registerUser stores a new user with a hashed password
+func (s *Server) registerUser(ctx context.Context, req createUserRequest) (db.Users, error) {
+	hashedPassword, err := util.HashPassword(req.Password)
+	if err != nil {
+		return db.Users{}, err
+	}
+
 	arg := db.CreateUserParams{
 		Name:         req.Name,
 		Email:        req.Email,
 		PasswordHash: hashedPassword,
 	}
-	user, err := s.store.CreateUser(ctx, arg)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
-	}
-
-	ctx.JSON(http.StatusOK, newUserResponse(user))
+	return s.store.CreateUser(ctx, arg)
 }
 
 // @Summary      Log in a user
-// @Description  Verify credentials and return access token
+// @Description  Verify credentials and return access token. Naming policies issues a token limited to what they grant on top of the user's own access.
 // @Tags         Auth
 // @Accept       json
 // @Produce      json
@@ -100,33 +109,43 @@ func (s *Server) loginUser(ctx *gin.Context) {
 		return
 	}
 
+	resp, err := s.authenticate(ctx, req)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// authenticate verifies the credentials and issues an access token
+func (s *Server) authenticate(ctx context.Context, req loginUserRequest) (loginUserResponse, error) {
 	user, err := s.store.GetUserByEmail(ctx, req.Email)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
+			return loginUserResponse{}, newStatusError(http.StatusNotFound, "%s", err)
 		}
-
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+		return loginUserResponse{}, err
 	}
 
 	err = util.VerifyPassword(req.Password, user.PasswordHash)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-		return
+		return loginUserResponse{}, newStatusError(http.StatusUnauthorized, "%s", err)
 	}
 
-	accessToken, err := s.tokenMaker.CreateToken(user.ID, user.Email, time.Duration(s.config.AccessTokenDuration))
+	for _, name := range req.Policies {
+		if _, ok := s.policies.Get(name); !ok {
+			return loginUserResponse{}, newStatusError(http.StatusBadRequest, "unknown policy %q", name)
+		}
+	}
+
+	accessToken, err := s.tokenMaker.CreateToken(user.ID, user.Email, time.Duration(s.config.AccessTokenDuration), req.Policies...)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+		return loginUserResponse{}, err
 	}
 
-	resp := loginUserResponse{
+	return loginUserResponse{
 		AccessToken: accessToken,
 		User:        newUserResponse(user),
-	}
-
-	ctx.JSON(http.StatusOK, resp)
+	}, nil
 }
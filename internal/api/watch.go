@@ -0,0 +1,256 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pixperk/vaultify/internal/auth"
+	"github.com/pixperk/vaultify/internal/config"
+	db "github.com/pixperk/vaultify/internal/db/sqlc"
+	"github.com/pixperk/vaultify/internal/events"
+	"github.com/pixperk/vaultify/internal/policy"
+	"github.com/pixperk/vaultify/internal/secretpath"
+)
+
+const (
+	// watchBuffer is how far a watcher may fall behind before its stream is reset
+	watchBuffer = 64
+	// watchHeartbeat keeps idle streams from being closed by proxies
+	watchHeartbeat = 25 * time.Second
+
+	defaultWaitTimeout = 30 * time.Second
+	maxWaitTimeout     = 5 * time.Minute
+)
+
+// watchable reports whether watchers are told about events of type t
+func watchable(t events.Type) bool {
+	return t == events.VersionChanged || t == events.Deleted || t == events.Expired
+}
+
+// versionChanged builds the event published once a new version of secret has been committed
+func versionChanged(secret db.GetLatestSecretByPathRow, env string, version int32) events.Event {
+	return events.Event{
+		Type:        events.VersionChanged,
+		Path:        secret.Path,
+		Environment: env,
+		Version:     version,
+		OwnerID:     secret.UserID,
+		TeamID:      secret.TeamID,
+	}
+}
+
+// publishTx sends the events with pg_notify inside the transaction. Listeners on every instance,
+// this one included, publish them to their bus once the transaction commits. This instance's
+// cache is also invalidated right after the commit, so it never serves what it just replaced
+// while the notification makes its way back.
+func (s *Server) publishTx(ctx context.Context, q *db.Queries, changes ...events.Event) error {
+	q.AfterCommit(func() {
+		for _, e := range changes {
+			s.cache.invalidate(e)
+		}
+	})
+	for _, e := range changes {
+		payload, err := events.Encode(e)
+		if err != nil {
+			return err
+		}
+		if err := q.NotifyEvent(ctx, payload); err != nil {
+			return fmt.Errorf("failed to publish event: %w", err)
+		}
+	}
+	return nil
+}
+
+// canReadEvent checks read access against the ownership carried by the event, which still
+// works after the secret has been deleted
+func (s *Server) canReadEvent(ctx context.Context, authPayload *auth.Payload, e events.Event) (bool, error) {
+	return s.can(ctx, authPayload, db.GetLatestSecretByPathRow{
+		UserID: e.OwnerID,
+		Path:   e.Path,
+		TeamID: e.TeamID,
+	}, policy.Read)
+}
+
+// watchFilter selects the events a watcher of the given paths, folder prefix and optional
+// environment is told about. Read access is checked separately for each event.
+func (s *Server) watchFilter(rawPaths []string, rawPrefix, env string) (func(events.Event) bool, error) {
+	paths := make(map[string]bool)
+	for _, raw := range rawPaths {
+		path, err := secretpath.Normalize(raw)
+		if err != nil {
+			return nil, newStatusError(http.StatusBadRequest, "%s", err)
+		}
+		paths[path] = true
+	}
+
+	var prefix string
+	if rawPrefix != "" {
+		var err error
+		if prefix, err = secretpath.NormalizePrefix(rawPrefix); err != nil {
+			return nil, newStatusError(http.StatusBadRequest, "%s", err)
+		}
+	}
+	if len(paths) == 0 && prefix == "" {
+		return nil, newStatusError(http.StatusBadRequest, "at least one path or a prefix is required")
+	}
+
+	if env != "" && !s.isKnownEnvironment(env) {
+		return nil, newStatusError(http.StatusBadRequest, "unknown environment %q", env)
+	}
+
+	return func(e events.Event) bool {
+		if !watchable(e.Type) || (env != "" && e.Environment != "" && e.Environment != env) {
+			return false
+		}
+		return paths[e.Path] || (prefix != "" && strings.HasPrefix(e.Path, prefix))
+	}, nil
+}
+
+// @Summary      Watch secrets for changes
+// @Description  Opens a Server-Sent Events stream of version_changed, deleted and expired events for the given paths and/or every path under a prefix. Only events for secrets the caller can read at the time of the event are sent, and values are never included. A reset event means the watcher fell behind and events were lost; reconnect and re-read the secrets.
+// @Tags         Secrets
+// @Produce      text/event-stream
+// @Param        path    query    []string  false  "Secret path to watch (repeatable)" collectionFormat(multi)
+// @Param        prefix  query    string    false  "Watch every secret under this folder"
+// @Param        env     query    string    false  "Only events for this environment"
+// @Success      200     {object} events.Event
+// @Failure      400     {object} swaggerErrorResponse "No path or prefix, or an invalid one"
+// @Security     BearerAuth
+// @Router       /watch [get]
+func (s *Server) watchSecrets(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	filter, err := s.watchFilter(ctx.QueryArray("path"), ctx.Query("prefix"), ctx.Query("env"))
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	sub := s.bus.Subscribe(watchBuffer, filter)
+	defer sub.Close()
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	ctx.Writer.Flush()
+
+	heartbeat := time.NewTicker(watchHeartbeat)
+	defer heartbeat.Stop()
+
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Request.Context().Done():
+			return false
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": keepalive\n\n")
+			return true
+		case e, ok := <-sub.C:
+			if !ok {
+				ctx.SSEvent("reset", gin.H{"reason": "watcher fell behind"})
+				return false
+			}
+			if e.Type == events.Reset {
+				ctx.SSEvent("reset", gin.H{"reason": "events may have been missed"})
+				return true
+			}
+			allowed, err := s.canReadEvent(ctx, authPayload, e)
+			if err != nil {
+				log.Printf("Error checking access for watch event on %s: %v\n", e.Path, err)
+				return false
+			}
+			if allowed {
+				ctx.SSEvent(string(e.Type), e)
+			}
+			return true
+		}
+	})
+}
+
+// WaitForVersion implements long-polling on GET /secrets/{path}?wait_for_version=N. While the
+// latest version in the requested environment is below N, the request blocks until a newer
+// version is written, the secret is deleted or the timeout passes, in which case it returns
+// 304 Not Modified. Everything else, including access errors, is left to RequireCapability.
+func (s *Server) WaitForVersion() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		param := ctx.Query("wait_for_version")
+		if param == "" {
+			return
+		}
+		want, err := strconv.ParseInt(param, 10, 32)
+		if err != nil || want < 1 {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid wait_for_version"})
+			return
+		}
+		if ctx.Query("version") != "" {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "wait_for_version cannot be combined with version"})
+			return
+		}
+
+		timeout := defaultWaitTimeout
+		if raw := ctx.Query("timeout"); raw != "" {
+			timeout, err = config.ParseDuration(raw)
+			if err != nil || timeout <= 0 {
+				ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid timeout"})
+				return
+			}
+			timeout = min(timeout, maxWaitTimeout)
+		}
+
+		path, err := secretpath.Normalize(ctx.Param("path"))
+		if err != nil {
+			return
+		}
+		env, err := s.environmentParam(ctx)
+		if err != nil {
+			return
+		}
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+		// subscribe before reading the current version so a write in between is not missed
+		sub := s.bus.Subscribe(watchBuffer, func(e events.Event) bool {
+			return watchable(e.Type) && e.Path == path && (e.Environment == "" || e.Environment == env)
+		})
+		defer sub.Close()
+
+		current, err := s.store.GetLatestSecretByPath(ctx, db.GetLatestSecretByPathParams{
+			Path:        path,
+			Environment: env,
+		})
+		if err != nil {
+			return
+		}
+		// never block on a secret the caller cannot read; RequireCapability rejects it right away
+		allowed, err := s.can(ctx, authPayload, current, policy.Read)
+		if err != nil || !allowed || int64(current.Version) >= want {
+			return
+		}
+
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+
+		for {
+			select {
+			case e, ok := <-sub.C:
+				// a reset may hide the version we are waiting for, so re-read
+				if !ok || e.Type != events.VersionChanged || int64(e.Version) >= want {
+					return
+				}
+			case <-timer.C:
+				ctx.AbortWithStatus(http.StatusNotModified)
+				return
+			case <-ctx.Request.Context().Done():
+				ctx.Abort()
+				return
+			}
+		}
+	}
+}
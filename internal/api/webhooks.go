@@ -0,0 +1,581 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pixperk/vaultify/internal/auth"
+	db "github.com/pixperk/vaultify/internal/db/sqlc"
+	"github.com/pixperk/vaultify/internal/policy"
+	"github.com/pixperk/vaultify/internal/secretpath"
+	"github.com/pixperk/vaultify/internal/webhook"
+)
+
+const (
+	// webhookBatchSize is how many due deliveries a single tick picks up
+	webhookBatchSize = 50
+	// webhookWorkers is how many deliveries of a batch are sent at the same time
+	webhookWorkers = 10
+	// webhookMinLease keeps a claimed delivery from being picked up by another instance while it
+	// is sent, even with a very short send timeout
+	webhookMinLease = 5 * time.Minute
+
+	defaultDeliveryLimit = 50
+	maxDeliveryLimit     = 200
+)
+
+type createWebhookRequest struct {
+	URL        string   `json:"url" binding:"required"`
+	PathPrefix string   `json:"path_prefix"`
+	Events     []string `json:"events"`
+}
+
+type webhookResponse struct {
+	ID         uuid.UUID `json:"id"`
+	URL        string    `json:"url"`
+	PathPrefix string    `json:"path_prefix"`
+	Events     []string  `json:"events"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type createWebhookResponse struct {
+	webhookResponse
+	// Secret signs every delivery. It is only returned once, when the subscription is created.
+	Secret string `json:"secret"`
+}
+
+type webhookDeliveryResponse struct {
+	ID             uuid.UUID       `json:"id"`
+	Event          string          `json:"event"`
+	Status         string          `json:"status"`
+	Attempts       int32           `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	LastStatusCode *int32          `json:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+}
+
+func newWebhookResponse(sub db.WebhookSubscriptions) webhookResponse {
+	return webhookResponse{
+		ID:         sub.ID,
+		URL:        sub.Url,
+		PathPrefix: sub.PathPrefix,
+		Events:     sub.EventTypes,
+		CreatedAt:  sub.CreatedAt.Time,
+	}
+}
+
+func newWebhookDeliveryResponse(d db.WebhookDeliveries) webhookDeliveryResponse {
+	resp := webhookDeliveryResponse{
+		ID:        d.ID,
+		Event:     d.EventType,
+		Status:    d.Status,
+		Attempts:  d.Attempts,
+		LastError: d.LastError.String,
+		CreatedAt: d.CreatedAt,
+		Payload:   d.Payload,
+	}
+	if d.Status == "pending" {
+		resp.NextAttemptAt = &d.NextAttemptAt
+	}
+	if d.LastStatusCode.Valid {
+		resp.LastStatusCode = &d.LastStatusCode.Int32
+	}
+	if d.DeliveredAt.Valid {
+		resp.DeliveredAt = &d.DeliveredAt.Time
+	}
+	return resp
+}
+
+// canSubscribe checks that the subscriber may read secrets under prefix: it must be in their own
+// namespace or in a team namespace, and the authorizer must grant them read on the folder
+func (s *Server) canSubscribe(ctx context.Context, authPayload *auth.Payload, prefix string) (bool, error) {
+	if !strings.HasPrefix(prefix, personalNamespace(authPayload.Email)) {
+		if _, _, ok := teamPrefixNamespace(prefix); !ok {
+			return false, nil
+		}
+	}
+
+	owner, err := s.pathOwnership(ctx, authPayload, strings.TrimSuffix(prefix, "/"))
+	if err != nil {
+		return false, err
+	}
+	return s.can(ctx, authPayload, owner, policy.Read)
+}
+
+// canReceiveEvent checks that subscriber may still read the secret or folder an event at path is about
+func (s *Server) canReceiveEvent(ctx context.Context, q *db.Queries, subscriber *auth.Payload, path string) (bool, error) {
+	// folder shares are logged as folder/*; the folder is what subscribers must be able to read
+	path = strings.TrimSuffix(path, folderShareSuffix)
+	secret, err := q.GetSecretByPath(ctx, path)
+	if err != nil && err != sql.ErrNoRows {
+		return false, err
+	}
+
+	owner := secretOwnership(secret)
+	if err == sql.ErrNoRows {
+		// gone secrets and folders belong to whoever's namespace they are in
+		if owner, err = s.pathOwnership(ctx, subscriber, path); err != nil {
+			return false, err
+		}
+	}
+	return s.can(ctx, subscriber, owner, policy.Read)
+}
+
+// enqueueWebhookDeliveries is an audit hook: it turns audit entries into pending deliveries for
+// every matching subscription, in the same transaction as the entry
+func (s *Server) enqueueWebhookDeliveries(ctx context.Context, q *db.Queries, entry db.AuditLogs) error {
+	event, ok := webhook.EventForAudit(entry.Action, entry.Success, entry.Reason.String)
+	if !ok {
+		return nil
+	}
+
+	subs, err := q.ListWebhookSubscriptionsForEvent(ctx, db.ListWebhookSubscriptionsForEventParams{
+		Path:      entry.ResourcePath,
+		EventType: event,
+	})
+	if err != nil || len(subs) == 0 {
+		return err
+	}
+
+	payload, err := json.Marshal(webhook.Payload{
+		ID:         entry.ID.String(),
+		Event:      event,
+		Path:       entry.ResourcePath,
+		Version:    entry.ResourceVersion,
+		Actor:      entry.UserEmail,
+		Detail:     entry.Reason.String,
+		OccurredAt: entry.CreatedAt.Time,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, sub := range subs {
+		// access may have been revoked since the subscription was created
+		allowed, err := s.canReceiveEvent(ctx, q, &auth.Payload{UserID: sub.UserID, Email: sub.UserEmail}, entry.ResourcePath)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			continue
+		}
+
+		if _, err := q.CreateWebhookDelivery(ctx, db.CreateWebhookDeliveryParams{
+			SubscriptionID: sub.ID,
+			EventType:      event,
+			Payload:        payload,
+		}); err != nil {
+			return fmt.Errorf("failed to queue webhook delivery: %w", err)
+		}
+	}
+	return nil
+}
+
+// StartWebhookLoop periodically sends pending webhook deliveries
+func (s *Server) StartWebhookLoop(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.deliverDueWebhooks(ctx)
+			case <-ctx.Done():
+				log.Println("Shutting down webhook delivery loop...")
+				return
+			}
+		}
+	}()
+}
+
+// webhookLease is how long a claimed batch is kept from other instances: twice the time its
+// sends can take when every worker waits out the full timeout on each of its deliveries
+func webhookLease(timeout time.Duration) time.Duration {
+	rounds := (webhookBatchSize + webhookWorkers - 1) / webhookWorkers
+	return max(webhookMinLease, 2*time.Duration(rounds)*timeout)
+}
+
+func (s *Server) deliverDueWebhooks(ctx context.Context) {
+	due, err := s.store.ClaimDueWebhookDeliveries(ctx, db.ClaimDueWebhookDeliveriesParams{
+		LeaseSeconds: int32(webhookLease(s.config.WebhookTimeout) / time.Second),
+		BatchSize:    webhookBatchSize,
+	})
+	if err != nil {
+		log.Printf("Claiming due webhook deliveries failed: %v", err)
+		return
+	}
+
+	deliveries := make(chan db.WebhookDeliveries)
+	var wg sync.WaitGroup
+	for range min(webhookWorkers, len(due)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for delivery := range deliveries {
+				if err := s.deliverWebhook(ctx, delivery); err != nil {
+					log.Printf("Webhook delivery %s failed: %v", delivery.ID, err)
+				}
+			}
+		}()
+	}
+	for _, delivery := range due {
+		deliveries <- delivery
+	}
+	close(deliveries)
+	wg.Wait()
+}
+
+// deliverWebhook makes one attempt at a delivery and records the outcome. Failed attempts are
+// retried with exponential backoff until webhook.MaxAttempts is reached.
+func (s *Server) deliverWebhook(ctx context.Context, delivery db.WebhookDeliveries) error {
+	sub, err := s.store.GetWebhookSubscription(ctx, delivery.SubscriptionID)
+	if err != nil {
+		return err
+	}
+	secret, err := s.encryptor.Decrypt(sub.SecretCiphertext, sub.SecretNonce)
+	if err != nil {
+		return err
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, s.config.WebhookTimeout)
+	status, sendErr := webhook.Sender{Client: s.webhooks}.Send(sendCtx, sub.Url, string(secret), delivery.ID.String(), delivery.EventType, delivery.Payload)
+	cancel()
+
+	lastStatus := sql.NullInt32{Int32: int32(status), Valid: status != 0}
+	if sendErr == nil {
+		return s.store.MarkWebhookDeliverySucceeded(ctx, db.MarkWebhookDeliverySucceededParams{
+			ID:             delivery.ID,
+			LastStatusCode: lastStatus,
+		})
+	}
+
+	if err := s.store.MarkWebhookDeliveryFailed(ctx, db.MarkWebhookDeliveryFailedParams{
+		ID:             delivery.ID,
+		MaxAttempts:    webhook.MaxAttempts,
+		LastStatusCode: lastStatus,
+		LastError:      sql.NullString{String: sendErr.Error(), Valid: true},
+		NextAttemptAt:  time.Now().Add(webhook.Backoff(int(delivery.Attempts) + 1)),
+	}); err != nil {
+		return fmt.Errorf("%w (recording the failure also failed: %v)", sendErr, err)
+	}
+	return sendErr
+}
+
+// ownWebhook loads the subscription in the :id parameter, answering 404 for other users' subscriptions
+func (s *Server) ownWebhook(ctx *gin.Context, authPayload *auth.Payload) (db.WebhookSubscriptions, bool) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("invalid webhook id")))
+		return db.WebhookSubscriptions{}, false
+	}
+
+	sub, err := s.store.GetWebhookSubscription(ctx, id)
+	if err == nil && sub.UserID != authPayload.UserID {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("webhook not found")))
+		} else {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return db.WebhookSubscriptions{}, false
+	}
+	return sub, true
+}
+
+// @Summary      Subscribe to secret events
+// @Description  Registers a URL that receives a signed POST for every matching event on secrets under path_prefix (the caller's own namespace by default; team folders need read access). An empty events list subscribes to every type. Each request carries X-Vaultify-Signature: sha256=HMAC-SHA256(secret, timestamp + "." + body) and X-Vaultify-Timestamp. The signing secret is only returned in this response. URLs that resolve to loopback, private, link-local or other internal addresses are refused unless they are in WEBHOOK_ALLOWED_NETWORKS, and redirects are not followed.
+// @Tags         Webhooks
+// @Accept       json
+// @Produce      json
+// @Param        request body     createWebhookRequest  true  "Receiver URL, path prefix and event types"
+// @Success      200     {object} createWebhookResponse
+// @Failure      400     {object} swaggerErrorResponse "Invalid or internal URL, prefix or event type"
+// @Failure      403     {object} swaggerErrorResponse "Prefix outside the caller's readable namespaces"
+// @Failure      500     {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /webhooks [post]
+func (s *Server) createWebhook(ctx *gin.Context) {
+	var req createWebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	if err := s.outbound.CheckURL(ctx, req.URL); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	target, err := url.Parse(req.URL)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.PathPrefix == "" {
+		req.PathPrefix = personalNamespace(authPayload.Email)
+	}
+	prefix, err := secretpath.NormalizePrefix(req.PathPrefix)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := webhook.ValidateEventTypes(req.Events); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if req.Events == nil {
+		req.Events = []string{}
+	}
+
+	allowed, err := s.canSubscribe(ctx, authPayload, prefix)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if !allowed {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("you can only subscribe to your own namespace or a team you can read")))
+		return
+	}
+
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ciphertext, nonce, err := s.encryptor.Encrypt([]byte(secret))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	var sub db.WebhookSubscriptions
+	err = s.store.ExecTx(ctx, func(q *db.Queries) error {
+		sub, err = q.CreateWebhookSubscription(ctx, db.CreateWebhookSubscriptionParams{
+			UserID:           authPayload.UserID,
+			Url:              target.String(),
+			PathPrefix:       prefix,
+			EventTypes:       req.Events,
+			SecretCiphertext: ciphertext,
+			SecretNonce:      nonce,
+		})
+		if err != nil {
+			return err
+		}
+
+		detail := "webhook to " + target.Redacted()
+		if err = s.auditSvc.LogTx(ctx, q, authPayload.UserID, authPayload.Email, "create_webhook", prefix, 0, true, &detail); err != nil {
+			return fmt.Errorf("failed to log action: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, createWebhookResponse{
+		webhookResponse: newWebhookResponse(sub),
+		Secret:          secret,
+	})
+}
+
+// @Summary      List my webhook subscriptions
+// @Tags         Webhooks
+// @Produce      json
+// @Success      200     {array}  webhookResponse
+// @Failure      500     {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /webhooks [get]
+func (s *Server) listWebhooks(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+
+	subs, err := s.store.ListWebhookSubscriptionsForUser(ctx, authPayload.UserID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	resp := make([]webhookResponse, 0, len(subs))
+	for _, sub := range subs {
+		resp = append(resp, newWebhookResponse(sub))
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// @Summary      Delete a webhook subscription
+// @Description  Stops deliveries to the subscription and discards its delivery log.
+// @Tags         Webhooks
+// @Param        id   path  string  true  "Subscription ID"
+// @Success      204
+// @Failure      404  {object} swaggerErrorResponse "Webhook not found"
+// @Failure      500  {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /webhooks/{id} [delete]
+func (s *Server) deleteWebhook(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+	sub, ok := s.ownWebhook(ctx, authPayload)
+	if !ok {
+		return
+	}
+
+	err := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		if _, err := q.DeleteWebhookSubscription(ctx, db.DeleteWebhookSubscriptionParams{
+			ID:     sub.ID,
+			UserID: authPayload.UserID,
+		}); err != nil {
+			return err
+		}
+
+		detail := "webhook " + sub.ID.String()
+		if err := s.auditSvc.LogTx(ctx, q, authPayload.UserID, authPayload.Email, "delete_webhook", sub.PathPrefix, 0, true, &detail); err != nil {
+			return fmt.Errorf("failed to log action: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// @Summary      Webhook delivery log
+// @Description  Lists the most recent deliveries of the subscription with their status, attempts and last response.
+// @Tags         Webhooks
+// @Produce      json
+// @Param        id     path     string  true   "Subscription ID"
+// @Param        limit  query    int     false  "Maximum number of deliveries (default 50, max 200)"
+// @Success      200    {array}  webhookDeliveryResponse
+// @Failure      404    {object} swaggerErrorResponse "Webhook not found"
+// @Failure      500    {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /webhooks/{id}/deliveries [get]
+func (s *Server) listWebhookDeliveries(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+	sub, ok := s.ownWebhook(ctx, authPayload)
+	if !ok {
+		return
+	}
+
+	limit := defaultDeliveryLimit
+	if raw := ctx.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("invalid limit")))
+			return
+		}
+		limit = min(n, maxDeliveryLimit)
+	}
+
+	deliveries, err := s.store.ListWebhookDeliveries(ctx, db.ListWebhookDeliveriesParams{
+		SubscriptionID: sub.ID,
+		Limit:          int32(limit),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	resp := make([]webhookDeliveryResponse, 0, len(deliveries))
+	for _, d := range deliveries {
+		resp = append(resp, newWebhookDeliveryResponse(d))
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// @Summary      Replay a webhook delivery
+// @Description  Queues a new delivery with the same payload, e.g. after a receiver outage. The payload keeps its event id so receivers can deduplicate. The caller must still be able to read the path the event is about.
+// @Tags         Webhooks
+// @Produce      json
+// @Param        id        path     string  true  "Subscription ID"
+// @Param        delivery  path     string  true  "Delivery ID"
+// @Success      202       {object} webhookDeliveryResponse
+// @Failure      403       {object} swaggerErrorResponse "No longer allowed to read the event's path"
+// @Failure      404       {object} swaggerErrorResponse "Webhook or delivery not found"
+// @Failure      500       {object} swaggerErrorResponse
+// @Security     BearerAuth
+// @Router       /webhooks/{id}/deliveries/{delivery}/replay [post]
+func (s *Server) replayWebhookDelivery(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*auth.Payload)
+	sub, ok := s.ownWebhook(ctx, authPayload)
+	if !ok {
+		return
+	}
+
+	deliveryID, err := uuid.Parse(ctx.Param("delivery"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("invalid delivery id")))
+		return
+	}
+
+	original, err := s.store.GetWebhookDelivery(ctx, db.GetWebhookDeliveryParams{
+		ID:             deliveryID,
+		SubscriptionID: sub.ID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("delivery not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	var payload webhook.Payload
+	if err := json.Unmarshal(original.Payload, &payload); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	var replay db.WebhookDeliveries
+	err = s.store.ExecTx(ctx, func(q *db.Queries) error {
+		allowed, err := s.canReceiveEvent(ctx, q, authPayload, payload.Path)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return newStatusError(http.StatusForbidden, "you can no longer read %s", payload.Path)
+		}
+
+		replay, err = q.CreateWebhookDelivery(ctx, db.CreateWebhookDeliveryParams{
+			SubscriptionID: sub.ID,
+			EventType:      original.EventType,
+			Payload:        original.Payload,
+		})
+		if err != nil {
+			return err
+		}
+
+		detail := "delivery " + original.ID.String() + " of webhook " + sub.ID.String()
+		if err := s.auditSvc.LogTx(ctx, q, authPayload.UserID, authPayload.Email, "replay_webhook_delivery", payload.Path, payload.Version, true, &detail); err != nil {
+			return fmt.Errorf("failed to log action: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, newWebhookDeliveryResponse(replay))
+}
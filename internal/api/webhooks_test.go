@@ -0,0 +1,14 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookLease(t *testing.T) {
+	require.Equal(t, webhookMinLease, webhookLease(10*time.Second))
+	// five rounds of ten workers, each waiting out a minute, twice over
+	require.Equal(t, 10*time.Minute, webhookLease(time.Minute))
+}
@@ -13,6 +13,16 @@ import (
 type Service struct {
 	store db.Store
 	log   *zap.Logger
+	hooks []Hook
+}
+
+// Hook is called for every audit entry written. Inside a transaction it receives the
+// transaction's Queries, so whatever it records commits or rolls back with the entry.
+type Hook func(ctx context.Context, q *db.Queries, entry db.AuditLogs) error
+
+// AddHook registers a hook for all subsequent audit entries
+func (a *Service) AddHook(h Hook) {
+	a.hooks = append(a.hooks, h)
 }
 
 func NewAuditService(store db.Store, env string) *Service {
@@ -30,7 +40,7 @@ func (a *Service) Log(ctx context.Context, userID uuid.UUID, email, action, reso
 	}
 
 	a.logToZap(auditLog, reason)
-	return nil
+	return a.runHooks(ctx, a.store.Queries, auditLog)
 }
 
 // LogTx writes audit logs using the given transaction Queries (inside tx)
@@ -41,6 +51,15 @@ func (a *Service) LogTx(ctx context.Context, tx *db.Queries, userID uuid.UUID, e
 	}
 
 	a.logToZap(auditLog, reason)
+	return a.runHooks(ctx, tx, auditLog)
+}
+
+func (a *Service) runHooks(ctx context.Context, q *db.Queries, auditLog db.AuditLogs) error {
+	for _, hook := range a.hooks {
+		if err := hook(ctx, q, auditLog); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
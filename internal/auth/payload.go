@@ -18,9 +18,11 @@ type Payload struct {
 	Email     string    `json:"email"`
 	IssuedAt  time.Time `json:"issued_at"`
 	ExpiredAt time.Time `json:"expired_at"`
+	// Policies limits the token to what these policies grant; empty means no limit
+	Policies []string `json:"policies,omitempty"`
 }
 
-func NewPayload(userId uuid.UUID, email string, duration time.Duration) (*Payload, error) {
+func NewPayload(userId uuid.UUID, email string, duration time.Duration, policies ...string) (*Payload, error) {
 	tokenId, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
@@ -32,6 +34,7 @@ func NewPayload(userId uuid.UUID, email string, duration time.Duration) (*Payloa
 		Email:     email,
 		IssuedAt:  time.Now(),
 		ExpiredAt: time.Now().Add(duration),
+		Policies:  policies,
 	}
 
 	return payload, nil
@@ -7,6 +7,7 @@ import (
 )
 
 type TokenMaker interface {
-	CreateToken(userId uuid.UUID, email string, duration time.Duration) (string, error)
+	// CreateToken issues a token for the user, limited to the named policies when any are given
+	CreateToken(userId uuid.UUID, email string, duration time.Duration, policies ...string) (string, error)
 	VerifyToken(token string) (*Payload, error)
 }
@@ -0,0 +1,8 @@
+package cache
+
+import "time"
+
+// SetClock replaces the time source, letting tests expire entries without sleeping
+func (c *LRU[K, V]) SetClock(now func() time.Time) {
+	c.now = now
+}
@@ -0,0 +1,152 @@
+// Package cache provides a bounded, expiring LRU map used to keep hot database rows in memory.
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stats counts cache activity since the cache was created
+type Stats struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+	Size      int    `json:"size"`
+	Capacity  int    `json:"capacity"`
+}
+
+type entry[K comparable, V any] struct {
+	key     K
+	value   V
+	expires time.Time
+}
+
+// LRU is a fixed-capacity map that evicts the least recently used entry when full and treats
+// entries older than its TTL as missing. It is safe for concurrent use. A capacity of zero
+// disables caching: every Get misses and Set is a no-op.
+type LRU[K comparable, V any] struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	order    *list.List
+	items    map[K]*list.Element
+	now      func() time.Time
+
+	hits, misses, evictions atomic.Uint64
+}
+
+func NewLRU[K comparable, V any](capacity int, ttl time.Duration) *LRU[K, V] {
+	return &LRU[K, V]{
+		capacity: max(capacity, 0),
+		ttl:      ttl,
+		order:    list.New(),
+		items:    make(map[K]*list.Element),
+		now:      time.Now,
+	}
+}
+
+// Get returns the value cached for key, if present and not expired
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*entry[K, V])
+		if c.now().Before(e.expires) {
+			c.order.MoveToFront(el)
+			c.hits.Add(1)
+			return e.value, true
+		}
+		c.removeElement(el)
+	}
+
+	c.misses.Add(1)
+	var zero V
+	return zero, false
+}
+
+// Set stores value for key, evicting the least recently used entry if the cache is full
+func (c *LRU[K, V]) Set(key K, value V) {
+	c.SetUntil(key, value, time.Time{})
+}
+
+// SetUntil is Set for a value that stops being valid at until, if that comes before the TTL.
+// A zero until means only the TTL applies.
+func (c *LRU[K, V]) SetUntil(key K, value V, until time.Time) {
+	if c.capacity == 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := c.now().Add(c.ttl)
+	if !until.IsZero() && until.Before(expires) {
+		expires = until
+	}
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*entry[K, V])
+		e.value, e.expires = value, expires
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&entry[K, V]{key: key, value: value, expires: expires})
+	if c.order.Len() > c.capacity {
+		c.removeElement(c.order.Back())
+		c.evictions.Add(1)
+	}
+}
+
+// Delete removes key from the cache
+func (c *LRU[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+}
+
+// DeleteFunc removes every entry whose key matches
+func (c *LRU[K, V]) DeleteFunc(match func(K) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, el := range c.items {
+		if match(key) {
+			c.removeElement(el)
+		}
+	}
+}
+
+// Purge removes every entry
+func (c *LRU[K, V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	clear(c.items)
+}
+
+func (c *LRU[K, V]) Stats() Stats {
+	c.mu.Lock()
+	size := c.order.Len()
+	c.mu.Unlock()
+
+	return Stats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+		Size:      size,
+		Capacity:  c.capacity,
+	}
+}
+
+// removeElement must be called with c.mu held
+func (c *LRU[K, V]) removeElement(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.items, el.Value.(*entry[K, V]).key)
+}
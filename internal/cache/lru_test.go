@@ -0,0 +1,94 @@
+package cache_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pixperk/vaultify/internal/cache"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := cache.NewLRU[string, int](2, time.Minute)
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	_, ok := c.Get("a")
+	require.True(t, ok)
+
+	// b is now the least recently used entry
+	c.Set("c", 3)
+	_, ok = c.Get("b")
+	require.False(t, ok)
+
+	v, ok := c.Get("a")
+	require.True(t, ok)
+	require.Equal(t, 1, v)
+
+	stats := c.Stats()
+	require.EqualValues(t, 2, stats.Hits)
+	require.EqualValues(t, 1, stats.Misses)
+	require.EqualValues(t, 1, stats.Evictions)
+	require.Equal(t, 2, stats.Size)
+}
+
+func TestLRUExpiresEntries(t *testing.T) {
+	now := time.Now()
+	c := cache.NewLRU[string, int](10, time.Minute)
+	c.SetClock(func() time.Time { return now })
+
+	c.Set("a", 1)
+	now = now.Add(59 * time.Second)
+	_, ok := c.Get("a")
+	require.True(t, ok)
+
+	now = now.Add(2 * time.Second)
+	_, ok = c.Get("a")
+	require.False(t, ok)
+	require.Zero(t, c.Stats().Size)
+}
+
+func TestLRUSetUntil(t *testing.T) {
+	now := time.Now()
+	c := cache.NewLRU[string, int](10, time.Minute)
+	c.SetClock(func() time.Time { return now })
+
+	c.SetUntil("short", 1, now.Add(10*time.Second))
+	c.SetUntil("long", 2, now.Add(time.Hour))
+
+	now = now.Add(11 * time.Second)
+	_, ok := c.Get("short")
+	require.False(t, ok)
+	_, ok = c.Get("long")
+	require.True(t, ok)
+
+	// the TTL still bounds values valid for longer
+	now = now.Add(time.Minute)
+	_, ok = c.Get("long")
+	require.False(t, ok)
+}
+
+func TestLRUDelete(t *testing.T) {
+	c := cache.NewLRU[string, int](10, time.Minute)
+	c.Set("team/db/a", 1)
+	c.Set("team/db/b", 2)
+	c.Set("team/web/a", 3)
+
+	c.Delete("team/db/a")
+	c.DeleteFunc(func(k string) bool { return strings.HasPrefix(k, "team/web/") })
+
+	require.Equal(t, 1, c.Stats().Size)
+	_, ok := c.Get("team/db/b")
+	require.True(t, ok)
+
+	c.Purge()
+	require.Zero(t, c.Stats().Size)
+}
+
+func TestLRUDisabled(t *testing.T) {
+	c := cache.NewLRU[string, int](0, time.Minute)
+	c.Set("a", 1)
+	_, ok := c.Get("a")
+	require.False(t, ok)
+}
@@ -1,6 +1,10 @@
 package config
 
 import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -8,6 +12,7 @@ import (
 
 type Config struct {
 	Port                    string `mapstructure:"PORT"`
+	GRPCPort                string `mapstructure:"GRPC_PORT"`
 	Env                     string `mapstructure:"ENV"`
 	DbHost                  string `mapstructure:"DB_HOST"`
 	DbPort                  string `mapstructure:"DB_PORT"`
@@ -22,6 +27,25 @@ type Config struct {
 	RedisAddr               string        `mapstructure:"REDIS_ADDR"`
 	RateLimitTokens         int           `mapstructure:"RATE_LIMIT_TOKENS"`
 	RateLimitRefill         float64       `mapstructure:"RATE_LIMIT_REFILL"`
+	Environments            string        `mapstructure:"ENVIRONMENTS"`
+	PromotionOrder          []string
+	RotationCheckInterval   time.Duration `mapstructure:"ROTATION_CHECK_INTERVAL"`
+	RotationHooksDir        string        `mapstructure:"ROTATION_HOOKS_DIR"`
+	RotationHookTimeout     time.Duration `mapstructure:"ROTATION_HOOK_TIMEOUT"`
+	ExpiryWarnings          string        `mapstructure:"EXPIRY_WARNINGS"`
+	ExpiryWarningThresholds []time.Duration
+	NotifySMTPAddr          string        `mapstructure:"NOTIFY_SMTP_ADDR"`
+	NotifySMTPFrom          string        `mapstructure:"NOTIFY_SMTP_FROM"`
+	NotifySMTPUsername      string        `mapstructure:"NOTIFY_SMTP_USERNAME"`
+	NotifySMTPPassword      string        `mapstructure:"NOTIFY_SMTP_PASSWORD"`
+	NotifyWebhookURL        string        `mapstructure:"NOTIFY_WEBHOOK_URL"`
+	WebhookDeliveryInterval time.Duration `mapstructure:"WEBHOOK_DELIVERY_INTERVAL"`
+	WebhookTimeout          time.Duration `mapstructure:"WEBHOOK_TIMEOUT"`
+	WebhookAllowedNetworks  string        `mapstructure:"WEBHOOK_ALLOWED_NETWORKS"`
+	CacheSize               int           `mapstructure:"CACHE_SIZE"`
+	CacheTTL                time.Duration `mapstructure:"CACHE_TTL"`
+	PolicyDir               string        `mapstructure:"POLICY_DIR"`
+	BootstrapAdminEmail     string        `mapstructure:"BOOTSTRAP_ADMIN_EMAIL"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
@@ -44,6 +68,79 @@ func LoadConfig(path string) (config Config, err error) {
 	config.DBSource = "postgres://" + config.DbUser + ":" + config.DbPass + "@" +
 		config.DbHost + ":" + config.DbPort + "/" + config.DbName + "?sslmode=disable"
 
+	// environments are listed in promotion order, e.g. dev,staging,prod
+	for _, env := range strings.Split(config.Environments, ",") {
+		if env = strings.TrimSpace(env); env != "" {
+			config.PromotionOrder = append(config.PromotionOrder, env)
+		}
+	}
+	if len(config.PromotionOrder) == 0 {
+		config.PromotionOrder = []string{"dev", "staging", "prod"}
+	}
+
+	if config.RotationCheckInterval <= 0 {
+		config.RotationCheckInterval = time.Minute
+	}
+	if config.RotationHookTimeout <= 0 {
+		config.RotationHookTimeout = 30 * time.Second
+	}
+
+	if config.WebhookDeliveryInterval <= 0 {
+		config.WebhookDeliveryInterval = 10 * time.Second
+	}
+	if config.WebhookTimeout <= 0 {
+		config.WebhookTimeout = 10 * time.Second
+	}
+
+	// a negative CACHE_SIZE disables the read cache
+	if config.CacheSize == 0 {
+		config.CacheSize = 10000
+	}
+	if config.CacheTTL <= 0 {
+		config.CacheTTL = 30 * time.Second
+	}
+
+	// warnings are sent this long before a secret or share expires, e.g. 7d,1d
+	if config.ExpiryWarnings == "" {
+		config.ExpiryWarnings = "7d,1d"
+	}
+	config.ExpiryWarningThresholds, err = parseThresholds(config.ExpiryWarnings)
+	if err != nil {
+		return
+	}
+
 	return config, nil
 
 }
+
+// parseThresholds parses a comma separated list of durations, longest first
+func parseThresholds(list string) ([]time.Duration, error) {
+	var thresholds []time.Duration
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		d, err := ParseDuration(item)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid EXPIRY_WARNINGS entry %q", item)
+		}
+		thresholds = append(thresholds, d)
+	}
+
+	sort.Slice(thresholds, func(i, j int) bool { return thresholds[i] > thresholds[j] })
+	return thresholds, nil
+}
+
+// ParseDuration is time.ParseDuration with support for whole days, e.g. 7d
+func ParseDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_secrets_team_id;
+ALTER TABLE secrets DROP COLUMN IF EXISTS team_id;
+
+DROP TABLE IF EXISTS team_members;
+DROP TABLE IF EXISTS teams;
+DROP TABLE IF EXISTS org_members;
+DROP TABLE IF EXISTS organizations;
@@ -0,0 +1,11 @@
+-- only the default environment survives a downgrade
+DELETE FROM secret_versions WHERE environment != 'default';
+
+ALTER TABLE secret_versions
+DROP CONSTRAINT IF EXISTS secret_versions_secret_id_environment_version_key;
+
+ALTER TABLE secret_versions
+ADD CONSTRAINT secret_versions_secret_id_version_key UNIQUE (secret_id, version);
+
+ALTER TABLE secret_versions
+DROP COLUMN IF EXISTS environment;
@@ -0,0 +1,9 @@
+-- every version belongs to one environment of its secret; versions are numbered per environment
+ALTER TABLE secret_versions
+ADD COLUMN IF NOT EXISTS environment TEXT NOT NULL DEFAULT 'default';
+
+ALTER TABLE secret_versions
+DROP CONSTRAINT IF EXISTS secret_versions_secret_id_version_key;
+
+ALTER TABLE secret_versions
+ADD CONSTRAINT secret_versions_secret_id_environment_version_key UNIQUE (secret_id, environment, version);
@@ -0,0 +1,5 @@
+ALTER TABLE sharing_rules
+DROP CONSTRAINT IF EXISTS sharing_rules_path_canonical;
+
+ALTER TABLE secrets
+DROP CONSTRAINT IF EXISTS secrets_path_canonical;
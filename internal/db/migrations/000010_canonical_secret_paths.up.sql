@@ -0,0 +1,48 @@
+-- lowercase existing paths unless that would collide with another secret
+UPDATE secrets s
+SET path = lower(s.path)
+WHERE s.path <> lower(s.path)
+  AND NOT EXISTS (SELECT 1 FROM secrets o WHERE o.path = lower(s.path));
+
+UPDATE sharing_rules r
+SET path = lower(r.path)
+WHERE r.path <> lower(r.path)
+  AND EXISTS (SELECT 1 FROM secrets s WHERE s.path = lower(r.path));
+
+-- shares of paths without a secret grant nothing
+DELETE FROM sharing_rules r
+WHERE NOT EXISTS (SELECT 1 FROM secrets s WHERE s.path = r.path);
+
+-- a legacy path that is still not canonical, e.g. because its lowercase form is taken, could
+-- never be looked up again, so the migration stops instead. Rename the listed secrets (and
+-- their sharing_rules) by hand, run "migrate force 9" and migrate up again.
+DO $$
+DECLARE
+    invalid TEXT;
+BEGIN
+    SELECT string_agg(path, ', ' ORDER BY path) INTO invalid
+    FROM secrets
+    WHERE NOT (
+        length(path) <= 512
+        AND path ~ '^[a-z0-9._@+-]+(/[a-z0-9._@+-]+)*$'
+        AND path !~ '(^|/)\.\.?(/|$)'
+    );
+    IF invalid IS NOT NULL THEN
+        RAISE EXCEPTION 'secret paths are not canonical and must be renamed first: %', invalid;
+    END IF;
+END $$;
+
+-- paths must be in the canonical form produced by internal/secretpath
+ALTER TABLE secrets
+ADD CONSTRAINT secrets_path_canonical CHECK (
+    length(path) <= 512
+    AND path ~ '^[a-z0-9._@+-]+(/[a-z0-9._@+-]+)*$'
+    AND path !~ '(^|/)\.\.?(/|$)'
+);
+
+ALTER TABLE sharing_rules
+ADD CONSTRAINT sharing_rules_path_canonical CHECK (
+    length(path) <= 512
+    AND path ~ '^[a-z0-9._@+-]+(/[a-z0-9._@+-]+)*$'
+    AND path !~ '(^|/)\.\.?(/|$)'
+);
@@ -0,0 +1,24 @@
+CREATE TABLE rotation_policies (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  secret_id UUID NOT NULL REFERENCES secrets(id) ON DELETE CASCADE,
+  environment TEXT NOT NULL DEFAULT 'default',
+  interval_seconds BIGINT,
+  cron_expr TEXT,
+  generator TEXT CHECK (generator IN ('password', 'webhook', 'exec')) NOT NULL,
+  password_length INT NOT NULL DEFAULT 32,
+  password_symbols BOOLEAN NOT NULL DEFAULT false,
+  hook_target TEXT,
+  next_rotation_at TIMESTAMPTZ NOT NULL,
+  last_rotated_at TIMESTAMPTZ,
+  last_error TEXT,
+  failure_count INT NOT NULL DEFAULT 0,
+  created_by UUID NOT NULL REFERENCES users(id),
+  created_at TIMESTAMPTZ DEFAULT now(),
+  UNIQUE (secret_id, environment),
+  -- a policy runs either on a fixed interval or on a cron schedule
+  CHECK ((interval_seconds IS NULL) <> (cron_expr IS NULL)),
+  -- hooks need a URL or an executable name, generated passwords do not
+  CHECK ((generator = 'password') = (hook_target IS NULL))
+);
+
+CREATE INDEX idx_rotation_policies_next_rotation_at ON rotation_policies(next_rotation_at);
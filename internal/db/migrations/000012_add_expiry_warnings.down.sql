@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS sharing_rules_shared_until_idx;
+DROP TABLE IF EXISTS expiry_warnings_sent;
@@ -0,0 +1,12 @@
+-- one row per warning already delivered, so restarts and multiple instances do not repeat them.
+-- expires_at is part of the key: extending a TTL re-arms the warnings for the new deadline.
+CREATE TABLE expiry_warnings_sent (
+  resource_type TEXT CHECK (resource_type IN ('secret', 'share')) NOT NULL,
+  resource_id UUID NOT NULL,
+  threshold_seconds BIGINT NOT NULL,
+  expires_at TIMESTAMPTZ NOT NULL,
+  sent_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (resource_type, resource_id, threshold_seconds, expires_at)
+);
+
+CREATE INDEX IF NOT EXISTS sharing_rules_shared_until_idx ON sharing_rules (shared_until);
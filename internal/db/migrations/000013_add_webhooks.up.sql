@@ -0,0 +1,32 @@
+CREATE TABLE webhook_subscriptions (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  url TEXT NOT NULL,
+  -- events are delivered for secrets under this folder, e.g. alice@example.com/ or org/acme/payments/
+  path_prefix TEXT NOT NULL,
+  -- an empty list subscribes to every event type
+  event_types TEXT[] NOT NULL DEFAULT '{}',
+  -- the signing secret is stored encrypted like secret values
+  secret_ciphertext BYTEA NOT NULL,
+  secret_nonce BYTEA NOT NULL,
+  created_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE INDEX idx_webhook_subscriptions_user_id ON webhook_subscriptions(user_id);
+
+CREATE TABLE webhook_deliveries (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  subscription_id UUID NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+  event_type TEXT NOT NULL,
+  payload JSONB NOT NULL,
+  status TEXT CHECK (status IN ('pending', 'succeeded', 'failed')) NOT NULL DEFAULT 'pending',
+  attempts INT NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  last_status_code INT,
+  last_error TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  delivered_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_webhook_deliveries_subscription ON webhook_deliveries(subscription_id, created_at);
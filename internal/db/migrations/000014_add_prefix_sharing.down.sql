@@ -0,0 +1,7 @@
+DELETE FROM sharing_rules WHERE is_prefix;
+
+DROP INDEX IF EXISTS idx_sharing_lookup;
+CREATE INDEX idx_sharing_lookup ON sharing_rules(target_email, path);
+
+ALTER TABLE sharing_rules
+DROP COLUMN IF EXISTS is_prefix;
@@ -0,0 +1,9 @@
+-- a prefix rule shares every secret below path, including secrets created later. path stays
+-- canonical (no trailing slash or wildcard); the API shows such rules as path/*.
+ALTER TABLE sharing_rules
+ADD COLUMN is_prefix BOOLEAN NOT NULL DEFAULT false;
+
+-- access checks look up the rules of one user on a secret path and all of its parent folders
+-- with path = ANY(...), so a lookup costs one index probe per path segment
+DROP INDEX IF EXISTS idx_sharing_lookup;
+CREATE INDEX idx_sharing_lookup ON sharing_rules(target_email, path, is_prefix);
@@ -0,0 +1,9 @@
+DELETE FROM sharing_rules WHERE target_group_id IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_sharing_group_lookup;
+
+ALTER TABLE sharing_rules
+DROP COLUMN IF EXISTS target_group_id;
+
+DROP TABLE IF EXISTS group_members;
+DROP TABLE IF EXISTS groups;
@@ -0,0 +1,26 @@
+-- groups are sets of users that secrets can be shared with, independent of organizations
+CREATE TABLE groups (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  slug TEXT NOT NULL UNIQUE,
+  name TEXT NOT NULL,
+  created_by UUID NOT NULL REFERENCES users(id),
+  created_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE TABLE group_members (
+  group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  role TEXT CHECK (role IN ('owner', 'member')) NOT NULL,
+  created_at TIMESTAMPTZ DEFAULT now(),
+  PRIMARY KEY (group_id, user_id)
+);
+
+CREATE INDEX idx_group_members_user_id ON group_members(user_id);
+
+-- a share with a group has target_group_id set and target_email = 'group:<slug>', so rules
+-- stay unique per target and lookups by target keep working for both kinds
+ALTER TABLE sharing_rules
+ADD COLUMN target_group_id UUID REFERENCES groups(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_sharing_group_lookup ON sharing_rules(target_group_id, path, is_prefix)
+WHERE target_group_id IS NOT NULL;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_users_role;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS role;
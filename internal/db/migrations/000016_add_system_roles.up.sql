@@ -0,0 +1,4 @@
+ALTER TABLE users
+ADD COLUMN role TEXT NOT NULL DEFAULT 'user' CHECK (role IN ('user', 'auditor', 'admin'));
+
+CREATE INDEX idx_users_role ON users(role) WHERE role <> 'user';
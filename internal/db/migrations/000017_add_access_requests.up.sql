@@ -0,0 +1,24 @@
+-- a request by a user for a share of a secret or folder they cannot reach; approving one
+-- creates a sharing rule that expires after duration_secs
+CREATE TABLE access_requests (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  requester_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  requester_email TEXT NOT NULL,
+  path TEXT NOT NULL,
+  is_prefix BOOLEAN NOT NULL DEFAULT FALSE,
+  permission TEXT CHECK (permission IN ('read', 'write')) NOT NULL,
+  justification TEXT NOT NULL,
+  duration_secs BIGINT NOT NULL CHECK (duration_secs > 0),
+  status TEXT CHECK (status IN ('pending', 'approved', 'denied', 'cancelled')) NOT NULL DEFAULT 'pending',
+  decided_by TEXT,
+  decision_reason TEXT,
+  decided_at TIMESTAMPTZ,
+  sharing_rule_id UUID REFERENCES sharing_rules(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- one open request per user and path
+CREATE UNIQUE INDEX idx_access_requests_pending ON access_requests(requester_id, path, is_prefix)
+WHERE status = 'pending';
+
+CREATE INDEX idx_access_requests_requester ON access_requests(requester_id, created_at DESC);
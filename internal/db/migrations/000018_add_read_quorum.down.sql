@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS quorum_approvals;
+DROP TABLE IF EXISTS quorum_requests;
+DROP TABLE IF EXISTS secret_quorums;
@@ -0,0 +1,38 @@
+-- secrets under the two-person rule: every read needs required_approvals other users who can
+-- read the secret to approve it within window_secs, and then grants one read within read_ttl_secs
+CREATE TABLE secret_quorums (
+  secret_id UUID PRIMARY KEY REFERENCES secrets(id) ON DELETE CASCADE,
+  required_approvals INT NOT NULL CHECK (required_approvals BETWEEN 1 AND 10),
+  window_secs BIGINT NOT NULL CHECK (window_secs > 0),
+  read_ttl_secs BIGINT NOT NULL CHECK (read_ttl_secs > 0),
+  created_by UUID NOT NULL REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE quorum_requests (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  secret_id UUID NOT NULL REFERENCES secrets(id) ON DELETE CASCADE,
+  environment TEXT NOT NULL DEFAULT 'default',
+  requester_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  requester_email TEXT NOT NULL,
+  required_approvals INT NOT NULL,
+  read_ttl_secs BIGINT NOT NULL,
+  -- pending until enough approvals arrive, granted until the read, consumed after it
+  status TEXT CHECK (status IN ('pending', 'granted', 'consumed')) NOT NULL DEFAULT 'pending',
+  -- approvals must arrive before expires_at, the read before granted_until
+  expires_at TIMESTAMPTZ NOT NULL,
+  granted_until TIMESTAMPTZ,
+  consumed_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_quorum_requests_requester ON quorum_requests(requester_id, secret_id, environment)
+WHERE status IN ('pending', 'granted');
+
+CREATE TABLE quorum_approvals (
+  request_id UUID NOT NULL REFERENCES quorum_requests(id) ON DELETE CASCADE,
+  approver_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  approver_email TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (request_id, approver_id)
+);
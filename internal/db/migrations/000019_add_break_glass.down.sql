@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_audit_logs_break_glass;
+
+ALTER TABLE audit_logs
+DROP COLUMN IF EXISTS break_glass_session_id;
+
+DROP TABLE IF EXISTS break_glass_sessions;
+DROP TABLE IF EXISTS break_glass_rules;
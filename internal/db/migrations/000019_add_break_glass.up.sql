@@ -0,0 +1,37 @@
+-- secrets, or folders of secrets, that members of an emergency group may open for themselves
+-- during an incident, with read access for at most max_duration_secs
+CREATE TABLE break_glass_rules (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  path TEXT NOT NULL,
+  is_prefix BOOLEAN NOT NULL DEFAULT FALSE,
+  group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+  max_duration_secs BIGINT NOT NULL CHECK (max_duration_secs > 0),
+  created_by UUID NOT NULL REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (path, is_prefix)
+);
+
+-- each use of break-glass access; it awaits review until an admin or auditor signs it off
+CREATE TABLE break_glass_sessions (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  rule_id UUID REFERENCES break_glass_rules(id) ON DELETE SET NULL,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  user_email TEXT NOT NULL,
+  path TEXT NOT NULL,
+  reason TEXT NOT NULL,
+  expires_at TIMESTAMPTZ NOT NULL,
+  reviewed_by TEXT,
+  review_notes TEXT,
+  reviewed_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_break_glass_sessions_user ON break_glass_sessions(user_id, expires_at);
+CREATE INDEX idx_break_glass_sessions_unreviewed ON break_glass_sessions(created_at)
+WHERE reviewed_at IS NULL;
+
+-- audit entries written during a session are flagged with it for the post-incident review
+ALTER TABLE audit_logs ADD COLUMN break_glass_session_id UUID REFERENCES break_glass_sessions(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_audit_logs_break_glass ON audit_logs(break_glass_session_id)
+WHERE break_glass_session_id IS NOT NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE rotation_policies
+DROP COLUMN IF EXISTS lease_expires_at,
+DROP COLUMN IF EXISTS lease_id;
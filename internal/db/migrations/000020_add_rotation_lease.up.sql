@@ -0,0 +1,5 @@
+-- a rotation leases its policy while the hook runs, outside of any transaction; the new version
+-- is only written if the lease is still held
+ALTER TABLE rotation_policies
+ADD COLUMN lease_id UUID,
+ADD COLUMN lease_expires_at TIMESTAMPTZ;
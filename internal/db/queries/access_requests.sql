@@ -0,0 +1,63 @@
+-- name: CreateAccessRequest :one
+INSERT INTO access_requests (requester_id, requester_email, path, is_prefix, permission, justification, duration_secs)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetAccessRequest :one
+SELECT * FROM access_requests
+WHERE id = $1;
+
+-- name: ListAccessRequestsByRequester :many
+SELECT * FROM access_requests
+WHERE requester_id = sqlc.arg(requester_id)
+AND (sqlc.arg(status)::TEXT = '' OR status = sqlc.arg(status))
+ORDER BY created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListPendingAccessRequestsForApprover :many
+-- pending requests of other users on paths the approver may hold share on: below their own
+-- namespace, the namespace of a team they maintain, or one of the given policy prefixes. The
+-- authorizer still has the final say on each of them.
+SELECT ar.* FROM access_requests ar
+WHERE ar.status = 'pending' AND ar.requester_id <> sqlc.arg(approver_id)
+AND (
+    starts_with(ar.path || '/', sqlc.arg(namespace)::TEXT)
+    OR EXISTS (
+        SELECT 1 FROM unnest(sqlc.arg(prefixes)::TEXT[]) AS prefix
+        WHERE starts_with(ar.path || '/', prefix)
+    )
+    OR EXISTS (
+        SELECT 1 FROM teams t
+        JOIN organizations o ON o.id = t.org_id
+        JOIN org_members om ON om.org_id = t.org_id AND om.user_id = sqlc.arg(approver_id)
+        LEFT JOIN team_members tm ON tm.team_id = t.id AND tm.user_id = sqlc.arg(approver_id)
+        WHERE (om.role IN ('owner', 'admin') OR tm.role = 'maintainer')
+        AND starts_with(ar.path || '/', 'org/' || o.slug || '/' || t.slug || '/')
+    )
+)
+ORDER BY ar.created_at
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: DecideAccessRequest :one
+-- only a pending request can be decided, so concurrent decisions cannot both succeed
+UPDATE access_requests
+SET status = sqlc.arg(status),
+    decided_by = sqlc.arg(decided_by),
+    decision_reason = sqlc.narg(decision_reason),
+    decided_at = NOW(),
+    sharing_rule_id = sqlc.narg(sharing_rule_id),
+    duration_secs = COALESCE(sqlc.narg(duration_secs), duration_secs)
+WHERE id = sqlc.arg(id) AND status = 'pending'
+RETURNING *;
+
+-- name: MovePendingAccessRequests :execrows
+UPDATE access_requests
+SET path = sqlc.arg(new_path)
+WHERE path = sqlc.arg(old_path) AND NOT is_prefix AND status = 'pending';
+
+-- name: MovePendingPrefixAccessRequests :execrows
+-- pending requests for old_prefix and for every folder below it follow the folder to new_prefix
+UPDATE access_requests
+SET path = sqlc.arg(new_prefix)::TEXT || substr(path, length(sqlc.arg(old_prefix)::TEXT) + 1)
+WHERE is_prefix AND status = 'pending'
+AND (path = sqlc.arg(old_prefix)::TEXT OR starts_with(path, sqlc.arg(old_prefix)::TEXT || '/'));
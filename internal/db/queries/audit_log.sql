@@ -16,15 +16,16 @@ RETURNING *;
 -- name: FilterAuditLogs :many
 SELECT * FROM audit_logs
 WHERE
-  (user_email = $1 OR $1 = '')
-  AND (resource_version = $2 OR $2 = 0)
-  AND (action = $3 OR $3 = '')
-  AND (created_at >= $4 OR $4 IS NULL)
-  AND (created_at <= $5 OR $5 IS NULL)
-  AND (resource_path = $6 OR $6 = '')
-  AND (success = $7 OR $7 IS NULL)
+  (user_email = sqlc.arg(user_email) OR sqlc.arg(user_email) = '')
+  AND (resource_version = sqlc.arg(resource_version) OR sqlc.arg(resource_version) = 0)
+  AND (action = sqlc.arg(action) OR sqlc.arg(action) = '')
+  AND (created_at >= sqlc.narg(created_at) OR sqlc.narg(created_at) IS NULL)
+  AND (created_at <= sqlc.narg(created_at_2) OR sqlc.narg(created_at_2) IS NULL)
+  AND (resource_path = sqlc.arg(resource_path) OR sqlc.arg(resource_path) = '')
+  AND (success = sqlc.narg(success) OR sqlc.narg(success) IS NULL)
+  AND ((break_glass_session_id IS NOT NULL) = sqlc.narg(flagged)::BOOLEAN OR sqlc.narg(flagged) IS NULL)
 ORDER BY created_at DESC
-LIMIT $8 OFFSET $9;
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
 
 
 
@@ -0,0 +1,83 @@
+-- name: UpsertBreakGlassRule :one
+INSERT INTO break_glass_rules (path, is_prefix, group_id, max_duration_secs, created_by)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (path, is_prefix) DO UPDATE
+SET group_id = EXCLUDED.group_id,
+    max_duration_secs = EXCLUDED.max_duration_secs,
+    created_by = EXCLUDED.created_by
+RETURNING *;
+
+-- name: DeleteBreakGlassRule :execrows
+DELETE FROM break_glass_rules
+WHERE path = $1 AND is_prefix = $2;
+
+-- name: MoveBreakGlassRule :execrows
+UPDATE break_glass_rules
+SET path = sqlc.arg(new_path)
+WHERE path = sqlc.arg(old_path) AND NOT is_prefix;
+
+-- name: MovePrefixBreakGlassRules :execrows
+-- folder rules on old_prefix and on every folder below it follow the folder to new_prefix
+UPDATE break_glass_rules
+SET path = sqlc.arg(new_prefix)::TEXT || substr(path, length(sqlc.arg(old_prefix)::TEXT) + 1)
+WHERE is_prefix AND (path = sqlc.arg(old_prefix)::TEXT OR starts_with(path, sqlc.arg(old_prefix)::TEXT || '/'));
+
+-- name: GetBreakGlassRuleForPath :one
+-- the rule for the secret itself, or else the one of the closest folder above it in parents
+SELECT r.*, g.slug AS group_slug
+FROM break_glass_rules r
+JOIN groups g ON g.id = r.group_id
+WHERE (r.path = sqlc.arg(path) AND NOT r.is_prefix) OR (r.is_prefix AND r.path = ANY(sqlc.arg(parents)::TEXT[]))
+ORDER BY r.is_prefix, length(r.path) DESC
+LIMIT 1;
+
+-- name: ListBreakGlassRulesForUser :many
+-- the rules the user set up or can use as a member of their group
+SELECT r.*, g.slug AS group_slug
+FROM break_glass_rules r
+JOIN groups g ON g.id = r.group_id
+WHERE r.created_by = sqlc.arg(user_id)
+   OR EXISTS (SELECT 1 FROM group_members gm WHERE gm.group_id = r.group_id AND gm.user_id = sqlc.arg(user_id))
+ORDER BY r.path;
+
+-- name: CreateBreakGlassSession :one
+INSERT INTO break_glass_sessions (rule_id, user_id, user_email, path, reason, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetBreakGlassSession :one
+SELECT * FROM break_glass_sessions
+WHERE id = $1;
+
+-- name: ListBreakGlassSessionsByUser :many
+SELECT * FROM break_glass_sessions
+WHERE user_id = $1
+ORDER BY created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListBreakGlassSessions :many
+-- every session, newest first, only reviewed or unreviewed ones when reviewed is set
+SELECT * FROM break_glass_sessions
+WHERE sqlc.narg(reviewed)::BOOLEAN IS NULL OR (reviewed_at IS NOT NULL) = sqlc.narg(reviewed)::BOOLEAN
+ORDER BY created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListActiveBreakGlassSessions :many
+SELECT * FROM break_glass_sessions
+WHERE user_id = $1 AND expires_at > NOW();
+
+-- name: ReviewBreakGlassSession :one
+UPDATE break_glass_sessions
+SET reviewed_by = $2, review_notes = $3, reviewed_at = NOW()
+WHERE id = $1 AND reviewed_at IS NULL
+RETURNING *;
+
+-- name: FlagAuditLog :exec
+UPDATE audit_logs
+SET break_glass_session_id = $2
+WHERE id = $1;
+
+-- name: ListAuditLogsForBreakGlassSession :many
+SELECT * FROM audit_logs
+WHERE break_glass_session_id = $1
+ORDER BY created_at;
@@ -0,0 +1,3 @@
+-- name: NotifyEvent :exec
+-- delivered to listeners when the surrounding transaction commits, and dropped if it rolls back
+SELECT pg_notify('vaultify_events', sqlc.arg(payload)::text);
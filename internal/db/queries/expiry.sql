@@ -0,0 +1,33 @@
+-- name: ListSecretsExpiringBefore :many
+SELECT s.id, s.path, s.user_id, s.team_id, s.expires_at, u.email AS owner_email
+FROM secrets s
+JOIN users u ON u.id = s.user_id
+WHERE s.expires_at > now() AND s.expires_at <= sqlc.arg(before)::timestamptz
+ORDER BY s.expires_at, s.path;
+
+-- name: ListSharesExpiringBefore :many
+SELECT id, owner_email, target_email, path, is_prefix, permission, shared_until
+FROM sharing_rules
+WHERE shared_until > now() AND shared_until <= sqlc.arg(before)::timestamptz
+ORDER BY shared_until, path;
+
+-- name: ListSharesExpiringBeforeForUser :many
+SELECT id, owner_email, target_email, path, is_prefix, permission, shared_until
+FROM sharing_rules
+WHERE shared_until > now() AND shared_until <= sqlc.arg(before)::timestamptz
+AND (owner_email = sqlc.arg(email) OR target_email = sqlc.arg(email))
+ORDER BY shared_until, path;
+
+-- name: ClaimExpiryWarning :execrows
+-- zero rows affected means the warning has already been sent
+INSERT INTO expiry_warnings_sent (resource_type, resource_id, threshold_seconds, expires_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT DO NOTHING;
+
+-- name: ReleaseExpiryWarning :exec
+DELETE FROM expiry_warnings_sent
+WHERE resource_type = $1 AND resource_id = $2 AND threshold_seconds = $3 AND expires_at = $4;
+
+-- name: DeleteStaleExpiryWarnings :exec
+DELETE FROM expiry_warnings_sent
+WHERE expires_at < now();
@@ -0,0 +1,42 @@
+-- name: CreateGroup :one
+INSERT INTO groups (slug, name, created_by)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetGroupBySlug :one
+SELECT * FROM groups WHERE slug = $1;
+
+-- name: ListGroupsForUser :many
+SELECT g.id, g.slug, g.name, gm.role, g.created_at
+FROM groups g
+JOIN group_members gm ON gm.group_id = g.id
+WHERE gm.user_id = $1
+ORDER BY g.slug;
+
+-- name: UpsertGroupMember :one
+INSERT INTO group_members (group_id, user_id, role)
+VALUES ($1, $2, $3)
+ON CONFLICT (group_id, user_id) DO UPDATE SET role = EXCLUDED.role
+RETURNING *;
+
+-- name: GetGroupMemberRole :one
+SELECT role FROM group_members
+WHERE group_id = $1 AND user_id = $2;
+
+-- name: ListGroupMembers :many
+SELECT u.email, u.name, gm.role, gm.created_at
+FROM group_members gm
+JOIN users u ON u.id = gm.user_id
+WHERE gm.group_id = $1
+ORDER BY u.email;
+
+-- name: LockGroupOwners :many
+-- locks the group's owners so that concurrent demotions see each other
+SELECT user_id FROM group_members
+WHERE group_id = $1 AND role = 'owner'
+ORDER BY user_id
+FOR UPDATE;
+
+-- name: RemoveGroupMember :execrows
+DELETE FROM group_members
+WHERE group_id = $1 AND user_id = $2;
@@ -0,0 +1,103 @@
+-- name: CreateOrganization :one
+INSERT INTO organizations (slug, name, created_by)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetOrganizationBySlug :one
+SELECT * FROM organizations WHERE slug = $1;
+
+-- name: ListOrganizationsForUser :many
+SELECT o.id, o.slug, o.name, om.role, o.created_at
+FROM organizations o
+JOIN org_members om ON om.org_id = o.id
+WHERE om.user_id = $1
+ORDER BY o.slug;
+
+-- name: UpsertOrgMember :one
+INSERT INTO org_members (org_id, user_id, role)
+VALUES ($1, $2, $3)
+ON CONFLICT (org_id, user_id) DO UPDATE SET role = EXCLUDED.role
+RETURNING *;
+
+-- name: GetOrgMemberRole :one
+SELECT role FROM org_members
+WHERE org_id = $1 AND user_id = $2;
+
+-- name: ListOrgMembers :many
+SELECT u.email, u.name, om.role, om.created_at
+FROM org_members om
+JOIN users u ON u.id = om.user_id
+WHERE om.org_id = $1
+ORDER BY u.email;
+
+-- name: LockOrgOwners :many
+-- locks the organization's owners so that concurrent demotions see each other
+SELECT user_id FROM org_members
+WHERE org_id = $1 AND role = 'owner'
+ORDER BY user_id
+FOR UPDATE;
+
+-- name: RemoveOrgMember :exec
+DELETE FROM org_members
+WHERE org_id = $1 AND user_id = $2;
+
+-- name: RemoveUserFromOrgTeams :exec
+DELETE FROM team_members
+WHERE user_id = $2
+  AND team_id IN (SELECT id FROM teams WHERE org_id = $1);
+
+-- name: CreateTeam :one
+INSERT INTO teams (org_id, slug, name)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetTeamBySlugs :one
+SELECT t.*
+FROM teams t
+JOIN organizations o ON o.id = t.org_id
+WHERE o.slug = $1 AND t.slug = $2;
+
+-- name: ListTeamsForOrg :many
+SELECT * FROM teams
+WHERE org_id = $1
+ORDER BY slug;
+
+-- name: UpsertTeamMember :one
+INSERT INTO team_members (team_id, user_id, role)
+VALUES ($1, $2, $3)
+ON CONFLICT (team_id, user_id) DO UPDATE SET role = EXCLUDED.role
+RETURNING *;
+
+-- name: RemoveTeamMember :exec
+DELETE FROM team_members
+WHERE team_id = $1 AND user_id = $2;
+
+-- name: ListTeamMembers :many
+SELECT u.email, u.name, tm.role, tm.created_at
+FROM team_members tm
+JOIN users u ON u.id = tm.user_id
+WHERE tm.team_id = $1
+ORDER BY u.email;
+
+-- name: GetTeamAccessForUser :one
+SELECT t.id AS team_id, t.org_id,
+       COALESCE(tm.role, '')::TEXT AS team_role,
+       COALESCE(om.role, '')::TEXT AS org_role
+FROM teams t
+LEFT JOIN team_members tm ON tm.team_id = t.id AND tm.user_id = $2
+LEFT JOIN org_members om ON om.org_id = t.org_id AND om.user_id = $2
+WHERE t.id = $1;
+
+-- name: ListTeamAccess :many
+-- everyone whose team access counts: team members still in the organization, and its owners and admins
+SELECT u.email,
+       COALESCE(tm.role, '')::TEXT AS team_role,
+       om.role AS org_role,
+       COALESCE(tm.created_at, om.created_at) AS created_at
+FROM teams t
+JOIN org_members om ON om.org_id = t.org_id
+JOIN users u ON u.id = om.user_id
+LEFT JOIN team_members tm ON tm.team_id = t.id AND tm.user_id = om.user_id
+WHERE t.id = $1
+  AND (tm.role IS NOT NULL OR om.role IN ('owner', 'admin'))
+ORDER BY u.email;
@@ -0,0 +1,88 @@
+-- name: UpsertSecretQuorum :one
+INSERT INTO secret_quorums (secret_id, required_approvals, window_secs, read_ttl_secs, created_by)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (secret_id) DO UPDATE
+SET required_approvals = EXCLUDED.required_approvals,
+    window_secs = EXCLUDED.window_secs,
+    read_ttl_secs = EXCLUDED.read_ttl_secs,
+    created_by = EXCLUDED.created_by
+RETURNING *;
+
+-- name: GetSecretQuorum :one
+SELECT * FROM secret_quorums
+WHERE secret_id = $1;
+
+-- name: DeleteSecretQuorum :execrows
+DELETE FROM secret_quorums
+WHERE secret_id = $1;
+
+-- name: CreateQuorumRequest :one
+INSERT INTO quorum_requests (secret_id, environment, requester_id, requester_email, required_approvals, read_ttl_secs, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetQuorumRequest :one
+SELECT * FROM quorum_requests
+WHERE id = $1;
+
+-- name: GetQuorumRequestForUpdate :one
+SELECT * FROM quorum_requests
+WHERE id = $1
+FOR UPDATE;
+
+-- name: GetOpenQuorumRequest :one
+-- the caller's request for the secret that is still waiting for approvals
+SELECT * FROM quorum_requests
+WHERE requester_id = $1 AND secret_id = $2 AND environment = $3
+AND status = 'pending' AND expires_at > NOW()
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: ConsumeQuorumGrant :one
+-- uses up one granted read of the secret; concurrent reads cannot both take the same grant
+UPDATE quorum_requests
+SET status = 'consumed', consumed_at = NOW()
+WHERE id = (
+    SELECT id FROM quorum_requests r
+    WHERE r.requester_id = $1 AND r.secret_id = $2 AND r.environment = $3
+    AND r.status = 'granted' AND r.granted_until > NOW()
+    ORDER BY r.granted_until
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: GrantQuorumRequest :one
+UPDATE quorum_requests
+SET status = 'granted', granted_until = NOW() + make_interval(secs => read_ttl_secs)
+WHERE id = $1 AND status = 'pending'
+RETURNING *;
+
+-- name: ListQuorumRequestsByRequester :many
+SELECT * FROM quorum_requests
+WHERE requester_id = $1
+ORDER BY created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListOpenQuorumRequests :many
+SELECT * FROM quorum_requests
+WHERE status = 'pending' AND expires_at > NOW()
+ORDER BY created_at;
+
+-- name: AddQuorumApproval :one
+INSERT INTO quorum_approvals (request_id, approver_id, approver_email)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: ListQuorumApprovals :many
+SELECT * FROM quorum_approvals
+WHERE request_id = $1
+ORDER BY created_at;
+
+-- name: HasQuorumGrant :one
+-- whether the user holds an approved read of the secret; ConsumeQuorumGrant uses it up
+SELECT EXISTS (
+    SELECT 1 FROM quorum_requests
+    WHERE requester_id = $1 AND secret_id = $2 AND environment = $3
+    AND status = 'granted' AND granted_until > NOW()
+);
@@ -0,0 +1,60 @@
+-- name: UpsertRotationPolicy :one
+INSERT INTO rotation_policies (
+  secret_id, environment, interval_seconds, cron_expr, generator,
+  password_length, password_symbols, hook_target, next_rotation_at, created_by
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (secret_id, environment) DO UPDATE
+SET interval_seconds = EXCLUDED.interval_seconds,
+    cron_expr = EXCLUDED.cron_expr,
+    generator = EXCLUDED.generator,
+    password_length = EXCLUDED.password_length,
+    password_symbols = EXCLUDED.password_symbols,
+    hook_target = EXCLUDED.hook_target,
+    next_rotation_at = EXCLUDED.next_rotation_at,
+    created_by = EXCLUDED.created_by,
+    last_error = NULL,
+    failure_count = 0,
+    -- a rotation running on the old policy must not write its value
+    lease_id = NULL,
+    lease_expires_at = NULL
+RETURNING *;
+
+-- name: GetRotationPolicy :one
+SELECT * FROM rotation_policies
+WHERE secret_id = $1 AND environment = $2;
+
+-- name: DeleteRotationPolicy :exec
+DELETE FROM rotation_policies
+WHERE secret_id = $1 AND environment = $2;
+
+-- name: ListDueRotationPolicies :many
+SELECT id FROM rotation_policies
+WHERE next_rotation_at <= now()
+ORDER BY next_rotation_at
+LIMIT $1;
+
+-- name: LeaseRotationPolicy :one
+-- leases the policy to one rotation so the hook can run outside of any transaction; a policy
+-- leased elsewhere is skipped until its lease runs out, which lets several instances share the work
+UPDATE rotation_policies p
+SET lease_id = gen_random_uuid(),
+    lease_expires_at = now() + make_interval(secs => sqlc.arg(lease_seconds)::int)
+FROM secrets s, users u
+WHERE p.id = sqlc.arg(id) AND s.id = p.secret_id AND u.id = p.created_by
+AND (p.lease_expires_at IS NULL OR p.lease_expires_at <= now())
+AND (NOT sqlc.arg(due_only)::boolean OR p.next_rotation_at <= now())
+RETURNING p.*, s.path, u.email AS owner_email;
+
+-- name: MarkRotationSucceeded :execrows
+-- only while the lease is held, so no row means the rotation lost its policy
+UPDATE rotation_policies
+SET last_rotated_at = now(), next_rotation_at = sqlc.arg(next_rotation_at), last_error = NULL, failure_count = 0,
+    lease_id = NULL, lease_expires_at = NULL
+WHERE id = sqlc.arg(id) AND lease_id = sqlc.arg(lease_id) AND lease_expires_at > now();
+
+-- name: MarkRotationFailed :execrows
+UPDATE rotation_policies
+SET last_error = sqlc.arg(last_error), next_rotation_at = sqlc.arg(next_rotation_at), failure_count = failure_count + 1,
+    lease_id = NULL, lease_expires_at = NULL
+WHERE id = sqlc.arg(id) AND lease_id = sqlc.arg(lease_id);
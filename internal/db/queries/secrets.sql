@@ -1,30 +1,40 @@
 -- name: CreateSecretWithVersion :one
 WITH inserted_secret AS (
-    INSERT INTO secrets (user_id, path, expires_at)
-    VALUES ($1, $2, $3)
+    INSERT INTO secrets (user_id, path, expires_at, team_id)
+    VALUES ($1, $2, $3, $8)
     RETURNING id
 )
 INSERT INTO secret_versions (
     secret_id, version, encrypted_value, nonce, created_by,
-    hmac_signature, hmac_key_id
+    hmac_signature, hmac_key_id, environment
 )
 VALUES (
     (SELECT id FROM inserted_secret), 1, $4, $5, $1,
-    $6, $7
+    $6, $7, $9
 )
 RETURNING *;
 
 
 
 -- name: GetLatestSecretByPath :one
-SELECT sv.*, s.id AS secret_id, s.user_id, s.path
+SELECT sv.*, s.id AS secret_id, s.user_id, s.path, s.team_id
 FROM secrets s
 JOIN secret_versions sv ON s.id = sv.secret_id
-WHERE s.path = $1
+WHERE s.path = $1 AND sv.environment = $2
   AND (s.expires_at IS NULL OR s.expires_at > now())
 ORDER BY sv.version DESC
 LIMIT 1;
 
+-- name: GetSecretByPath :one
+SELECT * FROM secrets
+WHERE path = $1
+  AND (expires_at IS NULL OR expires_at > now());
+
+-- name: GetSecretByID :one
+SELECT * FROM secrets
+WHERE id = $1
+  AND (expires_at IS NULL OR expires_at > now());
+
 -- name: GetLatestSecretsForUser :many
 SELECT DISTINCT ON (s.id) s.id AS secret_id, s.path, sv.version, sv.encrypted_value, sv.nonce, sv.created_at
 FROM secrets s
@@ -41,16 +51,16 @@ WITH secret_row AS (
 version_cte AS (
   SELECT COALESCE(MAX(version), 0) + 1 AS next_version
   FROM secret_versions
-  WHERE secret_id = (SELECT id FROM secret_row)
+  WHERE secret_id = (SELECT id FROM secret_row) AND environment = $7
 )
 INSERT INTO secret_versions (
   secret_id, version, encrypted_value, nonce, created_by,
-  hmac_signature, hmac_key_id
+  hmac_signature, hmac_key_id, environment
 )
 SELECT 
   (SELECT id FROM secret_row),
   (SELECT next_version FROM version_cte),
-  $2, $3, $4, $5, $6
+  $2, $3, $4, $5, $6, $7
 RETURNING *;
 
 
@@ -59,26 +69,41 @@ RETURNING *;
 
 
 -- name: GetSecretVersionByPathAndVersion :one
-SELECT sv.*, s.id AS secret_id,s.user_id, s.path
+SELECT sv.*, s.id AS secret_id,s.user_id, s.path, s.team_id
 FROM secrets s
 JOIN secret_versions sv ON s.id = sv.secret_id
-WHERE s.path = $1 AND sv.version = $2;
+WHERE s.path = $1 AND sv.version = $2 AND sv.environment = $3;
 
 -- name: GetAllSecretVersionsByPath :many
 SELECT sv.*
 FROM secrets s
 JOIN secret_versions sv ON s.id = sv.secret_id
-WHERE s.path = $1
+WHERE s.path = $1 AND sv.environment = $2
 ORDER BY sv.version DESC;
 
--- name: DeleteExpiredSecretAndVersions :exec
+-- name: GetLatestEnvironmentVersionsByPrefix :many
+SELECT DISTINCT ON (s.id, sv.environment)
+       s.id AS secret_id, s.path, s.user_id, s.team_id,
+       sv.environment, sv.version, sv.encrypted_value, sv.nonce,
+       sv.hmac_signature, sv.hmac_key_id
+FROM secrets s
+JOIN secret_versions sv ON s.id = sv.secret_id
+WHERE starts_with(s.path, sqlc.arg(prefix)::TEXT)
+  AND (s.expires_at IS NULL OR s.expires_at > now())
+ORDER BY s.id, sv.environment, sv.version DESC;
+
+-- name: DeleteExpiredSecretAndVersions :many
 WITH deleted AS (
     DELETE FROM secrets
     WHERE expires_at < now()
-    RETURNING id
+    RETURNING id, path, user_id, team_id
+), deleted_versions AS (
+    DELETE FROM secret_versions
+    WHERE secret_id IN (SELECT id FROM deleted)
 )
-DELETE FROM secret_versions
-WHERE secret_id IN (SELECT id FROM deleted);
+SELECT d.id, d.path, d.user_id, d.team_id, u.email AS owner_email
+FROM deleted d
+JOIN users u ON u.id = d.user_id;
 
 -- name: DeleteSecretAndVersionsByPath :exec
 WITH deleted_secret AS (
@@ -103,3 +128,37 @@ FROM secrets s
 LEFT JOIN secret_versions sv ON s.id = sv.secret_id
 WHERE s.path = $1;
 
+
+-- name: GetSecretByPathForUpdate :one
+SELECT * FROM secrets
+WHERE path = $1
+FOR UPDATE;
+
+-- name: ListSecretsByPrefixForUpdate :many
+SELECT * FROM secrets
+WHERE starts_with(path, sqlc.arg(prefix)::TEXT)
+ORDER BY path
+FOR UPDATE;
+
+-- name: MoveSecret :one
+UPDATE secrets
+SET path = sqlc.arg(new_path), user_id = sqlc.arg(user_id), team_id = sqlc.arg(team_id), updated_at = now()
+WHERE path = sqlc.arg(old_path)
+RETURNING *;
+
+-- name: GetLatestVersionsBySecretID :many
+SELECT DISTINCT ON (environment) *
+FROM secret_versions
+WHERE secret_id = $1
+ORDER BY environment, version DESC;
+
+-- name: ListLatestSecretsByPrefix :many
+SELECT DISTINCT ON (s.id)
+       s.path, s.user_id, s.team_id, s.expires_at,
+       sv.version, sv.created_at AS updated_at
+FROM secrets s
+JOIN secret_versions sv ON s.id = sv.secret_id
+WHERE starts_with(s.path, sqlc.arg(prefix)::TEXT)
+  AND sv.environment = sqlc.arg(environment)
+  AND (s.expires_at IS NULL OR s.expires_at > now())
+ORDER BY s.id, sv.version DESC;
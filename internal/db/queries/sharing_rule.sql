@@ -1,34 +1,97 @@
 -- name: ShareSecret :one
-INSERT INTO sharing_rules (owner_email, target_email, path, permission, shared_until)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO sharing_rules (owner_email, target_email, path, permission, shared_until, is_prefix, target_group_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
 RETURNING *;
 
 -- name: GetSharedWith :many
-SELECT owner_email, target_email 
-FROM sharing_rules
-WHERE path = $1 AND (shared_until IS NULL OR shared_until > NOW())
-AND target_email != $2;
+-- the rule that applies to each user: an exact share of the secret, or the share of the
+-- closest folder above it in parents
+SELECT *
+FROM (
+    SELECT DISTINCT ON (target_email) *
+    FROM sharing_rules
+    WHERE ((path = sqlc.arg(path) AND NOT is_prefix) OR (is_prefix AND path = ANY(sqlc.arg(parents)::TEXT[])))
+    AND (shared_until IS NULL OR shared_until > NOW())
+    AND target_email != sqlc.arg(target_email)
+    ORDER BY target_email, is_prefix, length(path) DESC
+) r
+WHERE sqlc.arg(permission)::TEXT = '' OR r.permission = sqlc.arg(permission)
+ORDER BY r.target_email;
 
 -- name: GetPermissions :one
 SELECT permission
 FROM sharing_rules
-WHERE path = $1 AND target_email = $2 
+WHERE path = $1 AND target_email = $2 AND NOT is_prefix
 AND (shared_until IS NULL OR shared_until > NOW());
 
+-- name: GetEffectiveShare :one
+-- most specific rule wins: an exact share of the secret, then the share of the closest folder
+-- above it, so a narrower rule can also restrict what a broader one grants. Among rules on the
+-- same path a share with the user beats shares with their groups, and write beats read.
+SELECT *
+FROM sharing_rules
+WHERE (target_email = sqlc.arg(target_email)
+    OR target_group_id IN (SELECT group_id FROM group_members WHERE user_id = sqlc.arg(user_id)))
+AND ((path = sqlc.arg(path) AND NOT is_prefix) OR (is_prefix AND path = ANY(sqlc.arg(parents)::TEXT[])))
+AND (shared_until IS NULL OR shared_until > NOW())
+ORDER BY is_prefix, length(path) DESC, target_group_id IS NOT NULL, permission = 'write' DESC
+LIMIT 1;
+
 -- name: GetSecretsSharedWithMe :many
-SELECT path, permission, owner_email
+SELECT *
 FROM sharing_rules
-WHERE target_email = $1 
-AND (shared_until IS NULL OR shared_until > NOW());
+WHERE (target_email = sqlc.arg(target_email)
+    OR target_group_id IN (SELECT group_id FROM group_members WHERE user_id = sqlc.arg(user_id)))
+AND (shared_until IS NULL OR shared_until > NOW())
+AND starts_with(CASE WHEN is_prefix THEN path || '/' ELSE path END, sqlc.arg(prefix)::TEXT)
+AND (sqlc.arg(permission)::TEXT = '' OR permission = sqlc.arg(permission))
+ORDER BY path;
+
+-- name: LockShare :exec
+-- serializes creating shares of one path with one target until the transaction ends, so the
+-- CheckIfShared that follows cannot race another share
+SELECT pg_advisory_xact_lock(hashtext(sqlc.arg(path)::TEXT || '|' || sqlc.arg(target_email)::TEXT));
 
 -- name: CheckIfShared :one
 SELECT EXISTS (
     SELECT 1
     FROM sharing_rules
-    WHERE path = $1 AND target_email = $2 
+    WHERE path = sqlc.arg(path) AND target_email = sqlc.arg(target_email)
+    AND is_prefix = sqlc.arg(is_prefix)
     AND (shared_until IS NULL OR shared_until > NOW())
 );
 
--- name: DeleteExpiredSharingRules :exec
+-- name: DeleteExpiredSharingRules :many
+DELETE FROM sharing_rules
+WHERE shared_until IS NOT NULL AND shared_until < NOW()
+RETURNING *;
+
+-- name: MoveSharingRules :execrows
+UPDATE sharing_rules
+SET path = sqlc.arg(new_path)
+WHERE path = sqlc.arg(old_path) AND NOT is_prefix;
+
+-- name: MovePrefixSharingRules :execrows
+-- folder shares on old_prefix and on every folder below it follow the folder to new_prefix
+UPDATE sharing_rules
+SET path = sqlc.arg(new_prefix)::TEXT || substr(path, length(sqlc.arg(old_prefix)::TEXT) + 1)
+WHERE is_prefix AND (path = sqlc.arg(old_prefix)::TEXT OR starts_with(path, sqlc.arg(old_prefix)::TEXT || '/'));
+
+-- name: UpdateSharingRule :many
+UPDATE sharing_rules
+SET permission = COALESCE(sqlc.narg(permission), permission),
+    shared_until = CASE WHEN sqlc.arg(set_shared_until)::boolean THEN sqlc.narg(shared_until) ELSE shared_until END
+WHERE path = sqlc.arg(path) AND target_email = sqlc.arg(target_email)
+AND is_prefix = sqlc.arg(is_prefix)
+AND (shared_until IS NULL OR shared_until > NOW())
+RETURNING *;
+
+-- name: DeleteSharingRule :many
 DELETE FROM sharing_rules
-WHERE shared_until IS NOT NULL AND shared_until < NOW();
\ No newline at end of file
+WHERE path = $1 AND target_email = $2 AND is_prefix = $3
+RETURNING *;
+
+-- name: DeleteSharingRulesByPath :many
+DELETE FROM sharing_rules
+WHERE path = $1 AND is_prefix = $2
+RETURNING *;
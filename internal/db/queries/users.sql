@@ -8,3 +8,44 @@ SELECT * FROM users WHERE email = $1;
 
 -- name: GetUserByID :one
 SELECT * FROM users WHERE id = $1;
+
+-- name: ListUsers :many
+SELECT * FROM users
+ORDER BY email
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: SetUserRole :one
+UPDATE users SET role = $2
+WHERE email = $1
+RETURNING *;
+
+-- name: LockAdmins :many
+-- locks every admin so that concurrent demotions see each other
+SELECT id FROM users
+WHERE role = 'admin'
+ORDER BY id
+FOR UPDATE;
+
+-- name: PromoteBootstrapAdmin :one
+-- only while nobody is an admin, so the bootstrap email cannot take the role back later. Emails
+-- are compared case-insensitively, and an email shared by several accounts promotes none.
+UPDATE users SET role = 'admin'
+WHERE lower(users.email) = lower(sqlc.arg(email))
+  AND (SELECT count(*) FROM users same WHERE lower(same.email) = lower(sqlc.arg(email))) = 1
+  AND NOT EXISTS (SELECT 1 FROM users admins WHERE admins.role = 'admin')
+RETURNING *;
+
+-- name: GetSystemStats :one
+SELECT
+  (SELECT count(*) FROM users) AS users,
+  (SELECT count(*) FROM secrets) AS secrets,
+  (SELECT count(*) FROM secret_versions) AS secret_versions,
+  (SELECT count(*) FROM sharing_rules) AS sharing_rules,
+  (SELECT count(*) FROM groups) AS groups,
+  (SELECT count(*) FROM organizations) AS organizations,
+  (SELECT count(*) FROM audit_logs) AS audit_logs;
+
+-- name: ListUserEmailsWithRoles :many
+SELECT email FROM users
+WHERE role = ANY(sqlc.arg(roles)::TEXT[])
+ORDER BY email;
@@ -0,0 +1,72 @@
+-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (user_id, url, path_prefix, event_types, secret_ciphertext, secret_nonce)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetWebhookSubscription :one
+SELECT * FROM webhook_subscriptions
+WHERE id = $1;
+
+-- name: ListWebhookSubscriptionsForUser :many
+SELECT * FROM webhook_subscriptions
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: DeleteWebhookSubscription :execrows
+DELETE FROM webhook_subscriptions
+WHERE id = $1 AND user_id = $2;
+
+-- name: ListWebhookSubscriptionsForEvent :many
+-- prefixes are compared literally; LIKE would treat _ in paths as a wildcard
+SELECT w.*, u.email AS user_email
+FROM webhook_subscriptions w
+JOIN users u ON u.id = w.user_id
+WHERE left(sqlc.arg(path)::text, length(w.path_prefix)) = w.path_prefix
+AND (cardinality(w.event_types) = 0 OR sqlc.arg(event_type)::text = ANY(w.event_types));
+
+-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (subscription_id, event_type, payload)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: ClaimDueWebhookDeliveries :many
+-- pushing next_attempt_at forward leases the deliveries to this instance while they are sent,
+-- so the HTTP calls happen outside of any transaction
+UPDATE webhook_deliveries
+SET next_attempt_at = now() + make_interval(secs => sqlc.arg(lease_seconds)::int)
+WHERE id IN (
+    SELECT id FROM webhook_deliveries
+    WHERE status = 'pending' AND next_attempt_at <= now()
+    ORDER BY next_attempt_at
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: MarkWebhookDeliverySucceeded :exec
+UPDATE webhook_deliveries
+SET status = 'succeeded',
+    attempts = attempts + 1,
+    last_status_code = $2,
+    last_error = NULL,
+    delivered_at = now()
+WHERE id = $1;
+
+-- name: MarkWebhookDeliveryFailed :exec
+UPDATE webhook_deliveries
+SET status = CASE WHEN attempts + 1 >= sqlc.arg(max_attempts)::int THEN 'failed' ELSE 'pending' END,
+    attempts = attempts + 1,
+    last_status_code = sqlc.arg(last_status_code),
+    last_error = sqlc.arg(last_error),
+    next_attempt_at = sqlc.arg(next_attempt_at)
+WHERE id = sqlc.arg(id);
+
+-- name: GetWebhookDelivery :one
+SELECT * FROM webhook_deliveries
+WHERE id = $1 AND subscription_id = $2;
+
+-- name: ListWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE subscription_id = $1
+ORDER BY created_at DESC
+LIMIT $2;
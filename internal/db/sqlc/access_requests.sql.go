@@ -0,0 +1,313 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: access_requests.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const createAccessRequest = `-- name: CreateAccessRequest :one
+INSERT INTO access_requests (requester_id, requester_email, path, is_prefix, permission, justification, duration_secs)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, requester_id, requester_email, path, is_prefix, permission, justification, duration_secs, status, decided_by, decision_reason, decided_at, sharing_rule_id, created_at
+`
+
+type CreateAccessRequestParams struct {
+	RequesterID    uuid.UUID `json:"requester_id"`
+	RequesterEmail string    `json:"requester_email"`
+	Path           string    `json:"path"`
+	IsPrefix       bool      `json:"is_prefix"`
+	Permission     string    `json:"permission"`
+	Justification  string    `json:"justification"`
+	DurationSecs   int64     `json:"duration_secs"`
+}
+
+func (q *Queries) CreateAccessRequest(ctx context.Context, arg CreateAccessRequestParams) (AccessRequests, error) {
+	row := q.db.QueryRowContext(ctx, createAccessRequest,
+		arg.RequesterID,
+		arg.RequesterEmail,
+		arg.Path,
+		arg.IsPrefix,
+		arg.Permission,
+		arg.Justification,
+		arg.DurationSecs,
+	)
+	var i AccessRequests
+	err := row.Scan(
+		&i.ID,
+		&i.RequesterID,
+		&i.RequesterEmail,
+		&i.Path,
+		&i.IsPrefix,
+		&i.Permission,
+		&i.Justification,
+		&i.DurationSecs,
+		&i.Status,
+		&i.DecidedBy,
+		&i.DecisionReason,
+		&i.DecidedAt,
+		&i.SharingRuleID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const decideAccessRequest = `-- name: DecideAccessRequest :one
+UPDATE access_requests
+SET status = $1,
+    decided_by = $2,
+    decision_reason = $3,
+    decided_at = NOW(),
+    sharing_rule_id = $4,
+    duration_secs = COALESCE($5, duration_secs)
+WHERE id = $6 AND status = 'pending'
+RETURNING id, requester_id, requester_email, path, is_prefix, permission, justification, duration_secs, status, decided_by, decision_reason, decided_at, sharing_rule_id, created_at
+`
+
+type DecideAccessRequestParams struct {
+	Status         string         `json:"status"`
+	DecidedBy      sql.NullString `json:"decided_by"`
+	DecisionReason sql.NullString `json:"decision_reason"`
+	SharingRuleID  uuid.NullUUID  `json:"sharing_rule_id"`
+	DurationSecs   sql.NullInt64  `json:"duration_secs"`
+	ID             uuid.UUID      `json:"id"`
+}
+
+// only a pending request can be decided, so concurrent decisions cannot both succeed
+func (q *Queries) DecideAccessRequest(ctx context.Context, arg DecideAccessRequestParams) (AccessRequests, error) {
+	row := q.db.QueryRowContext(ctx, decideAccessRequest,
+		arg.Status,
+		arg.DecidedBy,
+		arg.DecisionReason,
+		arg.SharingRuleID,
+		arg.DurationSecs,
+		arg.ID,
+	)
+	var i AccessRequests
+	err := row.Scan(
+		&i.ID,
+		&i.RequesterID,
+		&i.RequesterEmail,
+		&i.Path,
+		&i.IsPrefix,
+		&i.Permission,
+		&i.Justification,
+		&i.DurationSecs,
+		&i.Status,
+		&i.DecidedBy,
+		&i.DecisionReason,
+		&i.DecidedAt,
+		&i.SharingRuleID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getAccessRequest = `-- name: GetAccessRequest :one
+SELECT id, requester_id, requester_email, path, is_prefix, permission, justification, duration_secs, status, decided_by, decision_reason, decided_at, sharing_rule_id, created_at FROM access_requests
+WHERE id = $1
+`
+
+func (q *Queries) GetAccessRequest(ctx context.Context, id uuid.UUID) (AccessRequests, error) {
+	row := q.db.QueryRowContext(ctx, getAccessRequest, id)
+	var i AccessRequests
+	err := row.Scan(
+		&i.ID,
+		&i.RequesterID,
+		&i.RequesterEmail,
+		&i.Path,
+		&i.IsPrefix,
+		&i.Permission,
+		&i.Justification,
+		&i.DurationSecs,
+		&i.Status,
+		&i.DecidedBy,
+		&i.DecisionReason,
+		&i.DecidedAt,
+		&i.SharingRuleID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listAccessRequestsByRequester = `-- name: ListAccessRequestsByRequester :many
+SELECT id, requester_id, requester_email, path, is_prefix, permission, justification, duration_secs, status, decided_by, decision_reason, decided_at, sharing_rule_id, created_at FROM access_requests
+WHERE requester_id = $1
+AND ($2::TEXT = '' OR status = $2)
+ORDER BY created_at DESC
+LIMIT $4 OFFSET $3
+`
+
+type ListAccessRequestsByRequesterParams struct {
+	RequesterID uuid.UUID `json:"requester_id"`
+	Status      string    `json:"status"`
+	Offset      int32     `json:"offset"`
+	Limit       int32     `json:"limit"`
+}
+
+func (q *Queries) ListAccessRequestsByRequester(ctx context.Context, arg ListAccessRequestsByRequesterParams) ([]AccessRequests, error) {
+	rows, err := q.db.QueryContext(ctx, listAccessRequestsByRequester,
+		arg.RequesterID,
+		arg.Status,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AccessRequests{}
+	for rows.Next() {
+		var i AccessRequests
+		if err := rows.Scan(
+			&i.ID,
+			&i.RequesterID,
+			&i.RequesterEmail,
+			&i.Path,
+			&i.IsPrefix,
+			&i.Permission,
+			&i.Justification,
+			&i.DurationSecs,
+			&i.Status,
+			&i.DecidedBy,
+			&i.DecisionReason,
+			&i.DecidedAt,
+			&i.SharingRuleID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPendingAccessRequestsForApprover = `-- name: ListPendingAccessRequestsForApprover :many
+SELECT ar.id, ar.requester_id, ar.requester_email, ar.path, ar.is_prefix, ar.permission, ar.justification, ar.duration_secs, ar.status, ar.decided_by, ar.decision_reason, ar.decided_at, ar.sharing_rule_id, ar.created_at FROM access_requests ar
+WHERE ar.status = 'pending' AND ar.requester_id <> $1
+AND (
+    starts_with(ar.path || '/', $2::TEXT)
+    OR EXISTS (
+        SELECT 1 FROM unnest($3::TEXT[]) AS prefix
+        WHERE starts_with(ar.path || '/', prefix)
+    )
+    OR EXISTS (
+        SELECT 1 FROM teams t
+        JOIN organizations o ON o.id = t.org_id
+        JOIN org_members om ON om.org_id = t.org_id AND om.user_id = $1
+        LEFT JOIN team_members tm ON tm.team_id = t.id AND tm.user_id = $1
+        WHERE (om.role IN ('owner', 'admin') OR tm.role = 'maintainer')
+        AND starts_with(ar.path || '/', 'org/' || o.slug || '/' || t.slug || '/')
+    )
+)
+ORDER BY ar.created_at
+LIMIT $5 OFFSET $4
+`
+
+type ListPendingAccessRequestsForApproverParams struct {
+	ApproverID uuid.UUID `json:"approver_id"`
+	Namespace  string    `json:"namespace"`
+	Prefixes   []string  `json:"prefixes"`
+	Offset     int32     `json:"offset"`
+	Limit      int32     `json:"limit"`
+}
+
+// pending requests of other users on paths the approver may hold share on: below their own
+// namespace, the namespace of a team they maintain, or one of the given policy prefixes. The
+// authorizer still has the final say on each of them.
+func (q *Queries) ListPendingAccessRequestsForApprover(ctx context.Context, arg ListPendingAccessRequestsForApproverParams) ([]AccessRequests, error) {
+	rows, err := q.db.QueryContext(ctx, listPendingAccessRequestsForApprover,
+		arg.ApproverID,
+		arg.Namespace,
+		pq.Array(arg.Prefixes),
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AccessRequests{}
+	for rows.Next() {
+		var i AccessRequests
+		if err := rows.Scan(
+			&i.ID,
+			&i.RequesterID,
+			&i.RequesterEmail,
+			&i.Path,
+			&i.IsPrefix,
+			&i.Permission,
+			&i.Justification,
+			&i.DurationSecs,
+			&i.Status,
+			&i.DecidedBy,
+			&i.DecisionReason,
+			&i.DecidedAt,
+			&i.SharingRuleID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const movePendingAccessRequests = `-- name: MovePendingAccessRequests :execrows
+UPDATE access_requests
+SET path = $1
+WHERE path = $2 AND NOT is_prefix AND status = 'pending'
+`
+
+type MovePendingAccessRequestsParams struct {
+	NewPath string `json:"new_path"`
+	OldPath string `json:"old_path"`
+}
+
+func (q *Queries) MovePendingAccessRequests(ctx context.Context, arg MovePendingAccessRequestsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, movePendingAccessRequests, arg.NewPath, arg.OldPath)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const movePendingPrefixAccessRequests = `-- name: MovePendingPrefixAccessRequests :execrows
+UPDATE access_requests
+SET path = $1::TEXT || substr(path, length($2::TEXT) + 1)
+WHERE is_prefix AND status = 'pending'
+AND (path = $2::TEXT OR starts_with(path, $2::TEXT || '/'))
+`
+
+type MovePendingPrefixAccessRequestsParams struct {
+	NewPrefix string `json:"new_prefix"`
+	OldPrefix string `json:"old_prefix"`
+}
+
+// pending requests for old_prefix and for every folder below it follow the folder to new_prefix
+func (q *Queries) MovePendingPrefixAccessRequests(ctx context.Context, arg MovePendingPrefixAccessRequestsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, movePendingPrefixAccessRequests, arg.NewPrefix, arg.OldPrefix)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
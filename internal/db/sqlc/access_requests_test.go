@@ -0,0 +1,133 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/pixperk/vaultify/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomAccessRequest(t *testing.T, requester Users) AccessRequests {
+	arg := CreateAccessRequestParams{
+		RequesterID:    requester.ID,
+		RequesterEmail: requester.Email,
+		Path:           util.RandomEmail() + "/" + util.RandomString(6),
+		Permission:     "read",
+		Justification:  "incident " + util.RandomString(4),
+		DurationSecs:   3600,
+	}
+
+	request, err := testQueries.CreateAccessRequest(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Path, request.Path)
+	require.Equal(t, "pending", request.Status)
+	require.False(t, request.DecidedAt.Valid)
+	return request
+}
+
+func TestCreateAccessRequest(t *testing.T) {
+	requester := createRandomUser(t)
+	request := createRandomAccessRequest(t, requester)
+
+	// one pending request per user and path
+	_, err := testQueries.CreateAccessRequest(context.Background(), CreateAccessRequestParams{
+		RequesterID:    requester.ID,
+		RequesterEmail: requester.Email,
+		Path:           request.Path,
+		Permission:     "write",
+		Justification:  "again",
+		DurationSecs:   60,
+	})
+	require.Error(t, err)
+
+	got, err := testQueries.GetAccessRequest(context.Background(), request.ID)
+	require.NoError(t, err)
+	require.Equal(t, request.ID, got.ID)
+	require.Equal(t, request.Justification, got.Justification)
+	require.Equal(t, request.DurationSecs, got.DurationSecs)
+}
+
+func TestDecideAccessRequest(t *testing.T) {
+	requester := createRandomUser(t)
+	request := createRandomAccessRequest(t, requester)
+
+	decided, err := testQueries.DecideAccessRequest(context.Background(), DecideAccessRequestParams{
+		ID:             request.ID,
+		Status:         "approved",
+		DecidedBy:      sql.NullString{String: "owner@example.com", Valid: true},
+		DecisionReason: sql.NullString{String: "ok", Valid: true},
+		DurationSecs:   sql.NullInt64{Int64: 600, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "approved", decided.Status)
+	require.Equal(t, int64(600), decided.DurationSecs)
+	require.True(t, decided.DecidedAt.Valid)
+
+	// a decided request cannot be decided again
+	_, err = testQueries.DecideAccessRequest(context.Background(), DecideAccessRequestParams{
+		ID:     request.ID,
+		Status: "denied",
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// the path can be requested again once the first request is decided
+	_, err = testQueries.CreateAccessRequest(context.Background(), CreateAccessRequestParams{
+		RequesterID:    requester.ID,
+		RequesterEmail: requester.Email,
+		Path:           request.Path,
+		Permission:     "write",
+		Justification:  "need to write now",
+		DurationSecs:   60,
+	})
+	require.NoError(t, err)
+
+	requests, err := testQueries.ListAccessRequestsByRequester(context.Background(), ListAccessRequestsByRequesterParams{
+		RequesterID: requester.ID,
+		Limit:       10,
+	})
+	require.NoError(t, err)
+	require.Len(t, requests, 2)
+	require.Equal(t, "pending", requests[0].Status)
+
+	approved, err := testQueries.ListAccessRequestsByRequester(context.Background(), ListAccessRequestsByRequesterParams{
+		RequesterID: requester.ID,
+		Status:      "approved",
+		Limit:       10,
+	})
+	require.NoError(t, err)
+	require.Len(t, approved, 1)
+	require.Equal(t, request.ID, approved[0].ID)
+
+	// the second request is below the approver's namespace only when the namespace matches
+	approver := createRandomUser(t)
+	pending, err := testQueries.ListPendingAccessRequestsForApprover(context.Background(), ListPendingAccessRequestsForApproverParams{
+		ApproverID: approver.ID,
+		Namespace:  request.Path[:strings.Index(request.Path, "/")+1],
+		Limit:      10,
+	})
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	require.Equal(t, "pending", pending[0].Status)
+	require.Equal(t, request.Path, pending[0].Path)
+
+	pending, err = testQueries.ListPendingAccessRequestsForApprover(context.Background(), ListPendingAccessRequestsForApproverParams{
+		ApproverID: approver.ID,
+		Namespace:  approver.Email + "/",
+		Prefixes:   []string{request.Path + "/"},
+		Limit:      10,
+	})
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+
+	// requesters never see their own requests
+	pending, err = testQueries.ListPendingAccessRequestsForApprover(context.Background(), ListPendingAccessRequestsForApproverParams{
+		ApproverID: requester.ID,
+		Namespace:  request.Path[:strings.Index(request.Path, "/")+1],
+		Limit:      10,
+	})
+	require.NoError(t, err)
+	require.Empty(t, pending)
+}
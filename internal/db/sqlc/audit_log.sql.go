@@ -25,7 +25,7 @@ INSERT INTO audit_logs (
 VALUES (
   $1, $2, $3, $4, $5, $6, $7
 )
-RETURNING id, user_id, user_email, action, resource_version, resource_path, success, reason, created_at
+RETURNING id, user_id, user_email, action, resource_version, resource_path, success, reason, created_at, break_glass_session_id
 `
 
 type CreateAuditLogParams struct {
@@ -59,12 +59,13 @@ func (q *Queries) CreateAuditLog(ctx context.Context, arg CreateAuditLogParams)
 		&i.Success,
 		&i.Reason,
 		&i.CreatedAt,
+		&i.BreakGlassSessionID,
 	)
 	return i, err
 }
 
 const filterAuditLogs = `-- name: FilterAuditLogs :many
-SELECT id, user_id, user_email, action, resource_version, resource_path, success, reason, created_at FROM audit_logs
+SELECT id, user_id, user_email, action, resource_version, resource_path, success, reason, created_at, break_glass_session_id FROM audit_logs
 WHERE
   (user_email = $1 OR $1 = '')
   AND (resource_version = $2 OR $2 = 0)
@@ -73,8 +74,9 @@ WHERE
   AND (created_at <= $5 OR $5 IS NULL)
   AND (resource_path = $6 OR $6 = '')
   AND (success = $7 OR $7 IS NULL)
+  AND ((break_glass_session_id IS NOT NULL) = $8::BOOLEAN OR $8 IS NULL)
 ORDER BY created_at DESC
-LIMIT $8 OFFSET $9
+LIMIT $10 OFFSET $9
 `
 
 type FilterAuditLogsParams struct {
@@ -82,11 +84,12 @@ type FilterAuditLogsParams struct {
 	ResourceVersion int32        `json:"resource_version"`
 	Action          string       `json:"action"`
 	CreatedAt       sql.NullTime `json:"created_at"`
-	CreatedAt_2     sql.NullTime `json:"created_at_2"`
+	CreatedAt2      sql.NullTime `json:"created_at_2"`
 	ResourcePath    string       `json:"resource_path"`
-	Success         bool         `json:"success"`
-	Limit           int32        `json:"limit"`
+	Success         sql.NullBool `json:"success"`
+	Flagged         sql.NullBool `json:"flagged"`
 	Offset          int32        `json:"offset"`
+	Limit           int32        `json:"limit"`
 }
 
 func (q *Queries) FilterAuditLogs(ctx context.Context, arg FilterAuditLogsParams) ([]AuditLogs, error) {
@@ -95,11 +98,12 @@ func (q *Queries) FilterAuditLogs(ctx context.Context, arg FilterAuditLogsParams
 		arg.ResourceVersion,
 		arg.Action,
 		arg.CreatedAt,
-		arg.CreatedAt_2,
+		arg.CreatedAt2,
 		arg.ResourcePath,
 		arg.Success,
-		arg.Limit,
+		arg.Flagged,
 		arg.Offset,
+		arg.Limit,
 	)
 	if err != nil {
 		return nil, err
@@ -118,6 +122,7 @@ func (q *Queries) FilterAuditLogs(ctx context.Context, arg FilterAuditLogsParams
 			&i.Success,
 			&i.Reason,
 			&i.CreatedAt,
+			&i.BreakGlassSessionID,
 		); err != nil {
 			return nil, err
 		}
@@ -0,0 +1,496 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: break_glass.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const createBreakGlassSession = `-- name: CreateBreakGlassSession :one
+INSERT INTO break_glass_sessions (rule_id, user_id, user_email, path, reason, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, rule_id, user_id, user_email, path, reason, expires_at, reviewed_by, review_notes, reviewed_at, created_at
+`
+
+type CreateBreakGlassSessionParams struct {
+	RuleID    uuid.NullUUID `json:"rule_id"`
+	UserID    uuid.UUID     `json:"user_id"`
+	UserEmail string        `json:"user_email"`
+	Path      string        `json:"path"`
+	Reason    string        `json:"reason"`
+	ExpiresAt time.Time     `json:"expires_at"`
+}
+
+func (q *Queries) CreateBreakGlassSession(ctx context.Context, arg CreateBreakGlassSessionParams) (BreakGlassSessions, error) {
+	row := q.db.QueryRowContext(ctx, createBreakGlassSession,
+		arg.RuleID,
+		arg.UserID,
+		arg.UserEmail,
+		arg.Path,
+		arg.Reason,
+		arg.ExpiresAt,
+	)
+	var i BreakGlassSessions
+	err := row.Scan(
+		&i.ID,
+		&i.RuleID,
+		&i.UserID,
+		&i.UserEmail,
+		&i.Path,
+		&i.Reason,
+		&i.ExpiresAt,
+		&i.ReviewedBy,
+		&i.ReviewNotes,
+		&i.ReviewedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteBreakGlassRule = `-- name: DeleteBreakGlassRule :execrows
+DELETE FROM break_glass_rules
+WHERE path = $1 AND is_prefix = $2
+`
+
+type DeleteBreakGlassRuleParams struct {
+	Path     string `json:"path"`
+	IsPrefix bool   `json:"is_prefix"`
+}
+
+func (q *Queries) DeleteBreakGlassRule(ctx context.Context, arg DeleteBreakGlassRuleParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteBreakGlassRule, arg.Path, arg.IsPrefix)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const flagAuditLog = `-- name: FlagAuditLog :exec
+UPDATE audit_logs
+SET break_glass_session_id = $2
+WHERE id = $1
+`
+
+type FlagAuditLogParams struct {
+	ID                  uuid.UUID     `json:"id"`
+	BreakGlassSessionID uuid.NullUUID `json:"break_glass_session_id"`
+}
+
+func (q *Queries) FlagAuditLog(ctx context.Context, arg FlagAuditLogParams) error {
+	_, err := q.db.ExecContext(ctx, flagAuditLog, arg.ID, arg.BreakGlassSessionID)
+	return err
+}
+
+const getBreakGlassRuleForPath = `-- name: GetBreakGlassRuleForPath :one
+SELECT r.id, r.path, r.is_prefix, r.group_id, r.max_duration_secs, r.created_by, r.created_at, g.slug AS group_slug
+FROM break_glass_rules r
+JOIN groups g ON g.id = r.group_id
+WHERE (r.path = $1 AND NOT r.is_prefix) OR (r.is_prefix AND r.path = ANY($2::TEXT[]))
+ORDER BY r.is_prefix, length(r.path) DESC
+LIMIT 1
+`
+
+type GetBreakGlassRuleForPathParams struct {
+	Path    string   `json:"path"`
+	Parents []string `json:"parents"`
+}
+
+type GetBreakGlassRuleForPathRow struct {
+	ID              uuid.UUID `json:"id"`
+	Path            string    `json:"path"`
+	IsPrefix        bool      `json:"is_prefix"`
+	GroupID         uuid.UUID `json:"group_id"`
+	MaxDurationSecs int64     `json:"max_duration_secs"`
+	CreatedBy       uuid.UUID `json:"created_by"`
+	CreatedAt       time.Time `json:"created_at"`
+	GroupSlug       string    `json:"group_slug"`
+}
+
+// the rule for the secret itself, or else the one of the closest folder above it in parents
+func (q *Queries) GetBreakGlassRuleForPath(ctx context.Context, arg GetBreakGlassRuleForPathParams) (GetBreakGlassRuleForPathRow, error) {
+	row := q.db.QueryRowContext(ctx, getBreakGlassRuleForPath, arg.Path, pq.Array(arg.Parents))
+	var i GetBreakGlassRuleForPathRow
+	err := row.Scan(
+		&i.ID,
+		&i.Path,
+		&i.IsPrefix,
+		&i.GroupID,
+		&i.MaxDurationSecs,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.GroupSlug,
+	)
+	return i, err
+}
+
+const getBreakGlassSession = `-- name: GetBreakGlassSession :one
+SELECT id, rule_id, user_id, user_email, path, reason, expires_at, reviewed_by, review_notes, reviewed_at, created_at FROM break_glass_sessions
+WHERE id = $1
+`
+
+func (q *Queries) GetBreakGlassSession(ctx context.Context, id uuid.UUID) (BreakGlassSessions, error) {
+	row := q.db.QueryRowContext(ctx, getBreakGlassSession, id)
+	var i BreakGlassSessions
+	err := row.Scan(
+		&i.ID,
+		&i.RuleID,
+		&i.UserID,
+		&i.UserEmail,
+		&i.Path,
+		&i.Reason,
+		&i.ExpiresAt,
+		&i.ReviewedBy,
+		&i.ReviewNotes,
+		&i.ReviewedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listActiveBreakGlassSessions = `-- name: ListActiveBreakGlassSessions :many
+SELECT id, rule_id, user_id, user_email, path, reason, expires_at, reviewed_by, review_notes, reviewed_at, created_at FROM break_glass_sessions
+WHERE user_id = $1 AND expires_at > NOW()
+`
+
+func (q *Queries) ListActiveBreakGlassSessions(ctx context.Context, userID uuid.UUID) ([]BreakGlassSessions, error) {
+	rows, err := q.db.QueryContext(ctx, listActiveBreakGlassSessions, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []BreakGlassSessions{}
+	for rows.Next() {
+		var i BreakGlassSessions
+		if err := rows.Scan(
+			&i.ID,
+			&i.RuleID,
+			&i.UserID,
+			&i.UserEmail,
+			&i.Path,
+			&i.Reason,
+			&i.ExpiresAt,
+			&i.ReviewedBy,
+			&i.ReviewNotes,
+			&i.ReviewedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAuditLogsForBreakGlassSession = `-- name: ListAuditLogsForBreakGlassSession :many
+SELECT id, user_id, user_email, action, resource_version, resource_path, success, reason, created_at, break_glass_session_id FROM audit_logs
+WHERE break_glass_session_id = $1
+ORDER BY created_at
+`
+
+func (q *Queries) ListAuditLogsForBreakGlassSession(ctx context.Context, breakGlassSessionID uuid.NullUUID) ([]AuditLogs, error) {
+	rows, err := q.db.QueryContext(ctx, listAuditLogsForBreakGlassSession, breakGlassSessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AuditLogs{}
+	for rows.Next() {
+		var i AuditLogs
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.UserEmail,
+			&i.Action,
+			&i.ResourceVersion,
+			&i.ResourcePath,
+			&i.Success,
+			&i.Reason,
+			&i.CreatedAt,
+			&i.BreakGlassSessionID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBreakGlassRulesForUser = `-- name: ListBreakGlassRulesForUser :many
+SELECT r.id, r.path, r.is_prefix, r.group_id, r.max_duration_secs, r.created_by, r.created_at, g.slug AS group_slug
+FROM break_glass_rules r
+JOIN groups g ON g.id = r.group_id
+WHERE r.created_by = $1
+   OR EXISTS (SELECT 1 FROM group_members gm WHERE gm.group_id = r.group_id AND gm.user_id = $1)
+ORDER BY r.path
+`
+
+type ListBreakGlassRulesForUserRow struct {
+	ID              uuid.UUID `json:"id"`
+	Path            string    `json:"path"`
+	IsPrefix        bool      `json:"is_prefix"`
+	GroupID         uuid.UUID `json:"group_id"`
+	MaxDurationSecs int64     `json:"max_duration_secs"`
+	CreatedBy       uuid.UUID `json:"created_by"`
+	CreatedAt       time.Time `json:"created_at"`
+	GroupSlug       string    `json:"group_slug"`
+}
+
+// the rules the user set up or can use as a member of their group
+func (q *Queries) ListBreakGlassRulesForUser(ctx context.Context, userID uuid.UUID) ([]ListBreakGlassRulesForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, listBreakGlassRulesForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListBreakGlassRulesForUserRow{}
+	for rows.Next() {
+		var i ListBreakGlassRulesForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Path,
+			&i.IsPrefix,
+			&i.GroupID,
+			&i.MaxDurationSecs,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.GroupSlug,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBreakGlassSessions = `-- name: ListBreakGlassSessions :many
+SELECT id, rule_id, user_id, user_email, path, reason, expires_at, reviewed_by, review_notes, reviewed_at, created_at FROM break_glass_sessions
+WHERE $1::BOOLEAN IS NULL OR (reviewed_at IS NOT NULL) = $1::BOOLEAN
+ORDER BY created_at DESC
+LIMIT $3 OFFSET $2
+`
+
+type ListBreakGlassSessionsParams struct {
+	Reviewed sql.NullBool `json:"reviewed"`
+	Offset   int32        `json:"offset"`
+	Limit    int32        `json:"limit"`
+}
+
+// every session, newest first, only reviewed or unreviewed ones when reviewed is set
+func (q *Queries) ListBreakGlassSessions(ctx context.Context, arg ListBreakGlassSessionsParams) ([]BreakGlassSessions, error) {
+	rows, err := q.db.QueryContext(ctx, listBreakGlassSessions, arg.Reviewed, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []BreakGlassSessions{}
+	for rows.Next() {
+		var i BreakGlassSessions
+		if err := rows.Scan(
+			&i.ID,
+			&i.RuleID,
+			&i.UserID,
+			&i.UserEmail,
+			&i.Path,
+			&i.Reason,
+			&i.ExpiresAt,
+			&i.ReviewedBy,
+			&i.ReviewNotes,
+			&i.ReviewedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBreakGlassSessionsByUser = `-- name: ListBreakGlassSessionsByUser :many
+SELECT id, rule_id, user_id, user_email, path, reason, expires_at, reviewed_by, review_notes, reviewed_at, created_at FROM break_glass_sessions
+WHERE user_id = $1
+ORDER BY created_at DESC
+LIMIT $3 OFFSET $2
+`
+
+type ListBreakGlassSessionsByUserParams struct {
+	UserID uuid.UUID `json:"user_id"`
+	Offset int32     `json:"offset"`
+	Limit  int32     `json:"limit"`
+}
+
+func (q *Queries) ListBreakGlassSessionsByUser(ctx context.Context, arg ListBreakGlassSessionsByUserParams) ([]BreakGlassSessions, error) {
+	rows, err := q.db.QueryContext(ctx, listBreakGlassSessionsByUser, arg.UserID, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []BreakGlassSessions{}
+	for rows.Next() {
+		var i BreakGlassSessions
+		if err := rows.Scan(
+			&i.ID,
+			&i.RuleID,
+			&i.UserID,
+			&i.UserEmail,
+			&i.Path,
+			&i.Reason,
+			&i.ExpiresAt,
+			&i.ReviewedBy,
+			&i.ReviewNotes,
+			&i.ReviewedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const moveBreakGlassRule = `-- name: MoveBreakGlassRule :execrows
+UPDATE break_glass_rules
+SET path = $1
+WHERE path = $2 AND NOT is_prefix
+`
+
+type MoveBreakGlassRuleParams struct {
+	NewPath string `json:"new_path"`
+	OldPath string `json:"old_path"`
+}
+
+func (q *Queries) MoveBreakGlassRule(ctx context.Context, arg MoveBreakGlassRuleParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, moveBreakGlassRule, arg.NewPath, arg.OldPath)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const movePrefixBreakGlassRules = `-- name: MovePrefixBreakGlassRules :execrows
+UPDATE break_glass_rules
+SET path = $1::TEXT || substr(path, length($2::TEXT) + 1)
+WHERE is_prefix AND (path = $2::TEXT OR starts_with(path, $2::TEXT || '/'))
+`
+
+type MovePrefixBreakGlassRulesParams struct {
+	NewPrefix string `json:"new_prefix"`
+	OldPrefix string `json:"old_prefix"`
+}
+
+// folder rules on old_prefix and on every folder below it follow the folder to new_prefix
+func (q *Queries) MovePrefixBreakGlassRules(ctx context.Context, arg MovePrefixBreakGlassRulesParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, movePrefixBreakGlassRules, arg.NewPrefix, arg.OldPrefix)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const reviewBreakGlassSession = `-- name: ReviewBreakGlassSession :one
+UPDATE break_glass_sessions
+SET reviewed_by = $2, review_notes = $3, reviewed_at = NOW()
+WHERE id = $1 AND reviewed_at IS NULL
+RETURNING id, rule_id, user_id, user_email, path, reason, expires_at, reviewed_by, review_notes, reviewed_at, created_at
+`
+
+type ReviewBreakGlassSessionParams struct {
+	ID          uuid.UUID      `json:"id"`
+	ReviewedBy  sql.NullString `json:"reviewed_by"`
+	ReviewNotes sql.NullString `json:"review_notes"`
+}
+
+func (q *Queries) ReviewBreakGlassSession(ctx context.Context, arg ReviewBreakGlassSessionParams) (BreakGlassSessions, error) {
+	row := q.db.QueryRowContext(ctx, reviewBreakGlassSession, arg.ID, arg.ReviewedBy, arg.ReviewNotes)
+	var i BreakGlassSessions
+	err := row.Scan(
+		&i.ID,
+		&i.RuleID,
+		&i.UserID,
+		&i.UserEmail,
+		&i.Path,
+		&i.Reason,
+		&i.ExpiresAt,
+		&i.ReviewedBy,
+		&i.ReviewNotes,
+		&i.ReviewedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const upsertBreakGlassRule = `-- name: UpsertBreakGlassRule :one
+INSERT INTO break_glass_rules (path, is_prefix, group_id, max_duration_secs, created_by)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (path, is_prefix) DO UPDATE
+SET group_id = EXCLUDED.group_id,
+    max_duration_secs = EXCLUDED.max_duration_secs,
+    created_by = EXCLUDED.created_by
+RETURNING id, path, is_prefix, group_id, max_duration_secs, created_by, created_at
+`
+
+type UpsertBreakGlassRuleParams struct {
+	Path            string    `json:"path"`
+	IsPrefix        bool      `json:"is_prefix"`
+	GroupID         uuid.UUID `json:"group_id"`
+	MaxDurationSecs int64     `json:"max_duration_secs"`
+	CreatedBy       uuid.UUID `json:"created_by"`
+}
+
+func (q *Queries) UpsertBreakGlassRule(ctx context.Context, arg UpsertBreakGlassRuleParams) (BreakGlassRules, error) {
+	row := q.db.QueryRowContext(ctx, upsertBreakGlassRule,
+		arg.Path,
+		arg.IsPrefix,
+		arg.GroupID,
+		arg.MaxDurationSecs,
+		arg.CreatedBy,
+	)
+	var i BreakGlassRules
+	err := row.Scan(
+		&i.ID,
+		&i.Path,
+		&i.IsPrefix,
+		&i.GroupID,
+		&i.MaxDurationSecs,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,171 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pixperk/vaultify/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomBreakGlassSession(t *testing.T, user Users, path string, expiresAt time.Time) BreakGlassSessions {
+	arg := CreateBreakGlassSessionParams{
+		UserID:    user.ID,
+		UserEmail: user.Email,
+		Path:      path,
+		Reason:    "incident " + util.RandomString(4),
+		ExpiresAt: expiresAt,
+	}
+
+	session, err := testQueries.CreateBreakGlassSession(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Path, session.Path)
+	require.Equal(t, arg.Reason, session.Reason)
+	require.False(t, session.ReviewedAt.Valid)
+	return session
+}
+
+func TestGetBreakGlassRuleForPath(t *testing.T) {
+	owner := createRandomUser(t)
+	group := createRandomGroup(t, owner)
+	folder := util.RandomEmail() + "/" + util.RandomString(6)
+
+	rule, err := testQueries.UpsertBreakGlassRule(context.Background(), UpsertBreakGlassRuleParams{
+		Path:            folder,
+		IsPrefix:        true,
+		GroupID:         group.ID,
+		MaxDurationSecs: 3600,
+		CreatedBy:       owner.ID,
+	})
+	require.NoError(t, err)
+
+	path := folder + "/db/password"
+	got, err := testQueries.GetBreakGlassRuleForPath(context.Background(), GetBreakGlassRuleForPathParams{
+		Path:    path,
+		Parents: []string{folder, folder + "/db"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, rule.ID, got.ID)
+	require.Equal(t, group.Slug, got.GroupSlug)
+
+	// a rule on the secret itself wins over the folder's
+	exact, err := testQueries.UpsertBreakGlassRule(context.Background(), UpsertBreakGlassRuleParams{
+		Path:            path,
+		GroupID:         group.ID,
+		MaxDurationSecs: 600,
+		CreatedBy:       owner.ID,
+	})
+	require.NoError(t, err)
+	got, err = testQueries.GetBreakGlassRuleForPath(context.Background(), GetBreakGlassRuleForPathParams{
+		Path:    path,
+		Parents: []string{folder, folder + "/db"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, exact.ID, got.ID)
+
+	rules, err := testQueries.ListBreakGlassRulesForUser(context.Background(), owner.ID)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+
+	deleted, err := testQueries.DeleteBreakGlassRule(context.Background(), DeleteBreakGlassRuleParams{Path: folder, IsPrefix: true})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), deleted)
+
+	_, err = testQueries.GetBreakGlassRuleForPath(context.Background(), GetBreakGlassRuleForPathParams{
+		Path:    folder + "/api/key",
+		Parents: []string{folder, folder + "/api"},
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestFlagAuditLogForBreakGlassSession(t *testing.T) {
+	user := createRandomUser(t)
+	path := util.RandomEmail() + "/" + util.RandomString(6)
+	session := createRandomBreakGlassSession(t, user, path, time.Now().Add(time.Hour))
+	expired := createRandomBreakGlassSession(t, user, path, time.Now().Add(-time.Minute))
+
+	active, err := testQueries.ListActiveBreakGlassSessions(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	require.Equal(t, session.ID, active[0].ID)
+
+	entry, err := testQueries.CreateAuditLog(context.Background(), CreateAuditLogParams{
+		UserID:       user.ID,
+		UserEmail:    user.Email,
+		Action:       "read_secret",
+		ResourcePath: path,
+		Success:      true,
+	})
+	require.NoError(t, err)
+	require.False(t, entry.BreakGlassSessionID.Valid)
+
+	err = testQueries.FlagAuditLog(context.Background(), FlagAuditLogParams{
+		ID:                  entry.ID,
+		BreakGlassSessionID: uuid.NullUUID{UUID: session.ID, Valid: true},
+	})
+	require.NoError(t, err)
+
+	flagged, err := testQueries.ListAuditLogsForBreakGlassSession(context.Background(), uuid.NullUUID{UUID: session.ID, Valid: true})
+	require.NoError(t, err)
+	require.Len(t, flagged, 1)
+	require.Equal(t, entry.ID, flagged[0].ID)
+
+	logs, err := testQueries.FilterAuditLogs(context.Background(), FilterAuditLogsParams{
+		UserEmail: user.Email,
+		Flagged:   sql.NullBool{Bool: true, Valid: true},
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+
+	flagged, err = testQueries.ListAuditLogsForBreakGlassSession(context.Background(), uuid.NullUUID{UUID: expired.ID, Valid: true})
+	require.NoError(t, err)
+	require.Empty(t, flagged)
+}
+
+func TestReviewBreakGlassSession(t *testing.T) {
+	user := createRandomUser(t)
+	session := createRandomBreakGlassSession(t, user, util.RandomEmail()+"/"+util.RandomString(6), time.Now().Add(time.Hour))
+
+	unreviewed, err := testQueries.ListBreakGlassSessions(context.Background(), ListBreakGlassSessionsParams{
+		Reviewed: sql.NullBool{Bool: false, Valid: true},
+		Limit:    1000,
+	})
+	require.NoError(t, err)
+	require.Contains(t, breakGlassSessionIDs(unreviewed), session.ID)
+
+	reviewed, err := testQueries.ReviewBreakGlassSession(context.Background(), ReviewBreakGlassSessionParams{
+		ID:          session.ID,
+		ReviewedBy:  sql.NullString{String: "security@example.com", Valid: true},
+		ReviewNotes: sql.NullString{String: "justified", Valid: true},
+	})
+	require.NoError(t, err)
+	require.True(t, reviewed.ReviewedAt.Valid)
+	require.Equal(t, "justified", reviewed.ReviewNotes.String)
+
+	// a session is reviewed once
+	_, err = testQueries.ReviewBreakGlassSession(context.Background(), ReviewBreakGlassSessionParams{ID: session.ID})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	unreviewed, err = testQueries.ListBreakGlassSessions(context.Background(), ListBreakGlassSessionsParams{
+		Reviewed: sql.NullBool{Bool: false, Valid: true},
+		Limit:    1000,
+	})
+	require.NoError(t, err)
+	require.NotContains(t, breakGlassSessionIDs(unreviewed), session.ID)
+
+	mine, err := testQueries.ListBreakGlassSessionsByUser(context.Background(), ListBreakGlassSessionsByUserParams{UserID: user.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, mine, 1)
+}
+
+func breakGlassSessionIDs(sessions []BreakGlassSessions) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(sessions))
+	for _, s := range sessions {
+		ids = append(ids, s.ID)
+	}
+	return ids
+}
@@ -0,0 +1,20 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: events.sql
+
+package db
+
+import (
+	"context"
+)
+
+const notifyEvent = `-- name: NotifyEvent :exec
+SELECT pg_notify('vaultify_events', $1::text)
+`
+
+// delivered to listeners when the surrounding transaction commits, and dropped if it rolls back
+func (q *Queries) NotifyEvent(ctx context.Context, payload string) error {
+	_, err := q.db.ExecContext(ctx, notifyEvent, payload)
+	return err
+}
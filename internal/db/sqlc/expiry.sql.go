@@ -0,0 +1,222 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: expiry.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const claimExpiryWarning = `-- name: ClaimExpiryWarning :execrows
+INSERT INTO expiry_warnings_sent (resource_type, resource_id, threshold_seconds, expires_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT DO NOTHING
+`
+
+type ClaimExpiryWarningParams struct {
+	ResourceType     string    `json:"resource_type"`
+	ResourceID       uuid.UUID `json:"resource_id"`
+	ThresholdSeconds int64     `json:"threshold_seconds"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
+
+// zero rows affected means the warning has already been sent
+func (q *Queries) ClaimExpiryWarning(ctx context.Context, arg ClaimExpiryWarningParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, claimExpiryWarning,
+		arg.ResourceType,
+		arg.ResourceID,
+		arg.ThresholdSeconds,
+		arg.ExpiresAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteStaleExpiryWarnings = `-- name: DeleteStaleExpiryWarnings :exec
+DELETE FROM expiry_warnings_sent
+WHERE expires_at < now()
+`
+
+func (q *Queries) DeleteStaleExpiryWarnings(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteStaleExpiryWarnings)
+	return err
+}
+
+const listSecretsExpiringBefore = `-- name: ListSecretsExpiringBefore :many
+SELECT s.id, s.path, s.user_id, s.team_id, s.expires_at, u.email AS owner_email
+FROM secrets s
+JOIN users u ON u.id = s.user_id
+WHERE s.expires_at > now() AND s.expires_at <= $1::timestamptz
+ORDER BY s.expires_at, s.path
+`
+
+type ListSecretsExpiringBeforeRow struct {
+	ID         uuid.UUID     `json:"id"`
+	Path       string        `json:"path"`
+	UserID     uuid.UUID     `json:"user_id"`
+	TeamID     uuid.NullUUID `json:"team_id"`
+	ExpiresAt  sql.NullTime  `json:"expires_at"`
+	OwnerEmail string        `json:"owner_email"`
+}
+
+func (q *Queries) ListSecretsExpiringBefore(ctx context.Context, before time.Time) ([]ListSecretsExpiringBeforeRow, error) {
+	rows, err := q.db.QueryContext(ctx, listSecretsExpiringBefore, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListSecretsExpiringBeforeRow{}
+	for rows.Next() {
+		var i ListSecretsExpiringBeforeRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Path,
+			&i.UserID,
+			&i.TeamID,
+			&i.ExpiresAt,
+			&i.OwnerEmail,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSharesExpiringBefore = `-- name: ListSharesExpiringBefore :many
+SELECT id, owner_email, target_email, path, is_prefix, permission, shared_until
+FROM sharing_rules
+WHERE shared_until > now() AND shared_until <= $1::timestamptz
+ORDER BY shared_until, path
+`
+
+type ListSharesExpiringBeforeRow struct {
+	ID          uuid.UUID    `json:"id"`
+	OwnerEmail  string       `json:"owner_email"`
+	TargetEmail string       `json:"target_email"`
+	Path        string       `json:"path"`
+	IsPrefix    bool         `json:"is_prefix"`
+	Permission  string       `json:"permission"`
+	SharedUntil sql.NullTime `json:"shared_until"`
+}
+
+func (q *Queries) ListSharesExpiringBefore(ctx context.Context, before time.Time) ([]ListSharesExpiringBeforeRow, error) {
+	rows, err := q.db.QueryContext(ctx, listSharesExpiringBefore, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListSharesExpiringBeforeRow{}
+	for rows.Next() {
+		var i ListSharesExpiringBeforeRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OwnerEmail,
+			&i.TargetEmail,
+			&i.Path,
+			&i.IsPrefix,
+			&i.Permission,
+			&i.SharedUntil,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSharesExpiringBeforeForUser = `-- name: ListSharesExpiringBeforeForUser :many
+SELECT id, owner_email, target_email, path, is_prefix, permission, shared_until
+FROM sharing_rules
+WHERE shared_until > now() AND shared_until <= $1::timestamptz
+AND (owner_email = $2 OR target_email = $2)
+ORDER BY shared_until, path
+`
+
+type ListSharesExpiringBeforeForUserParams struct {
+	Before time.Time `json:"before"`
+	Email  string    `json:"email"`
+}
+
+type ListSharesExpiringBeforeForUserRow struct {
+	ID          uuid.UUID    `json:"id"`
+	OwnerEmail  string       `json:"owner_email"`
+	TargetEmail string       `json:"target_email"`
+	Path        string       `json:"path"`
+	IsPrefix    bool         `json:"is_prefix"`
+	Permission  string       `json:"permission"`
+	SharedUntil sql.NullTime `json:"shared_until"`
+}
+
+func (q *Queries) ListSharesExpiringBeforeForUser(ctx context.Context, arg ListSharesExpiringBeforeForUserParams) ([]ListSharesExpiringBeforeForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, listSharesExpiringBeforeForUser, arg.Before, arg.Email)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListSharesExpiringBeforeForUserRow{}
+	for rows.Next() {
+		var i ListSharesExpiringBeforeForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OwnerEmail,
+			&i.TargetEmail,
+			&i.Path,
+			&i.IsPrefix,
+			&i.Permission,
+			&i.SharedUntil,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const releaseExpiryWarning = `-- name: ReleaseExpiryWarning :exec
+DELETE FROM expiry_warnings_sent
+WHERE resource_type = $1 AND resource_id = $2 AND threshold_seconds = $3 AND expires_at = $4
+`
+
+type ReleaseExpiryWarningParams struct {
+	ResourceType     string    `json:"resource_type"`
+	ResourceID       uuid.UUID `json:"resource_id"`
+	ThresholdSeconds int64     `json:"threshold_seconds"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
+
+func (q *Queries) ReleaseExpiryWarning(ctx context.Context, arg ReleaseExpiryWarningParams) error {
+	_, err := q.db.ExecContext(ctx, releaseExpiryWarning,
+		arg.ResourceType,
+		arg.ResourceID,
+		arg.ThresholdSeconds,
+		arg.ExpiresAt,
+	)
+	return err
+}
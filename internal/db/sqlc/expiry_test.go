@@ -0,0 +1,43 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaimExpiryWarning(t *testing.T) {
+	secret, _ := createNewSecret(t)
+
+	arg := ClaimExpiryWarningParams{
+		ResourceType:     "secret",
+		ResourceID:       secret.SecretID,
+		ThresholdSeconds: int64((24 * time.Hour).Seconds()),
+		ExpiresAt:        time.Now().Add(12 * time.Hour).Truncate(time.Microsecond),
+	}
+
+	claimed, err := testQueries.ClaimExpiryWarning(context.Background(), arg)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, claimed)
+
+	// the same warning for the same deadline is only sent once
+	claimed, err = testQueries.ClaimExpiryWarning(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, claimed)
+
+	// a new deadline re-arms the warning
+	extended := arg
+	extended.ExpiresAt = arg.ExpiresAt.Add(24 * time.Hour)
+	claimed, err = testQueries.ClaimExpiryWarning(context.Background(), extended)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, claimed)
+
+	// a released claim can be taken again, e.g. after a failed delivery
+	err = testQueries.ReleaseExpiryWarning(context.Background(), ReleaseExpiryWarningParams(arg))
+	require.NoError(t, err)
+	claimed, err = testQueries.ClaimExpiryWarning(context.Background(), arg)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, claimed)
+}
@@ -0,0 +1,234 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: groups.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+const createGroup = `-- name: CreateGroup :one
+INSERT INTO groups (slug, name, created_by)
+VALUES ($1, $2, $3)
+RETURNING id, slug, name, created_by, created_at
+`
+
+type CreateGroupParams struct {
+	Slug      string    `json:"slug"`
+	Name      string    `json:"name"`
+	CreatedBy uuid.UUID `json:"created_by"`
+}
+
+func (q *Queries) CreateGroup(ctx context.Context, arg CreateGroupParams) (Groups, error) {
+	row := q.db.QueryRowContext(ctx, createGroup, arg.Slug, arg.Name, arg.CreatedBy)
+	var i Groups
+	err := row.Scan(
+		&i.ID,
+		&i.Slug,
+		&i.Name,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getGroupBySlug = `-- name: GetGroupBySlug :one
+SELECT id, slug, name, created_by, created_at FROM groups WHERE slug = $1
+`
+
+func (q *Queries) GetGroupBySlug(ctx context.Context, slug string) (Groups, error) {
+	row := q.db.QueryRowContext(ctx, getGroupBySlug, slug)
+	var i Groups
+	err := row.Scan(
+		&i.ID,
+		&i.Slug,
+		&i.Name,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getGroupMemberRole = `-- name: GetGroupMemberRole :one
+SELECT role FROM group_members
+WHERE group_id = $1 AND user_id = $2
+`
+
+type GetGroupMemberRoleParams struct {
+	GroupID uuid.UUID `json:"group_id"`
+	UserID  uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) GetGroupMemberRole(ctx context.Context, arg GetGroupMemberRoleParams) (string, error) {
+	row := q.db.QueryRowContext(ctx, getGroupMemberRole, arg.GroupID, arg.UserID)
+	var role string
+	err := row.Scan(&role)
+	return role, err
+}
+
+const listGroupMembers = `-- name: ListGroupMembers :many
+SELECT u.email, u.name, gm.role, gm.created_at
+FROM group_members gm
+JOIN users u ON u.id = gm.user_id
+WHERE gm.group_id = $1
+ORDER BY u.email
+`
+
+type ListGroupMembersRow struct {
+	Email     string       `json:"email"`
+	Name      string       `json:"name"`
+	Role      string       `json:"role"`
+	CreatedAt sql.NullTime `json:"created_at"`
+}
+
+func (q *Queries) ListGroupMembers(ctx context.Context, groupID uuid.UUID) ([]ListGroupMembersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listGroupMembers, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListGroupMembersRow{}
+	for rows.Next() {
+		var i ListGroupMembersRow
+		if err := rows.Scan(
+			&i.Email,
+			&i.Name,
+			&i.Role,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listGroupsForUser = `-- name: ListGroupsForUser :many
+SELECT g.id, g.slug, g.name, gm.role, g.created_at
+FROM groups g
+JOIN group_members gm ON gm.group_id = g.id
+WHERE gm.user_id = $1
+ORDER BY g.slug
+`
+
+type ListGroupsForUserRow struct {
+	ID        uuid.UUID    `json:"id"`
+	Slug      string       `json:"slug"`
+	Name      string       `json:"name"`
+	Role      string       `json:"role"`
+	CreatedAt sql.NullTime `json:"created_at"`
+}
+
+func (q *Queries) ListGroupsForUser(ctx context.Context, userID uuid.UUID) ([]ListGroupsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, listGroupsForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListGroupsForUserRow{}
+	for rows.Next() {
+		var i ListGroupsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Slug,
+			&i.Name,
+			&i.Role,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockGroupOwners = `-- name: LockGroupOwners :many
+SELECT user_id FROM group_members
+WHERE group_id = $1 AND role = 'owner'
+ORDER BY user_id
+FOR UPDATE
+`
+
+// locks the group's owners so that concurrent demotions see each other
+func (q *Queries) LockGroupOwners(ctx context.Context, groupID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, lockGroupOwners, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []uuid.UUID{}
+	for rows.Next() {
+		var user_id uuid.UUID
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const removeGroupMember = `-- name: RemoveGroupMember :execrows
+DELETE FROM group_members
+WHERE group_id = $1 AND user_id = $2
+`
+
+type RemoveGroupMemberParams struct {
+	GroupID uuid.UUID `json:"group_id"`
+	UserID  uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) RemoveGroupMember(ctx context.Context, arg RemoveGroupMemberParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, removeGroupMember, arg.GroupID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const upsertGroupMember = `-- name: UpsertGroupMember :one
+INSERT INTO group_members (group_id, user_id, role)
+VALUES ($1, $2, $3)
+ON CONFLICT (group_id, user_id) DO UPDATE SET role = EXCLUDED.role
+RETURNING group_id, user_id, role, created_at
+`
+
+type UpsertGroupMemberParams struct {
+	GroupID uuid.UUID `json:"group_id"`
+	UserID  uuid.UUID `json:"user_id"`
+	Role    string    `json:"role"`
+}
+
+func (q *Queries) UpsertGroupMember(ctx context.Context, arg UpsertGroupMemberParams) (GroupMembers, error) {
+	row := q.db.QueryRowContext(ctx, upsertGroupMember, arg.GroupID, arg.UserID, arg.Role)
+	var i GroupMembers
+	err := row.Scan(
+		&i.GroupID,
+		&i.UserID,
+		&i.Role,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,112 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pixperk/vaultify/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomGroup(t *testing.T, owner Users) Groups {
+	arg := CreateGroupParams{
+		Slug:      util.RandomString(8),
+		Name:      util.RandomName(),
+		CreatedBy: owner.ID,
+	}
+
+	group, err := testQueries.CreateGroup(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Slug, group.Slug)
+	require.Equal(t, owner.ID, group.CreatedBy)
+
+	_, err = testQueries.UpsertGroupMember(context.Background(), UpsertGroupMemberParams{
+		GroupID: group.ID,
+		UserID:  owner.ID,
+		Role:    "owner",
+	})
+	require.NoError(t, err)
+
+	return group
+}
+
+func TestGroupMembers(t *testing.T) {
+	owner := createRandomUser(t)
+	member := createRandomUser(t)
+	group := createRandomGroup(t, owner)
+
+	_, err := testQueries.UpsertGroupMember(context.Background(), UpsertGroupMemberParams{GroupID: group.ID, UserID: member.ID, Role: "member"})
+	require.NoError(t, err)
+
+	groups, err := testQueries.ListGroupsForUser(context.Background(), member.ID)
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	require.Equal(t, group.Slug, groups[0].Slug)
+	require.Equal(t, "member", groups[0].Role)
+
+	members, err := testQueries.ListGroupMembers(context.Background(), group.ID)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+
+	owners, err := testQueries.LockGroupOwners(context.Background(), group.ID)
+	require.NoError(t, err)
+	require.Len(t, owners, 1)
+
+	removed, err := testQueries.RemoveGroupMember(context.Background(), RemoveGroupMemberParams{GroupID: group.ID, UserID: member.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), removed)
+
+	_, err = testQueries.GetGroupMemberRole(context.Background(), GetGroupMemberRoleParams{GroupID: group.ID, UserID: member.ID})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestGetEffectiveShareThroughGroup(t *testing.T) {
+	owner := createRandomUser(t)
+	member := createRandomUser(t)
+	group := createRandomGroup(t, owner)
+	_, path := createNewSecret(t)
+
+	_, err := testQueries.UpsertGroupMember(context.Background(), UpsertGroupMemberParams{GroupID: group.ID, UserID: member.ID, Role: "member"})
+	require.NoError(t, err)
+
+	_, err = testQueries.ShareSecret(context.Background(), ShareSecretParams{
+		OwnerEmail:    owner.Email,
+		TargetEmail:   "group:" + group.Slug,
+		Path:          path,
+		Permission:    "write",
+		TargetGroupID: uuid.NullUUID{UUID: group.ID, Valid: true},
+	})
+	require.NoError(t, err)
+
+	effective := GetEffectiveShareParams{TargetEmail: member.Email, UserID: member.ID, Path: path}
+	rule, err := testQueries.GetEffectiveShare(context.Background(), effective)
+	require.NoError(t, err)
+	require.Equal(t, "write", rule.Permission)
+
+	shared, err := testQueries.GetSecretsSharedWithMe(context.Background(), GetSecretsSharedWithMeParams{TargetEmail: member.Email, UserID: member.ID})
+	require.NoError(t, err)
+	require.Len(t, shared, 1)
+
+	// a share with the user beats the share with their group on the same path
+	_, err = testQueries.ShareSecret(context.Background(), ShareSecretParams{
+		OwnerEmail:  owner.Email,
+		TargetEmail: member.Email,
+		Path:        path,
+		Permission:  "read",
+	})
+	require.NoError(t, err)
+	rule, err = testQueries.GetEffectiveShare(context.Background(), effective)
+	require.NoError(t, err)
+	require.Equal(t, "read", rule.Permission)
+	require.False(t, rule.TargetGroupID.Valid)
+
+	// leaving the group ends the group share
+	_, err = testQueries.DeleteSharingRule(context.Background(), DeleteSharingRuleParams{Path: path, TargetEmail: member.Email})
+	require.NoError(t, err)
+	_, err = testQueries.RemoveGroupMember(context.Background(), RemoveGroupMemberParams{GroupID: group.ID, UserID: member.ID})
+	require.NoError(t, err)
+	_, err = testQueries.GetEffectiveShare(context.Background(), effective)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
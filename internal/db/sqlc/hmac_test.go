@@ -135,6 +135,7 @@ func TestGetSecretVersionWithHMAC(t *testing.T) {
 			Valid: true,
 		},
 		HmacSignature: hmacSignature,
+		Environment:   "default",
 	}
 
 	newSecret, err := testQueries.CreateSecretWithVersion(context.Background(), arg)
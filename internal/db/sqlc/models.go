@@ -6,20 +6,87 @@ package db
 
 import (
 	"database/sql"
+	"encoding/json"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+type AccessRequests struct {
+	ID             uuid.UUID      `json:"id"`
+	RequesterID    uuid.UUID      `json:"requester_id"`
+	RequesterEmail string         `json:"requester_email"`
+	Path           string         `json:"path"`
+	IsPrefix       bool           `json:"is_prefix"`
+	Permission     string         `json:"permission"`
+	Justification  string         `json:"justification"`
+	DurationSecs   int64          `json:"duration_secs"`
+	Status         string         `json:"status"`
+	DecidedBy      sql.NullString `json:"decided_by"`
+	DecisionReason sql.NullString `json:"decision_reason"`
+	DecidedAt      sql.NullTime   `json:"decided_at"`
+	SharingRuleID  uuid.NullUUID  `json:"sharing_rule_id"`
+	CreatedAt      time.Time      `json:"created_at"`
+}
+
 type AuditLogs struct {
-	ID              uuid.UUID      `json:"id"`
-	UserID          uuid.UUID      `json:"user_id"`
-	UserEmail       string         `json:"user_email"`
-	Action          string         `json:"action"`
-	ResourceVersion int32          `json:"resource_version"`
-	ResourcePath    string         `json:"resource_path"`
-	Success         bool           `json:"success"`
-	Reason          sql.NullString `json:"reason"`
-	CreatedAt       sql.NullTime   `json:"created_at"`
+	ID                  uuid.UUID      `json:"id"`
+	UserID              uuid.UUID      `json:"user_id"`
+	UserEmail           string         `json:"user_email"`
+	Action              string         `json:"action"`
+	ResourceVersion     int32          `json:"resource_version"`
+	ResourcePath        string         `json:"resource_path"`
+	Success             bool           `json:"success"`
+	Reason              sql.NullString `json:"reason"`
+	CreatedAt           sql.NullTime   `json:"created_at"`
+	BreakGlassSessionID uuid.NullUUID  `json:"break_glass_session_id"`
+}
+
+type BreakGlassRules struct {
+	ID              uuid.UUID `json:"id"`
+	Path            string    `json:"path"`
+	IsPrefix        bool      `json:"is_prefix"`
+	GroupID         uuid.UUID `json:"group_id"`
+	MaxDurationSecs int64     `json:"max_duration_secs"`
+	CreatedBy       uuid.UUID `json:"created_by"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type BreakGlassSessions struct {
+	ID          uuid.UUID      `json:"id"`
+	RuleID      uuid.NullUUID  `json:"rule_id"`
+	UserID      uuid.UUID      `json:"user_id"`
+	UserEmail   string         `json:"user_email"`
+	Path        string         `json:"path"`
+	Reason      string         `json:"reason"`
+	ExpiresAt   time.Time      `json:"expires_at"`
+	ReviewedBy  sql.NullString `json:"reviewed_by"`
+	ReviewNotes sql.NullString `json:"review_notes"`
+	ReviewedAt  sql.NullTime   `json:"reviewed_at"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+type ExpiryWarningsSent struct {
+	ResourceType     string    `json:"resource_type"`
+	ResourceID       uuid.UUID `json:"resource_id"`
+	ThresholdSeconds int64     `json:"threshold_seconds"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	SentAt           time.Time `json:"sent_at"`
+}
+
+type GroupMembers struct {
+	GroupID   uuid.UUID    `json:"group_id"`
+	UserID    uuid.UUID    `json:"user_id"`
+	Role      string       `json:"role"`
+	CreatedAt sql.NullTime `json:"created_at"`
+}
+
+type Groups struct {
+	ID        uuid.UUID    `json:"id"`
+	Slug      string       `json:"slug"`
+	Name      string       `json:"name"`
+	CreatedBy uuid.UUID    `json:"created_by"`
+	CreatedAt sql.NullTime `json:"created_at"`
 }
 
 type HmacKeys struct {
@@ -29,6 +96,72 @@ type HmacKeys struct {
 	IsActive  sql.NullBool `json:"is_active"`
 }
 
+type OrgMembers struct {
+	OrgID     uuid.UUID    `json:"org_id"`
+	UserID    uuid.UUID    `json:"user_id"`
+	Role      string       `json:"role"`
+	CreatedAt sql.NullTime `json:"created_at"`
+}
+
+type Organizations struct {
+	ID        uuid.UUID    `json:"id"`
+	Slug      string       `json:"slug"`
+	Name      string       `json:"name"`
+	CreatedBy uuid.UUID    `json:"created_by"`
+	CreatedAt sql.NullTime `json:"created_at"`
+}
+
+type QuorumApprovals struct {
+	RequestID     uuid.UUID `json:"request_id"`
+	ApproverID    uuid.UUID `json:"approver_id"`
+	ApproverEmail string    `json:"approver_email"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type QuorumRequests struct {
+	ID                uuid.UUID    `json:"id"`
+	SecretID          uuid.UUID    `json:"secret_id"`
+	Environment       string       `json:"environment"`
+	RequesterID       uuid.UUID    `json:"requester_id"`
+	RequesterEmail    string       `json:"requester_email"`
+	RequiredApprovals int32        `json:"required_approvals"`
+	ReadTtlSecs       int64        `json:"read_ttl_secs"`
+	Status            string       `json:"status"`
+	ExpiresAt         time.Time    `json:"expires_at"`
+	GrantedUntil      sql.NullTime `json:"granted_until"`
+	ConsumedAt        sql.NullTime `json:"consumed_at"`
+	CreatedAt         time.Time    `json:"created_at"`
+}
+
+type RotationPolicies struct {
+	ID              uuid.UUID      `json:"id"`
+	SecretID        uuid.UUID      `json:"secret_id"`
+	Environment     string         `json:"environment"`
+	IntervalSeconds sql.NullInt64  `json:"interval_seconds"`
+	CronExpr        sql.NullString `json:"cron_expr"`
+	Generator       string         `json:"generator"`
+	PasswordLength  int32          `json:"password_length"`
+	PasswordSymbols bool           `json:"password_symbols"`
+	HookTarget      sql.NullString `json:"hook_target"`
+	NextRotationAt  time.Time      `json:"next_rotation_at"`
+	LastRotatedAt   sql.NullTime   `json:"last_rotated_at"`
+	LastError       sql.NullString `json:"last_error"`
+	FailureCount    int32          `json:"failure_count"`
+	CreatedBy       uuid.UUID      `json:"created_by"`
+	CreatedAt       sql.NullTime   `json:"created_at"`
+	LeaseID         uuid.NullUUID  `json:"lease_id"`
+	LeaseExpiresAt  sql.NullTime   `json:"lease_expires_at"`
+}
+
+type SecretQuorums struct {
+	SecretID          uuid.UUID `json:"secret_id"`
+	RequiredApprovals int32     `json:"required_approvals"`
+	WindowSecs        int64     `json:"window_secs"`
+	ReadTtlSecs       int64     `json:"read_ttl_secs"`
+	CreatedBy         uuid.UUID `json:"created_by"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
 type SecretVersions struct {
 	ID             uuid.UUID     `json:"id"`
 	SecretID       uuid.UUID     `json:"secret_id"`
@@ -39,25 +172,44 @@ type SecretVersions struct {
 	CreatedBy      uuid.NullUUID `json:"created_by"`
 	HmacSignature  []byte        `json:"hmac_signature"`
 	HmacKeyID      uuid.NullUUID `json:"hmac_key_id"`
+	Environment    string        `json:"environment"`
 }
 
 type Secrets struct {
-	ID        uuid.UUID    `json:"id"`
+	ID        uuid.UUID     `json:"id"`
+	UserID    uuid.UUID     `json:"user_id"`
+	Path      string        `json:"path"`
+	CreatedAt sql.NullTime  `json:"created_at"`
+	UpdatedAt sql.NullTime  `json:"updated_at"`
+	ExpiresAt sql.NullTime  `json:"expires_at"`
+	TeamID    uuid.NullUUID `json:"team_id"`
+}
+
+type SharingRules struct {
+	ID            uuid.UUID     `json:"id"`
+	OwnerEmail    string        `json:"owner_email"`
+	TargetEmail   string        `json:"target_email"`
+	Path          string        `json:"path"`
+	Permission    string        `json:"permission"`
+	CreatedAt     sql.NullTime  `json:"created_at"`
+	SharedUntil   sql.NullTime  `json:"shared_until"`
+	IsPrefix      bool          `json:"is_prefix"`
+	TargetGroupID uuid.NullUUID `json:"target_group_id"`
+}
+
+type TeamMembers struct {
+	TeamID    uuid.UUID    `json:"team_id"`
 	UserID    uuid.UUID    `json:"user_id"`
-	Path      string       `json:"path"`
+	Role      string       `json:"role"`
 	CreatedAt sql.NullTime `json:"created_at"`
-	UpdatedAt sql.NullTime `json:"updated_at"`
-	ExpiresAt sql.NullTime `json:"expires_at"`
 }
 
-type SharingRules struct {
-	ID          uuid.UUID    `json:"id"`
-	OwnerEmail  string       `json:"owner_email"`
-	TargetEmail string       `json:"target_email"`
-	Path        string       `json:"path"`
-	Permission  string       `json:"permission"`
-	CreatedAt   sql.NullTime `json:"created_at"`
-	SharedUntil sql.NullTime `json:"shared_until"`
+type Teams struct {
+	ID        uuid.UUID    `json:"id"`
+	OrgID     uuid.UUID    `json:"org_id"`
+	Slug      string       `json:"slug"`
+	Name      string       `json:"name"`
+	CreatedAt sql.NullTime `json:"created_at"`
 }
 
 type Users struct {
@@ -66,4 +218,30 @@ type Users struct {
 	Name         string       `json:"name"`
 	PasswordHash string       `json:"password_hash"`
 	CreatedAt    sql.NullTime `json:"created_at"`
+	Role         string       `json:"role"`
+}
+
+type WebhookDeliveries struct {
+	ID             uuid.UUID       `json:"id"`
+	SubscriptionID uuid.UUID       `json:"subscription_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int32           `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastStatusCode sql.NullInt32   `json:"last_status_code"`
+	LastError      sql.NullString  `json:"last_error"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    sql.NullTime    `json:"delivered_at"`
+}
+
+type WebhookSubscriptions struct {
+	ID               uuid.UUID    `json:"id"`
+	UserID           uuid.UUID    `json:"user_id"`
+	Url              string       `json:"url"`
+	PathPrefix       string       `json:"path_prefix"`
+	EventTypes       []string     `json:"event_types"`
+	SecretCiphertext []byte       `json:"secret_ciphertext"`
+	SecretNonce      []byte       `json:"secret_nonce"`
+	CreatedAt        sql.NullTime `json:"created_at"`
 }
@@ -0,0 +1,499 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: organizations.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+const createOrganization = `-- name: CreateOrganization :one
+INSERT INTO organizations (slug, name, created_by)
+VALUES ($1, $2, $3)
+RETURNING id, slug, name, created_by, created_at
+`
+
+type CreateOrganizationParams struct {
+	Slug      string    `json:"slug"`
+	Name      string    `json:"name"`
+	CreatedBy uuid.UUID `json:"created_by"`
+}
+
+func (q *Queries) CreateOrganization(ctx context.Context, arg CreateOrganizationParams) (Organizations, error) {
+	row := q.db.QueryRowContext(ctx, createOrganization, arg.Slug, arg.Name, arg.CreatedBy)
+	var i Organizations
+	err := row.Scan(
+		&i.ID,
+		&i.Slug,
+		&i.Name,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createTeam = `-- name: CreateTeam :one
+INSERT INTO teams (org_id, slug, name)
+VALUES ($1, $2, $3)
+RETURNING id, org_id, slug, name, created_at
+`
+
+type CreateTeamParams struct {
+	OrgID uuid.UUID `json:"org_id"`
+	Slug  string    `json:"slug"`
+	Name  string    `json:"name"`
+}
+
+func (q *Queries) CreateTeam(ctx context.Context, arg CreateTeamParams) (Teams, error) {
+	row := q.db.QueryRowContext(ctx, createTeam, arg.OrgID, arg.Slug, arg.Name)
+	var i Teams
+	err := row.Scan(
+		&i.ID,
+		&i.OrgID,
+		&i.Slug,
+		&i.Name,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getOrgMemberRole = `-- name: GetOrgMemberRole :one
+SELECT role FROM org_members
+WHERE org_id = $1 AND user_id = $2
+`
+
+type GetOrgMemberRoleParams struct {
+	OrgID  uuid.UUID `json:"org_id"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) GetOrgMemberRole(ctx context.Context, arg GetOrgMemberRoleParams) (string, error) {
+	row := q.db.QueryRowContext(ctx, getOrgMemberRole, arg.OrgID, arg.UserID)
+	var role string
+	err := row.Scan(&role)
+	return role, err
+}
+
+const getOrganizationBySlug = `-- name: GetOrganizationBySlug :one
+SELECT id, slug, name, created_by, created_at FROM organizations WHERE slug = $1
+`
+
+func (q *Queries) GetOrganizationBySlug(ctx context.Context, slug string) (Organizations, error) {
+	row := q.db.QueryRowContext(ctx, getOrganizationBySlug, slug)
+	var i Organizations
+	err := row.Scan(
+		&i.ID,
+		&i.Slug,
+		&i.Name,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getTeamAccessForUser = `-- name: GetTeamAccessForUser :one
+SELECT t.id AS team_id, t.org_id,
+       COALESCE(tm.role, '')::TEXT AS team_role,
+       COALESCE(om.role, '')::TEXT AS org_role
+FROM teams t
+LEFT JOIN team_members tm ON tm.team_id = t.id AND tm.user_id = $2
+LEFT JOIN org_members om ON om.org_id = t.org_id AND om.user_id = $2
+WHERE t.id = $1
+`
+
+type GetTeamAccessForUserParams struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+type GetTeamAccessForUserRow struct {
+	TeamID   uuid.UUID `json:"team_id"`
+	OrgID    uuid.UUID `json:"org_id"`
+	TeamRole string    `json:"team_role"`
+	OrgRole  string    `json:"org_role"`
+}
+
+func (q *Queries) GetTeamAccessForUser(ctx context.Context, arg GetTeamAccessForUserParams) (GetTeamAccessForUserRow, error) {
+	row := q.db.QueryRowContext(ctx, getTeamAccessForUser, arg.ID, arg.UserID)
+	var i GetTeamAccessForUserRow
+	err := row.Scan(
+		&i.TeamID,
+		&i.OrgID,
+		&i.TeamRole,
+		&i.OrgRole,
+	)
+	return i, err
+}
+
+const getTeamBySlugs = `-- name: GetTeamBySlugs :one
+SELECT t.id, t.org_id, t.slug, t.name, t.created_at
+FROM teams t
+JOIN organizations o ON o.id = t.org_id
+WHERE o.slug = $1 AND t.slug = $2
+`
+
+type GetTeamBySlugsParams struct {
+	Slug   string `json:"slug"`
+	Slug_2 string `json:"slug_2"`
+}
+
+func (q *Queries) GetTeamBySlugs(ctx context.Context, arg GetTeamBySlugsParams) (Teams, error) {
+	row := q.db.QueryRowContext(ctx, getTeamBySlugs, arg.Slug, arg.Slug_2)
+	var i Teams
+	err := row.Scan(
+		&i.ID,
+		&i.OrgID,
+		&i.Slug,
+		&i.Name,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listOrgMembers = `-- name: ListOrgMembers :many
+SELECT u.email, u.name, om.role, om.created_at
+FROM org_members om
+JOIN users u ON u.id = om.user_id
+WHERE om.org_id = $1
+ORDER BY u.email
+`
+
+type ListOrgMembersRow struct {
+	Email     string       `json:"email"`
+	Name      string       `json:"name"`
+	Role      string       `json:"role"`
+	CreatedAt sql.NullTime `json:"created_at"`
+}
+
+func (q *Queries) ListOrgMembers(ctx context.Context, orgID uuid.UUID) ([]ListOrgMembersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listOrgMembers, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListOrgMembersRow{}
+	for rows.Next() {
+		var i ListOrgMembersRow
+		if err := rows.Scan(
+			&i.Email,
+			&i.Name,
+			&i.Role,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrganizationsForUser = `-- name: ListOrganizationsForUser :many
+SELECT o.id, o.slug, o.name, om.role, o.created_at
+FROM organizations o
+JOIN org_members om ON om.org_id = o.id
+WHERE om.user_id = $1
+ORDER BY o.slug
+`
+
+type ListOrganizationsForUserRow struct {
+	ID        uuid.UUID    `json:"id"`
+	Slug      string       `json:"slug"`
+	Name      string       `json:"name"`
+	Role      string       `json:"role"`
+	CreatedAt sql.NullTime `json:"created_at"`
+}
+
+func (q *Queries) ListOrganizationsForUser(ctx context.Context, userID uuid.UUID) ([]ListOrganizationsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, listOrganizationsForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListOrganizationsForUserRow{}
+	for rows.Next() {
+		var i ListOrganizationsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Slug,
+			&i.Name,
+			&i.Role,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTeamAccess = `-- name: ListTeamAccess :many
+SELECT u.email,
+       COALESCE(tm.role, '')::TEXT AS team_role,
+       om.role AS org_role,
+       COALESCE(tm.created_at, om.created_at) AS created_at
+FROM teams t
+JOIN org_members om ON om.org_id = t.org_id
+JOIN users u ON u.id = om.user_id
+LEFT JOIN team_members tm ON tm.team_id = t.id AND tm.user_id = om.user_id
+WHERE t.id = $1
+  AND (tm.role IS NOT NULL OR om.role IN ('owner', 'admin'))
+ORDER BY u.email
+`
+
+type ListTeamAccessRow struct {
+	Email     string       `json:"email"`
+	TeamRole  string       `json:"team_role"`
+	OrgRole   string       `json:"org_role"`
+	CreatedAt sql.NullTime `json:"created_at"`
+}
+
+// everyone whose team access counts: team members still in the organization, and its owners and admins
+func (q *Queries) ListTeamAccess(ctx context.Context, id uuid.UUID) ([]ListTeamAccessRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTeamAccess, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTeamAccessRow{}
+	for rows.Next() {
+		var i ListTeamAccessRow
+		if err := rows.Scan(
+			&i.Email,
+			&i.TeamRole,
+			&i.OrgRole,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTeamMembers = `-- name: ListTeamMembers :many
+SELECT u.email, u.name, tm.role, tm.created_at
+FROM team_members tm
+JOIN users u ON u.id = tm.user_id
+WHERE tm.team_id = $1
+ORDER BY u.email
+`
+
+type ListTeamMembersRow struct {
+	Email     string       `json:"email"`
+	Name      string       `json:"name"`
+	Role      string       `json:"role"`
+	CreatedAt sql.NullTime `json:"created_at"`
+}
+
+func (q *Queries) ListTeamMembers(ctx context.Context, teamID uuid.UUID) ([]ListTeamMembersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTeamMembers, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTeamMembersRow{}
+	for rows.Next() {
+		var i ListTeamMembersRow
+		if err := rows.Scan(
+			&i.Email,
+			&i.Name,
+			&i.Role,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTeamsForOrg = `-- name: ListTeamsForOrg :many
+SELECT id, org_id, slug, name, created_at FROM teams
+WHERE org_id = $1
+ORDER BY slug
+`
+
+func (q *Queries) ListTeamsForOrg(ctx context.Context, orgID uuid.UUID) ([]Teams, error) {
+	rows, err := q.db.QueryContext(ctx, listTeamsForOrg, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Teams{}
+	for rows.Next() {
+		var i Teams
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrgID,
+			&i.Slug,
+			&i.Name,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockOrgOwners = `-- name: LockOrgOwners :many
+SELECT user_id FROM org_members
+WHERE org_id = $1 AND role = 'owner'
+ORDER BY user_id
+FOR UPDATE
+`
+
+// locks the organization's owners so that concurrent demotions see each other
+func (q *Queries) LockOrgOwners(ctx context.Context, orgID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, lockOrgOwners, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []uuid.UUID{}
+	for rows.Next() {
+		var user_id uuid.UUID
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const removeOrgMember = `-- name: RemoveOrgMember :exec
+DELETE FROM org_members
+WHERE org_id = $1 AND user_id = $2
+`
+
+type RemoveOrgMemberParams struct {
+	OrgID  uuid.UUID `json:"org_id"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) RemoveOrgMember(ctx context.Context, arg RemoveOrgMemberParams) error {
+	_, err := q.db.ExecContext(ctx, removeOrgMember, arg.OrgID, arg.UserID)
+	return err
+}
+
+const removeTeamMember = `-- name: RemoveTeamMember :exec
+DELETE FROM team_members
+WHERE team_id = $1 AND user_id = $2
+`
+
+type RemoveTeamMemberParams struct {
+	TeamID uuid.UUID `json:"team_id"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) RemoveTeamMember(ctx context.Context, arg RemoveTeamMemberParams) error {
+	_, err := q.db.ExecContext(ctx, removeTeamMember, arg.TeamID, arg.UserID)
+	return err
+}
+
+const removeUserFromOrgTeams = `-- name: RemoveUserFromOrgTeams :exec
+DELETE FROM team_members
+WHERE user_id = $2
+  AND team_id IN (SELECT id FROM teams WHERE org_id = $1)
+`
+
+type RemoveUserFromOrgTeamsParams struct {
+	OrgID  uuid.UUID `json:"org_id"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) RemoveUserFromOrgTeams(ctx context.Context, arg RemoveUserFromOrgTeamsParams) error {
+	_, err := q.db.ExecContext(ctx, removeUserFromOrgTeams, arg.OrgID, arg.UserID)
+	return err
+}
+
+const upsertOrgMember = `-- name: UpsertOrgMember :one
+INSERT INTO org_members (org_id, user_id, role)
+VALUES ($1, $2, $3)
+ON CONFLICT (org_id, user_id) DO UPDATE SET role = EXCLUDED.role
+RETURNING org_id, user_id, role, created_at
+`
+
+type UpsertOrgMemberParams struct {
+	OrgID  uuid.UUID `json:"org_id"`
+	UserID uuid.UUID `json:"user_id"`
+	Role   string    `json:"role"`
+}
+
+func (q *Queries) UpsertOrgMember(ctx context.Context, arg UpsertOrgMemberParams) (OrgMembers, error) {
+	row := q.db.QueryRowContext(ctx, upsertOrgMember, arg.OrgID, arg.UserID, arg.Role)
+	var i OrgMembers
+	err := row.Scan(
+		&i.OrgID,
+		&i.UserID,
+		&i.Role,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const upsertTeamMember = `-- name: UpsertTeamMember :one
+INSERT INTO team_members (team_id, user_id, role)
+VALUES ($1, $2, $3)
+ON CONFLICT (team_id, user_id) DO UPDATE SET role = EXCLUDED.role
+RETURNING team_id, user_id, role, created_at
+`
+
+type UpsertTeamMemberParams struct {
+	TeamID uuid.UUID `json:"team_id"`
+	UserID uuid.UUID `json:"user_id"`
+	Role   string    `json:"role"`
+}
+
+func (q *Queries) UpsertTeamMember(ctx context.Context, arg UpsertTeamMemberParams) (TeamMembers, error) {
+	row := q.db.QueryRowContext(ctx, upsertTeamMember, arg.TeamID, arg.UserID, arg.Role)
+	var i TeamMembers
+	err := row.Scan(
+		&i.TeamID,
+		&i.UserID,
+		&i.Role,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,154 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pixperk/vaultify/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomOrganization(t *testing.T, owner Users) Organizations {
+	arg := CreateOrganizationParams{
+		Slug:      util.RandomString(8),
+		Name:      util.RandomName(),
+		CreatedBy: owner.ID,
+	}
+
+	org, err := testQueries.CreateOrganization(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Slug, org.Slug)
+	require.Equal(t, arg.Name, org.Name)
+	require.Equal(t, owner.ID, org.CreatedBy)
+	require.NotZero(t, org.CreatedAt)
+
+	_, err = testQueries.UpsertOrgMember(context.Background(), UpsertOrgMemberParams{
+		OrgID:  org.ID,
+		UserID: owner.ID,
+		Role:   "owner",
+	})
+	require.NoError(t, err)
+
+	return org
+}
+
+func createRandomTeam(t *testing.T, org Organizations) Teams {
+	team, err := testQueries.CreateTeam(context.Background(), CreateTeamParams{
+		OrgID: org.ID,
+		Slug:  util.RandomString(8),
+		Name:  util.RandomName(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, org.ID, team.OrgID)
+
+	return team
+}
+
+func TestCreateOrganization(t *testing.T) {
+	owner := createRandomUser(t)
+	org := createRandomOrganization(t, owner)
+
+	found, err := testQueries.GetOrganizationBySlug(context.Background(), org.Slug)
+	require.NoError(t, err)
+	require.Equal(t, org.ID, found.ID)
+
+	orgs, err := testQueries.ListOrganizationsForUser(context.Background(), owner.ID)
+	require.NoError(t, err)
+	require.Len(t, orgs, 1)
+	require.Equal(t, "owner", orgs[0].Role)
+
+	owners, err := testQueries.LockOrgOwners(context.Background(), org.ID)
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{owner.ID}, owners)
+}
+
+func TestUpsertOrgMemberChangesRole(t *testing.T) {
+	owner := createRandomUser(t)
+	member := createRandomUser(t)
+	org := createRandomOrganization(t, owner)
+
+	arg := UpsertOrgMemberParams{OrgID: org.ID, UserID: member.ID, Role: "member"}
+	_, err := testQueries.UpsertOrgMember(context.Background(), arg)
+	require.NoError(t, err)
+
+	arg.Role = "admin"
+	updated, err := testQueries.UpsertOrgMember(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, "admin", updated.Role)
+
+	members, err := testQueries.ListOrgMembers(context.Background(), org.ID)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+}
+
+func TestGetTeamAccessForUser(t *testing.T) {
+	owner := createRandomUser(t)
+	member := createRandomUser(t)
+	outsider := createRandomUser(t)
+	org := createRandomOrganization(t, owner)
+	team := createRandomTeam(t, org)
+
+	_, err := testQueries.UpsertOrgMember(context.Background(), UpsertOrgMemberParams{OrgID: org.ID, UserID: member.ID, Role: "member"})
+	require.NoError(t, err)
+	_, err = testQueries.UpsertTeamMember(context.Background(), UpsertTeamMemberParams{TeamID: team.ID, UserID: member.ID, Role: "viewer"})
+	require.NoError(t, err)
+
+	access, err := testQueries.GetTeamAccessForUser(context.Background(), GetTeamAccessForUserParams{ID: team.ID, UserID: member.ID})
+	require.NoError(t, err)
+	require.Equal(t, "viewer", access.TeamRole)
+	require.Equal(t, "member", access.OrgRole)
+
+	access, err = testQueries.GetTeamAccessForUser(context.Background(), GetTeamAccessForUserParams{ID: team.ID, UserID: owner.ID})
+	require.NoError(t, err)
+	require.Empty(t, access.TeamRole)
+	require.Equal(t, "owner", access.OrgRole)
+
+	access, err = testQueries.GetTeamAccessForUser(context.Background(), GetTeamAccessForUserParams{ID: team.ID, UserID: outsider.ID})
+	require.NoError(t, err)
+	require.Empty(t, access.TeamRole)
+	require.Empty(t, access.OrgRole)
+
+	// Leaving the organization drops every team membership in it
+	err = testQueries.RemoveUserFromOrgTeams(context.Background(), RemoveUserFromOrgTeamsParams{OrgID: org.ID, UserID: member.ID})
+	require.NoError(t, err)
+	err = testQueries.RemoveOrgMember(context.Background(), RemoveOrgMemberParams{OrgID: org.ID, UserID: member.ID})
+	require.NoError(t, err)
+
+	access, err = testQueries.GetTeamAccessForUser(context.Background(), GetTeamAccessForUserParams{ID: team.ID, UserID: member.ID})
+	require.NoError(t, err)
+	require.Empty(t, access.TeamRole)
+	require.Empty(t, access.OrgRole)
+}
+
+func TestListTeamAccess(t *testing.T) {
+	owner := createRandomUser(t)
+	member := createRandomUser(t)
+	former := createRandomUser(t)
+	bystander := createRandomUser(t)
+	org := createRandomOrganization(t, owner)
+	team := createRandomTeam(t, org)
+
+	_, err := testQueries.UpsertOrgMember(context.Background(), UpsertOrgMemberParams{OrgID: org.ID, UserID: member.ID, Role: "member"})
+	require.NoError(t, err)
+	_, err = testQueries.UpsertTeamMember(context.Background(), UpsertTeamMemberParams{TeamID: team.ID, UserID: member.ID, Role: "viewer"})
+	require.NoError(t, err)
+	_, err = testQueries.UpsertOrgMember(context.Background(), UpsertOrgMemberParams{OrgID: org.ID, UserID: bystander.ID, Role: "member"})
+	require.NoError(t, err)
+	// a team role without organization membership does not count
+	_, err = testQueries.UpsertTeamMember(context.Background(), UpsertTeamMemberParams{TeamID: team.ID, UserID: former.ID, Role: "member"})
+	require.NoError(t, err)
+
+	access, err := testQueries.ListTeamAccess(context.Background(), team.ID)
+	require.NoError(t, err)
+	require.Len(t, access, 2)
+
+	roles := make(map[string]ListTeamAccessRow)
+	for _, row := range access {
+		roles[row.Email] = row
+	}
+	require.Equal(t, "owner", roles[owner.Email].OrgRole)
+	require.Empty(t, roles[owner.Email].TeamRole)
+	require.Equal(t, "viewer", roles[member.Email].TeamRole)
+	require.True(t, roles[member.Email].CreatedAt.Valid)
+}
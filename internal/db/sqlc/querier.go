@@ -6,37 +6,178 @@ package db
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type Querier interface {
+	AddQuorumApproval(ctx context.Context, arg AddQuorumApprovalParams) (QuorumApprovals, error)
 	CheckIfShared(ctx context.Context, arg CheckIfSharedParams) (bool, error)
+	// pushing next_attempt_at forward leases the deliveries to this instance while they are sent,
+	// so the HTTP calls happen outside of any transaction
+	ClaimDueWebhookDeliveries(ctx context.Context, arg ClaimDueWebhookDeliveriesParams) ([]WebhookDeliveries, error)
+	// zero rows affected means the warning has already been sent
+	ClaimExpiryWarning(ctx context.Context, arg ClaimExpiryWarningParams) (int64, error)
+	// uses up one granted read of the secret; concurrent reads cannot both take the same grant
+	ConsumeQuorumGrant(ctx context.Context, arg ConsumeQuorumGrantParams) (QuorumRequests, error)
+	CreateAccessRequest(ctx context.Context, arg CreateAccessRequestParams) (AccessRequests, error)
 	CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) (AuditLogs, error)
+	CreateBreakGlassSession(ctx context.Context, arg CreateBreakGlassSessionParams) (BreakGlassSessions, error)
+	CreateGroup(ctx context.Context, arg CreateGroupParams) (Groups, error)
 	CreateNewSecretVersion(ctx context.Context, arg CreateNewSecretVersionParams) (SecretVersions, error)
+	CreateOrganization(ctx context.Context, arg CreateOrganizationParams) (Organizations, error)
+	CreateQuorumRequest(ctx context.Context, arg CreateQuorumRequestParams) (QuorumRequests, error)
 	CreateSecretWithVersion(ctx context.Context, arg CreateSecretWithVersionParams) (SecretVersions, error)
+	CreateTeam(ctx context.Context, arg CreateTeamParams) (Teams, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (Users, error)
+	CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) (WebhookDeliveries, error)
+	CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) (WebhookSubscriptions, error)
 	DeactivateAllHMACKeys(ctx context.Context) error
-	DeleteExpiredSecretAndVersions(ctx context.Context) error
-	DeleteExpiredSharingRules(ctx context.Context) error
+	// only a pending request can be decided, so concurrent decisions cannot both succeed
+	DecideAccessRequest(ctx context.Context, arg DecideAccessRequestParams) (AccessRequests, error)
+	DeleteBreakGlassRule(ctx context.Context, arg DeleteBreakGlassRuleParams) (int64, error)
+	DeleteExpiredSecretAndVersions(ctx context.Context) ([]DeleteExpiredSecretAndVersionsRow, error)
+	DeleteExpiredSharingRules(ctx context.Context) ([]SharingRules, error)
+	DeleteRotationPolicy(ctx context.Context, arg DeleteRotationPolicyParams) error
 	DeleteSecretAndVersionsByPath(ctx context.Context, path string) error
+	DeleteSecretQuorum(ctx context.Context, secretID uuid.UUID) (int64, error)
+	DeleteSharingRule(ctx context.Context, arg DeleteSharingRuleParams) ([]SharingRules, error)
+	DeleteSharingRulesByPath(ctx context.Context, arg DeleteSharingRulesByPathParams) ([]SharingRules, error)
+	DeleteStaleExpiryWarnings(ctx context.Context) error
+	DeleteWebhookSubscription(ctx context.Context, arg DeleteWebhookSubscriptionParams) (int64, error)
 	FilterAuditLogs(ctx context.Context, arg FilterAuditLogsParams) ([]AuditLogs, error)
+	FlagAuditLog(ctx context.Context, arg FlagAuditLogParams) error
+	GetAccessRequest(ctx context.Context, id uuid.UUID) (AccessRequests, error)
 	GetActiveHMACKey(ctx context.Context) (HmacKeys, error)
-	GetAllSecretVersionsByPath(ctx context.Context, path string) ([]SecretVersions, error)
+	GetAllSecretVersionsByPath(ctx context.Context, arg GetAllSecretVersionsByPathParams) ([]SecretVersions, error)
+	// the rule for the secret itself, or else the one of the closest folder above it in parents
+	GetBreakGlassRuleForPath(ctx context.Context, arg GetBreakGlassRuleForPathParams) (GetBreakGlassRuleForPathRow, error)
+	GetBreakGlassSession(ctx context.Context, id uuid.UUID) (BreakGlassSessions, error)
+	// most specific rule wins: an exact share of the secret, then the share of the closest folder
+	// above it, so a narrower rule can also restrict what a broader one grants. Among rules on the
+	// same path a share with the user beats shares with their groups, and write beats read.
+	GetEffectiveShare(ctx context.Context, arg GetEffectiveShareParams) (SharingRules, error)
+	GetGroupBySlug(ctx context.Context, slug string) (Groups, error)
+	GetGroupMemberRole(ctx context.Context, arg GetGroupMemberRoleParams) (string, error)
 	GetHMACKeyByID(ctx context.Context, id uuid.UUID) (HmacKeys, error)
-	GetLatestSecretByPath(ctx context.Context, path string) (GetLatestSecretByPathRow, error)
+	GetLatestEnvironmentVersionsByPrefix(ctx context.Context, prefix string) ([]GetLatestEnvironmentVersionsByPrefixRow, error)
+	GetLatestSecretByPath(ctx context.Context, arg GetLatestSecretByPathParams) (GetLatestSecretByPathRow, error)
 	GetLatestSecretsForUser(ctx context.Context, userID uuid.UUID) ([]GetLatestSecretsForUserRow, error)
 	GetLatestVersionNumberByPath(ctx context.Context, path string) (interface{}, error)
+	GetLatestVersionsBySecretID(ctx context.Context, secretID uuid.UUID) ([]SecretVersions, error)
+	// the caller's request for the secret that is still waiting for approvals
+	GetOpenQuorumRequest(ctx context.Context, arg GetOpenQuorumRequestParams) (QuorumRequests, error)
+	GetOrgMemberRole(ctx context.Context, arg GetOrgMemberRoleParams) (string, error)
+	GetOrganizationBySlug(ctx context.Context, slug string) (Organizations, error)
 	GetPermissions(ctx context.Context, arg GetPermissionsParams) (string, error)
+	GetQuorumRequest(ctx context.Context, id uuid.UUID) (QuorumRequests, error)
+	GetQuorumRequestForUpdate(ctx context.Context, id uuid.UUID) (QuorumRequests, error)
+	GetRotationPolicy(ctx context.Context, arg GetRotationPolicyParams) (RotationPolicies, error)
+	GetSecretByID(ctx context.Context, id uuid.UUID) (Secrets, error)
+	GetSecretByPath(ctx context.Context, path string) (Secrets, error)
+	GetSecretByPathForUpdate(ctx context.Context, path string) (Secrets, error)
+	GetSecretQuorum(ctx context.Context, secretID uuid.UUID) (SecretQuorums, error)
 	GetSecretVersionByPathAndVersion(ctx context.Context, arg GetSecretVersionByPathAndVersionParams) (GetSecretVersionByPathAndVersionRow, error)
 	GetSecretVersionWithHMAC(ctx context.Context, arg GetSecretVersionWithHMACParams) (GetSecretVersionWithHMACRow, error)
-	GetSecretsSharedWithMe(ctx context.Context, targetEmail string) ([]GetSecretsSharedWithMeRow, error)
+	GetSecretsSharedWithMe(ctx context.Context, arg GetSecretsSharedWithMeParams) ([]SharingRules, error)
 	GetSecretsWithVersionCount(ctx context.Context) ([]GetSecretsWithVersionCountRow, error)
-	GetSharedWith(ctx context.Context, arg GetSharedWithParams) ([]GetSharedWithRow, error)
+	// the rule that applies to each user: an exact share of the secret, or the share of the
+	// closest folder above it in parents
+	GetSharedWith(ctx context.Context, arg GetSharedWithParams) ([]SharingRules, error)
+	GetSystemStats(ctx context.Context) (GetSystemStatsRow, error)
+	GetTeamAccessForUser(ctx context.Context, arg GetTeamAccessForUserParams) (GetTeamAccessForUserRow, error)
+	GetTeamBySlugs(ctx context.Context, arg GetTeamBySlugsParams) (Teams, error)
 	GetUserByEmail(ctx context.Context, email string) (Users, error)
 	GetUserByID(ctx context.Context, id uuid.UUID) (Users, error)
+	GetWebhookDelivery(ctx context.Context, arg GetWebhookDeliveryParams) (WebhookDeliveries, error)
+	GetWebhookSubscription(ctx context.Context, id uuid.UUID) (WebhookSubscriptions, error)
+	GrantQuorumRequest(ctx context.Context, id uuid.UUID) (QuorumRequests, error)
+	// whether the user holds an approved read of the secret; ConsumeQuorumGrant uses it up
+	HasQuorumGrant(ctx context.Context, arg HasQuorumGrantParams) (bool, error)
 	InsertHMACKey(ctx context.Context, key []byte) (uuid.UUID, error)
+	// leases the policy to one rotation so the hook can run outside of any transaction; a policy
+	// leased elsewhere is skipped until its lease runs out, which lets several instances share the work
+	LeaseRotationPolicy(ctx context.Context, arg LeaseRotationPolicyParams) (LeaseRotationPolicyRow, error)
+	ListAccessRequestsByRequester(ctx context.Context, arg ListAccessRequestsByRequesterParams) ([]AccessRequests, error)
+	ListActiveBreakGlassSessions(ctx context.Context, userID uuid.UUID) ([]BreakGlassSessions, error)
+	ListAuditLogsForBreakGlassSession(ctx context.Context, breakGlassSessionID uuid.NullUUID) ([]AuditLogs, error)
+	// the rules the user set up or can use as a member of their group
+	ListBreakGlassRulesForUser(ctx context.Context, userID uuid.UUID) ([]ListBreakGlassRulesForUserRow, error)
+	// every session, newest first, only reviewed or unreviewed ones when reviewed is set
+	ListBreakGlassSessions(ctx context.Context, arg ListBreakGlassSessionsParams) ([]BreakGlassSessions, error)
+	ListBreakGlassSessionsByUser(ctx context.Context, arg ListBreakGlassSessionsByUserParams) ([]BreakGlassSessions, error)
+	ListDueRotationPolicies(ctx context.Context, limit int32) ([]uuid.UUID, error)
+	ListGroupMembers(ctx context.Context, groupID uuid.UUID) ([]ListGroupMembersRow, error)
+	ListGroupsForUser(ctx context.Context, userID uuid.UUID) ([]ListGroupsForUserRow, error)
+	ListLatestSecretsByPrefix(ctx context.Context, arg ListLatestSecretsByPrefixParams) ([]ListLatestSecretsByPrefixRow, error)
+	ListOpenQuorumRequests(ctx context.Context) ([]QuorumRequests, error)
+	ListOrgMembers(ctx context.Context, orgID uuid.UUID) ([]ListOrgMembersRow, error)
+	ListOrganizationsForUser(ctx context.Context, userID uuid.UUID) ([]ListOrganizationsForUserRow, error)
+	// pending requests of other users on paths the approver may hold share on: below their own
+	// namespace, the namespace of a team they maintain, or one of the given policy prefixes. The
+	// authorizer still has the final say on each of them.
+	ListPendingAccessRequestsForApprover(ctx context.Context, arg ListPendingAccessRequestsForApproverParams) ([]AccessRequests, error)
+	ListQuorumApprovals(ctx context.Context, requestID uuid.UUID) ([]QuorumApprovals, error)
+	ListQuorumRequestsByRequester(ctx context.Context, arg ListQuorumRequestsByRequesterParams) ([]QuorumRequests, error)
+	ListSecretsByPrefixForUpdate(ctx context.Context, prefix string) ([]Secrets, error)
+	ListSecretsExpiringBefore(ctx context.Context, before time.Time) ([]ListSecretsExpiringBeforeRow, error)
+	ListSharesExpiringBefore(ctx context.Context, before time.Time) ([]ListSharesExpiringBeforeRow, error)
+	ListSharesExpiringBeforeForUser(ctx context.Context, arg ListSharesExpiringBeforeForUserParams) ([]ListSharesExpiringBeforeForUserRow, error)
+	// everyone whose team access counts: team members still in the organization, and its owners and admins
+	ListTeamAccess(ctx context.Context, id uuid.UUID) ([]ListTeamAccessRow, error)
+	ListTeamMembers(ctx context.Context, teamID uuid.UUID) ([]ListTeamMembersRow, error)
+	ListTeamsForOrg(ctx context.Context, orgID uuid.UUID) ([]Teams, error)
+	ListUserEmailsWithRoles(ctx context.Context, roles []string) ([]string, error)
+	ListUsers(ctx context.Context, arg ListUsersParams) ([]Users, error)
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDeliveries, error)
+	// prefixes are compared literally; LIKE would treat _ in paths as a wildcard
+	ListWebhookSubscriptionsForEvent(ctx context.Context, arg ListWebhookSubscriptionsForEventParams) ([]ListWebhookSubscriptionsForEventRow, error)
+	ListWebhookSubscriptionsForUser(ctx context.Context, userID uuid.UUID) ([]WebhookSubscriptions, error)
+	// locks every admin so that concurrent demotions see each other
+	LockAdmins(ctx context.Context) ([]uuid.UUID, error)
+	// locks the group's owners so that concurrent demotions see each other
+	LockGroupOwners(ctx context.Context, groupID uuid.UUID) ([]uuid.UUID, error)
+	// locks the organization's owners so that concurrent demotions see each other
+	LockOrgOwners(ctx context.Context, orgID uuid.UUID) ([]uuid.UUID, error)
+	// serializes creating shares of one path with one target until the transaction ends, so the
+	// CheckIfShared that follows cannot race another share
+	LockShare(ctx context.Context, arg LockShareParams) error
+	MarkRotationFailed(ctx context.Context, arg MarkRotationFailedParams) (int64, error)
+	// only while the lease is held, so no row means the rotation lost its policy
+	MarkRotationSucceeded(ctx context.Context, arg MarkRotationSucceededParams) (int64, error)
+	MarkWebhookDeliveryFailed(ctx context.Context, arg MarkWebhookDeliveryFailedParams) error
+	MarkWebhookDeliverySucceeded(ctx context.Context, arg MarkWebhookDeliverySucceededParams) error
+	MoveBreakGlassRule(ctx context.Context, arg MoveBreakGlassRuleParams) (int64, error)
+	MovePendingAccessRequests(ctx context.Context, arg MovePendingAccessRequestsParams) (int64, error)
+	// pending requests for old_prefix and for every folder below it follow the folder to new_prefix
+	MovePendingPrefixAccessRequests(ctx context.Context, arg MovePendingPrefixAccessRequestsParams) (int64, error)
+	// folder rules on old_prefix and on every folder below it follow the folder to new_prefix
+	MovePrefixBreakGlassRules(ctx context.Context, arg MovePrefixBreakGlassRulesParams) (int64, error)
+	// folder shares on old_prefix and on every folder below it follow the folder to new_prefix
+	MovePrefixSharingRules(ctx context.Context, arg MovePrefixSharingRulesParams) (int64, error)
+	MoveSecret(ctx context.Context, arg MoveSecretParams) (Secrets, error)
+	MoveSharingRules(ctx context.Context, arg MoveSharingRulesParams) (int64, error)
+	// delivered to listeners when the surrounding transaction commits, and dropped if it rolls back
+	NotifyEvent(ctx context.Context, payload string) error
+	// only while nobody is an admin, so the bootstrap email cannot take the role back later. Emails
+	// are compared case-insensitively, and an email shared by several accounts promotes none.
+	PromoteBootstrapAdmin(ctx context.Context, email string) (Users, error)
+	ReleaseExpiryWarning(ctx context.Context, arg ReleaseExpiryWarningParams) error
+	RemoveGroupMember(ctx context.Context, arg RemoveGroupMemberParams) (int64, error)
+	RemoveOrgMember(ctx context.Context, arg RemoveOrgMemberParams) error
+	RemoveTeamMember(ctx context.Context, arg RemoveTeamMemberParams) error
+	RemoveUserFromOrgTeams(ctx context.Context, arg RemoveUserFromOrgTeamsParams) error
+	ReviewBreakGlassSession(ctx context.Context, arg ReviewBreakGlassSessionParams) (BreakGlassSessions, error)
+	SetUserRole(ctx context.Context, arg SetUserRoleParams) (Users, error)
 	ShareSecret(ctx context.Context, arg ShareSecretParams) (SharingRules, error)
+	UpdateSharingRule(ctx context.Context, arg UpdateSharingRuleParams) ([]SharingRules, error)
+	UpsertBreakGlassRule(ctx context.Context, arg UpsertBreakGlassRuleParams) (BreakGlassRules, error)
+	UpsertGroupMember(ctx context.Context, arg UpsertGroupMemberParams) (GroupMembers, error)
+	UpsertOrgMember(ctx context.Context, arg UpsertOrgMemberParams) (OrgMembers, error)
+	UpsertRotationPolicy(ctx context.Context, arg UpsertRotationPolicyParams) (RotationPolicies, error)
+	UpsertSecretQuorum(ctx context.Context, arg UpsertSecretQuorumParams) (SecretQuorums, error)
+	UpsertTeamMember(ctx context.Context, arg UpsertTeamMemberParams) (TeamMembers, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,453 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: quorum.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const addQuorumApproval = `-- name: AddQuorumApproval :one
+INSERT INTO quorum_approvals (request_id, approver_id, approver_email)
+VALUES ($1, $2, $3)
+RETURNING request_id, approver_id, approver_email, created_at
+`
+
+type AddQuorumApprovalParams struct {
+	RequestID     uuid.UUID `json:"request_id"`
+	ApproverID    uuid.UUID `json:"approver_id"`
+	ApproverEmail string    `json:"approver_email"`
+}
+
+func (q *Queries) AddQuorumApproval(ctx context.Context, arg AddQuorumApprovalParams) (QuorumApprovals, error) {
+	row := q.db.QueryRowContext(ctx, addQuorumApproval, arg.RequestID, arg.ApproverID, arg.ApproverEmail)
+	var i QuorumApprovals
+	err := row.Scan(
+		&i.RequestID,
+		&i.ApproverID,
+		&i.ApproverEmail,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const consumeQuorumGrant = `-- name: ConsumeQuorumGrant :one
+UPDATE quorum_requests
+SET status = 'consumed', consumed_at = NOW()
+WHERE id = (
+    SELECT id FROM quorum_requests r
+    WHERE r.requester_id = $1 AND r.secret_id = $2 AND r.environment = $3
+    AND r.status = 'granted' AND r.granted_until > NOW()
+    ORDER BY r.granted_until
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, secret_id, environment, requester_id, requester_email, required_approvals, read_ttl_secs, status, expires_at, granted_until, consumed_at, created_at
+`
+
+type ConsumeQuorumGrantParams struct {
+	RequesterID uuid.UUID `json:"requester_id"`
+	SecretID    uuid.UUID `json:"secret_id"`
+	Environment string    `json:"environment"`
+}
+
+// uses up one granted read of the secret; concurrent reads cannot both take the same grant
+func (q *Queries) ConsumeQuorumGrant(ctx context.Context, arg ConsumeQuorumGrantParams) (QuorumRequests, error) {
+	row := q.db.QueryRowContext(ctx, consumeQuorumGrant, arg.RequesterID, arg.SecretID, arg.Environment)
+	var i QuorumRequests
+	err := row.Scan(
+		&i.ID,
+		&i.SecretID,
+		&i.Environment,
+		&i.RequesterID,
+		&i.RequesterEmail,
+		&i.RequiredApprovals,
+		&i.ReadTtlSecs,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.GrantedUntil,
+		&i.ConsumedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createQuorumRequest = `-- name: CreateQuorumRequest :one
+INSERT INTO quorum_requests (secret_id, environment, requester_id, requester_email, required_approvals, read_ttl_secs, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, secret_id, environment, requester_id, requester_email, required_approvals, read_ttl_secs, status, expires_at, granted_until, consumed_at, created_at
+`
+
+type CreateQuorumRequestParams struct {
+	SecretID          uuid.UUID `json:"secret_id"`
+	Environment       string    `json:"environment"`
+	RequesterID       uuid.UUID `json:"requester_id"`
+	RequesterEmail    string    `json:"requester_email"`
+	RequiredApprovals int32     `json:"required_approvals"`
+	ReadTtlSecs       int64     `json:"read_ttl_secs"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateQuorumRequest(ctx context.Context, arg CreateQuorumRequestParams) (QuorumRequests, error) {
+	row := q.db.QueryRowContext(ctx, createQuorumRequest,
+		arg.SecretID,
+		arg.Environment,
+		arg.RequesterID,
+		arg.RequesterEmail,
+		arg.RequiredApprovals,
+		arg.ReadTtlSecs,
+		arg.ExpiresAt,
+	)
+	var i QuorumRequests
+	err := row.Scan(
+		&i.ID,
+		&i.SecretID,
+		&i.Environment,
+		&i.RequesterID,
+		&i.RequesterEmail,
+		&i.RequiredApprovals,
+		&i.ReadTtlSecs,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.GrantedUntil,
+		&i.ConsumedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteSecretQuorum = `-- name: DeleteSecretQuorum :execrows
+DELETE FROM secret_quorums
+WHERE secret_id = $1
+`
+
+func (q *Queries) DeleteSecretQuorum(ctx context.Context, secretID uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteSecretQuorum, secretID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getOpenQuorumRequest = `-- name: GetOpenQuorumRequest :one
+SELECT id, secret_id, environment, requester_id, requester_email, required_approvals, read_ttl_secs, status, expires_at, granted_until, consumed_at, created_at FROM quorum_requests
+WHERE requester_id = $1 AND secret_id = $2 AND environment = $3
+AND status = 'pending' AND expires_at > NOW()
+ORDER BY created_at DESC
+LIMIT 1
+`
+
+type GetOpenQuorumRequestParams struct {
+	RequesterID uuid.UUID `json:"requester_id"`
+	SecretID    uuid.UUID `json:"secret_id"`
+	Environment string    `json:"environment"`
+}
+
+// the caller's request for the secret that is still waiting for approvals
+func (q *Queries) GetOpenQuorumRequest(ctx context.Context, arg GetOpenQuorumRequestParams) (QuorumRequests, error) {
+	row := q.db.QueryRowContext(ctx, getOpenQuorumRequest, arg.RequesterID, arg.SecretID, arg.Environment)
+	var i QuorumRequests
+	err := row.Scan(
+		&i.ID,
+		&i.SecretID,
+		&i.Environment,
+		&i.RequesterID,
+		&i.RequesterEmail,
+		&i.RequiredApprovals,
+		&i.ReadTtlSecs,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.GrantedUntil,
+		&i.ConsumedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getQuorumRequest = `-- name: GetQuorumRequest :one
+SELECT id, secret_id, environment, requester_id, requester_email, required_approvals, read_ttl_secs, status, expires_at, granted_until, consumed_at, created_at FROM quorum_requests
+WHERE id = $1
+`
+
+func (q *Queries) GetQuorumRequest(ctx context.Context, id uuid.UUID) (QuorumRequests, error) {
+	row := q.db.QueryRowContext(ctx, getQuorumRequest, id)
+	var i QuorumRequests
+	err := row.Scan(
+		&i.ID,
+		&i.SecretID,
+		&i.Environment,
+		&i.RequesterID,
+		&i.RequesterEmail,
+		&i.RequiredApprovals,
+		&i.ReadTtlSecs,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.GrantedUntil,
+		&i.ConsumedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getQuorumRequestForUpdate = `-- name: GetQuorumRequestForUpdate :one
+SELECT id, secret_id, environment, requester_id, requester_email, required_approvals, read_ttl_secs, status, expires_at, granted_until, consumed_at, created_at FROM quorum_requests
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetQuorumRequestForUpdate(ctx context.Context, id uuid.UUID) (QuorumRequests, error) {
+	row := q.db.QueryRowContext(ctx, getQuorumRequestForUpdate, id)
+	var i QuorumRequests
+	err := row.Scan(
+		&i.ID,
+		&i.SecretID,
+		&i.Environment,
+		&i.RequesterID,
+		&i.RequesterEmail,
+		&i.RequiredApprovals,
+		&i.ReadTtlSecs,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.GrantedUntil,
+		&i.ConsumedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getSecretQuorum = `-- name: GetSecretQuorum :one
+SELECT secret_id, required_approvals, window_secs, read_ttl_secs, created_by, created_at FROM secret_quorums
+WHERE secret_id = $1
+`
+
+func (q *Queries) GetSecretQuorum(ctx context.Context, secretID uuid.UUID) (SecretQuorums, error) {
+	row := q.db.QueryRowContext(ctx, getSecretQuorum, secretID)
+	var i SecretQuorums
+	err := row.Scan(
+		&i.SecretID,
+		&i.RequiredApprovals,
+		&i.WindowSecs,
+		&i.ReadTtlSecs,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const grantQuorumRequest = `-- name: GrantQuorumRequest :one
+UPDATE quorum_requests
+SET status = 'granted', granted_until = NOW() + make_interval(secs => read_ttl_secs)
+WHERE id = $1 AND status = 'pending'
+RETURNING id, secret_id, environment, requester_id, requester_email, required_approvals, read_ttl_secs, status, expires_at, granted_until, consumed_at, created_at
+`
+
+func (q *Queries) GrantQuorumRequest(ctx context.Context, id uuid.UUID) (QuorumRequests, error) {
+	row := q.db.QueryRowContext(ctx, grantQuorumRequest, id)
+	var i QuorumRequests
+	err := row.Scan(
+		&i.ID,
+		&i.SecretID,
+		&i.Environment,
+		&i.RequesterID,
+		&i.RequesterEmail,
+		&i.RequiredApprovals,
+		&i.ReadTtlSecs,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.GrantedUntil,
+		&i.ConsumedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const hasQuorumGrant = `-- name: HasQuorumGrant :one
+SELECT EXISTS (
+    SELECT 1 FROM quorum_requests
+    WHERE requester_id = $1 AND secret_id = $2 AND environment = $3
+    AND status = 'granted' AND granted_until > NOW()
+)
+`
+
+type HasQuorumGrantParams struct {
+	RequesterID uuid.UUID `json:"requester_id"`
+	SecretID    uuid.UUID `json:"secret_id"`
+	Environment string    `json:"environment"`
+}
+
+// whether the user holds an approved read of the secret; ConsumeQuorumGrant uses it up
+func (q *Queries) HasQuorumGrant(ctx context.Context, arg HasQuorumGrantParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, hasQuorumGrant, arg.RequesterID, arg.SecretID, arg.Environment)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const listOpenQuorumRequests = `-- name: ListOpenQuorumRequests :many
+SELECT id, secret_id, environment, requester_id, requester_email, required_approvals, read_ttl_secs, status, expires_at, granted_until, consumed_at, created_at FROM quorum_requests
+WHERE status = 'pending' AND expires_at > NOW()
+ORDER BY created_at
+`
+
+func (q *Queries) ListOpenQuorumRequests(ctx context.Context) ([]QuorumRequests, error) {
+	rows, err := q.db.QueryContext(ctx, listOpenQuorumRequests)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []QuorumRequests{}
+	for rows.Next() {
+		var i QuorumRequests
+		if err := rows.Scan(
+			&i.ID,
+			&i.SecretID,
+			&i.Environment,
+			&i.RequesterID,
+			&i.RequesterEmail,
+			&i.RequiredApprovals,
+			&i.ReadTtlSecs,
+			&i.Status,
+			&i.ExpiresAt,
+			&i.GrantedUntil,
+			&i.ConsumedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listQuorumApprovals = `-- name: ListQuorumApprovals :many
+SELECT request_id, approver_id, approver_email, created_at FROM quorum_approvals
+WHERE request_id = $1
+ORDER BY created_at
+`
+
+func (q *Queries) ListQuorumApprovals(ctx context.Context, requestID uuid.UUID) ([]QuorumApprovals, error) {
+	rows, err := q.db.QueryContext(ctx, listQuorumApprovals, requestID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []QuorumApprovals{}
+	for rows.Next() {
+		var i QuorumApprovals
+		if err := rows.Scan(
+			&i.RequestID,
+			&i.ApproverID,
+			&i.ApproverEmail,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listQuorumRequestsByRequester = `-- name: ListQuorumRequestsByRequester :many
+SELECT id, secret_id, environment, requester_id, requester_email, required_approvals, read_ttl_secs, status, expires_at, granted_until, consumed_at, created_at FROM quorum_requests
+WHERE requester_id = $1
+ORDER BY created_at DESC
+LIMIT $3 OFFSET $2
+`
+
+type ListQuorumRequestsByRequesterParams struct {
+	RequesterID uuid.UUID `json:"requester_id"`
+	Offset      int32     `json:"offset"`
+	Limit       int32     `json:"limit"`
+}
+
+func (q *Queries) ListQuorumRequestsByRequester(ctx context.Context, arg ListQuorumRequestsByRequesterParams) ([]QuorumRequests, error) {
+	rows, err := q.db.QueryContext(ctx, listQuorumRequestsByRequester, arg.RequesterID, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []QuorumRequests{}
+	for rows.Next() {
+		var i QuorumRequests
+		if err := rows.Scan(
+			&i.ID,
+			&i.SecretID,
+			&i.Environment,
+			&i.RequesterID,
+			&i.RequesterEmail,
+			&i.RequiredApprovals,
+			&i.ReadTtlSecs,
+			&i.Status,
+			&i.ExpiresAt,
+			&i.GrantedUntil,
+			&i.ConsumedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertSecretQuorum = `-- name: UpsertSecretQuorum :one
+INSERT INTO secret_quorums (secret_id, required_approvals, window_secs, read_ttl_secs, created_by)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (secret_id) DO UPDATE
+SET required_approvals = EXCLUDED.required_approvals,
+    window_secs = EXCLUDED.window_secs,
+    read_ttl_secs = EXCLUDED.read_ttl_secs,
+    created_by = EXCLUDED.created_by
+RETURNING secret_id, required_approvals, window_secs, read_ttl_secs, created_by, created_at
+`
+
+type UpsertSecretQuorumParams struct {
+	SecretID          uuid.UUID `json:"secret_id"`
+	RequiredApprovals int32     `json:"required_approvals"`
+	WindowSecs        int64     `json:"window_secs"`
+	ReadTtlSecs       int64     `json:"read_ttl_secs"`
+	CreatedBy         uuid.UUID `json:"created_by"`
+}
+
+func (q *Queries) UpsertSecretQuorum(ctx context.Context, arg UpsertSecretQuorumParams) (SecretQuorums, error) {
+	row := q.db.QueryRowContext(ctx, upsertSecretQuorum,
+		arg.SecretID,
+		arg.RequiredApprovals,
+		arg.WindowSecs,
+		arg.ReadTtlSecs,
+		arg.CreatedBy,
+	)
+	var i SecretQuorums
+	err := row.Scan(
+		&i.SecretID,
+		&i.RequiredApprovals,
+		&i.WindowSecs,
+		&i.ReadTtlSecs,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,160 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomQuorumRequest(t *testing.T, secret SecretVersions, requester Users, required int32, expiresAt time.Time) QuorumRequests {
+	arg := CreateQuorumRequestParams{
+		SecretID:          secret.SecretID,
+		Environment:       "default",
+		RequesterID:       requester.ID,
+		RequesterEmail:    requester.Email,
+		RequiredApprovals: required,
+		ReadTtlSecs:       600,
+		ExpiresAt:         expiresAt,
+	}
+
+	request, err := testQueries.CreateQuorumRequest(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, "pending", request.Status)
+	require.Equal(t, required, request.RequiredApprovals)
+	require.False(t, request.GrantedUntil.Valid)
+	return request
+}
+
+func TestUpsertSecretQuorum(t *testing.T) {
+	secret, _ := createNewSecret(t)
+	owner := createRandomUser(t)
+
+	_, err := testQueries.GetSecretQuorum(context.Background(), secret.SecretID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	quorum, err := testQueries.UpsertSecretQuorum(context.Background(), UpsertSecretQuorumParams{
+		SecretID:          secret.SecretID,
+		RequiredApprovals: 1,
+		WindowSecs:        3600,
+		ReadTtlSecs:       900,
+		CreatedBy:         owner.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(1), quorum.RequiredApprovals)
+
+	quorum, err = testQueries.UpsertSecretQuorum(context.Background(), UpsertSecretQuorumParams{
+		SecretID:          secret.SecretID,
+		RequiredApprovals: 2,
+		WindowSecs:        600,
+		ReadTtlSecs:       60,
+		CreatedBy:         owner.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(2), quorum.RequiredApprovals)
+	require.Equal(t, int64(60), quorum.ReadTtlSecs)
+
+	deleted, err := testQueries.DeleteSecretQuorum(context.Background(), secret.SecretID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), deleted)
+
+	deleted, err = testQueries.DeleteSecretQuorum(context.Background(), secret.SecretID)
+	require.NoError(t, err)
+	require.Zero(t, deleted)
+}
+
+func TestQuorumApprovalsAndGrant(t *testing.T) {
+	secret, _ := createNewSecret(t)
+	requester := createRandomUser(t)
+	approver := createRandomUser(t)
+	request := createRandomQuorumRequest(t, secret, requester, 1, time.Now().Add(time.Hour))
+
+	open, err := testQueries.GetOpenQuorumRequest(context.Background(), GetOpenQuorumRequestParams{
+		RequesterID: requester.ID,
+		SecretID:    secret.SecretID,
+		Environment: "default",
+	})
+	require.NoError(t, err)
+	require.Equal(t, request.ID, open.ID)
+
+	_, err = testQueries.AddQuorumApproval(context.Background(), AddQuorumApprovalParams{
+		RequestID:     request.ID,
+		ApproverID:    approver.ID,
+		ApproverEmail: approver.Email,
+	})
+	require.NoError(t, err)
+
+	// the same user approves only once
+	_, err = testQueries.AddQuorumApproval(context.Background(), AddQuorumApprovalParams{
+		RequestID:     request.ID,
+		ApproverID:    approver.ID,
+		ApproverEmail: approver.Email,
+	})
+	require.Error(t, err)
+
+	approvals, err := testQueries.ListQuorumApprovals(context.Background(), request.ID)
+	require.NoError(t, err)
+	require.Len(t, approvals, 1)
+	require.Equal(t, approver.Email, approvals[0].ApproverEmail)
+
+	granted, err := testQueries.GrantQuorumRequest(context.Background(), request.ID)
+	require.NoError(t, err)
+	require.Equal(t, "granted", granted.Status)
+	require.True(t, granted.GrantedUntil.Valid)
+	require.WithinDuration(t, time.Now().Add(10*time.Minute), granted.GrantedUntil.Time, time.Minute)
+
+	_, err = testQueries.GrantQuorumRequest(context.Background(), request.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// a granted read is used up by the first read
+	grantArg := HasQuorumGrantParams{
+		RequesterID: requester.ID,
+		SecretID:    secret.SecretID,
+		Environment: "default",
+	}
+	hasGrant, err := testQueries.HasQuorumGrant(context.Background(), grantArg)
+	require.NoError(t, err)
+	require.True(t, hasGrant)
+
+	consumeArg := ConsumeQuorumGrantParams(grantArg)
+	consumed, err := testQueries.ConsumeQuorumGrant(context.Background(), consumeArg)
+	require.NoError(t, err)
+	require.Equal(t, request.ID, consumed.ID)
+	require.Equal(t, "consumed", consumed.Status)
+	require.True(t, consumed.ConsumedAt.Valid)
+
+	_, err = testQueries.ConsumeQuorumGrant(context.Background(), consumeArg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	hasGrant, err = testQueries.HasQuorumGrant(context.Background(), grantArg)
+	require.NoError(t, err)
+	require.False(t, hasGrant)
+}
+
+func TestExpiredQuorumRequestIsNotOpen(t *testing.T) {
+	secret, _ := createNewSecret(t)
+	requester := createRandomUser(t)
+	request := createRandomQuorumRequest(t, secret, requester, 2, time.Now().Add(-time.Minute))
+
+	_, err := testQueries.GetOpenQuorumRequest(context.Background(), GetOpenQuorumRequestParams{
+		RequesterID: requester.ID,
+		SecretID:    secret.SecretID,
+		Environment: "default",
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	open, err := testQueries.ListOpenQuorumRequests(context.Background())
+	require.NoError(t, err)
+	for _, o := range open {
+		require.NotEqual(t, request.ID, o.ID)
+	}
+
+	mine, err := testQueries.ListQuorumRequestsByRequester(context.Background(), ListQuorumRequestsByRequesterParams{
+		RequesterID: requester.ID,
+		Limit:       10,
+	})
+	require.NoError(t, err)
+	require.Len(t, mine, 1)
+}
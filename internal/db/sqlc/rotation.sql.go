@@ -0,0 +1,283 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: rotation.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const deleteRotationPolicy = `-- name: DeleteRotationPolicy :exec
+DELETE FROM rotation_policies
+WHERE secret_id = $1 AND environment = $2
+`
+
+type DeleteRotationPolicyParams struct {
+	SecretID    uuid.UUID `json:"secret_id"`
+	Environment string    `json:"environment"`
+}
+
+func (q *Queries) DeleteRotationPolicy(ctx context.Context, arg DeleteRotationPolicyParams) error {
+	_, err := q.db.ExecContext(ctx, deleteRotationPolicy, arg.SecretID, arg.Environment)
+	return err
+}
+
+const getRotationPolicy = `-- name: GetRotationPolicy :one
+SELECT id, secret_id, environment, interval_seconds, cron_expr, generator, password_length, password_symbols, hook_target, next_rotation_at, last_rotated_at, last_error, failure_count, created_by, created_at, lease_id, lease_expires_at FROM rotation_policies
+WHERE secret_id = $1 AND environment = $2
+`
+
+type GetRotationPolicyParams struct {
+	SecretID    uuid.UUID `json:"secret_id"`
+	Environment string    `json:"environment"`
+}
+
+func (q *Queries) GetRotationPolicy(ctx context.Context, arg GetRotationPolicyParams) (RotationPolicies, error) {
+	row := q.db.QueryRowContext(ctx, getRotationPolicy, arg.SecretID, arg.Environment)
+	var i RotationPolicies
+	err := row.Scan(
+		&i.ID,
+		&i.SecretID,
+		&i.Environment,
+		&i.IntervalSeconds,
+		&i.CronExpr,
+		&i.Generator,
+		&i.PasswordLength,
+		&i.PasswordSymbols,
+		&i.HookTarget,
+		&i.NextRotationAt,
+		&i.LastRotatedAt,
+		&i.LastError,
+		&i.FailureCount,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.LeaseID,
+		&i.LeaseExpiresAt,
+	)
+	return i, err
+}
+
+const leaseRotationPolicy = `-- name: LeaseRotationPolicy :one
+UPDATE rotation_policies p
+SET lease_id = gen_random_uuid(),
+    lease_expires_at = now() + make_interval(secs => $1::int)
+FROM secrets s, users u
+WHERE p.id = $2 AND s.id = p.secret_id AND u.id = p.created_by
+AND (p.lease_expires_at IS NULL OR p.lease_expires_at <= now())
+AND (NOT $3::boolean OR p.next_rotation_at <= now())
+RETURNING p.id, p.secret_id, p.environment, p.interval_seconds, p.cron_expr, p.generator, p.password_length, p.password_symbols, p.hook_target, p.next_rotation_at, p.last_rotated_at, p.last_error, p.failure_count, p.created_by, p.created_at, p.lease_id, p.lease_expires_at, s.path, u.email AS owner_email
+`
+
+type LeaseRotationPolicyParams struct {
+	LeaseSeconds int32     `json:"lease_seconds"`
+	ID           uuid.UUID `json:"id"`
+	DueOnly      bool      `json:"due_only"`
+}
+
+type LeaseRotationPolicyRow struct {
+	ID              uuid.UUID      `json:"id"`
+	SecretID        uuid.UUID      `json:"secret_id"`
+	Environment     string         `json:"environment"`
+	IntervalSeconds sql.NullInt64  `json:"interval_seconds"`
+	CronExpr        sql.NullString `json:"cron_expr"`
+	Generator       string         `json:"generator"`
+	PasswordLength  int32          `json:"password_length"`
+	PasswordSymbols bool           `json:"password_symbols"`
+	HookTarget      sql.NullString `json:"hook_target"`
+	NextRotationAt  time.Time      `json:"next_rotation_at"`
+	LastRotatedAt   sql.NullTime   `json:"last_rotated_at"`
+	LastError       sql.NullString `json:"last_error"`
+	FailureCount    int32          `json:"failure_count"`
+	CreatedBy       uuid.UUID      `json:"created_by"`
+	CreatedAt       sql.NullTime   `json:"created_at"`
+	LeaseID         uuid.NullUUID  `json:"lease_id"`
+	LeaseExpiresAt  sql.NullTime   `json:"lease_expires_at"`
+	Path            string         `json:"path"`
+	OwnerEmail      string         `json:"owner_email"`
+}
+
+// leases the policy to one rotation so the hook can run outside of any transaction; a policy
+// leased elsewhere is skipped until its lease runs out, which lets several instances share the work
+func (q *Queries) LeaseRotationPolicy(ctx context.Context, arg LeaseRotationPolicyParams) (LeaseRotationPolicyRow, error) {
+	row := q.db.QueryRowContext(ctx, leaseRotationPolicy, arg.LeaseSeconds, arg.ID, arg.DueOnly)
+	var i LeaseRotationPolicyRow
+	err := row.Scan(
+		&i.ID,
+		&i.SecretID,
+		&i.Environment,
+		&i.IntervalSeconds,
+		&i.CronExpr,
+		&i.Generator,
+		&i.PasswordLength,
+		&i.PasswordSymbols,
+		&i.HookTarget,
+		&i.NextRotationAt,
+		&i.LastRotatedAt,
+		&i.LastError,
+		&i.FailureCount,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.LeaseID,
+		&i.LeaseExpiresAt,
+		&i.Path,
+		&i.OwnerEmail,
+	)
+	return i, err
+}
+
+const listDueRotationPolicies = `-- name: ListDueRotationPolicies :many
+SELECT id FROM rotation_policies
+WHERE next_rotation_at <= now()
+ORDER BY next_rotation_at
+LIMIT $1
+`
+
+func (q *Queries) ListDueRotationPolicies(ctx context.Context, limit int32) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, listDueRotationPolicies, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markRotationFailed = `-- name: MarkRotationFailed :execrows
+UPDATE rotation_policies
+SET last_error = $1, next_rotation_at = $2, failure_count = failure_count + 1,
+    lease_id = NULL, lease_expires_at = NULL
+WHERE id = $3 AND lease_id = $4
+`
+
+type MarkRotationFailedParams struct {
+	LastError      sql.NullString `json:"last_error"`
+	NextRotationAt time.Time      `json:"next_rotation_at"`
+	ID             uuid.UUID      `json:"id"`
+	LeaseID        uuid.NullUUID  `json:"lease_id"`
+}
+
+func (q *Queries) MarkRotationFailed(ctx context.Context, arg MarkRotationFailedParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markRotationFailed,
+		arg.LastError,
+		arg.NextRotationAt,
+		arg.ID,
+		arg.LeaseID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const markRotationSucceeded = `-- name: MarkRotationSucceeded :execrows
+UPDATE rotation_policies
+SET last_rotated_at = now(), next_rotation_at = $1, last_error = NULL, failure_count = 0,
+    lease_id = NULL, lease_expires_at = NULL
+WHERE id = $2 AND lease_id = $3 AND lease_expires_at > now()
+`
+
+type MarkRotationSucceededParams struct {
+	NextRotationAt time.Time     `json:"next_rotation_at"`
+	ID             uuid.UUID     `json:"id"`
+	LeaseID        uuid.NullUUID `json:"lease_id"`
+}
+
+// only while the lease is held, so no row means the rotation lost its policy
+func (q *Queries) MarkRotationSucceeded(ctx context.Context, arg MarkRotationSucceededParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markRotationSucceeded, arg.NextRotationAt, arg.ID, arg.LeaseID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const upsertRotationPolicy = `-- name: UpsertRotationPolicy :one
+INSERT INTO rotation_policies (
+  secret_id, environment, interval_seconds, cron_expr, generator,
+  password_length, password_symbols, hook_target, next_rotation_at, created_by
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (secret_id, environment) DO UPDATE
+SET interval_seconds = EXCLUDED.interval_seconds,
+    cron_expr = EXCLUDED.cron_expr,
+    generator = EXCLUDED.generator,
+    password_length = EXCLUDED.password_length,
+    password_symbols = EXCLUDED.password_symbols,
+    hook_target = EXCLUDED.hook_target,
+    next_rotation_at = EXCLUDED.next_rotation_at,
+    created_by = EXCLUDED.created_by,
+    last_error = NULL,
+    failure_count = 0,
+    -- a rotation running on the old policy must not write its value
+    lease_id = NULL,
+    lease_expires_at = NULL
+RETURNING id, secret_id, environment, interval_seconds, cron_expr, generator, password_length, password_symbols, hook_target, next_rotation_at, last_rotated_at, last_error, failure_count, created_by, created_at, lease_id, lease_expires_at
+`
+
+type UpsertRotationPolicyParams struct {
+	SecretID        uuid.UUID      `json:"secret_id"`
+	Environment     string         `json:"environment"`
+	IntervalSeconds sql.NullInt64  `json:"interval_seconds"`
+	CronExpr        sql.NullString `json:"cron_expr"`
+	Generator       string         `json:"generator"`
+	PasswordLength  int32          `json:"password_length"`
+	PasswordSymbols bool           `json:"password_symbols"`
+	HookTarget      sql.NullString `json:"hook_target"`
+	NextRotationAt  time.Time      `json:"next_rotation_at"`
+	CreatedBy       uuid.UUID      `json:"created_by"`
+}
+
+func (q *Queries) UpsertRotationPolicy(ctx context.Context, arg UpsertRotationPolicyParams) (RotationPolicies, error) {
+	row := q.db.QueryRowContext(ctx, upsertRotationPolicy,
+		arg.SecretID,
+		arg.Environment,
+		arg.IntervalSeconds,
+		arg.CronExpr,
+		arg.Generator,
+		arg.PasswordLength,
+		arg.PasswordSymbols,
+		arg.HookTarget,
+		arg.NextRotationAt,
+		arg.CreatedBy,
+	)
+	var i RotationPolicies
+	err := row.Scan(
+		&i.ID,
+		&i.SecretID,
+		&i.Environment,
+		&i.IntervalSeconds,
+		&i.CronExpr,
+		&i.Generator,
+		&i.PasswordLength,
+		&i.PasswordSymbols,
+		&i.HookTarget,
+		&i.NextRotationAt,
+		&i.LastRotatedAt,
+		&i.LastError,
+		&i.FailureCount,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.LeaseID,
+		&i.LeaseExpiresAt,
+	)
+	return i, err
+}
@@ -0,0 +1,129 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createRotationPolicy(t *testing.T, secret SecretVersions, nextRotationAt time.Time) RotationPolicies {
+	owner := createRandomUser(t)
+
+	arg := UpsertRotationPolicyParams{
+		SecretID:        secret.SecretID,
+		Environment:     "default",
+		IntervalSeconds: sql.NullInt64{Int64: 3600, Valid: true},
+		Generator:       "password",
+		PasswordLength:  32,
+		NextRotationAt:  nextRotationAt,
+		CreatedBy:       owner.ID,
+	}
+
+	policy, err := testQueries.UpsertRotationPolicy(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.SecretID, policy.SecretID)
+	require.Equal(t, arg.Generator, policy.Generator)
+	require.WithinDuration(t, nextRotationAt, policy.NextRotationAt, time.Second)
+
+	return policy
+}
+
+func TestUpsertRotationPolicy(t *testing.T) {
+	secret, _ := createNewSecret(t)
+	policy := createRotationPolicy(t, secret, time.Now().Add(time.Hour))
+
+	// a second upsert replaces the policy of the same secret and environment
+	updated, err := testQueries.UpsertRotationPolicy(context.Background(), UpsertRotationPolicyParams{
+		SecretID:       secret.SecretID,
+		Environment:    "default",
+		CronExpr:       sql.NullString{String: "0 3 * * *", Valid: true},
+		Generator:      "webhook",
+		PasswordLength: 32,
+		HookTarget:     sql.NullString{String: "https://example.com/rotate", Valid: true},
+		NextRotationAt: time.Now().Add(time.Hour),
+		CreatedBy:      policy.CreatedBy,
+	})
+	require.NoError(t, err)
+	require.Equal(t, policy.ID, updated.ID)
+	require.Equal(t, "webhook", updated.Generator)
+	require.False(t, updated.IntervalSeconds.Valid)
+
+	// a hook generator without a target violates the table constraints
+	_, err = testQueries.UpsertRotationPolicy(context.Background(), UpsertRotationPolicyParams{
+		SecretID:        secret.SecretID,
+		Environment:     "prod",
+		IntervalSeconds: sql.NullInt64{Int64: 3600, Valid: true},
+		Generator:       "exec",
+		PasswordLength:  32,
+		NextRotationAt:  time.Now(),
+		CreatedBy:       policy.CreatedBy,
+	})
+	require.Error(t, err)
+
+	err = testQueries.DeleteRotationPolicy(context.Background(), DeleteRotationPolicyParams{SecretID: secret.SecretID, Environment: "default"})
+	require.NoError(t, err)
+
+	_, err = testQueries.GetRotationPolicy(context.Background(), GetRotationPolicyParams{SecretID: secret.SecretID, Environment: "default"})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestDueRotationPolicies(t *testing.T) {
+	dueSecret, duePath := createNewSecret(t)
+	due := createRotationPolicy(t, dueSecret, time.Now().Add(-time.Minute))
+
+	laterSecret, _ := createNewSecret(t)
+	later := createRotationPolicy(t, laterSecret, time.Now().Add(time.Hour))
+
+	ids, err := testQueries.ListDueRotationPolicies(context.Background(), 1000)
+	require.NoError(t, err)
+	require.Contains(t, ids, due.ID)
+	require.NotContains(t, ids, later.ID)
+
+	leased, err := testQueries.LeaseRotationPolicy(context.Background(), LeaseRotationPolicyParams{ID: due.ID, LeaseSeconds: 60, DueOnly: true})
+	require.NoError(t, err)
+	require.Equal(t, duePath, leased.Path)
+	require.NotEmpty(t, leased.OwnerEmail)
+	require.True(t, leased.LeaseID.Valid)
+
+	// a leased policy is not handed out again, and a policy that is not due only to a manual rotation
+	_, err = testQueries.LeaseRotationPolicy(context.Background(), LeaseRotationPolicyParams{ID: due.ID, LeaseSeconds: 60})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = testQueries.LeaseRotationPolicy(context.Background(), LeaseRotationPolicyParams{ID: later.ID, LeaseSeconds: 60, DueOnly: true})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	next := time.Now().Add(time.Hour)
+	marked, err := testQueries.MarkRotationFailed(context.Background(), MarkRotationFailedParams{
+		ID:             due.ID,
+		LeaseID:        leased.LeaseID,
+		LastError:      sql.NullString{String: "hook failed", Valid: true},
+		NextRotationAt: next,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), marked)
+
+	failed, err := testQueries.GetRotationPolicy(context.Background(), GetRotationPolicyParams{SecretID: dueSecret.SecretID, Environment: "default"})
+	require.NoError(t, err)
+	require.Equal(t, int32(1), failed.FailureCount)
+	require.Equal(t, "hook failed", failed.LastError.String)
+	require.False(t, failed.LeaseID.Valid)
+
+	// the released lease no longer lets a rotation write
+	marked, err = testQueries.MarkRotationSucceeded(context.Background(), MarkRotationSucceededParams{ID: due.ID, LeaseID: leased.LeaseID, NextRotationAt: next})
+	require.NoError(t, err)
+	require.Zero(t, marked)
+
+	leased, err = testQueries.LeaseRotationPolicy(context.Background(), LeaseRotationPolicyParams{ID: due.ID, LeaseSeconds: 60})
+	require.NoError(t, err)
+	marked, err = testQueries.MarkRotationSucceeded(context.Background(), MarkRotationSucceededParams{ID: due.ID, LeaseID: leased.LeaseID, NextRotationAt: next})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), marked)
+
+	succeeded, err := testQueries.GetRotationPolicy(context.Background(), GetRotationPolicyParams{SecretID: dueSecret.SecretID, Environment: "default"})
+	require.NoError(t, err)
+	require.Zero(t, succeeded.FailureCount)
+	require.False(t, succeeded.LastError.Valid)
+	require.True(t, succeeded.LastRotatedAt.Valid)
+}
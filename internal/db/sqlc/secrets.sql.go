@@ -19,17 +19,17 @@ WITH secret_row AS (
 version_cte AS (
   SELECT COALESCE(MAX(version), 0) + 1 AS next_version
   FROM secret_versions
-  WHERE secret_id = (SELECT id FROM secret_row)
+  WHERE secret_id = (SELECT id FROM secret_row) AND environment = $7
 )
 INSERT INTO secret_versions (
   secret_id, version, encrypted_value, nonce, created_by,
-  hmac_signature, hmac_key_id
+  hmac_signature, hmac_key_id, environment
 )
 SELECT 
   (SELECT id FROM secret_row),
   (SELECT next_version FROM version_cte),
-  $2, $3, $4, $5, $6
-RETURNING id, secret_id, version, encrypted_value, nonce, created_at, created_by, hmac_signature, hmac_key_id
+  $2, $3, $4, $5, $6, $7
+RETURNING id, secret_id, version, encrypted_value, nonce, created_at, created_by, hmac_signature, hmac_key_id, environment
 `
 
 type CreateNewSecretVersionParams struct {
@@ -39,6 +39,7 @@ type CreateNewSecretVersionParams struct {
 	CreatedBy      uuid.NullUUID `json:"created_by"`
 	HmacSignature  []byte        `json:"hmac_signature"`
 	HmacKeyID      uuid.NullUUID `json:"hmac_key_id"`
+	Environment    string        `json:"environment"`
 }
 
 func (q *Queries) CreateNewSecretVersion(ctx context.Context, arg CreateNewSecretVersionParams) (SecretVersions, error) {
@@ -49,6 +50,7 @@ func (q *Queries) CreateNewSecretVersion(ctx context.Context, arg CreateNewSecre
 		arg.CreatedBy,
 		arg.HmacSignature,
 		arg.HmacKeyID,
+		arg.Environment,
 	)
 	var i SecretVersions
 	err := row.Scan(
@@ -61,25 +63,26 @@ func (q *Queries) CreateNewSecretVersion(ctx context.Context, arg CreateNewSecre
 		&i.CreatedBy,
 		&i.HmacSignature,
 		&i.HmacKeyID,
+		&i.Environment,
 	)
 	return i, err
 }
 
 const createSecretWithVersion = `-- name: CreateSecretWithVersion :one
 WITH inserted_secret AS (
-    INSERT INTO secrets (user_id, path, expires_at)
-    VALUES ($1, $2, $3)
+    INSERT INTO secrets (user_id, path, expires_at, team_id)
+    VALUES ($1, $2, $3, $8)
     RETURNING id
 )
 INSERT INTO secret_versions (
     secret_id, version, encrypted_value, nonce, created_by,
-    hmac_signature, hmac_key_id
+    hmac_signature, hmac_key_id, environment
 )
 VALUES (
     (SELECT id FROM inserted_secret), 1, $4, $5, $1,
-    $6, $7
+    $6, $7, $9
 )
-RETURNING id, secret_id, version, encrypted_value, nonce, created_at, created_by, hmac_signature, hmac_key_id
+RETURNING id, secret_id, version, encrypted_value, nonce, created_at, created_by, hmac_signature, hmac_key_id, environment
 `
 
 type CreateSecretWithVersionParams struct {
@@ -90,6 +93,8 @@ type CreateSecretWithVersionParams struct {
 	Nonce          []byte        `json:"nonce"`
 	HmacSignature  []byte        `json:"hmac_signature"`
 	HmacKeyID      uuid.NullUUID `json:"hmac_key_id"`
+	TeamID         uuid.NullUUID `json:"team_id"`
+	Environment    string        `json:"environment"`
 }
 
 func (q *Queries) CreateSecretWithVersion(ctx context.Context, arg CreateSecretWithVersionParams) (SecretVersions, error) {
@@ -101,6 +106,8 @@ func (q *Queries) CreateSecretWithVersion(ctx context.Context, arg CreateSecretW
 		arg.Nonce,
 		arg.HmacSignature,
 		arg.HmacKeyID,
+		arg.TeamID,
+		arg.Environment,
 	)
 	var i SecretVersions
 	err := row.Scan(
@@ -113,23 +120,60 @@ func (q *Queries) CreateSecretWithVersion(ctx context.Context, arg CreateSecretW
 		&i.CreatedBy,
 		&i.HmacSignature,
 		&i.HmacKeyID,
+		&i.Environment,
 	)
 	return i, err
 }
 
-const deleteExpiredSecretAndVersions = `-- name: DeleteExpiredSecretAndVersions :exec
+const deleteExpiredSecretAndVersions = `-- name: DeleteExpiredSecretAndVersions :many
 WITH deleted AS (
     DELETE FROM secrets
     WHERE expires_at < now()
-    RETURNING id
+    RETURNING id, path, user_id, team_id
+), deleted_versions AS (
+    DELETE FROM secret_versions
+    WHERE secret_id IN (SELECT id FROM deleted)
 )
-DELETE FROM secret_versions
-WHERE secret_id IN (SELECT id FROM deleted)
+SELECT d.id, d.path, d.user_id, d.team_id, u.email AS owner_email
+FROM deleted d
+JOIN users u ON u.id = d.user_id
 `
 
-func (q *Queries) DeleteExpiredSecretAndVersions(ctx context.Context) error {
-	_, err := q.db.ExecContext(ctx, deleteExpiredSecretAndVersions)
-	return err
+type DeleteExpiredSecretAndVersionsRow struct {
+	ID         uuid.UUID     `json:"id"`
+	Path       string        `json:"path"`
+	UserID     uuid.UUID     `json:"user_id"`
+	TeamID     uuid.NullUUID `json:"team_id"`
+	OwnerEmail string        `json:"owner_email"`
+}
+
+func (q *Queries) DeleteExpiredSecretAndVersions(ctx context.Context) ([]DeleteExpiredSecretAndVersionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, deleteExpiredSecretAndVersions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []DeleteExpiredSecretAndVersionsRow{}
+	for rows.Next() {
+		var i DeleteExpiredSecretAndVersionsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Path,
+			&i.UserID,
+			&i.TeamID,
+			&i.OwnerEmail,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const deleteSecretAndVersionsByPath = `-- name: DeleteSecretAndVersionsByPath :exec
@@ -148,15 +192,20 @@ func (q *Queries) DeleteSecretAndVersionsByPath(ctx context.Context, path string
 }
 
 const getAllSecretVersionsByPath = `-- name: GetAllSecretVersionsByPath :many
-SELECT sv.id, sv.secret_id, sv.version, sv.encrypted_value, sv.nonce, sv.created_at, sv.created_by, sv.hmac_signature, sv.hmac_key_id
+SELECT sv.id, sv.secret_id, sv.version, sv.encrypted_value, sv.nonce, sv.created_at, sv.created_by, sv.hmac_signature, sv.hmac_key_id, sv.environment
 FROM secrets s
 JOIN secret_versions sv ON s.id = sv.secret_id
-WHERE s.path = $1
+WHERE s.path = $1 AND sv.environment = $2
 ORDER BY sv.version DESC
 `
 
-func (q *Queries) GetAllSecretVersionsByPath(ctx context.Context, path string) ([]SecretVersions, error) {
-	rows, err := q.db.QueryContext(ctx, getAllSecretVersionsByPath, path)
+type GetAllSecretVersionsByPathParams struct {
+	Path        string `json:"path"`
+	Environment string `json:"environment"`
+}
+
+func (q *Queries) GetAllSecretVersionsByPath(ctx context.Context, arg GetAllSecretVersionsByPathParams) ([]SecretVersions, error) {
+	rows, err := q.db.QueryContext(ctx, getAllSecretVersionsByPath, arg.Path, arg.Environment)
 	if err != nil {
 		return nil, err
 	}
@@ -174,6 +223,66 @@ func (q *Queries) GetAllSecretVersionsByPath(ctx context.Context, path string) (
 			&i.CreatedBy,
 			&i.HmacSignature,
 			&i.HmacKeyID,
+			&i.Environment,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLatestEnvironmentVersionsByPrefix = `-- name: GetLatestEnvironmentVersionsByPrefix :many
+SELECT DISTINCT ON (s.id, sv.environment)
+       s.id AS secret_id, s.path, s.user_id, s.team_id,
+       sv.environment, sv.version, sv.encrypted_value, sv.nonce,
+       sv.hmac_signature, sv.hmac_key_id
+FROM secrets s
+JOIN secret_versions sv ON s.id = sv.secret_id
+WHERE starts_with(s.path, $1::TEXT)
+  AND (s.expires_at IS NULL OR s.expires_at > now())
+ORDER BY s.id, sv.environment, sv.version DESC
+`
+
+type GetLatestEnvironmentVersionsByPrefixRow struct {
+	SecretID       uuid.UUID     `json:"secret_id"`
+	Path           string        `json:"path"`
+	UserID         uuid.UUID     `json:"user_id"`
+	TeamID         uuid.NullUUID `json:"team_id"`
+	Environment    string        `json:"environment"`
+	Version        int32         `json:"version"`
+	EncryptedValue []byte        `json:"encrypted_value"`
+	Nonce          []byte        `json:"nonce"`
+	HmacSignature  []byte        `json:"hmac_signature"`
+	HmacKeyID      uuid.NullUUID `json:"hmac_key_id"`
+}
+
+func (q *Queries) GetLatestEnvironmentVersionsByPrefix(ctx context.Context, prefix string) ([]GetLatestEnvironmentVersionsByPrefixRow, error) {
+	rows, err := q.db.QueryContext(ctx, getLatestEnvironmentVersionsByPrefix, prefix)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetLatestEnvironmentVersionsByPrefixRow{}
+	for rows.Next() {
+		var i GetLatestEnvironmentVersionsByPrefixRow
+		if err := rows.Scan(
+			&i.SecretID,
+			&i.Path,
+			&i.UserID,
+			&i.TeamID,
+			&i.Environment,
+			&i.Version,
+			&i.EncryptedValue,
+			&i.Nonce,
+			&i.HmacSignature,
+			&i.HmacKeyID,
 		); err != nil {
 			return nil, err
 		}
@@ -189,15 +298,20 @@ func (q *Queries) GetAllSecretVersionsByPath(ctx context.Context, path string) (
 }
 
 const getLatestSecretByPath = `-- name: GetLatestSecretByPath :one
-SELECT sv.id, sv.secret_id, sv.version, sv.encrypted_value, sv.nonce, sv.created_at, sv.created_by, sv.hmac_signature, sv.hmac_key_id, s.id AS secret_id, s.user_id, s.path
+SELECT sv.id, sv.secret_id, sv.version, sv.encrypted_value, sv.nonce, sv.created_at, sv.created_by, sv.hmac_signature, sv.hmac_key_id, sv.environment, s.id AS secret_id, s.user_id, s.path, s.team_id
 FROM secrets s
 JOIN secret_versions sv ON s.id = sv.secret_id
-WHERE s.path = $1
+WHERE s.path = $1 AND sv.environment = $2
   AND (s.expires_at IS NULL OR s.expires_at > now())
 ORDER BY sv.version DESC
 LIMIT 1
 `
 
+type GetLatestSecretByPathParams struct {
+	Path        string `json:"path"`
+	Environment string `json:"environment"`
+}
+
 type GetLatestSecretByPathRow struct {
 	ID             uuid.UUID     `json:"id"`
 	SecretID       uuid.UUID     `json:"secret_id"`
@@ -208,13 +322,15 @@ type GetLatestSecretByPathRow struct {
 	CreatedBy      uuid.NullUUID `json:"created_by"`
 	HmacSignature  []byte        `json:"hmac_signature"`
 	HmacKeyID      uuid.NullUUID `json:"hmac_key_id"`
+	Environment    string        `json:"environment"`
 	SecretID_2     uuid.UUID     `json:"secret_id_2"`
 	UserID         uuid.UUID     `json:"user_id"`
 	Path           string        `json:"path"`
+	TeamID         uuid.NullUUID `json:"team_id"`
 }
 
-func (q *Queries) GetLatestSecretByPath(ctx context.Context, path string) (GetLatestSecretByPathRow, error) {
-	row := q.db.QueryRowContext(ctx, getLatestSecretByPath, path)
+func (q *Queries) GetLatestSecretByPath(ctx context.Context, arg GetLatestSecretByPathParams) (GetLatestSecretByPathRow, error) {
+	row := q.db.QueryRowContext(ctx, getLatestSecretByPath, arg.Path, arg.Environment)
 	var i GetLatestSecretByPathRow
 	err := row.Scan(
 		&i.ID,
@@ -226,9 +342,11 @@ func (q *Queries) GetLatestSecretByPath(ctx context.Context, path string) (GetLa
 		&i.CreatedBy,
 		&i.HmacSignature,
 		&i.HmacKeyID,
+		&i.Environment,
 		&i.SecretID_2,
 		&i.UserID,
 		&i.Path,
+		&i.TeamID,
 	)
 	return i, err
 }
@@ -295,16 +413,121 @@ func (q *Queries) GetLatestVersionNumberByPath(ctx context.Context, path string)
 	return latest_version, err
 }
 
+const getLatestVersionsBySecretID = `-- name: GetLatestVersionsBySecretID :many
+SELECT DISTINCT ON (environment) id, secret_id, version, encrypted_value, nonce, created_at, created_by, hmac_signature, hmac_key_id, environment
+FROM secret_versions
+WHERE secret_id = $1
+ORDER BY environment, version DESC
+`
+
+func (q *Queries) GetLatestVersionsBySecretID(ctx context.Context, secretID uuid.UUID) ([]SecretVersions, error) {
+	rows, err := q.db.QueryContext(ctx, getLatestVersionsBySecretID, secretID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SecretVersions{}
+	for rows.Next() {
+		var i SecretVersions
+		if err := rows.Scan(
+			&i.ID,
+			&i.SecretID,
+			&i.Version,
+			&i.EncryptedValue,
+			&i.Nonce,
+			&i.CreatedAt,
+			&i.CreatedBy,
+			&i.HmacSignature,
+			&i.HmacKeyID,
+			&i.Environment,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSecretByID = `-- name: GetSecretByID :one
+SELECT id, user_id, path, created_at, updated_at, expires_at, team_id FROM secrets
+WHERE id = $1
+  AND (expires_at IS NULL OR expires_at > now())
+`
+
+func (q *Queries) GetSecretByID(ctx context.Context, id uuid.UUID) (Secrets, error) {
+	row := q.db.QueryRowContext(ctx, getSecretByID, id)
+	var i Secrets
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Path,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresAt,
+		&i.TeamID,
+	)
+	return i, err
+}
+
+const getSecretByPath = `-- name: GetSecretByPath :one
+SELECT id, user_id, path, created_at, updated_at, expires_at, team_id FROM secrets
+WHERE path = $1
+  AND (expires_at IS NULL OR expires_at > now())
+`
+
+func (q *Queries) GetSecretByPath(ctx context.Context, path string) (Secrets, error) {
+	row := q.db.QueryRowContext(ctx, getSecretByPath, path)
+	var i Secrets
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Path,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresAt,
+		&i.TeamID,
+	)
+	return i, err
+}
+
+const getSecretByPathForUpdate = `-- name: GetSecretByPathForUpdate :one
+SELECT id, user_id, path, created_at, updated_at, expires_at, team_id FROM secrets
+WHERE path = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetSecretByPathForUpdate(ctx context.Context, path string) (Secrets, error) {
+	row := q.db.QueryRowContext(ctx, getSecretByPathForUpdate, path)
+	var i Secrets
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Path,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresAt,
+		&i.TeamID,
+	)
+	return i, err
+}
+
 const getSecretVersionByPathAndVersion = `-- name: GetSecretVersionByPathAndVersion :one
-SELECT sv.id, sv.secret_id, sv.version, sv.encrypted_value, sv.nonce, sv.created_at, sv.created_by, sv.hmac_signature, sv.hmac_key_id, s.id AS secret_id,s.user_id, s.path
+SELECT sv.id, sv.secret_id, sv.version, sv.encrypted_value, sv.nonce, sv.created_at, sv.created_by, sv.hmac_signature, sv.hmac_key_id, sv.environment, s.id AS secret_id,s.user_id, s.path, s.team_id
 FROM secrets s
 JOIN secret_versions sv ON s.id = sv.secret_id
-WHERE s.path = $1 AND sv.version = $2
+WHERE s.path = $1 AND sv.version = $2 AND sv.environment = $3
 `
 
 type GetSecretVersionByPathAndVersionParams struct {
-	Path    string `json:"path"`
-	Version int32  `json:"version"`
+	Path        string `json:"path"`
+	Version     int32  `json:"version"`
+	Environment string `json:"environment"`
 }
 
 type GetSecretVersionByPathAndVersionRow struct {
@@ -317,13 +540,15 @@ type GetSecretVersionByPathAndVersionRow struct {
 	CreatedBy      uuid.NullUUID `json:"created_by"`
 	HmacSignature  []byte        `json:"hmac_signature"`
 	HmacKeyID      uuid.NullUUID `json:"hmac_key_id"`
+	Environment    string        `json:"environment"`
 	SecretID_2     uuid.UUID     `json:"secret_id_2"`
 	UserID         uuid.UUID     `json:"user_id"`
 	Path           string        `json:"path"`
+	TeamID         uuid.NullUUID `json:"team_id"`
 }
 
 func (q *Queries) GetSecretVersionByPathAndVersion(ctx context.Context, arg GetSecretVersionByPathAndVersionParams) (GetSecretVersionByPathAndVersionRow, error) {
-	row := q.db.QueryRowContext(ctx, getSecretVersionByPathAndVersion, arg.Path, arg.Version)
+	row := q.db.QueryRowContext(ctx, getSecretVersionByPathAndVersion, arg.Path, arg.Version, arg.Environment)
 	var i GetSecretVersionByPathAndVersionRow
 	err := row.Scan(
 		&i.ID,
@@ -335,9 +560,11 @@ func (q *Queries) GetSecretVersionByPathAndVersion(ctx context.Context, arg GetS
 		&i.CreatedBy,
 		&i.HmacSignature,
 		&i.HmacKeyID,
+		&i.Environment,
 		&i.SecretID_2,
 		&i.UserID,
 		&i.Path,
+		&i.TeamID,
 	)
 	return i, err
 }
@@ -378,3 +605,131 @@ func (q *Queries) GetSecretsWithVersionCount(ctx context.Context) ([]GetSecretsW
 	}
 	return items, nil
 }
+
+const listLatestSecretsByPrefix = `-- name: ListLatestSecretsByPrefix :many
+SELECT DISTINCT ON (s.id)
+       s.path, s.user_id, s.team_id, s.expires_at,
+       sv.version, sv.created_at AS updated_at
+FROM secrets s
+JOIN secret_versions sv ON s.id = sv.secret_id
+WHERE starts_with(s.path, $1::TEXT)
+  AND sv.environment = $2
+  AND (s.expires_at IS NULL OR s.expires_at > now())
+ORDER BY s.id, sv.version DESC
+`
+
+type ListLatestSecretsByPrefixParams struct {
+	Prefix      string `json:"prefix"`
+	Environment string `json:"environment"`
+}
+
+type ListLatestSecretsByPrefixRow struct {
+	Path      string        `json:"path"`
+	UserID    uuid.UUID     `json:"user_id"`
+	TeamID    uuid.NullUUID `json:"team_id"`
+	ExpiresAt sql.NullTime  `json:"expires_at"`
+	Version   int32         `json:"version"`
+	UpdatedAt sql.NullTime  `json:"updated_at"`
+}
+
+func (q *Queries) ListLatestSecretsByPrefix(ctx context.Context, arg ListLatestSecretsByPrefixParams) ([]ListLatestSecretsByPrefixRow, error) {
+	rows, err := q.db.QueryContext(ctx, listLatestSecretsByPrefix, arg.Prefix, arg.Environment)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListLatestSecretsByPrefixRow{}
+	for rows.Next() {
+		var i ListLatestSecretsByPrefixRow
+		if err := rows.Scan(
+			&i.Path,
+			&i.UserID,
+			&i.TeamID,
+			&i.ExpiresAt,
+			&i.Version,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSecretsByPrefixForUpdate = `-- name: ListSecretsByPrefixForUpdate :many
+SELECT id, user_id, path, created_at, updated_at, expires_at, team_id FROM secrets
+WHERE starts_with(path, $1::TEXT)
+ORDER BY path
+FOR UPDATE
+`
+
+func (q *Queries) ListSecretsByPrefixForUpdate(ctx context.Context, prefix string) ([]Secrets, error) {
+	rows, err := q.db.QueryContext(ctx, listSecretsByPrefixForUpdate, prefix)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Secrets{}
+	for rows.Next() {
+		var i Secrets
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Path,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ExpiresAt,
+			&i.TeamID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const moveSecret = `-- name: MoveSecret :one
+UPDATE secrets
+SET path = $1, user_id = $2, team_id = $3, updated_at = now()
+WHERE path = $4
+RETURNING id, user_id, path, created_at, updated_at, expires_at, team_id
+`
+
+type MoveSecretParams struct {
+	NewPath string        `json:"new_path"`
+	UserID  uuid.UUID     `json:"user_id"`
+	TeamID  uuid.NullUUID `json:"team_id"`
+	OldPath string        `json:"old_path"`
+}
+
+func (q *Queries) MoveSecret(ctx context.Context, arg MoveSecretParams) (Secrets, error) {
+	row := q.db.QueryRowContext(ctx, moveSecret,
+		arg.NewPath,
+		arg.UserID,
+		arg.TeamID,
+		arg.OldPath,
+	)
+	var i Secrets
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Path,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresAt,
+		&i.TeamID,
+	)
+	return i, err
+}
@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"strings"
 	"testing"
 	"time"
 
@@ -31,8 +32,12 @@ func encryptAndDecrypt(t *testing.T, secret string) (encrypted, nonce []byte, de
 }
 
 func createNewSecret(t *testing.T) (newSecret SecretVersions, path string) {
-	user := createRandomUser(t)
 	path = util.RandomName()
+	return createSecretAt(t, path), path
+}
+
+func createSecretAt(t *testing.T, path string) (newSecret SecretVersions) {
+	user := createRandomUser(t)
 	secret := util.RandomString(int(util.RandomInt(1, 200)))
 	hmacId := createRandomHmacKey(t)
 	encrypted, nonce, _ := encryptAndDecrypt(t, secret)
@@ -52,6 +57,7 @@ func createNewSecret(t *testing.T) (newSecret SecretVersions, path string) {
 			Valid: true,
 		},
 		HmacSignature: hmacSignature,
+		Environment:   "default",
 	}
 
 	newSecret, err := testQueries.CreateSecretWithVersion(context.Background(), arg)
@@ -91,6 +97,7 @@ func TestCreateNewSecretVersion(t *testing.T) {
 			Valid: true,
 		},
 		HmacSignature: hmacSignature,
+		Environment:   "default",
 	}
 
 	updatedSecret, err := testQueries.CreateNewSecretVersion(context.Background(), args)
@@ -126,6 +133,7 @@ func TestGetLatestSecretByPath(t *testing.T) {
 			Valid: true,
 		},
 		HmacSignature: hmacSignature,
+		Environment:   "default",
 	}
 
 	newVersion, err := testQueries.CreateNewSecretVersion(context.Background(), args)
@@ -133,7 +141,7 @@ func TestGetLatestSecretByPath(t *testing.T) {
 	require.Equal(t, newVersion.Version, secret.Version+1)
 
 	// Get the latest secret version
-	latestSecret, err := testQueries.GetLatestSecretByPath(context.Background(), path)
+	latestSecret, err := testQueries.GetLatestSecretByPath(context.Background(), GetLatestSecretByPathParams{Path: path, Environment: "default"})
 	require.NoError(t, err)
 
 	require.Equal(t, latestSecret.Path, path)
@@ -162,6 +170,7 @@ func TestGetSecretVersionByPathAndVersion(t *testing.T) {
 			Valid: true,
 		},
 		HmacSignature: hmacSignature,
+		Environment:   "default",
 	}
 
 	newVersion, err := testQueries.CreateNewSecretVersion(context.Background(), args)
@@ -169,8 +178,9 @@ func TestGetSecretVersionByPathAndVersion(t *testing.T) {
 
 	// Get the first version
 	params := GetSecretVersionByPathAndVersionParams{
-		Path:    path,
-		Version: 1,
+		Path:        path,
+		Version:     1,
+		Environment: "default",
 	}
 
 	firstVersion, err := testQueries.GetSecretVersionByPathAndVersion(context.Background(), params)
@@ -208,13 +218,14 @@ func TestGetAllSecretVersionsByPath(t *testing.T) {
 			Valid: true,
 		},
 		HmacSignature: hmacSignature,
+		Environment:   "default",
 	}
 
 	newVersion, err := testQueries.CreateNewSecretVersion(context.Background(), args)
 	require.NoError(t, err)
 
 	// Get all versions
-	versions, err := testQueries.GetAllSecretVersionsByPath(context.Background(), path)
+	versions, err := testQueries.GetAllSecretVersionsByPath(context.Background(), GetAllSecretVersionsByPathParams{Path: path, Environment: "default"})
 	require.NoError(t, err)
 	require.Len(t, versions, 2)
 
@@ -256,6 +267,7 @@ func TestGetLatestSecretsForUser(t *testing.T) {
 				Valid: true,
 			},
 			HmacSignature: hmacSignature,
+			Environment:   "default",
 		}
 
 		_, err := testQueries.CreateSecretWithVersion(context.Background(), arg)
@@ -275,6 +287,7 @@ func TestGetLatestSecretsForUser(t *testing.T) {
 				CreatedBy:      arg.CreatedBy,
 				HmacKeyID:      arg.HmacKeyID,
 				HmacSignature:  newHmacSignature,
+				Environment:    "default",
 			}
 
 			_, err = testQueries.CreateNewSecretVersion(context.Background(), versionArg)
@@ -318,13 +331,14 @@ func TestDeleteSecretAndVersionsByPath(t *testing.T) {
 			Valid: true,
 		},
 		HmacSignature: hmacSignature,
+		Environment:   "default",
 	}
 
 	_, err := testQueries.CreateNewSecretVersion(context.Background(), args)
 	require.NoError(t, err)
 
 	// Verify secret exists
-	versions, err := testQueries.GetAllSecretVersionsByPath(context.Background(), path)
+	versions, err := testQueries.GetAllSecretVersionsByPath(context.Background(), GetAllSecretVersionsByPathParams{Path: path, Environment: "default"})
 	require.NoError(t, err)
 	require.Len(t, versions, 2)
 
@@ -333,7 +347,7 @@ func TestDeleteSecretAndVersionsByPath(t *testing.T) {
 	require.NoError(t, err)
 
 	// Verify the secret no longer exists
-	latestSecret, err := testQueries.GetLatestSecretByPath(context.Background(), path)
+	latestSecret, err := testQueries.GetLatestSecretByPath(context.Background(), GetLatestSecretByPathParams{Path: path, Environment: "default"})
 	require.Error(t, err)
 	require.Empty(t, latestSecret)
 }
@@ -368,6 +382,7 @@ func TestDeleteExpiredSecretAndVersions(t *testing.T) {
 			Valid: true,
 		},
 		HmacSignature: hmacSignature,
+		Environment:   "default",
 	}
 
 	expiredSecretVersion, err := testQueries.CreateSecretWithVersion(context.Background(), expiredArg)
@@ -401,6 +416,7 @@ func TestDeleteExpiredSecretAndVersions(t *testing.T) {
 			Valid: true,
 		},
 		HmacSignature: validHmacSignature,
+		Environment:   "default",
 	}
 
 	validSecretVersion, err := testQueries.CreateSecretWithVersion(context.Background(), validArg)
@@ -429,6 +445,7 @@ func TestDeleteExpiredSecretAndVersions(t *testing.T) {
 			Valid: true,
 		},
 		HmacSignature: noExpiryHmacSignature,
+		Environment:   "default",
 	}
 
 	noExpirySecretVersion, err := testQueries.CreateSecretWithVersion(context.Background(), noExpiryArg)
@@ -436,22 +453,30 @@ func TestDeleteExpiredSecretAndVersions(t *testing.T) {
 	require.NotEmpty(t, noExpirySecretVersion)
 
 	// Delete expired secrets
-	err = testQueries.DeleteExpiredSecretAndVersions(context.Background())
+	deleted, err := testQueries.DeleteExpiredSecretAndVersions(context.Background())
 	require.NoError(t, err)
 
+	var deletedPaths []string
+	for _, d := range deleted {
+		deletedPaths = append(deletedPaths, d.Path)
+	}
+	require.Contains(t, deletedPaths, expiredPath)
+	require.NotContains(t, deletedPaths, validPath)
+	require.NotContains(t, deletedPaths, noExpiryPath)
+
 	// Verify the expired secret no longer exists
-	expiredLatestSecret, err := testQueries.GetLatestSecretByPath(context.Background(), expiredPath)
+	expiredLatestSecret, err := testQueries.GetLatestSecretByPath(context.Background(), GetLatestSecretByPathParams{Path: expiredPath, Environment: "default"})
 	require.Error(t, err)
 	require.Empty(t, expiredLatestSecret)
 
 	// Verify the valid (not expired) secret still exists
-	validLatestSecret, err := testQueries.GetLatestSecretByPath(context.Background(), validPath)
+	validLatestSecret, err := testQueries.GetLatestSecretByPath(context.Background(), GetLatestSecretByPathParams{Path: validPath, Environment: "default"})
 	require.NoError(t, err)
 	require.NotEmpty(t, validLatestSecret)
 	require.Equal(t, validLatestSecret.Path, validPath)
 
 	// Verify the no expiry secret still exists
-	noExpiryLatestSecret, err := testQueries.GetLatestSecretByPath(context.Background(), noExpiryPath)
+	noExpiryLatestSecret, err := testQueries.GetLatestSecretByPath(context.Background(), GetLatestSecretByPathParams{Path: noExpiryPath, Environment: "default"})
 	require.NoError(t, err)
 	require.NotEmpty(t, noExpiryLatestSecret)
 	require.Equal(t, noExpiryLatestSecret.Path, noExpiryPath)
@@ -481,6 +506,7 @@ func TestGetSecretsWithVersionCount(t *testing.T) {
 						Valid: true,
 					},
 					HmacSignature: hmacSignature,
+					Environment:   "default",
 				}
 
 				_, err := testQueries.CreateNewSecretVersion(context.Background(), args)
@@ -506,6 +532,7 @@ func TestGetSecretsWithVersionCount(t *testing.T) {
 					Valid: true,
 				},
 				HmacSignature: hmacSignature,
+				Environment:   "default",
 			}
 
 			_, err := testQueries.CreateNewSecretVersion(context.Background(), args)
@@ -549,6 +576,7 @@ func TestGetLatestVersionNumberByPath(t *testing.T) {
 			Valid: true,
 		},
 		HmacSignature: hmacSignature,
+		Environment:   "default",
 	}
 
 	_, err = testQueries.CreateNewSecretVersion(context.Background(), args)
@@ -565,3 +593,149 @@ func TestGetLatestVersionNumberByPath(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, int64(0), latestVersion)
 }
+
+func TestSecretVersionsArePerEnvironment(t *testing.T) {
+	secret, path := createNewSecret(t)
+
+	hmacId := createRandomHmacKey(t)
+	for _, env := range []string{"dev", "dev", "prod"} {
+		encrypted, nonce, _ := encryptAndDecrypt(t, util.RandomString(32))
+		hmacSignature := append([]byte{}, encrypted...)
+		hmacSignature = append(hmacSignature, nonce...)
+
+		_, err := testQueries.CreateNewSecretVersion(context.Background(), CreateNewSecretVersionParams{
+			Path:           path,
+			EncryptedValue: encrypted,
+			Nonce:          nonce,
+			CreatedBy:      secret.CreatedBy,
+			HmacKeyID: uuid.NullUUID{
+				UUID:  hmacId,
+				Valid: true,
+			},
+			HmacSignature: hmacSignature,
+			Environment:   env,
+		})
+		require.NoError(t, err)
+	}
+
+	dev, err := testQueries.GetLatestSecretByPath(context.Background(), GetLatestSecretByPathParams{Path: path, Environment: "dev"})
+	require.NoError(t, err)
+	require.Equal(t, int32(2), dev.Version)
+
+	prod, err := testQueries.GetLatestSecretByPath(context.Background(), GetLatestSecretByPathParams{Path: path, Environment: "prod"})
+	require.NoError(t, err)
+	require.Equal(t, int32(1), prod.Version)
+
+	// the default environment is untouched by writes to other environments
+	def, err := testQueries.GetLatestSecretByPath(context.Background(), GetLatestSecretByPathParams{Path: path, Environment: "default"})
+	require.NoError(t, err)
+	require.Equal(t, secret.Version, def.Version)
+
+	_, err = testQueries.GetLatestSecretByPath(context.Background(), GetLatestSecretByPathParams{Path: path, Environment: "staging"})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	rows, err := testQueries.GetLatestEnvironmentVersionsByPrefix(context.Background(), path)
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	for _, row := range rows {
+		switch row.Environment {
+		case "dev":
+			require.Equal(t, int32(2), row.Version)
+		case "prod", "default":
+			require.Equal(t, int32(1), row.Version)
+		default:
+			t.Fatalf("unexpected environment %s", row.Environment)
+		}
+	}
+}
+
+func TestMoveSecretKeepsVersions(t *testing.T) {
+	secret, path := createNewSecret(t)
+	newOwner := createRandomUser(t)
+
+	locked, err := testQueries.GetSecretByPathForUpdate(context.Background(), path)
+	require.NoError(t, err)
+	require.Equal(t, secret.SecretID, locked.ID)
+
+	newPath := util.RandomName() + "/" + util.RandomName()
+	moved, err := testQueries.MoveSecret(context.Background(), MoveSecretParams{
+		OldPath: path,
+		NewPath: newPath,
+		UserID:  newOwner.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, secret.SecretID, moved.ID)
+	require.Equal(t, newPath, moved.Path)
+	require.Equal(t, newOwner.ID, moved.UserID)
+
+	latest, err := testQueries.GetLatestSecretByPath(context.Background(), GetLatestSecretByPathParams{Path: newPath, Environment: "default"})
+	require.NoError(t, err)
+	require.Equal(t, secret.ID, latest.ID)
+
+	_, err = testQueries.GetSecretByPath(context.Background(), path)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	versions, err := testQueries.GetLatestVersionsBySecretID(context.Background(), moved.ID)
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+	require.Equal(t, secret.ID, versions[0].ID)
+}
+
+func TestListSecretsByPrefixForUpdate(t *testing.T) {
+	prefix := util.RandomName() + "/"
+	for i := 0; i < 3; i++ {
+		createSecretAt(t, prefix+util.RandomName())
+	}
+	// a sibling sharing the prefix string but not the folder
+	createSecretAt(t, strings.TrimSuffix(prefix, "/")+"x/"+util.RandomName())
+
+	found, err := testQueries.ListSecretsByPrefixForUpdate(context.Background(), prefix)
+	require.NoError(t, err)
+	require.Len(t, found, 3)
+	for _, secret := range found {
+		require.True(t, strings.HasPrefix(secret.Path, prefix))
+	}
+}
+
+func TestListLatestSecretsByPrefix(t *testing.T) {
+	prefix := util.RandomName() + "/"
+	updatedPath := prefix + util.RandomName()
+	first := createSecretAt(t, updatedPath)
+	createSecretAt(t, prefix+util.RandomName())
+	// a sibling sharing the prefix string but not the folder
+	createSecretAt(t, strings.TrimSuffix(prefix, "/")+"x/"+util.RandomName())
+
+	_, err := testQueries.CreateNewSecretVersion(context.Background(), CreateNewSecretVersionParams{
+		Path:           updatedPath,
+		EncryptedValue: first.EncryptedValue,
+		Nonce:          first.Nonce,
+		CreatedBy:      first.CreatedBy,
+		HmacSignature:  first.HmacSignature,
+		HmacKeyID:      first.HmacKeyID,
+		Environment:    "default",
+	})
+	require.NoError(t, err)
+
+	found, err := testQueries.ListLatestSecretsByPrefix(context.Background(), ListLatestSecretsByPrefixParams{
+		Prefix:      prefix,
+		Environment: "default",
+	})
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	for _, row := range found {
+		require.True(t, strings.HasPrefix(row.Path, prefix))
+		if row.Path == updatedPath {
+			require.EqualValues(t, 2, row.Version)
+		} else {
+			require.EqualValues(t, 1, row.Version)
+		}
+	}
+
+	// nothing has a value in other environments yet
+	found, err = testQueries.ListLatestSecretsByPrefix(context.Background(), ListLatestSecretsByPrefixParams{
+		Prefix:      prefix,
+		Environment: "prod",
+	})
+	require.NoError(t, err)
+	require.Empty(t, found)
+}
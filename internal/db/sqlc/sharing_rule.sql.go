@@ -8,13 +8,17 @@ package db
 import (
 	"context"
 	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const checkIfShared = `-- name: CheckIfShared :one
 SELECT EXISTS (
     SELECT 1
     FROM sharing_rules
-    WHERE path = $1 AND target_email = $2 
+    WHERE path = $1 AND target_email = $2
+    AND is_prefix = $3
     AND (shared_until IS NULL OR shared_until > NOW())
 )
 `
@@ -22,29 +26,191 @@ SELECT EXISTS (
 type CheckIfSharedParams struct {
 	Path        string `json:"path"`
 	TargetEmail string `json:"target_email"`
+	IsPrefix    bool   `json:"is_prefix"`
 }
 
 func (q *Queries) CheckIfShared(ctx context.Context, arg CheckIfSharedParams) (bool, error) {
-	row := q.db.QueryRowContext(ctx, checkIfShared, arg.Path, arg.TargetEmail)
+	row := q.db.QueryRowContext(ctx, checkIfShared, arg.Path, arg.TargetEmail, arg.IsPrefix)
 	var exists bool
 	err := row.Scan(&exists)
 	return exists, err
 }
 
-const deleteExpiredSharingRules = `-- name: DeleteExpiredSharingRules :exec
+const deleteExpiredSharingRules = `-- name: DeleteExpiredSharingRules :many
 DELETE FROM sharing_rules
 WHERE shared_until IS NOT NULL AND shared_until < NOW()
+RETURNING id, owner_email, target_email, path, permission, created_at, shared_until, is_prefix, target_group_id
 `
 
-func (q *Queries) DeleteExpiredSharingRules(ctx context.Context) error {
-	_, err := q.db.ExecContext(ctx, deleteExpiredSharingRules)
-	return err
+func (q *Queries) DeleteExpiredSharingRules(ctx context.Context) ([]SharingRules, error) {
+	rows, err := q.db.QueryContext(ctx, deleteExpiredSharingRules)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SharingRules{}
+	for rows.Next() {
+		var i SharingRules
+		if err := rows.Scan(
+			&i.ID,
+			&i.OwnerEmail,
+			&i.TargetEmail,
+			&i.Path,
+			&i.Permission,
+			&i.CreatedAt,
+			&i.SharedUntil,
+			&i.IsPrefix,
+			&i.TargetGroupID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteSharingRule = `-- name: DeleteSharingRule :many
+DELETE FROM sharing_rules
+WHERE path = $1 AND target_email = $2 AND is_prefix = $3
+RETURNING id, owner_email, target_email, path, permission, created_at, shared_until, is_prefix, target_group_id
+`
+
+type DeleteSharingRuleParams struct {
+	Path        string `json:"path"`
+	TargetEmail string `json:"target_email"`
+	IsPrefix    bool   `json:"is_prefix"`
+}
+
+func (q *Queries) DeleteSharingRule(ctx context.Context, arg DeleteSharingRuleParams) ([]SharingRules, error) {
+	rows, err := q.db.QueryContext(ctx, deleteSharingRule, arg.Path, arg.TargetEmail, arg.IsPrefix)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SharingRules{}
+	for rows.Next() {
+		var i SharingRules
+		if err := rows.Scan(
+			&i.ID,
+			&i.OwnerEmail,
+			&i.TargetEmail,
+			&i.Path,
+			&i.Permission,
+			&i.CreatedAt,
+			&i.SharedUntil,
+			&i.IsPrefix,
+			&i.TargetGroupID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteSharingRulesByPath = `-- name: DeleteSharingRulesByPath :many
+DELETE FROM sharing_rules
+WHERE path = $1 AND is_prefix = $2
+RETURNING id, owner_email, target_email, path, permission, created_at, shared_until, is_prefix, target_group_id
+`
+
+type DeleteSharingRulesByPathParams struct {
+	Path     string `json:"path"`
+	IsPrefix bool   `json:"is_prefix"`
+}
+
+func (q *Queries) DeleteSharingRulesByPath(ctx context.Context, arg DeleteSharingRulesByPathParams) ([]SharingRules, error) {
+	rows, err := q.db.QueryContext(ctx, deleteSharingRulesByPath, arg.Path, arg.IsPrefix)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SharingRules{}
+	for rows.Next() {
+		var i SharingRules
+		if err := rows.Scan(
+			&i.ID,
+			&i.OwnerEmail,
+			&i.TargetEmail,
+			&i.Path,
+			&i.Permission,
+			&i.CreatedAt,
+			&i.SharedUntil,
+			&i.IsPrefix,
+			&i.TargetGroupID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEffectiveShare = `-- name: GetEffectiveShare :one
+SELECT id, owner_email, target_email, path, permission, created_at, shared_until, is_prefix, target_group_id
+FROM sharing_rules
+WHERE (target_email = $1
+    OR target_group_id IN (SELECT group_id FROM group_members WHERE user_id = $2))
+AND ((path = $3 AND NOT is_prefix) OR (is_prefix AND path = ANY($4::TEXT[])))
+AND (shared_until IS NULL OR shared_until > NOW())
+ORDER BY is_prefix, length(path) DESC, target_group_id IS NOT NULL, permission = 'write' DESC
+LIMIT 1
+`
+
+type GetEffectiveShareParams struct {
+	TargetEmail string    `json:"target_email"`
+	UserID      uuid.UUID `json:"user_id"`
+	Path        string    `json:"path"`
+	Parents     []string  `json:"parents"`
+}
+
+// most specific rule wins: an exact share of the secret, then the share of the closest folder
+// above it, so a narrower rule can also restrict what a broader one grants. Among rules on the
+// same path a share with the user beats shares with their groups, and write beats read.
+func (q *Queries) GetEffectiveShare(ctx context.Context, arg GetEffectiveShareParams) (SharingRules, error) {
+	row := q.db.QueryRowContext(ctx, getEffectiveShare,
+		arg.TargetEmail,
+		arg.UserID,
+		arg.Path,
+		pq.Array(arg.Parents),
+	)
+	var i SharingRules
+	err := row.Scan(
+		&i.ID,
+		&i.OwnerEmail,
+		&i.TargetEmail,
+		&i.Path,
+		&i.Permission,
+		&i.CreatedAt,
+		&i.SharedUntil,
+		&i.IsPrefix,
+		&i.TargetGroupID,
+	)
+	return i, err
 }
 
 const getPermissions = `-- name: GetPermissions :one
 SELECT permission
 FROM sharing_rules
-WHERE path = $1 AND target_email = $2 
+WHERE path = $1 AND target_email = $2 AND NOT is_prefix
 AND (shared_until IS NULL OR shared_until > NOW())
 `
 
@@ -61,28 +227,48 @@ func (q *Queries) GetPermissions(ctx context.Context, arg GetPermissionsParams)
 }
 
 const getSecretsSharedWithMe = `-- name: GetSecretsSharedWithMe :many
-SELECT path, permission, owner_email
+SELECT id, owner_email, target_email, path, permission, created_at, shared_until, is_prefix, target_group_id
 FROM sharing_rules
-WHERE target_email = $1 
+WHERE (target_email = $1
+    OR target_group_id IN (SELECT group_id FROM group_members WHERE user_id = $2))
 AND (shared_until IS NULL OR shared_until > NOW())
+AND starts_with(CASE WHEN is_prefix THEN path || '/' ELSE path END, $3::TEXT)
+AND ($4::TEXT = '' OR permission = $4)
+ORDER BY path
 `
 
-type GetSecretsSharedWithMeRow struct {
-	Path       string `json:"path"`
-	Permission string `json:"permission"`
-	OwnerEmail string `json:"owner_email"`
+type GetSecretsSharedWithMeParams struct {
+	TargetEmail string    `json:"target_email"`
+	UserID      uuid.UUID `json:"user_id"`
+	Prefix      string    `json:"prefix"`
+	Permission  string    `json:"permission"`
 }
 
-func (q *Queries) GetSecretsSharedWithMe(ctx context.Context, targetEmail string) ([]GetSecretsSharedWithMeRow, error) {
-	rows, err := q.db.QueryContext(ctx, getSecretsSharedWithMe, targetEmail)
+func (q *Queries) GetSecretsSharedWithMe(ctx context.Context, arg GetSecretsSharedWithMeParams) ([]SharingRules, error) {
+	rows, err := q.db.QueryContext(ctx, getSecretsSharedWithMe,
+		arg.TargetEmail,
+		arg.UserID,
+		arg.Prefix,
+		arg.Permission,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []GetSecretsSharedWithMeRow{}
+	items := []SharingRules{}
 	for rows.Next() {
-		var i GetSecretsSharedWithMeRow
-		if err := rows.Scan(&i.Path, &i.Permission, &i.OwnerEmail); err != nil {
+		var i SharingRules
+		if err := rows.Scan(
+			&i.ID,
+			&i.OwnerEmail,
+			&i.TargetEmail,
+			&i.Path,
+			&i.Permission,
+			&i.CreatedAt,
+			&i.SharedUntil,
+			&i.IsPrefix,
+			&i.TargetGroupID,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -97,32 +283,53 @@ func (q *Queries) GetSecretsSharedWithMe(ctx context.Context, targetEmail string
 }
 
 const getSharedWith = `-- name: GetSharedWith :many
-SELECT owner_email, target_email 
-FROM sharing_rules
-WHERE path = $1 AND (shared_until IS NULL OR shared_until > NOW())
-AND target_email != $2
+SELECT id, owner_email, target_email, path, permission, created_at, shared_until, is_prefix, target_group_id
+FROM (
+    SELECT DISTINCT ON (target_email) id, owner_email, target_email, path, permission, created_at, shared_until, is_prefix, target_group_id
+    FROM sharing_rules
+    WHERE ((path = $1 AND NOT is_prefix) OR (is_prefix AND path = ANY($2::TEXT[])))
+    AND (shared_until IS NULL OR shared_until > NOW())
+    AND target_email != $3
+    ORDER BY target_email, is_prefix, length(path) DESC
+) r
+WHERE $4::TEXT = '' OR r.permission = $4
+ORDER BY r.target_email
 `
 
 type GetSharedWithParams struct {
-	Path        string `json:"path"`
-	TargetEmail string `json:"target_email"`
+	Path        string   `json:"path"`
+	Parents     []string `json:"parents"`
+	TargetEmail string   `json:"target_email"`
+	Permission  string   `json:"permission"`
 }
 
-type GetSharedWithRow struct {
-	OwnerEmail  string `json:"owner_email"`
-	TargetEmail string `json:"target_email"`
-}
-
-func (q *Queries) GetSharedWith(ctx context.Context, arg GetSharedWithParams) ([]GetSharedWithRow, error) {
-	rows, err := q.db.QueryContext(ctx, getSharedWith, arg.Path, arg.TargetEmail)
+// the rule that applies to each user: an exact share of the secret, or the share of the
+// closest folder above it in parents
+func (q *Queries) GetSharedWith(ctx context.Context, arg GetSharedWithParams) ([]SharingRules, error) {
+	rows, err := q.db.QueryContext(ctx, getSharedWith,
+		arg.Path,
+		pq.Array(arg.Parents),
+		arg.TargetEmail,
+		arg.Permission,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []GetSharedWithRow{}
+	items := []SharingRules{}
 	for rows.Next() {
-		var i GetSharedWithRow
-		if err := rows.Scan(&i.OwnerEmail, &i.TargetEmail); err != nil {
+		var i SharingRules
+		if err := rows.Scan(
+			&i.ID,
+			&i.OwnerEmail,
+			&i.TargetEmail,
+			&i.Path,
+			&i.Permission,
+			&i.CreatedAt,
+			&i.SharedUntil,
+			&i.IsPrefix,
+			&i.TargetGroupID,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -136,18 +343,75 @@ func (q *Queries) GetSharedWith(ctx context.Context, arg GetSharedWithParams) ([
 	return items, nil
 }
 
+const lockShare = `-- name: LockShare :exec
+SELECT pg_advisory_xact_lock(hashtext($1::TEXT || '|' || $2::TEXT))
+`
+
+type LockShareParams struct {
+	Path        string `json:"path"`
+	TargetEmail string `json:"target_email"`
+}
+
+// serializes creating shares of one path with one target until the transaction ends, so the
+// CheckIfShared that follows cannot race another share
+func (q *Queries) LockShare(ctx context.Context, arg LockShareParams) error {
+	_, err := q.db.ExecContext(ctx, lockShare, arg.Path, arg.TargetEmail)
+	return err
+}
+
+const movePrefixSharingRules = `-- name: MovePrefixSharingRules :execrows
+UPDATE sharing_rules
+SET path = $1::TEXT || substr(path, length($2::TEXT) + 1)
+WHERE is_prefix AND (path = $2::TEXT OR starts_with(path, $2::TEXT || '/'))
+`
+
+type MovePrefixSharingRulesParams struct {
+	NewPrefix string `json:"new_prefix"`
+	OldPrefix string `json:"old_prefix"`
+}
+
+// folder shares on old_prefix and on every folder below it follow the folder to new_prefix
+func (q *Queries) MovePrefixSharingRules(ctx context.Context, arg MovePrefixSharingRulesParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, movePrefixSharingRules, arg.NewPrefix, arg.OldPrefix)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const moveSharingRules = `-- name: MoveSharingRules :execrows
+UPDATE sharing_rules
+SET path = $1
+WHERE path = $2 AND NOT is_prefix
+`
+
+type MoveSharingRulesParams struct {
+	NewPath string `json:"new_path"`
+	OldPath string `json:"old_path"`
+}
+
+func (q *Queries) MoveSharingRules(ctx context.Context, arg MoveSharingRulesParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, moveSharingRules, arg.NewPath, arg.OldPath)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const shareSecret = `-- name: ShareSecret :one
-INSERT INTO sharing_rules (owner_email, target_email, path, permission, shared_until)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, owner_email, target_email, path, permission, created_at, shared_until
+INSERT INTO sharing_rules (owner_email, target_email, path, permission, shared_until, is_prefix, target_group_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, owner_email, target_email, path, permission, created_at, shared_until, is_prefix, target_group_id
 `
 
 type ShareSecretParams struct {
-	OwnerEmail  string       `json:"owner_email"`
-	TargetEmail string       `json:"target_email"`
-	Path        string       `json:"path"`
-	Permission  string       `json:"permission"`
-	SharedUntil sql.NullTime `json:"shared_until"`
+	OwnerEmail    string        `json:"owner_email"`
+	TargetEmail   string        `json:"target_email"`
+	Path          string        `json:"path"`
+	Permission    string        `json:"permission"`
+	SharedUntil   sql.NullTime  `json:"shared_until"`
+	IsPrefix      bool          `json:"is_prefix"`
+	TargetGroupID uuid.NullUUID `json:"target_group_id"`
 }
 
 func (q *Queries) ShareSecret(ctx context.Context, arg ShareSecretParams) (SharingRules, error) {
@@ -157,6 +421,8 @@ func (q *Queries) ShareSecret(ctx context.Context, arg ShareSecretParams) (Shari
 		arg.Path,
 		arg.Permission,
 		arg.SharedUntil,
+		arg.IsPrefix,
+		arg.TargetGroupID,
 	)
 	var i SharingRules
 	err := row.Scan(
@@ -167,6 +433,67 @@ func (q *Queries) ShareSecret(ctx context.Context, arg ShareSecretParams) (Shari
 		&i.Permission,
 		&i.CreatedAt,
 		&i.SharedUntil,
+		&i.IsPrefix,
+		&i.TargetGroupID,
 	)
 	return i, err
 }
+
+const updateSharingRule = `-- name: UpdateSharingRule :many
+UPDATE sharing_rules
+SET permission = COALESCE($1, permission),
+    shared_until = CASE WHEN $2::boolean THEN $3 ELSE shared_until END
+WHERE path = $4 AND target_email = $5
+AND is_prefix = $6
+AND (shared_until IS NULL OR shared_until > NOW())
+RETURNING id, owner_email, target_email, path, permission, created_at, shared_until, is_prefix, target_group_id
+`
+
+type UpdateSharingRuleParams struct {
+	Permission     sql.NullString `json:"permission"`
+	SetSharedUntil bool           `json:"set_shared_until"`
+	SharedUntil    sql.NullTime   `json:"shared_until"`
+	Path           string         `json:"path"`
+	TargetEmail    string         `json:"target_email"`
+	IsPrefix       bool           `json:"is_prefix"`
+}
+
+func (q *Queries) UpdateSharingRule(ctx context.Context, arg UpdateSharingRuleParams) ([]SharingRules, error) {
+	rows, err := q.db.QueryContext(ctx, updateSharingRule,
+		arg.Permission,
+		arg.SetSharedUntil,
+		arg.SharedUntil,
+		arg.Path,
+		arg.TargetEmail,
+		arg.IsPrefix,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SharingRules{}
+	for rows.Next() {
+		var i SharingRules
+		if err := rows.Scan(
+			&i.ID,
+			&i.OwnerEmail,
+			&i.TargetEmail,
+			&i.Path,
+			&i.Permission,
+			&i.CreatedAt,
+			&i.SharedUntil,
+			&i.IsPrefix,
+			&i.TargetGroupID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
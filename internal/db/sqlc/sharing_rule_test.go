@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/pixperk/vaultify/internal/util"
 	"github.com/stretchr/testify/require"
 )
 
@@ -76,9 +77,17 @@ func TestDeleteExpiredSharingRules(t *testing.T) {
 	require.NotEmpty(t, noExpiryRule)
 
 	// Delete expired sharing rules
-	err = testQueries.DeleteExpiredSharingRules(context.Background())
+	deleted, err := testQueries.DeleteExpiredSharingRules(context.Background())
 	require.NoError(t, err)
 
+	var deletedPaths []string
+	for _, d := range deleted {
+		deletedPaths = append(deletedPaths, d.Path)
+	}
+	require.Contains(t, deletedPaths, path)
+	require.NotContains(t, deletedPaths, validPath)
+	require.NotContains(t, deletedPaths, noExpiryPath)
+
 	// Verify the expired sharing rule no longer exists
 	expiredCheckParams := CheckIfSharedParams{
 		Path:        path,
@@ -129,13 +138,39 @@ func TestGetSecretsSharedWithMe(t *testing.T) {
 	require.NotEmpty(t, sharedSecret)
 
 	// Get secrets shared with the target user
-	sharedSecrets, err := testQueries.GetSecretsSharedWithMe(context.Background(), target.Email)
+	sharedSecrets, err := testQueries.GetSecretsSharedWithMe(context.Background(), GetSecretsSharedWithMeParams{
+		TargetEmail: target.Email,
+	})
 	require.NoError(t, err)
 	require.NotEmpty(t, sharedSecrets)
 	require.Len(t, sharedSecrets, 1)
 	require.Equal(t, sharedSecrets[0].Path, path)
 	require.Equal(t, sharedSecrets[0].Permission, "read")
 	require.Equal(t, sharedSecrets[0].OwnerEmail, owner.Email)
+	require.False(t, sharedSecrets[0].SharedUntil.Valid)
+
+	// Filter by prefix and permission
+	sharedSecrets, err = testQueries.GetSecretsSharedWithMe(context.Background(), GetSecretsSharedWithMeParams{
+		TargetEmail: target.Email,
+		Prefix:      path[:len(path)-1],
+		Permission:  "read",
+	})
+	require.NoError(t, err)
+	require.Len(t, sharedSecrets, 1)
+
+	sharedSecrets, err = testQueries.GetSecretsSharedWithMe(context.Background(), GetSecretsSharedWithMeParams{
+		TargetEmail: target.Email,
+		Permission:  "write",
+	})
+	require.NoError(t, err)
+	require.Empty(t, sharedSecrets)
+
+	sharedSecrets, err = testQueries.GetSecretsSharedWithMe(context.Background(), GetSecretsSharedWithMeParams{
+		TargetEmail: target.Email,
+		Prefix:      path + "/",
+	})
+	require.NoError(t, err)
+	require.Empty(t, sharedSecrets)
 }
 
 func TestGetPermissions(t *testing.T) {
@@ -259,6 +294,13 @@ func TestGetSharedWith(t *testing.T) {
 
 	require.True(t, emails[target1.Email])
 	require.True(t, emails[target2.Email])
+
+	// Filter by permission
+	getSharedWithParams.Permission = "write"
+	sharedWith, err = testQueries.GetSharedWith(context.Background(), getSharedWithParams)
+	require.NoError(t, err)
+	require.Len(t, sharedWith, 1)
+	require.Equal(t, target2.Email, sharedWith[0].TargetEmail)
 }
 
 func TestShareSecret(t *testing.T) {
@@ -360,3 +402,231 @@ func TestShareSecret(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "write", permission2)
 }
+
+func TestMoveSharingRules(t *testing.T) {
+	owner := createRandomUser(t)
+	target := createRandomUser(t)
+	_, path := createNewSecret(t)
+
+	_, err := testQueries.ShareSecret(context.Background(), ShareSecretParams{
+		OwnerEmail:  owner.Email,
+		TargetEmail: target.Email,
+		Path:        path,
+		Permission:  "read",
+	})
+	require.NoError(t, err)
+
+	newPath := path + "/moved"
+	moved, err := testQueries.MoveSharingRules(context.Background(), MoveSharingRulesParams{
+		OldPath: path,
+		NewPath: newPath,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), moved)
+
+	shared, err := testQueries.CheckIfShared(context.Background(), CheckIfSharedParams{Path: newPath, TargetEmail: target.Email})
+	require.NoError(t, err)
+	require.True(t, shared)
+
+	shared, err = testQueries.CheckIfShared(context.Background(), CheckIfSharedParams{Path: path, TargetEmail: target.Email})
+	require.NoError(t, err)
+	require.False(t, shared)
+}
+
+func TestMovePrefixSharingRules(t *testing.T) {
+	owner := createRandomUser(t)
+	target := createRandomUser(t)
+	folder := util.RandomEmail() + "/" + util.RandomString(6)
+
+	for _, path := range []string{folder, folder + "/db", folder + "x"} {
+		_, err := testQueries.ShareSecret(context.Background(), ShareSecretParams{
+			OwnerEmail:  owner.Email,
+			TargetEmail: target.Email,
+			Path:        path,
+			Permission:  "read",
+			IsPrefix:    true,
+		})
+		require.NoError(t, err)
+	}
+
+	// the folder's own share and the one below it move, the sibling with a longer name does not
+	newFolder := folder + "-moved"
+	moved, err := testQueries.MovePrefixSharingRules(context.Background(), MovePrefixSharingRulesParams{
+		OldPrefix: folder,
+		NewPrefix: newFolder,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), moved)
+
+	for path, want := range map[string]bool{newFolder: true, newFolder + "/db": true, folder + "x": true, folder: false} {
+		shared, err := testQueries.CheckIfShared(context.Background(), CheckIfSharedParams{Path: path, TargetEmail: target.Email, IsPrefix: true})
+		require.NoError(t, err)
+		require.Equal(t, want, shared, path)
+	}
+}
+
+func TestUpdateSharingRule(t *testing.T) {
+	owner := createRandomUser(t)
+	target := createRandomUser(t)
+	_, path := createNewSecret(t)
+
+	_, err := testQueries.ShareSecret(context.Background(), ShareSecretParams{
+		OwnerEmail:  owner.Email,
+		TargetEmail: target.Email,
+		Path:        path,
+		Permission:  "write",
+	})
+	require.NoError(t, err)
+
+	// change only the permission
+	updated, err := testQueries.UpdateSharingRule(context.Background(), UpdateSharingRuleParams{
+		Permission:  sql.NullString{String: "read", Valid: true},
+		Path:        path,
+		TargetEmail: target.Email,
+	})
+	require.NoError(t, err)
+	require.Len(t, updated, 1)
+	require.Equal(t, "read", updated[0].Permission)
+	require.False(t, updated[0].SharedUntil.Valid)
+
+	// change only the expiry
+	until := time.Now().Add(time.Hour)
+	updated, err = testQueries.UpdateSharingRule(context.Background(), UpdateSharingRuleParams{
+		SetSharedUntil: true,
+		SharedUntil:    sql.NullTime{Time: until, Valid: true},
+		Path:           path,
+		TargetEmail:    target.Email,
+	})
+	require.NoError(t, err)
+	require.Len(t, updated, 1)
+	require.Equal(t, "read", updated[0].Permission)
+	require.WithinDuration(t, until, updated[0].SharedUntil.Time, time.Second)
+
+	permission, err := testQueries.GetPermissions(context.Background(), GetPermissionsParams{
+		Path:        path,
+		TargetEmail: target.Email,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "read", permission)
+
+	// no rule for another user
+	updated, err = testQueries.UpdateSharingRule(context.Background(), UpdateSharingRuleParams{
+		Permission:  sql.NullString{String: "write", Valid: true},
+		Path:        path,
+		TargetEmail: owner.Email,
+	})
+	require.NoError(t, err)
+	require.Empty(t, updated)
+}
+
+func TestDeleteSharingRules(t *testing.T) {
+	owner := createRandomUser(t)
+	first := createRandomUser(t)
+	second := createRandomUser(t)
+	_, path := createNewSecret(t)
+
+	for _, target := range []Users{first, second} {
+		_, err := testQueries.ShareSecret(context.Background(), ShareSecretParams{
+			OwnerEmail:  owner.Email,
+			TargetEmail: target.Email,
+			Path:        path,
+			Permission:  "read",
+		})
+		require.NoError(t, err)
+	}
+
+	deleted, err := testQueries.DeleteSharingRule(context.Background(), DeleteSharingRuleParams{
+		Path:        path,
+		TargetEmail: first.Email,
+	})
+	require.NoError(t, err)
+	require.Len(t, deleted, 1)
+
+	shared, err := testQueries.CheckIfShared(context.Background(), CheckIfSharedParams{Path: path, TargetEmail: first.Email})
+	require.NoError(t, err)
+	require.False(t, shared)
+	shared, err = testQueries.CheckIfShared(context.Background(), CheckIfSharedParams{Path: path, TargetEmail: second.Email})
+	require.NoError(t, err)
+	require.True(t, shared)
+
+	deleted, err = testQueries.DeleteSharingRulesByPath(context.Background(), DeleteSharingRulesByPathParams{Path: path})
+	require.NoError(t, err)
+	require.Len(t, deleted, 1)
+	require.Equal(t, second.Email, deleted[0].TargetEmail)
+}
+
+func TestGetEffectiveShare(t *testing.T) {
+	owner := createRandomUser(t)
+	target := createRandomUser(t)
+	folder := util.RandomName()
+	path := folder + "/payments/db"
+
+	share := func(path, permission string, prefix bool) {
+		_, err := testQueries.ShareSecret(context.Background(), ShareSecretParams{
+			OwnerEmail:  owner.Email,
+			TargetEmail: target.Email,
+			Path:        path,
+			Permission:  permission,
+			IsPrefix:    prefix,
+		})
+		require.NoError(t, err)
+	}
+	effective := func() (SharingRules, error) {
+		return testQueries.GetEffectiveShare(context.Background(), GetEffectiveShareParams{
+			TargetEmail: target.Email,
+			Path:        path,
+			Parents:     []string{folder, folder + "/payments"},
+		})
+	}
+
+	_, err := effective()
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// a folder share covers secrets below it
+	share(folder, "write", true)
+	rule, err := effective()
+	require.NoError(t, err)
+	require.True(t, rule.IsPrefix)
+	require.Equal(t, folder, rule.Path)
+	require.Equal(t, "write", rule.Permission)
+
+	// the closer folder wins
+	share(folder+"/payments", "read", true)
+	rule, err = effective()
+	require.NoError(t, err)
+	require.Equal(t, folder+"/payments", rule.Path)
+	require.Equal(t, "read", rule.Permission)
+
+	// a share of the secret itself wins over every folder
+	share(path, "write", false)
+	rule, err = effective()
+	require.NoError(t, err)
+	require.False(t, rule.IsPrefix)
+	require.Equal(t, "write", rule.Permission)
+
+	// a folder share with the same path as the secret does not cover it
+	other := createRandomUser(t)
+	_, err = testQueries.ShareSecret(context.Background(), ShareSecretParams{
+		OwnerEmail:  owner.Email,
+		TargetEmail: other.Email,
+		Path:        path,
+		Permission:  "read",
+		IsPrefix:    true,
+	})
+	require.NoError(t, err)
+	_, err = testQueries.GetEffectiveShare(context.Background(), GetEffectiveShareParams{
+		TargetEmail: other.Email,
+		Path:        path,
+		Parents:     []string{folder, folder + "/payments"},
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	sharedWith, err := testQueries.GetSharedWith(context.Background(), GetSharedWithParams{
+		Path:    path,
+		Parents: []string{folder, folder + "/payments"},
+	})
+	require.NoError(t, err)
+	require.Len(t, sharedWith, 1)
+	require.Equal(t, target.Email, sharedWith[0].TargetEmail)
+	require.False(t, sharedWith[0].IsPrefix)
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/pixperk/vaultify/internal/util"
@@ -29,6 +30,10 @@ func (store *Store) ExecTx(ctx context.Context, fn func(*Queries) error) error {
 	}
 
 	q := New(tx)
+	hooks := &commitHooks{}
+	afterCommit.Store(q, hooks)
+	defer afterCommit.Delete(q)
+
 	err = fn(q)
 	if err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
@@ -37,7 +42,44 @@ func (store *Store) ExecTx(ctx context.Context, fn func(*Queries) error) error {
 		return err
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	hooks.run()
+	return nil
+}
+
+// afterCommit maps the Queries of each open ExecTx transaction to the callbacks registered on it
+var afterCommit sync.Map
+
+type commitHooks struct {
+	mu  sync.Mutex
+	fns []func()
+}
+
+func (h *commitHooks) add(fn func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.fns = append(h.fns, fn)
+}
+
+func (h *commitHooks) run() {
+	h.mu.Lock()
+	fns := h.fns
+	h.mu.Unlock()
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+// AfterCommit runs fn once the ExecTx transaction q belongs to has committed. It is dropped
+// if the transaction rolls back. Outside a transaction fn runs right away.
+func (q *Queries) AfterCommit(fn func()) {
+	if hooks, ok := afterCommit.Load(q); ok {
+		hooks.(*commitHooks).add(fn)
+		return
+	}
+	fn()
 }
 
 func (s *Store) RotateHmacKey(ctx context.Context, staleDuration time.Duration) error {
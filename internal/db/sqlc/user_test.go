@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 
 	"github.com/pixperk/vaultify/internal/util"
@@ -60,3 +61,49 @@ func TestGetUserByID(t *testing.T) {
 	require.Equal(t, user1.Email, user2.Email)
 	require.Equal(t, user1.PasswordHash, user2.PasswordHash)
 }
+
+func TestSetUserRole(t *testing.T) {
+	user := createRandomUser(t)
+	require.Equal(t, "user", user.Role)
+
+	updated, err := testQueries.SetUserRole(context.Background(), SetUserRoleParams{Email: user.Email, Role: "auditor"})
+	require.NoError(t, err)
+	require.Equal(t, user.ID, updated.ID)
+	require.Equal(t, "auditor", updated.Role)
+
+	_, err = testQueries.SetUserRole(context.Background(), SetUserRoleParams{Email: user.Email, Role: "root"})
+	require.Error(t, err)
+
+	auditors, err := testQueries.ListUserEmailsWithRoles(context.Background(), []string{"auditor"})
+	require.NoError(t, err)
+	require.Contains(t, auditors, user.Email)
+}
+
+func TestPromoteBootstrapAdmin(t *testing.T) {
+	admin := createRandomUser(t)
+	_, err := testQueries.SetUserRole(context.Background(), SetUserRoleParams{Email: admin.Email, Role: "admin"})
+	require.NoError(t, err)
+
+	// once an admin exists nobody else is promoted
+	user := createRandomUser(t)
+	_, err = testQueries.PromoteBootstrapAdmin(context.Background(), user.Email)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	stats, err := testQueries.GetSystemStats(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, stats.Users, int64(2))
+
+	users, err := testQueries.ListUsers(context.Background(), ListUsersParams{Limit: int32(stats.Users), Offset: 0})
+	require.NoError(t, err)
+	require.NotEmpty(t, users)
+}
+
+func TestLockAdmins(t *testing.T) {
+	admin := createRandomUser(t)
+	_, err := testQueries.SetUserRole(context.Background(), SetUserRoleParams{Email: admin.Email, Role: "admin"})
+	require.NoError(t, err)
+
+	admins, err := testQueries.LockAdmins(context.Background())
+	require.NoError(t, err)
+	require.Contains(t, admins, admin.ID)
+}
@@ -9,12 +9,13 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (name, email, password_hash)
 VALUES ($1, $2, $3)
-RETURNING id, email, name, password_hash, created_at
+RETURNING id, email, name, password_hash, created_at, role
 `
 
 type CreateUserParams struct {
@@ -32,12 +33,49 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (Users,
 		&i.Name,
 		&i.PasswordHash,
 		&i.CreatedAt,
+		&i.Role,
+	)
+	return i, err
+}
+
+const getSystemStats = `-- name: GetSystemStats :one
+SELECT
+  (SELECT count(*) FROM users) AS users,
+  (SELECT count(*) FROM secrets) AS secrets,
+  (SELECT count(*) FROM secret_versions) AS secret_versions,
+  (SELECT count(*) FROM sharing_rules) AS sharing_rules,
+  (SELECT count(*) FROM groups) AS groups,
+  (SELECT count(*) FROM organizations) AS organizations,
+  (SELECT count(*) FROM audit_logs) AS audit_logs
+`
+
+type GetSystemStatsRow struct {
+	Users          int64 `json:"users"`
+	Secrets        int64 `json:"secrets"`
+	SecretVersions int64 `json:"secret_versions"`
+	SharingRules   int64 `json:"sharing_rules"`
+	Groups         int64 `json:"groups"`
+	Organizations  int64 `json:"organizations"`
+	AuditLogs      int64 `json:"audit_logs"`
+}
+
+func (q *Queries) GetSystemStats(ctx context.Context) (GetSystemStatsRow, error) {
+	row := q.db.QueryRowContext(ctx, getSystemStats)
+	var i GetSystemStatsRow
+	err := row.Scan(
+		&i.Users,
+		&i.Secrets,
+		&i.SecretVersions,
+		&i.SharingRules,
+		&i.Groups,
+		&i.Organizations,
+		&i.AuditLogs,
 	)
 	return i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, email, name, password_hash, created_at FROM users WHERE email = $1
+SELECT id, email, name, password_hash, created_at, role FROM users WHERE email = $1
 `
 
 func (q *Queries) GetUserByEmail(ctx context.Context, email string) (Users, error) {
@@ -49,12 +87,13 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (Users, erro
 		&i.Name,
 		&i.PasswordHash,
 		&i.CreatedAt,
+		&i.Role,
 	)
 	return i, err
 }
 
 const getUserByID = `-- name: GetUserByID :one
-SELECT id, email, name, password_hash, created_at FROM users WHERE id = $1
+SELECT id, email, name, password_hash, created_at, role FROM users WHERE id = $1
 `
 
 func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (Users, error) {
@@ -66,6 +105,157 @@ func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (Users, error)
 		&i.Name,
 		&i.PasswordHash,
 		&i.CreatedAt,
+		&i.Role,
+	)
+	return i, err
+}
+
+const listUserEmailsWithRoles = `-- name: ListUserEmailsWithRoles :many
+SELECT email FROM users
+WHERE role = ANY($1::TEXT[])
+ORDER BY email
+`
+
+func (q *Queries) ListUserEmailsWithRoles(ctx context.Context, roles []string) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listUserEmailsWithRoles, pq.Array(roles))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, err
+		}
+		items = append(items, email)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsers = `-- name: ListUsers :many
+SELECT id, email, name, password_hash, created_at, role FROM users
+ORDER BY email
+LIMIT $2 OFFSET $1
+`
+
+type ListUsersParams struct {
+	Offset int32 `json:"offset"`
+	Limit  int32 `json:"limit"`
+}
+
+func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]Users, error) {
+	rows, err := q.db.QueryContext(ctx, listUsers, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Users{}
+	for rows.Next() {
+		var i Users
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.Name,
+			&i.PasswordHash,
+			&i.CreatedAt,
+			&i.Role,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockAdmins = `-- name: LockAdmins :many
+SELECT id FROM users
+WHERE role = 'admin'
+ORDER BY id
+FOR UPDATE
+`
+
+// locks every admin so that concurrent demotions see each other
+func (q *Queries) LockAdmins(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, lockAdmins)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const promoteBootstrapAdmin = `-- name: PromoteBootstrapAdmin :one
+UPDATE users SET role = 'admin'
+WHERE lower(users.email) = lower($1)
+  AND (SELECT count(*) FROM users same WHERE lower(same.email) = lower($1)) = 1
+  AND NOT EXISTS (SELECT 1 FROM users admins WHERE admins.role = 'admin')
+RETURNING id, email, name, password_hash, created_at, role
+`
+
+// only while nobody is an admin, so the bootstrap email cannot take the role back later. Emails
+// are compared case-insensitively, and an email shared by several accounts promotes none.
+func (q *Queries) PromoteBootstrapAdmin(ctx context.Context, email string) (Users, error) {
+	row := q.db.QueryRowContext(ctx, promoteBootstrapAdmin, email)
+	var i Users
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Name,
+		&i.PasswordHash,
+		&i.CreatedAt,
+		&i.Role,
+	)
+	return i, err
+}
+
+const setUserRole = `-- name: SetUserRole :one
+UPDATE users SET role = $2
+WHERE email = $1
+RETURNING id, email, name, password_hash, created_at, role
+`
+
+type SetUserRoleParams struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+func (q *Queries) SetUserRole(ctx context.Context, arg SetUserRoleParams) (Users, error) {
+	row := q.db.QueryRowContext(ctx, setUserRole, arg.Email, arg.Role)
+	var i Users
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Name,
+		&i.PasswordHash,
+		&i.CreatedAt,
+		&i.Role,
 	)
 	return i, err
 }
@@ -0,0 +1,401 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: webhooks.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const claimDueWebhookDeliveries = `-- name: ClaimDueWebhookDeliveries :many
+UPDATE webhook_deliveries
+SET next_attempt_at = now() + make_interval(secs => $1::int)
+WHERE id IN (
+    SELECT id FROM webhook_deliveries
+    WHERE status = 'pending' AND next_attempt_at <= now()
+    ORDER BY next_attempt_at
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, subscription_id, event_type, payload, status, attempts, next_attempt_at, last_status_code, last_error, created_at, delivered_at
+`
+
+type ClaimDueWebhookDeliveriesParams struct {
+	LeaseSeconds int32 `json:"lease_seconds"`
+	BatchSize    int32 `json:"batch_size"`
+}
+
+// pushing next_attempt_at forward leases the deliveries to this instance while they are sent,
+// so the HTTP calls happen outside of any transaction
+func (q *Queries) ClaimDueWebhookDeliveries(ctx context.Context, arg ClaimDueWebhookDeliveriesParams) ([]WebhookDeliveries, error) {
+	rows, err := q.db.QueryContext(ctx, claimDueWebhookDeliveries, arg.LeaseSeconds, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WebhookDeliveries{}
+	for rows.Next() {
+		var i WebhookDeliveries
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubscriptionID,
+			&i.EventType,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.LastStatusCode,
+			&i.LastError,
+			&i.CreatedAt,
+			&i.DeliveredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createWebhookDelivery = `-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (subscription_id, event_type, payload)
+VALUES ($1, $2, $3)
+RETURNING id, subscription_id, event_type, payload, status, attempts, next_attempt_at, last_status_code, last_error, created_at, delivered_at
+`
+
+type CreateWebhookDeliveryParams struct {
+	SubscriptionID uuid.UUID       `json:"subscription_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+}
+
+func (q *Queries) CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) (WebhookDeliveries, error) {
+	row := q.db.QueryRowContext(ctx, createWebhookDelivery, arg.SubscriptionID, arg.EventType, arg.Payload)
+	var i WebhookDeliveries
+	err := row.Scan(
+		&i.ID,
+		&i.SubscriptionID,
+		&i.EventType,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.NextAttemptAt,
+		&i.LastStatusCode,
+		&i.LastError,
+		&i.CreatedAt,
+		&i.DeliveredAt,
+	)
+	return i, err
+}
+
+const createWebhookSubscription = `-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (user_id, url, path_prefix, event_types, secret_ciphertext, secret_nonce)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, user_id, url, path_prefix, event_types, secret_ciphertext, secret_nonce, created_at
+`
+
+type CreateWebhookSubscriptionParams struct {
+	UserID           uuid.UUID `json:"user_id"`
+	Url              string    `json:"url"`
+	PathPrefix       string    `json:"path_prefix"`
+	EventTypes       []string  `json:"event_types"`
+	SecretCiphertext []byte    `json:"secret_ciphertext"`
+	SecretNonce      []byte    `json:"secret_nonce"`
+}
+
+func (q *Queries) CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) (WebhookSubscriptions, error) {
+	row := q.db.QueryRowContext(ctx, createWebhookSubscription,
+		arg.UserID,
+		arg.Url,
+		arg.PathPrefix,
+		pq.Array(arg.EventTypes),
+		arg.SecretCiphertext,
+		arg.SecretNonce,
+	)
+	var i WebhookSubscriptions
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Url,
+		&i.PathPrefix,
+		pq.Array(&i.EventTypes),
+		&i.SecretCiphertext,
+		&i.SecretNonce,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteWebhookSubscription = `-- name: DeleteWebhookSubscription :execrows
+DELETE FROM webhook_subscriptions
+WHERE id = $1 AND user_id = $2
+`
+
+type DeleteWebhookSubscriptionParams struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) DeleteWebhookSubscription(ctx context.Context, arg DeleteWebhookSubscriptionParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteWebhookSubscription, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getWebhookDelivery = `-- name: GetWebhookDelivery :one
+SELECT id, subscription_id, event_type, payload, status, attempts, next_attempt_at, last_status_code, last_error, created_at, delivered_at FROM webhook_deliveries
+WHERE id = $1 AND subscription_id = $2
+`
+
+type GetWebhookDeliveryParams struct {
+	ID             uuid.UUID `json:"id"`
+	SubscriptionID uuid.UUID `json:"subscription_id"`
+}
+
+func (q *Queries) GetWebhookDelivery(ctx context.Context, arg GetWebhookDeliveryParams) (WebhookDeliveries, error) {
+	row := q.db.QueryRowContext(ctx, getWebhookDelivery, arg.ID, arg.SubscriptionID)
+	var i WebhookDeliveries
+	err := row.Scan(
+		&i.ID,
+		&i.SubscriptionID,
+		&i.EventType,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.NextAttemptAt,
+		&i.LastStatusCode,
+		&i.LastError,
+		&i.CreatedAt,
+		&i.DeliveredAt,
+	)
+	return i, err
+}
+
+const getWebhookSubscription = `-- name: GetWebhookSubscription :one
+SELECT id, user_id, url, path_prefix, event_types, secret_ciphertext, secret_nonce, created_at FROM webhook_subscriptions
+WHERE id = $1
+`
+
+func (q *Queries) GetWebhookSubscription(ctx context.Context, id uuid.UUID) (WebhookSubscriptions, error) {
+	row := q.db.QueryRowContext(ctx, getWebhookSubscription, id)
+	var i WebhookSubscriptions
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Url,
+		&i.PathPrefix,
+		pq.Array(&i.EventTypes),
+		&i.SecretCiphertext,
+		&i.SecretNonce,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listWebhookDeliveries = `-- name: ListWebhookDeliveries :many
+SELECT id, subscription_id, event_type, payload, status, attempts, next_attempt_at, last_status_code, last_error, created_at, delivered_at FROM webhook_deliveries
+WHERE subscription_id = $1
+ORDER BY created_at DESC
+LIMIT $2
+`
+
+type ListWebhookDeliveriesParams struct {
+	SubscriptionID uuid.UUID `json:"subscription_id"`
+	Limit          int32     `json:"limit"`
+}
+
+func (q *Queries) ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDeliveries, error) {
+	rows, err := q.db.QueryContext(ctx, listWebhookDeliveries, arg.SubscriptionID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WebhookDeliveries{}
+	for rows.Next() {
+		var i WebhookDeliveries
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubscriptionID,
+			&i.EventType,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.LastStatusCode,
+			&i.LastError,
+			&i.CreatedAt,
+			&i.DeliveredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhookSubscriptionsForEvent = `-- name: ListWebhookSubscriptionsForEvent :many
+SELECT w.id, w.user_id, w.url, w.path_prefix, w.event_types, w.secret_ciphertext, w.secret_nonce, w.created_at, u.email AS user_email
+FROM webhook_subscriptions w
+JOIN users u ON u.id = w.user_id
+WHERE left($1::text, length(w.path_prefix)) = w.path_prefix
+AND (cardinality(w.event_types) = 0 OR $2::text = ANY(w.event_types))
+`
+
+type ListWebhookSubscriptionsForEventParams struct {
+	Path      string `json:"path"`
+	EventType string `json:"event_type"`
+}
+
+type ListWebhookSubscriptionsForEventRow struct {
+	ID               uuid.UUID    `json:"id"`
+	UserID           uuid.UUID    `json:"user_id"`
+	Url              string       `json:"url"`
+	PathPrefix       string       `json:"path_prefix"`
+	EventTypes       []string     `json:"event_types"`
+	SecretCiphertext []byte       `json:"secret_ciphertext"`
+	SecretNonce      []byte       `json:"secret_nonce"`
+	CreatedAt        sql.NullTime `json:"created_at"`
+	UserEmail        string       `json:"user_email"`
+}
+
+// prefixes are compared literally; LIKE would treat _ in paths as a wildcard
+func (q *Queries) ListWebhookSubscriptionsForEvent(ctx context.Context, arg ListWebhookSubscriptionsForEventParams) ([]ListWebhookSubscriptionsForEventRow, error) {
+	rows, err := q.db.QueryContext(ctx, listWebhookSubscriptionsForEvent, arg.Path, arg.EventType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListWebhookSubscriptionsForEventRow{}
+	for rows.Next() {
+		var i ListWebhookSubscriptionsForEventRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Url,
+			&i.PathPrefix,
+			pq.Array(&i.EventTypes),
+			&i.SecretCiphertext,
+			&i.SecretNonce,
+			&i.CreatedAt,
+			&i.UserEmail,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhookSubscriptionsForUser = `-- name: ListWebhookSubscriptionsForUser :many
+SELECT id, user_id, url, path_prefix, event_types, secret_ciphertext, secret_nonce, created_at FROM webhook_subscriptions
+WHERE user_id = $1
+ORDER BY created_at
+`
+
+func (q *Queries) ListWebhookSubscriptionsForUser(ctx context.Context, userID uuid.UUID) ([]WebhookSubscriptions, error) {
+	rows, err := q.db.QueryContext(ctx, listWebhookSubscriptionsForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WebhookSubscriptions{}
+	for rows.Next() {
+		var i WebhookSubscriptions
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Url,
+			&i.PathPrefix,
+			pq.Array(&i.EventTypes),
+			&i.SecretCiphertext,
+			&i.SecretNonce,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markWebhookDeliveryFailed = `-- name: MarkWebhookDeliveryFailed :exec
+UPDATE webhook_deliveries
+SET status = CASE WHEN attempts + 1 >= $1::int THEN 'failed' ELSE 'pending' END,
+    attempts = attempts + 1,
+    last_status_code = $2,
+    last_error = $3,
+    next_attempt_at = $4
+WHERE id = $5
+`
+
+type MarkWebhookDeliveryFailedParams struct {
+	MaxAttempts    int32          `json:"max_attempts"`
+	LastStatusCode sql.NullInt32  `json:"last_status_code"`
+	LastError      sql.NullString `json:"last_error"`
+	NextAttemptAt  time.Time      `json:"next_attempt_at"`
+	ID             uuid.UUID      `json:"id"`
+}
+
+func (q *Queries) MarkWebhookDeliveryFailed(ctx context.Context, arg MarkWebhookDeliveryFailedParams) error {
+	_, err := q.db.ExecContext(ctx, markWebhookDeliveryFailed,
+		arg.MaxAttempts,
+		arg.LastStatusCode,
+		arg.LastError,
+		arg.NextAttemptAt,
+		arg.ID,
+	)
+	return err
+}
+
+const markWebhookDeliverySucceeded = `-- name: MarkWebhookDeliverySucceeded :exec
+UPDATE webhook_deliveries
+SET status = 'succeeded',
+    attempts = attempts + 1,
+    last_status_code = $2,
+    last_error = NULL,
+    delivered_at = now()
+WHERE id = $1
+`
+
+type MarkWebhookDeliverySucceededParams struct {
+	ID             uuid.UUID     `json:"id"`
+	LastStatusCode sql.NullInt32 `json:"last_status_code"`
+}
+
+func (q *Queries) MarkWebhookDeliverySucceeded(ctx context.Context, arg MarkWebhookDeliverySucceededParams) error {
+	_, err := q.db.ExecContext(ctx, markWebhookDeliverySucceeded, arg.ID, arg.LastStatusCode)
+	return err
+}
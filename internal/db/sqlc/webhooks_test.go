@@ -0,0 +1,113 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/pixperk/vaultify/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomWebhook(t *testing.T, prefix string, events []string) WebhookSubscriptions {
+	user := createRandomUser(t)
+
+	sub, err := testQueries.CreateWebhookSubscription(context.Background(), CreateWebhookSubscriptionParams{
+		UserID:           user.ID,
+		Url:              "https://example.com/hooks",
+		PathPrefix:       prefix,
+		EventTypes:       events,
+		SecretCiphertext: []byte("ciphertext"),
+		SecretNonce:      []byte("nonce"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, prefix, sub.PathPrefix)
+	require.Equal(t, events, sub.EventTypes)
+	return sub
+}
+
+func TestListWebhookSubscriptionsForEvent(t *testing.T) {
+	prefix := util.RandomString(8) + "_x/"
+	all := createRandomWebhook(t, prefix, []string{})
+	sharesOnly := createRandomWebhook(t, prefix, []string{"secret.shared"})
+
+	ids := func(subs []ListWebhookSubscriptionsForEventRow) []string {
+		var out []string
+		for _, s := range subs {
+			out = append(out, s.ID.String())
+		}
+		return out
+	}
+
+	subs, err := testQueries.ListWebhookSubscriptionsForEvent(context.Background(), ListWebhookSubscriptionsForEventParams{
+		Path:      prefix + "db/password",
+		EventType: "secret.updated",
+	})
+	require.NoError(t, err)
+	require.Contains(t, ids(subs), all.ID.String())
+	require.NotContains(t, ids(subs), sharesOnly.ID.String())
+
+	subs, err = testQueries.ListWebhookSubscriptionsForEvent(context.Background(), ListWebhookSubscriptionsForEventParams{
+		Path:      prefix + "db/password",
+		EventType: "secret.shared",
+	})
+	require.NoError(t, err)
+	require.Contains(t, ids(subs), sharesOnly.ID.String())
+
+	// the underscore in the prefix is not a wildcard
+	subs, err = testQueries.ListWebhookSubscriptionsForEvent(context.Background(), ListWebhookSubscriptionsForEventParams{
+		Path:      prefix[:8] + "Zx/db/password",
+		EventType: "secret.updated",
+	})
+	require.NoError(t, err)
+	require.NotContains(t, ids(subs), all.ID.String())
+}
+
+func TestWebhookDeliveryRetries(t *testing.T) {
+	sub := createRandomWebhook(t, util.RandomString(8)+"/", []string{})
+
+	delivery, err := testQueries.CreateWebhookDelivery(context.Background(), CreateWebhookDeliveryParams{
+		SubscriptionID: sub.ID,
+		EventType:      "secret.created",
+		Payload:        json.RawMessage(`{"event":"secret.created"}`),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "pending", delivery.Status)
+
+	claimed, err := testQueries.ClaimDueWebhookDeliveries(context.Background(), ClaimDueWebhookDeliveriesParams{
+		LeaseSeconds: 300,
+		BatchSize:    1000,
+	})
+	require.NoError(t, err)
+	var found bool
+	for _, d := range claimed {
+		if d.ID == delivery.ID {
+			found = true
+			require.True(t, d.NextAttemptAt.After(time.Now().Add(4*time.Minute)))
+		}
+	}
+	require.True(t, found)
+
+	// the last allowed attempt marks the delivery failed instead of rescheduling it
+	for i := 0; i < 2; i++ {
+		err = testQueries.MarkWebhookDeliveryFailed(context.Background(), MarkWebhookDeliveryFailedParams{
+			ID:             delivery.ID,
+			MaxAttempts:    2,
+			LastStatusCode: sql.NullInt32{Int32: 503, Valid: true},
+			LastError:      sql.NullString{String: "receiver returned 503", Valid: true},
+			NextAttemptAt:  time.Now().Add(time.Minute),
+		})
+		require.NoError(t, err)
+	}
+
+	failed, err := testQueries.GetWebhookDelivery(context.Background(), GetWebhookDeliveryParams{
+		ID:             delivery.ID,
+		SubscriptionID: sub.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "failed", failed.Status)
+	require.EqualValues(t, 2, failed.Attempts)
+	require.EqualValues(t, 503, failed.LastStatusCode.Int32)
+}
@@ -0,0 +1,118 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Type string
+
+const (
+	// VersionChanged is published when a secret gets a new version in an environment,
+	// by an update, rollback, promotion, rotation, copy or move
+	VersionChanged Type = "version_changed"
+	// Deleted is published when a secret disappears from a path, e.g. because it was moved away
+	Deleted Type = "deleted"
+	// Expired is published when the expiration worker deletes a secret whose TTL has passed
+	Expired Type = "expired"
+	// ShareChanged is published when a sharing rule on Path for Target is created or changed
+	ShareChanged Type = "share_changed"
+	// MembershipChanged is published when organization or team membership or roles change
+	MembershipChanged Type = "membership_changed"
+	// HMACKeyRotated is published when a new HMAC signing key becomes active
+	HMACKeyRotated Type = "hmac_key_rotated"
+	// Reset tells subscribers that events may have been lost, e.g. while the connection that
+	// carries them between instances was down, and that any derived state should be rebuilt.
+	// It is delivered to every subscriber regardless of its filter.
+	Reset Type = "reset"
+)
+
+// Event describes a change to the secret at Path. Environment is empty when the change
+// affects every environment, as deletes and expirations do.
+type Event struct {
+	Type        Type      `json:"type"`
+	Path        string    `json:"path"`
+	Environment string    `json:"environment,omitempty"`
+	Version     int32     `json:"version,omitempty"`
+	At          time.Time `json:"at"`
+
+	// ownership of the secret at the time of the event, so subscribers can check read
+	// access even after the secret is gone
+	OwnerID uuid.UUID     `json:"-"`
+	TeamID  uuid.NullUUID `json:"-"`
+	// Target is the user a ShareChanged event is about
+	Target string `json:"-"`
+}
+
+// Bus fans published events out to subscribers in-process
+type Bus struct {
+	mu   sync.Mutex
+	subs map[*Subscription]struct{}
+}
+
+func NewBus() *Bus {
+	return &Bus{subs: make(map[*Subscription]struct{})}
+}
+
+// Subscription receives the events accepted by its filter on C. C is closed when the
+// subscription is closed, or when the subscriber falls more than its buffer behind; a
+// subscriber that sees C closed without closing it should resubscribe and re-read state.
+type Subscription struct {
+	C <-chan Event
+
+	bus    *Bus
+	ch     chan Event
+	filter func(Event) bool
+}
+
+// Subscribe registers a subscriber. A nil filter accepts every event.
+func (b *Bus) Subscribe(buffer int, filter func(Event) bool) *Subscription {
+	ch := make(chan Event, buffer)
+	sub := &Subscription{C: ch, bus: b, ch: ch, filter: filter}
+
+	b.mu.Lock()
+	b.subs[sub] = struct{}{}
+	b.mu.Unlock()
+
+	return sub
+}
+
+// Close unregisters the subscription. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	s.bus.remove(s)
+}
+
+// remove must be called with b.mu held
+func (b *Bus) remove(sub *Subscription) {
+	if _, ok := b.subs[sub]; ok {
+		delete(b.subs, sub)
+		close(sub.ch)
+	}
+}
+
+// Publish delivers the events to every matching subscriber without blocking
+func (b *Bus) Publish(events ...Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, e := range events {
+		if e.At.IsZero() {
+			e.At = time.Now()
+		}
+		for sub := range b.subs {
+			if e.Type != Reset && sub.filter != nil && !sub.filter(e) {
+				continue
+			}
+			select {
+			case sub.ch <- e:
+			default:
+				// a slow subscriber must not hold up writers; drop it instead of losing events silently
+				b.remove(sub)
+			}
+		}
+	}
+}
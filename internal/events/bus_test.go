@@ -0,0 +1,101 @@
+package events_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pixperk/vaultify/internal/events"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBusFiltersEvents(t *testing.T) {
+	bus := events.NewBus()
+
+	all := bus.Subscribe(10, nil)
+	defer all.Close()
+	prod := bus.Subscribe(10, func(e events.Event) bool { return e.Environment == "prod" })
+	defer prod.Close()
+
+	bus.Publish(
+		events.Event{Type: events.VersionChanged, Path: "team/db", Environment: "dev", Version: 2},
+		events.Event{Type: events.VersionChanged, Path: "team/db", Environment: "prod", Version: 5},
+	)
+
+	first := <-all.C
+	require.Equal(t, "dev", first.Environment)
+	require.False(t, first.At.IsZero())
+	require.Equal(t, "prod", (<-all.C).Environment)
+
+	got := <-prod.C
+	require.Equal(t, int32(5), got.Version)
+	require.Empty(t, prod.C)
+}
+
+func TestBusDropsSlowSubscribers(t *testing.T) {
+	bus := events.NewBus()
+
+	slow := bus.Subscribe(1, nil)
+	bus.Publish(events.Event{Path: "a"}, events.Event{Path: "b"})
+
+	// the first event is still delivered, then the channel is closed
+	e, ok := <-slow.C
+	require.True(t, ok)
+	require.Equal(t, "a", e.Path)
+	_, ok = <-slow.C
+	require.False(t, ok)
+
+	// closing an already dropped subscription is a no-op
+	slow.Close()
+}
+
+func TestSubscriptionClose(t *testing.T) {
+	bus := events.NewBus()
+
+	sub := bus.Subscribe(1, nil)
+	sub.Close()
+	sub.Close()
+
+	_, ok := <-sub.C
+	require.False(t, ok)
+
+	// publishing after close does not panic
+	bus.Publish(events.Event{Path: "a"})
+}
+
+func TestResetBypassesFilters(t *testing.T) {
+	bus := events.NewBus()
+
+	sub := bus.Subscribe(10, func(e events.Event) bool { return e.Path == "team/db" })
+	defer sub.Close()
+
+	bus.Publish(events.Event{Type: events.VersionChanged, Path: "other"}, events.Event{Type: events.Reset})
+
+	got := <-sub.C
+	require.Equal(t, events.Reset, got.Type)
+	require.Empty(t, sub.C)
+}
+
+func TestEncodeDecode(t *testing.T) {
+	e := events.Event{
+		Type:        events.VersionChanged,
+		Path:        "org/acme/payments/stripe",
+		Environment: "prod",
+		Version:     7,
+		OwnerID:     uuid.New(),
+		TeamID:      uuid.NullUUID{UUID: uuid.New(), Valid: true},
+	}
+
+	payload, err := events.Encode(e)
+	require.NoError(t, err)
+
+	decoded, err := events.Decode(payload)
+	require.NoError(t, err)
+	require.False(t, decoded.At.IsZero())
+
+	// ownership is hidden from API clients but survives the trip between instances
+	decoded.At = e.At
+	require.Equal(t, e, decoded)
+
+	_, err = events.Decode("not json")
+	require.Error(t, err)
+}
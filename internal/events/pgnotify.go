@@ -0,0 +1,86 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Channel is the Postgres NOTIFY channel events travel on between server instances
+const Channel = "vaultify_events"
+
+// wireEvent is the NOTIFY payload. Unlike the public JSON form of Event it carries the
+// ownership fields, which every instance needs for its access checks.
+type wireEvent struct {
+	Type        Type          `json:"type"`
+	Path        string        `json:"path,omitempty"`
+	Environment string        `json:"environment,omitempty"`
+	Version     int32         `json:"version,omitempty"`
+	At          time.Time     `json:"at"`
+	OwnerID     uuid.UUID     `json:"owner_id"`
+	TeamID      uuid.NullUUID `json:"team_id"`
+	Target      string        `json:"target,omitempty"`
+}
+
+// Encode returns the NOTIFY payload for e
+func Encode(e Event) (string, error) {
+	if e.At.IsZero() {
+		e.At = time.Now()
+	}
+	data, err := json.Marshal(wireEvent(e))
+	return string(data), err
+}
+
+// Decode parses a NOTIFY payload produced by Encode
+func Decode(payload string) (Event, error) {
+	var w wireEvent
+	if err := json.Unmarshal([]byte(payload), &w); err != nil {
+		return Event{}, err
+	}
+	return Event(w), nil
+}
+
+// Listen LISTENs on Channel and publishes every notification to bus until ctx is done. Events
+// are sent with pg_notify inside the writing transaction, so they arrive only once it commits,
+// on every instance including the one that wrote. After the connection is re-established a
+// Reset event is published, since notifications sent while it was down are lost.
+func Listen(ctx context.Context, dsn string, bus *Bus) error {
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Event listener: %v\n", err)
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(Channel); err != nil {
+		return err
+	}
+
+	ping := time.NewTicker(90 * time.Second)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case n := <-listener.Notify:
+			if n == nil {
+				bus.Publish(Event{Type: Reset})
+				continue
+			}
+			e, err := Decode(n.Extra)
+			if err != nil {
+				log.Printf("Event listener: dropping malformed notification: %v\n", err)
+				continue
+			}
+			bus.Publish(e)
+		case <-ping.C:
+			// detects dead connections that would otherwise go unnoticed while idle
+			go listener.Ping()
+		}
+	}
+}
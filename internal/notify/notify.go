@@ -0,0 +1,42 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Message is a notification addressed to one or more users
+type Message struct {
+	Event      string     `json:"event"`
+	Recipients []string   `json:"recipients"`
+	Subject    string     `json:"subject"`
+	Body       string     `json:"body"`
+	Path       string     `json:"path,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+}
+
+// Notifier delivers messages to users, e.g. by email or to a chat webhook
+type Notifier interface {
+	Notify(ctx context.Context, msg Message) error
+}
+
+// Discard drops every message. It is used when no notifier is configured.
+type Discard struct{}
+
+func (Discard) Notify(ctx context.Context, msg Message) error {
+	return nil
+}
+
+// Multi delivers each message through every notifier and joins their errors
+type Multi []Notifier
+
+func (m Multi) Notify(ctx context.Context, msg Message) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.Notify(ctx, msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
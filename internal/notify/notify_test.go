@@ -0,0 +1,61 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pixperk/vaultify/internal/notify"
+	"github.com/stretchr/testify/require"
+)
+
+type failingNotifier struct{}
+
+func (failingNotifier) Notify(ctx context.Context, msg notify.Message) error {
+	return errors.New("unavailable")
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var got notify.Message
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+	}))
+	defer srv.Close()
+
+	msg := notify.Message{
+		Event:      "secret.expiring",
+		Recipients: []string{"alice@example.com"},
+		Subject:    "expiring",
+		Path:       "alice@example.com/db/password",
+	}
+	err := notify.WebhookNotifier{URL: srv.URL}.Notify(context.Background(), msg)
+	require.NoError(t, err)
+	require.Equal(t, msg, got)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+
+	err = notify.WebhookNotifier{URL: failing.URL}.Notify(context.Background(), msg)
+	require.Error(t, err)
+}
+
+func TestMultiNotifier(t *testing.T) {
+	first, second := &notify.Memory{}, &notify.Memory{}
+	msg := notify.Message{Event: "share.expiring", Recipients: []string{"bob@example.com"}}
+
+	// a failing notifier does not stop delivery through the others
+	err := notify.Multi{first, failingNotifier{}, second}.Notify(context.Background(), msg)
+	require.Error(t, err)
+	require.Equal(t, []notify.Message{msg}, first.Messages())
+	require.Equal(t, []notify.Message{msg}, second.Messages())
+
+	require.NoError(t, notify.Multi{}.Notify(context.Background(), msg))
+	require.NoError(t, notify.Discard{}.Notify(context.Background(), msg))
+}
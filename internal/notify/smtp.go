@@ -0,0 +1,62 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTPNotifier emails each message to its recipients. Username and Password are optional;
+// when set, PLAIN auth is used, which net/smtp only allows over TLS or to localhost.
+type SMTPNotifier struct {
+	Addr     string
+	From     string
+	Username string
+	Password string
+}
+
+func (n SMTPNotifier) Notify(ctx context.Context, msg Message) error {
+	if len(msg.Recipients) == 0 {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if n.Username != "" {
+		host, _, err := net.SplitHostPort(n.Addr)
+		if err != nil {
+			return fmt.Errorf("invalid SMTP address: %w", err)
+		}
+		auth = smtp.PlainAuth("", n.Username, n.Password, host)
+	}
+
+	if err := smtp.SendMail(n.Addr, auth, n.From, msg.Recipients, n.format(msg)); err != nil {
+		return fmt.Errorf("send mail: %w", err)
+	}
+	return nil
+}
+
+func (n SMTPNotifier) format(msg Message) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", headerValue(n.From))
+	fmt.Fprintf(&b, "To: %s\r\n", headerValue(strings.Join(msg.Recipients, ", ")))
+	fmt.Fprintf(&b, "Subject: %s\r\n", headerValue(msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return b.Bytes()
+}
+
+// headerValue strips line breaks so values cannot inject extra headers
+func headerValue(v string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(v)
+}
@@ -0,0 +1,237 @@
+package policy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// The HCL parser understands the subset policies need: string and string list attributes,
+// path "GLOB" { ... } blocks, and #, // and /* */ comments.
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of file"
+	case tokenString:
+		return strconv.Quote(t.text)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+func lexHCL(src string) ([]token, error) {
+	var tokens []token
+	line := 1
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == '#' || strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated comment", line)
+			}
+			line += strings.Count(src[i:i+2+end], "\n")
+			i += end + 4
+		case c == '"':
+			j := i + 1
+			for j < len(src) && src[j] != '"' {
+				if src[j] == '\\' {
+					j++
+				}
+				if j < len(src) && src[j] == '\n' {
+					return nil, fmt.Errorf("line %d: unterminated string", line)
+				}
+				j++
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("line %d: unterminated string", line)
+			}
+			text, err := strconv.Unquote(src[i : j+1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid string %s", line, src[i:j+1])
+			}
+			tokens = append(tokens, token{tokenString, text, line})
+			i = j + 1
+		case strings.ContainsRune("={}[],", rune(c)):
+			tokens = append(tokens, token{tokenPunct, string(c), line})
+			i++
+		case c == '_' || unicode.IsLetter(rune(c)):
+			j := i
+			for j < len(src) && (src[j] == '_' || src[j] == '-' || unicode.IsLetter(rune(src[j])) || unicode.IsDigit(rune(src[j]))) {
+				j++
+			}
+			tokens = append(tokens, token{tokenIdent, src[i:j], line})
+			i = j
+		default:
+			return nil, fmt.Errorf("line %d: unexpected character %q", line, c)
+		}
+	}
+	return append(tokens, token{tokenEOF, "", line}), nil
+}
+
+type hclParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *hclParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *hclParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *hclParser) expect(kind tokenKind, want string) (token, error) {
+	t := p.next()
+	if t.kind != kind {
+		return t, fmt.Errorf("line %d: expected %s, got %s", t.line, want, t)
+	}
+	return t, nil
+}
+
+func (p *hclParser) punct(text string) error {
+	t := p.next()
+	if t.kind != tokenPunct || t.text != text {
+		return fmt.Errorf("line %d: expected %q, got %s", t.line, text, t)
+	}
+	return nil
+}
+
+// skipPunct consumes the next token if it is text
+func (p *hclParser) skipPunct(text string) bool {
+	if t := p.peek(); t.kind == tokenPunct && t.text == text {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *hclParser) stringValue() (string, error) {
+	t, err := p.expect(tokenString, "a string")
+	return t.text, err
+}
+
+// stringList parses ["a", "b"], allowing a trailing comma
+func (p *hclParser) stringList() ([]string, error) {
+	if err := p.punct("["); err != nil {
+		return nil, err
+	}
+	list := []string{}
+	for !p.skipPunct("]") {
+		s, err := p.stringValue()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+		if !p.skipPunct(",") {
+			if err := p.punct("]"); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	return list, nil
+}
+
+func parseHCL(src string) (document, error) {
+	var doc document
+	tokens, err := lexHCL(src)
+	if err != nil {
+		return doc, err
+	}
+	p := &hclParser{tokens: tokens}
+
+	for p.peek().kind != tokenEOF {
+		key, err := p.expect(tokenIdent, "a name")
+		if err != nil {
+			return doc, err
+		}
+
+		if key.text == "path" {
+			rule, err := p.pathBlock()
+			if err != nil {
+				return doc, err
+			}
+			doc.Rules = append(doc.Rules, rule)
+			continue
+		}
+
+		if err := p.punct("="); err != nil {
+			return doc, err
+		}
+		switch key.text {
+		case "name":
+			doc.Name, err = p.stringValue()
+		case "description":
+			doc.Description, err = p.stringValue()
+		case "users":
+			doc.Users, err = p.stringList()
+		case "groups":
+			doc.Groups, err = p.stringList()
+		default:
+			return doc, fmt.Errorf("line %d: unknown attribute %q", key.line, key.text)
+		}
+		if err != nil {
+			return doc, err
+		}
+	}
+	return doc, nil
+}
+
+// pathBlock parses "GLOB" { capabilities = [...] } after the path keyword
+func (p *hclParser) pathBlock() (ruleDocument, error) {
+	var rule ruleDocument
+	var err error
+	if rule.Path, err = p.stringValue(); err != nil {
+		return rule, err
+	}
+	if err := p.punct("{"); err != nil {
+		return rule, err
+	}
+	for !p.skipPunct("}") {
+		key, err := p.expect(tokenIdent, "a name")
+		if err != nil {
+			return rule, err
+		}
+		if key.text != "capabilities" {
+			return rule, fmt.Errorf("line %d: unknown attribute %q in path block", key.line, key.text)
+		}
+		if err := p.punct("="); err != nil {
+			return rule, err
+		}
+		if rule.Capabilities, err = p.stringList(); err != nil {
+			return rule, err
+		}
+	}
+	return rule, nil
+}
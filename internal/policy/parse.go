@@ -0,0 +1,157 @@
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]*$`)
+
+// document is the shape shared by JSON and HCL policies:
+//
+//	{"name": "ci", "users": ["ci@example.com"], "groups": ["platform"],
+//	 "rules": [{"path": "org/acme/**", "capabilities": ["read", "list"]}]}
+//
+// or in HCL:
+//
+//	name   = "ci"
+//	users  = ["ci@example.com"]
+//	groups = ["platform"]
+//
+//	path "org/acme/**" {
+//	  capabilities = ["read", "list"]
+//	}
+type document struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Users       []string       `json:"users"`
+	Groups      []string       `json:"groups"`
+	Rules       []ruleDocument `json:"rules"`
+}
+
+type ruleDocument struct {
+	Path         string   `json:"path"`
+	Capabilities []string `json:"capabilities"`
+}
+
+// ParseJSON parses a JSON policy document
+func ParseJSON(data []byte) (*Policy, error) {
+	doc, err := decodeJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy: %w", err)
+	}
+	return doc.compile()
+}
+
+// ParseHCL parses an HCL policy document
+func ParseHCL(data []byte) (*Policy, error) {
+	doc, err := parseHCL(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy: %w", err)
+	}
+	return doc.compile()
+}
+
+// LoadDir parses every .json and .hcl file in dir. A policy without a name is named after its file.
+func LoadDir(dir string) ([]*Policy, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var policies []*Policy
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".json" && ext != ".hcl") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		var doc document
+		if ext == ".json" {
+			doc, err = decodeJSON(data)
+		} else {
+			doc, err = parseHCL(string(data))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid policy: %w", entry.Name(), err)
+		}
+		if doc.Name == "" {
+			doc.Name = strings.TrimSuffix(entry.Name(), ext)
+		}
+
+		p, err := doc.compile()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		policies = append(policies, p)
+	}
+	return policies, nil
+}
+
+func decodeJSON(data []byte) (document, error) {
+	var doc document
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&doc)
+	return doc, err
+}
+
+func (doc document) compile() (*Policy, error) {
+	if !namePattern.MatchString(doc.Name) {
+		return nil, fmt.Errorf("policy name %q must be lowercase letters, digits, '.', '_' and '-'", doc.Name)
+	}
+
+	p := &Policy{Name: doc.Name, Description: doc.Description}
+	for _, email := range doc.Users {
+		p.Users = append(p.Users, strings.ToLower(strings.TrimSpace(email)))
+	}
+	for _, group := range doc.Groups {
+		p.Groups = append(p.Groups, strings.TrimSpace(group))
+	}
+
+	for _, rd := range doc.Rules {
+		// secret paths are stored lowercased, so patterns are too
+		pattern := strings.ToLower(strings.Trim(strings.TrimSpace(rd.Path), "/"))
+		if pattern == "" {
+			return nil, fmt.Errorf("policy %s: a rule has no path", doc.Name)
+		}
+		if strings.ContainsFunc(pattern, unicode.IsSpace) || strings.Contains(pattern, "//") {
+			return nil, fmt.Errorf("policy %s: path %q can never match: secret paths have no whitespace or empty segments", doc.Name, rd.Path)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("policy %s: invalid path %q: %w", doc.Name, rd.Path, err)
+		}
+		if len(rd.Capabilities) == 0 {
+			return nil, fmt.Errorf("policy %s: path %q has no capabilities", doc.Name, rd.Path)
+		}
+
+		rule := Rule{Path: pattern}
+		for _, name := range rd.Capabilities {
+			if name == Deny {
+				if len(rd.Capabilities) > 1 {
+					return nil, fmt.Errorf("policy %s: path %q: deny cannot be combined with other capabilities", doc.Name, rd.Path)
+				}
+				rule.Deny = true
+				continue
+			}
+			c, err := ParseCapability(name)
+			if err != nil {
+				return nil, fmt.Errorf("policy %s: path %q: %w", doc.Name, rd.Path, err)
+			}
+			rule.Capabilities |= c
+		}
+		p.Rules = append(p.Rules, rule)
+	}
+	return p, nil
+}
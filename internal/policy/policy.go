@@ -0,0 +1,296 @@
+// Package policy evaluates declarative access policies. A policy is a named list of rules, each
+// granting capabilities on the secret paths matched by a glob, and is attached to users and
+// groups. Access is denied unless some rule grants it, and a matching deny rule always wins.
+package policy
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Capability is a single action on a secret path
+type Capability uint16
+
+const (
+	Read Capability = 1 << iota
+	Create
+	Update
+	Delete
+	List
+	Rollback
+	// Share also covers managing the secret itself: its shares, access list and rotation policy
+	Share
+	History
+)
+
+// All holds every capability
+const All = Read | Create | Update | Delete | List | Rollback | Share | History
+
+// Deny is the capability name that denies everything on the matched paths
+const Deny = "deny"
+
+var capabilityNames = []struct {
+	c    Capability
+	name string
+}{
+	{Read, "read"},
+	{Create, "create"},
+	{Update, "update"},
+	{Delete, "delete"},
+	{List, "list"},
+	{Rollback, "rollback"},
+	{Share, "share"},
+	{History, "history"},
+}
+
+// ParseCapability returns the capability called name
+func ParseCapability(name string) (Capability, error) {
+	for _, c := range capabilityNames {
+		if c.name == name {
+			return c.c, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown capability %q", name)
+}
+
+// Has reports whether c holds every capability of want
+func (c Capability) Has(want Capability) bool {
+	return c&want == want
+}
+
+// Names lists the capabilities in c in a fixed order
+func (c Capability) Names() []string {
+	names := []string{}
+	for _, n := range capabilityNames {
+		if c.Has(n.c) {
+			names = append(names, n.name)
+		}
+	}
+	return names
+}
+
+func (c Capability) String() string {
+	return strings.Join(c.Names(), ",")
+}
+
+// Rule grants capabilities on every path matching Path, or denies them all
+type Rule struct {
+	Path         string
+	Capabilities Capability
+	Deny         bool
+}
+
+// Policy is a named set of rules and the users and groups it applies to
+type Policy struct {
+	Name        string
+	Description string
+	// Users are emails, Groups are group slugs
+	Users  []string
+	Groups []string
+	Rules  []Rule
+}
+
+// Subject is who access is evaluated for
+type Subject struct {
+	Email  string
+	Groups []string
+}
+
+// appliesTo reports whether the policy is attached to the subject
+func (p *Policy) appliesTo(sub Subject) bool {
+	if slices.Contains(p.Users, sub.Email) {
+		return true
+	}
+	for _, group := range sub.Groups {
+		if slices.Contains(p.Groups, group) {
+			return true
+		}
+	}
+	return false
+}
+
+// Result is the outcome of evaluating policies on one path
+type Result struct {
+	// Granted holds what the matching rules allow, Denied is set when a deny rule matched
+	Granted Capability
+	Denied  bool
+	// Policies names the policies with a rule matching the path
+	Policies []string
+}
+
+// Allowed is what the result leaves of the capabilities in base plus those granted
+func (r Result) Allowed(base Capability) Capability {
+	if r.Denied {
+		return 0
+	}
+	return base | r.Granted
+}
+
+func (r *Result) add(p *Policy, secretPath string) {
+	matched := false
+	for _, rule := range p.Rules {
+		if !Match(rule.Path, secretPath) {
+			continue
+		}
+		matched = true
+		if rule.Deny {
+			r.Denied = true
+		} else {
+			r.Granted |= rule.Capabilities
+		}
+	}
+	if matched {
+		r.Policies = append(r.Policies, p.Name)
+	}
+}
+
+// Set is a collection of policies with unique names
+type Set struct {
+	policies []*Policy
+	byName   map[string]*Policy
+}
+
+// NewSet builds a set, rejecting policies that share a name
+func NewSet(policies ...*Policy) (*Set, error) {
+	s := &Set{byName: make(map[string]*Policy, len(policies))}
+	for _, p := range policies {
+		if _, dup := s.byName[p.Name]; dup {
+			return nil, fmt.Errorf("policy %q is defined twice", p.Name)
+		}
+		s.byName[p.Name] = p
+		s.policies = append(s.policies, p)
+	}
+	return s, nil
+}
+
+// Get returns the policy called name
+func (s *Set) Get(name string) (*Policy, bool) {
+	p, ok := s.byName[name]
+	return p, ok
+}
+
+// Names lists the policies in the order they were added
+func (s *Set) Names() []string {
+	names := make([]string, 0, len(s.policies))
+	for _, p := range s.policies {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// UsesGroups reports whether any policy is attached to a group, so callers only need to
+// look up a subject's groups when it can make a difference
+func (s *Set) UsesGroups() bool {
+	for _, p := range s.policies {
+		if len(p.Groups) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Evaluate combines the rules of every policy attached to sub that match secretPath
+func (s *Set) Evaluate(sub Subject, secretPath string) Result {
+	var r Result
+	for _, p := range s.policies {
+		if p.appliesTo(sub) {
+			r.add(p, secretPath)
+		}
+	}
+	return r
+}
+
+// EvaluateNamed combines the rules of the named policies that match secretPath, regardless of
+// whom they are attached to. Unknown names are skipped.
+func (s *Set) EvaluateNamed(names []string, secretPath string) Result {
+	var r Result
+	for _, name := range names {
+		if p, ok := s.byName[name]; ok {
+			r.add(p, secretPath)
+		}
+	}
+	return r
+}
+
+// Grantees returns the users and groups the policies granting want on secretPath are attached
+// to, e.g. to find who can approve access to it. A deny in another policy is not considered.
+func (s *Set) Grantees(secretPath string, want Capability) (users, groups []string) {
+	for _, p := range s.policies {
+		var r Result
+		r.add(p, secretPath)
+		if !r.Allowed(0).Has(want) {
+			continue
+		}
+		for _, email := range p.Users {
+			if !slices.Contains(users, email) {
+				users = append(users, email)
+			}
+		}
+		for _, group := range p.Groups {
+			if !slices.Contains(groups, group) {
+				groups = append(groups, group)
+			}
+		}
+	}
+	return users, groups
+}
+
+// Prefixes returns the fixed leading folders, ending in a slash, of the rules granting want in
+// the policies attached to sub. Every path those rules match, followed by a slash, starts with
+// one of them, so they narrow down where sub may hold want. A rule starting with a wildcard
+// yields "", which every path starts with.
+func (s *Set) Prefixes(sub Subject, want Capability) []string {
+	var prefixes []string
+	for _, p := range s.policies {
+		if !p.appliesTo(sub) {
+			continue
+		}
+		for _, rule := range p.Rules {
+			if rule.Deny || !rule.Capabilities.Has(want) {
+				continue
+			}
+			var prefix strings.Builder
+			for _, segment := range strings.Split(rule.Path, "/") {
+				if strings.ContainsAny(segment, `*?[\`) {
+					break
+				}
+				prefix.WriteString(segment + "/")
+			}
+			if !slices.Contains(prefixes, prefix.String()) {
+				prefixes = append(prefixes, prefix.String())
+			}
+		}
+	}
+	return prefixes
+}
+
+// Match reports whether secretPath matches the glob pattern. Patterns are compared segment by
+// segment with path.Match, so "*" stays within one segment, and a "**" segment matches any
+// number of segments, including none: "org/acme/**" matches everything under org/acme.
+func Match(pattern, secretPath string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(secretPath, "/"))
+}
+
+func matchSegments(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			rest := pattern[1:]
+			for i := 0; i <= len(segments); i++ {
+				if matchSegments(rest, segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+			return false
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+	return len(segments) == 0
+}
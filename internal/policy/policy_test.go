@@ -0,0 +1,157 @@
+package policy_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pixperk/vaultify/internal/policy"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	cases := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"org/acme/payments/db", "org/acme/payments/db", true},
+		{"org/acme/*/db", "org/acme/payments/db", true},
+		{"org/acme/*/db", "org/acme/payments/eu/db", false},
+		{"org/acme/**", "org/acme/payments/eu/db", true},
+		{"org/acme/**", "org/acme", true},
+		{"org/acme/**", "org/acmeco/db", false},
+		{"org/*/prod/**", "org/acme/prod/db/password", true},
+		{"org/**/password", "org/acme/prod/db/password", true},
+		{"org/**/password", "org/acme/prod/db/user", false},
+		{"*@example.com/db-*", "alice@example.com/db-main", true},
+		{"**", "anything/at/all", true},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.want, policy.Match(c.pattern, c.path), "%s on %s", c.pattern, c.path)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	readers, err := policy.ParseJSON([]byte(`{
+		"name": "readers",
+		"users": ["Alice@Example.com"],
+		"groups": ["platform"],
+		"rules": [
+			{"path": "org/acme/**", "capabilities": ["read", "list", "history"]},
+			{"path": "org/acme/prod/**", "capabilities": ["deny"]}
+		]
+	}`))
+	require.NoError(t, err)
+	writers, err := policy.ParseHCL([]byte(`
+		name  = "writers"
+		users = ["bob@example.com"]
+
+		# bob maintains the payments folder
+		path "org/acme/payments/*" {
+			capabilities = ["update", "rollback", "share"]
+		}
+	`))
+	require.NoError(t, err)
+	set, err := policy.NewSet(readers, writers)
+	require.NoError(t, err)
+	require.True(t, set.UsesGroups())
+
+	alice := policy.Subject{Email: "alice@example.com"}
+	r := set.Evaluate(alice, "org/acme/payments/db")
+	require.Equal(t, policy.Read|policy.List|policy.History, r.Allowed(0))
+	require.Equal(t, []string{"readers"}, r.Policies)
+
+	// a deny wins over every grant, including the caller's own
+	r = set.Evaluate(alice, "org/acme/prod/db")
+	require.True(t, r.Denied)
+	require.Zero(t, r.Allowed(policy.All))
+
+	// attached through a group
+	carol := policy.Subject{Email: "carol@example.com", Groups: []string{"platform"}}
+	require.True(t, set.Evaluate(carol, "org/acme/payments/db").Allowed(0).Has(policy.Read))
+	require.Zero(t, set.Evaluate(policy.Subject{Email: "dave@example.com"}, "org/acme/payments/db").Allowed(0))
+
+	bob := policy.Subject{Email: "bob@example.com"}
+	require.Equal(t, policy.Update|policy.Rollback|policy.Share, set.Evaluate(bob, "org/acme/payments/db").Allowed(0))
+	require.Zero(t, set.Evaluate(bob, "org/acme/payments/eu/db").Allowed(0))
+
+	users, groups := set.Grantees("org/acme/payments/db", policy.Share)
+	require.Equal(t, []string{"bob@example.com"}, users)
+	require.Empty(t, groups)
+	users, groups = set.Grantees("org/acme/payments/db", policy.Read)
+	require.Equal(t, []string{"alice@example.com"}, users)
+	require.Equal(t, []string{"platform"}, groups)
+	users, _ = set.Grantees("org/acme/prod/db", policy.Read)
+	require.Empty(t, users)
+
+	require.Equal(t, []string{"org/acme/payments/"}, set.Prefixes(bob, policy.Share))
+	require.Equal(t, []string{"org/acme/"}, set.Prefixes(carol, policy.Read))
+	require.Empty(t, set.Prefixes(alice, policy.Share))
+
+	// named evaluation ignores attachments
+	require.Equal(t, policy.Read|policy.List|policy.History, set.EvaluateNamed([]string{"readers", "missing"}, "org/acme/db").Allowed(0))
+}
+
+func TestParseRejects(t *testing.T) {
+	cases := []string{
+		`{"name": "Bad Name", "rules": []}`,
+		`{"name": "p", "rules": [{"path": "", "capabilities": ["read"]}]}`,
+		`{"name": "p", "rules": [{"path": "a/[", "capabilities": ["read"]}]}`,
+		`{"name": "p", "rules": [{"path": "a", "capabilities": []}]}`,
+		`{"name": "p", "rules": [{"path": "a", "capabilities": ["sudo"]}]}`,
+		`{"name": "p", "rules": [{"path": "a", "capabilities": ["deny", "read"]}]}`,
+		`{"name": "p", "rules": [{"path": "a b/c", "capabilities": ["read"]}]}`,
+		`{"name": "p", "rules": [{"path": "a//c", "capabilities": ["read"]}]}`,
+		`{"name": "p", "unknown": true}`,
+	}
+	for _, c := range cases {
+		_, err := policy.ParseJSON([]byte(c))
+		require.Error(t, err, c)
+	}
+
+	hcl := []string{
+		`name = "p"` + "\n" + `path "a" { capabilities = ["read" }`,
+		`name = "p"` + "\n" + `path "a" { verbs = ["read"] }`,
+		`owner = "p"`,
+		`name = "p`,
+		`/* open`,
+	}
+	for _, c := range hcl {
+		_, err := policy.ParseHCL([]byte(c))
+		require.Error(t, err, c)
+	}
+
+	_, err := policy.NewSet(&policy.Policy{Name: "p"}, &policy.Policy{Name: "p"})
+	require.Error(t, err)
+}
+
+func TestParseLowercasesPatterns(t *testing.T) {
+	p, err := policy.ParseJSON([]byte(`{"name": "p", "users": ["CI@Example.com"], "rules": [{"path": " /Org/Acme/** ", "capabilities": ["read"]}]}`))
+	require.NoError(t, err)
+	require.Equal(t, "org/acme/**", p.Rules[0].Path)
+
+	set, err := policy.NewSet(p)
+	require.NoError(t, err)
+	require.True(t, set.Evaluate(policy.Subject{Email: "ci@example.com"}, "org/acme/db").Allowed(0).Has(policy.Read))
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ci.hcl"), []byte(`
+		users = ["ci@example.com"] // named after the file
+		path "org/acme/**" { capabilities = ["read", "list",] }
+	`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ops.json"), []byte(`{"name": "operations", "rules": []}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("ignored"), 0o600))
+
+	policies, err := policy.LoadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, policies, 2)
+	require.Equal(t, "ci", policies[0].Name)
+	require.Equal(t, []policy.Rule{{Path: "org/acme/**", Capabilities: policy.Read | policy.List}}, policies[0].Rules)
+	require.Equal(t, "operations", policies[1].Name)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.json"), []byte(`{`), 0o600))
+	_, err = policy.LoadDir(dir)
+	require.ErrorContains(t, err, "broken.json")
+}
@@ -0,0 +1,151 @@
+package rotation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// maxHookOutput bounds how much of a hook's response is read
+const maxHookOutput = 64 << 10
+
+var ErrHookFailed = errors.New("rotation hook failed")
+
+// Request describes the secret being rotated. It never contains the current value.
+type Request struct {
+	Path           string `json:"path"`
+	Environment    string `json:"environment"`
+	CurrentVersion int32  `json:"current_version"`
+}
+
+// Hook obtains a new credential, typically by asking the system that owns it to issue one
+type Hook interface {
+	Rotate(ctx context.Context, req Request) (string, error)
+}
+
+// WebhookHook POSTs the request as JSON and expects {"value": "..."} back with a 2xx status
+type WebhookHook struct {
+	URL    string
+	Client *http.Client
+}
+
+type webhookResponse struct {
+	Value string `json:"value"`
+}
+
+func (h WebhookHook) Rotate(ctx context.Context, req Request) (string, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrHookFailed, err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	client := h.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrHookFailed, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxHookOutput))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrHookFailed, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", fmt.Errorf("%w: webhook returned %s", ErrHookFailed, resp.Status)
+	}
+
+	var out webhookResponse
+	if err := json.Unmarshal(data, &out); err != nil {
+		return "", fmt.Errorf("%w: invalid webhook response: %v", ErrHookFailed, err)
+	}
+	if out.Value == "" {
+		return "", fmt.Errorf("%w: webhook returned an empty value", ErrHookFailed)
+	}
+	return out.Value, nil
+}
+
+// ExecHook runs an executable from a fixed hooks directory. The secret being rotated is passed in
+// VAULTIFY_SECRET_PATH, VAULTIFY_ENVIRONMENT and VAULTIFY_CURRENT_VERSION; the new value is read
+// from stdout. A non-zero exit status fails the rotation.
+type ExecHook struct {
+	Dir  string
+	Name string
+}
+
+// ValidateHookName rejects names that could escape the hooks directory
+func ValidateHookName(name string) error {
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return fmt.Errorf("invalid hook name %q", name)
+	}
+	return nil
+}
+
+func (h ExecHook) Rotate(ctx context.Context, req Request) (string, error) {
+	if h.Dir == "" {
+		return "", fmt.Errorf("%w: executable hooks are disabled", ErrHookFailed)
+	}
+	if err := ValidateHookName(h.Name); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrHookFailed, err)
+	}
+
+	cmd := exec.CommandContext(ctx, filepath.Join(h.Dir, h.Name))
+	cmd.Env = []string{
+		"VAULTIFY_SECRET_PATH=" + req.Path,
+		"VAULTIFY_ENVIRONMENT=" + req.Environment,
+		"VAULTIFY_CURRENT_VERSION=" + strconv.Itoa(int(req.CurrentVersion)),
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &limitedWriter{w: &stdout, n: maxHookOutput}
+	cmd.Stderr = &limitedWriter{w: &stderr, n: maxHookOutput}
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if len(msg) > 200 {
+			msg = msg[:200]
+		}
+		return "", fmt.Errorf("%w: %v: %s", ErrHookFailed, err, msg)
+	}
+
+	value := strings.TrimRight(stdout.String(), "\r\n")
+	if value == "" {
+		return "", fmt.Errorf("%w: hook printed no value", ErrHookFailed)
+	}
+	return value, nil
+}
+
+// limitedWriter silently drops output beyond n bytes
+type limitedWriter struct {
+	w io.Writer
+	n int
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	written := len(p)
+	if len(p) > l.n {
+		p = p[:l.n]
+	}
+	l.n -= len(p)
+	if _, err := l.w.Write(p); err != nil {
+		return 0, err
+	}
+	return written, nil
+}
@@ -0,0 +1,84 @@
+package rotation
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+const (
+	lowercaseChars = "abcdefghijklmnopqrstuvwxyz"
+	uppercaseChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digitChars     = "0123456789"
+	symbolChars    = "!#$%&*+-=?@^_~"
+
+	MinPasswordLength = 12
+	MaxPasswordLength = 256
+)
+
+var ErrInvalidPasswordPolicy = errors.New("invalid password policy")
+
+// PasswordPolicy describes generated values. Lowercase letters, uppercase letters and digits are
+// always used; symbols are optional because many systems reject them.
+type PasswordPolicy struct {
+	Length  int
+	Symbols bool
+}
+
+func (p PasswordPolicy) Validate() error {
+	if p.Length < MinPasswordLength || p.Length > MaxPasswordLength {
+		return fmt.Errorf("%w: length must be between %d and %d", ErrInvalidPasswordPolicy, MinPasswordLength, MaxPasswordLength)
+	}
+	return nil
+}
+
+// GeneratePassword returns a random password with at least one character of every enabled class
+func GeneratePassword(policy PasswordPolicy) (string, error) {
+	if err := policy.Validate(); err != nil {
+		return "", err
+	}
+
+	classes := []string{lowercaseChars, uppercaseChars, digitChars}
+	if policy.Symbols {
+		classes = append(classes, symbolChars)
+	}
+
+	var all string
+	for _, class := range classes {
+		all += class
+	}
+
+	password := make([]byte, policy.Length)
+	for i := range password {
+		// the first characters cover every class, the rest draw from all of them
+		set := all
+		if i < len(classes) {
+			set = classes[i]
+		}
+		c, err := randomChar(set)
+		if err != nil {
+			return "", err
+		}
+		password[i] = c
+	}
+
+	// shuffle so the guaranteed characters are not always in front
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j.Int64()] = password[j.Int64()], password[i]
+	}
+
+	return string(password), nil
+}
+
+func randomChar(set string) (byte, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(len(set))))
+	if err != nil {
+		return 0, err
+	}
+	return set[n.Int64()], nil
+}
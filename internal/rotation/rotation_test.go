@@ -0,0 +1,113 @@
+package rotation_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode"
+
+	"github.com/pixperk/vaultify/internal/rotation"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchedule(t *testing.T) {
+	start := time.Date(2025, 1, 1, 10, 30, 0, 0, time.UTC)
+
+	interval, err := rotation.ParseSchedule(24*time.Hour, "")
+	require.NoError(t, err)
+	require.Equal(t, start.Add(24*time.Hour), interval.Next(start))
+
+	cron, err := rotation.ParseSchedule(0, "0 3 * * *")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2025, 1, 2, 3, 0, 0, 0, time.UTC), cron.Next(start))
+
+	every, err := rotation.ParseSchedule(0, "@every 2h")
+	require.NoError(t, err)
+	require.Equal(t, start.Add(2*time.Hour), every.Next(start))
+
+	_, err = rotation.ParseSchedule(0, "* * * * *")
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		interval time.Duration
+		cron     string
+	}{
+		{0, ""},
+		{time.Hour, "0 3 * * *"},
+		{time.Second, ""},
+		{0, "not a cron"},
+		{0, "@every 1s"},
+		{0, "@every 59s"},
+	} {
+		_, err := rotation.ParseSchedule(tc.interval, tc.cron)
+		require.ErrorIs(t, err, rotation.ErrInvalidSchedule)
+	}
+}
+
+func TestGeneratePassword(t *testing.T) {
+	password, err := rotation.GeneratePassword(rotation.PasswordPolicy{Length: 24})
+	require.NoError(t, err)
+	require.Len(t, password, 24)
+	require.True(t, strings.IndexFunc(password, unicode.IsLower) >= 0)
+	require.True(t, strings.IndexFunc(password, unicode.IsUpper) >= 0)
+	require.True(t, strings.IndexFunc(password, unicode.IsDigit) >= 0)
+	require.False(t, strings.ContainsAny(password, "!#$%&*+-=?@^_~"))
+
+	withSymbols, err := rotation.GeneratePassword(rotation.PasswordPolicy{Length: 24, Symbols: true})
+	require.NoError(t, err)
+	require.True(t, strings.ContainsAny(withSymbols, "!#$%&*+-=?@^_~"))
+	require.NotEqual(t, password, withSymbols)
+
+	_, err = rotation.GeneratePassword(rotation.PasswordPolicy{Length: 4})
+	require.ErrorIs(t, err, rotation.ErrInvalidPasswordPolicy)
+}
+
+func TestWebhookHook(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req rotation.Request
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if req.Path == "broken" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"value": "new-" + req.Path})
+	}))
+	defer srv.Close()
+
+	hook := rotation.WebhookHook{URL: srv.URL}
+
+	value, err := hook.Rotate(context.Background(), rotation.Request{Path: "api/token", Environment: "prod", CurrentVersion: 3})
+	require.NoError(t, err)
+	require.Equal(t, "new-api/token", value)
+
+	_, err = hook.Rotate(context.Background(), rotation.Request{Path: "broken"})
+	require.ErrorIs(t, err, rotation.ErrHookFailed)
+}
+
+func TestExecHook(t *testing.T) {
+	dir := t.TempDir()
+	script := "#!/bin/sh\nif [ \"$VAULTIFY_ENVIRONMENT\" = fail ]; then echo boom >&2; exit 1; fi\necho \"$VAULTIFY_SECRET_PATH-$VAULTIFY_CURRENT_VERSION\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "issue"), []byte(script), 0o755))
+
+	hook := rotation.ExecHook{Dir: dir, Name: "issue"}
+
+	value, err := hook.Rotate(context.Background(), rotation.Request{Path: "db/password", Environment: "prod", CurrentVersion: 2})
+	require.NoError(t, err)
+	require.Equal(t, "db/password-2", value)
+
+	_, err = hook.Rotate(context.Background(), rotation.Request{Path: "db/password", Environment: "fail"})
+	require.ErrorIs(t, err, rotation.ErrHookFailed)
+	require.Contains(t, err.Error(), "boom")
+
+	_, err = rotation.ExecHook{Dir: dir, Name: "../issue"}.Rotate(context.Background(), rotation.Request{})
+	require.ErrorIs(t, err, rotation.ErrHookFailed)
+
+	_, err = rotation.ExecHook{Name: "issue"}.Rotate(context.Background(), rotation.Request{})
+	require.ErrorIs(t, err, rotation.ErrHookFailed)
+}
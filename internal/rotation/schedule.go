@@ -0,0 +1,75 @@
+// Package rotation contains the building blocks of scheduled secret rotation: schedules,
+// password generation and hooks that obtain a new credential from an external system.
+package rotation
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// MinInterval is the shortest interval a rotation policy may use
+const MinInterval = time.Minute
+
+// cronChecks is how many upcoming runs of a cron schedule are checked against MinInterval
+const cronChecks = 10
+
+var ErrInvalidSchedule = errors.New("invalid rotation schedule")
+
+// Schedule returns the next rotation time after a given time
+type Schedule interface {
+	Next(time.Time) time.Time
+}
+
+type intervalSchedule time.Duration
+
+func (i intervalSchedule) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(i))
+}
+
+// ParseSchedule builds a schedule from either a fixed interval or a standard five field cron
+// expression (e.g. "0 3 * * 1"). Exactly one of them must be set. Descriptors such as
+// "@every 1s" are accepted as long as their runs are at least MinInterval apart.
+func ParseSchedule(interval time.Duration, cronExpr string) (Schedule, error) {
+	switch {
+	case interval > 0 && cronExpr != "":
+		return nil, fmt.Errorf("%w: set either an interval or a cron expression, not both", ErrInvalidSchedule)
+	case interval > 0:
+		if interval < MinInterval {
+			return nil, fmt.Errorf("%w: interval must be at least %s", ErrInvalidSchedule, MinInterval)
+		}
+		return intervalSchedule(interval), nil
+	case cronExpr != "":
+		schedule, err := cron.ParseStandard(cronExpr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+		}
+		if err := checkCronInterval(schedule); err != nil {
+			return nil, err
+		}
+		return schedule, nil
+	default:
+		return nil, fmt.Errorf("%w: an interval or a cron expression is required", ErrInvalidSchedule)
+	}
+}
+
+// checkCronInterval rejects a cron schedule whose next few runs come less than MinInterval apart
+func checkCronInterval(schedule cron.Schedule) error {
+	t := schedule.Next(time.Now())
+	if t.IsZero() {
+		return nil
+	}
+	for range cronChecks {
+		next := schedule.Next(t)
+		if next.IsZero() {
+			return nil
+		}
+		if next.Sub(t) < MinInterval {
+			return fmt.Errorf("%w: runs must be at least %s apart", ErrInvalidSchedule, MinInterval)
+		}
+		t = next
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+// Package secretpath defines the canonical form of secret paths. Every path that reaches the
+// database goes through Normalize so that lookups, permission checks and shares always compare
+// the same string.
+package secretpath
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// MaxLength is the maximum length of a normalized path
+	MaxLength = 512
+	// MaxSegments is the maximum number of slash-separated segments
+	MaxSegments = 32
+	// MaxSegmentLength is the maximum length of a single segment
+	MaxSegmentLength = 128
+)
+
+var ErrInvalidPath = errors.New("invalid secret path")
+
+// Normalize returns the canonical form of raw:
+//   - surrounding whitespace and leading/trailing slashes are removed
+//   - runs of whitespace become a single "-"
+//   - letters are lowercased
+//
+// It rejects empty paths and segments (double slashes), "." and ".." segments, paths over the
+// length and segment limits and any character outside [a-z0-9._@+-]. Non-ASCII input is always
+// rejected, which rules out look-alike characters such as a Cyrillic "а" posing as a Latin "a".
+func Normalize(raw string) (string, error) {
+	path := strings.Trim(strings.TrimSpace(raw), "/")
+	if path == "" {
+		return "", fmt.Errorf("%w: path is empty", ErrInvalidPath)
+	}
+
+	path = strings.Join(strings.Fields(path), "-")
+	path = strings.ToLower(path)
+
+	if len(path) > MaxLength {
+		return "", fmt.Errorf("%w: path is longer than %d characters", ErrInvalidPath, MaxLength)
+	}
+
+	segments := strings.Split(path, "/")
+	if len(segments) > MaxSegments {
+		return "", fmt.Errorf("%w: path has more than %d segments", ErrInvalidPath, MaxSegments)
+	}
+
+	for _, segment := range segments {
+		if err := validateSegment(segment); err != nil {
+			return "", err
+		}
+	}
+
+	return path, nil
+}
+
+// NormalizePrefix normalizes a path prefix used for listing. A trailing slash is kept so that
+// "team/db/" matches "team/db/x" but not "team/dbx".
+func NormalizePrefix(raw string) (string, error) {
+	trimmed := strings.TrimSpace(raw)
+	prefix, err := Normalize(trimmed)
+	if err != nil {
+		return "", err
+	}
+	if strings.HasSuffix(trimmed, "/") {
+		prefix += "/"
+	}
+	return prefix, nil
+}
+
+// Join normalizes the concatenation of the given parts, ignoring slashes around each part
+func Join(parts ...string) (string, error) {
+	trimmed := make([]string, len(parts))
+	for i, part := range parts {
+		trimmed[i] = strings.Trim(strings.TrimSpace(part), "/")
+	}
+	return Normalize(strings.Join(trimmed, "/"))
+}
+
+// Parents returns the folders above a normalized path, outermost first: a/b/c has a and a/b
+func Parents(path string) []string {
+	var parents []string
+	for i := 0; i < len(path); i++ {
+		if path[i] == '/' {
+			parents = append(parents, path[:i])
+		}
+	}
+	return parents
+}
+
+func validateSegment(segment string) error {
+	switch segment {
+	case "":
+		return fmt.Errorf("%w: empty segment (double slash)", ErrInvalidPath)
+	case ".", "..":
+		return fmt.Errorf("%w: %q segments are not allowed", ErrInvalidPath, segment)
+	}
+
+	if len(segment) > MaxSegmentLength {
+		return fmt.Errorf("%w: segment %q is longer than %d characters", ErrInvalidPath, segment, MaxSegmentLength)
+	}
+
+	for _, r := range segment {
+		if r >= utf8.RuneSelf {
+			return fmt.Errorf("%w: non-ASCII character %q", ErrInvalidPath, r)
+		}
+		if !isAllowed(r) {
+			if unicode.IsControl(r) {
+				return fmt.Errorf("%w: control character %U", ErrInvalidPath, r)
+			}
+			return fmt.Errorf("%w: character %q is not allowed", ErrInvalidPath, r)
+		}
+	}
+	return nil
+}
+
+func isAllowed(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+		return true
+	case r == '.', r == '_', r == '-', r == '@', r == '+':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package secretpath_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pixperk/vaultify/internal/secretpath"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	cases := map[string]string{
+		"alice@example.com/db":          "alice@example.com/db",
+		"/alice@example.com/db/":        "alice@example.com/db",
+		"Alice@Example.com/DB/Password": "alice@example.com/db/password",
+		"team/my  api key":              "team/my-api-key",
+		"  team/key  ":                  "team/key",
+		"team/v1.2_prod+eu":             "team/v1.2_prod+eu",
+	}
+
+	for raw, want := range cases {
+		got, err := secretpath.Normalize(raw)
+		require.NoError(t, err, raw)
+		require.Equal(t, want, got, raw)
+	}
+}
+
+func TestNormalizeRejects(t *testing.T) {
+	cases := []string{
+		"",
+		"/",
+		"team//key",
+		"team/../other/key",
+		"team/./key",
+		"..",
+		"team/key?version=1",
+		"team/key#1",
+		"team\\key",
+		"team/k\x00ey",
+		"team/pаssword", // Cyrillic "а"
+		"team/ｋey",      // fullwidth "k"
+		strings.Repeat("a", secretpath.MaxSegmentLength+1),
+		strings.Repeat("a/", secretpath.MaxSegments) + "a",
+		strings.Repeat(strings.Repeat("a", 100)+"/", 6),
+	}
+
+	for _, raw := range cases {
+		_, err := secretpath.Normalize(raw)
+		require.ErrorIs(t, err, secretpath.ErrInvalidPath, "%q", raw)
+	}
+}
+
+func TestNormalizeIsIdempotent(t *testing.T) {
+	once, err := secretpath.Normalize("/Team/My Key/")
+	require.NoError(t, err)
+
+	twice, err := secretpath.Normalize(once)
+	require.NoError(t, err)
+	require.Equal(t, once, twice)
+}
+
+func TestNormalizePrefix(t *testing.T) {
+	prefix, err := secretpath.NormalizePrefix("Team/DB/")
+	require.NoError(t, err)
+	require.Equal(t, "team/db/", prefix)
+
+	prefix, err = secretpath.NormalizePrefix("team/db")
+	require.NoError(t, err)
+	require.Equal(t, "team/db", prefix)
+
+	_, err = secretpath.NormalizePrefix("team//db/")
+	require.ErrorIs(t, err, secretpath.ErrInvalidPath)
+}
+
+func TestJoin(t *testing.T) {
+	path, err := secretpath.Join("Alice@Example.com", "/db password")
+	require.NoError(t, err)
+	require.Equal(t, "alice@example.com/db-password", path)
+
+	_, err = secretpath.Join("alice@example.com", "../bob@example.com/db")
+	require.ErrorIs(t, err, secretpath.ErrInvalidPath)
+}
+
+func TestParents(t *testing.T) {
+	require.Equal(t, []string{"org", "org/acme", "org/acme/payments"}, secretpath.Parents("org/acme/payments/db"))
+	require.Empty(t, secretpath.Parents("db"))
+}
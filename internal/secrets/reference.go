@@ -0,0 +1,135 @@
+package secrets
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pixperk/vaultify/internal/secretpath"
+)
+
+var (
+	ErrReferenceCycle    = errors.New("secret reference cycle detected")
+	ErrReferenceDepth    = errors.New("secret reference depth limit exceeded")
+	ErrReferenceKey      = errors.New("secret reference key not found")
+	ErrReferenceNotAJSON = errors.New("referenced secret is not a JSON object")
+)
+
+// referencePattern matches {{ secret "path" }} and {{ secret "path" "key" }}
+var referencePattern = regexp.MustCompile(`\{\{\s*secret\s+"([^"]+)"(?:\s+"([^"]*)")?\s*\}\}`)
+
+// ReferenceLookup returns the plaintext value stored at path
+type ReferenceLookup func(path string) (string, error)
+
+type ReferenceResolver struct {
+	lookup   ReferenceLookup
+	maxDepth int
+}
+
+func NewReferenceResolver(lookup ReferenceLookup, maxDepth int) *ReferenceResolver {
+	return &ReferenceResolver{
+		lookup:   lookup,
+		maxDepth: maxDepth,
+	}
+}
+
+// HasReferences reports whether value contains at least one secret reference
+func HasReferences(value string) bool {
+	return referencePattern.MatchString(value)
+}
+
+// Resolve replaces every reference in value (read from path) with the value of the referenced secret.
+// References are resolved recursively; a path appearing twice in the same chain is a cycle.
+func (r *ReferenceResolver) Resolve(path, value string) (string, error) {
+	cache := make(map[string]string)
+	if normalized, err := secretpath.Normalize(path); err == nil {
+		path = normalized
+	}
+	return r.resolve(value, []string{path}, cache)
+}
+
+func (r *ReferenceResolver) resolve(value string, chain []string, cache map[string]string) (string, error) {
+	var resolveErr error
+
+	resolved := referencePattern.ReplaceAllStringFunc(value, func(match string) string {
+		if resolveErr != nil {
+			return match
+		}
+
+		groups := referencePattern.FindStringSubmatch(match)
+		refPath, err := secretpath.Normalize(groups[1])
+		if err != nil {
+			resolveErr = err
+			return match
+		}
+		key := groups[2]
+
+		for _, p := range chain {
+			if p == refPath {
+				resolveErr = fmt.Errorf("%w: %s -> %s", ErrReferenceCycle, strings.Join(chain, " -> "), refPath)
+				return match
+			}
+		}
+		if len(chain) > r.maxDepth {
+			resolveErr = fmt.Errorf("%w (max %d)", ErrReferenceDepth, r.maxDepth)
+			return match
+		}
+
+		refValue, ok := cache[refPath]
+		if !ok {
+			raw, err := r.lookup(refPath)
+			if err != nil {
+				resolveErr = err
+				return match
+			}
+
+			refValue, err = r.resolve(raw, append(chain[:len(chain):len(chain)], refPath), cache)
+			if err != nil {
+				resolveErr = err
+				return match
+			}
+			cache[refPath] = refValue
+		}
+
+		if key == "" {
+			return refValue
+		}
+
+		field, err := extractKey(refValue, key)
+		if err != nil {
+			resolveErr = fmt.Errorf("%s: %w", refPath, err)
+			return match
+		}
+		return field
+	})
+
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+	return resolved, nil
+}
+
+// extractKey reads a top level field from a secret holding a JSON object
+func extractKey(value, key string) (string, error) {
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return "", ErrReferenceNotAJSON
+	}
+
+	field, ok := fields[key]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrReferenceKey, key)
+	}
+
+	if str, ok := field.(string); ok {
+		return str, nil
+	}
+
+	encoded, err := json.Marshal(field)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
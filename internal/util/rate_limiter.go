@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/pixperk/vaultify/internal/auth"
 	"github.com/redis/go-redis/v9"
 )
@@ -38,29 +39,37 @@ func NewRateLimiter(redisAddr string, maxTokens int, refillRate float64) *RateLi
 	}
 }
 
+// Allow takes a token from the user's bucket and reports whether the request may go ahead
+func (rl *RateLimiter) Allow(userID uuid.UUID) (bool, error) {
+	key := fmt.Sprintf("rate_limit:%s", userID)
+	now := float64(time.Now().Unix())
+
+	// Call the Lua script to manage the token bucket
+	result, err := rl.client.Eval(ctx, rl.script, []string{key},
+		rl.maxTokens,
+		rl.refillRate,
+		now,
+	).Result()
+	if err != nil {
+		return false, err
+	}
+
+	// Lua returns -1 if rate limit exceeded.
+	tokensLeft := result.(int64)
+	return tokensLeft >= 0, nil
+}
+
 // Middleware
 func (rl *RateLimiter) Middleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authorizationPayload := c.MustGet("authorization_payload").(*auth.Payload)
-		key := fmt.Sprintf("rate_limit:%s", authorizationPayload.UserID)
-		now := float64(time.Now().Unix())
-
-		// Call the Lua script to manage the token bucket
-		result, err := rl.client.Eval(ctx, rl.script, []string{key},
-			rl.maxTokens,
-			rl.refillRate,
-			now,
-		).Result()
-
+		allowed, err := rl.Allow(authorizationPayload.UserID)
 		if err != nil {
 			fmt.Println("Redis rate limiter error:", err)
 			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
 			return
 		}
-
-		// Lua returns -1 if rate limit exceeded.
-		tokensLeft := result.(int64)
-		if tokensLeft < 0 {
+		if !allowed {
 			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
 			return
 		}
@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// MaxAttempts is how often a delivery is tried before it is marked failed
+	MaxAttempts = 8
+
+	baseBackoff = 30 * time.Second
+	maxBackoff  = 6 * time.Hour
+)
+
+// Backoff returns the delay before the next try after the given number of failed attempts:
+// 30s, 1m, 2m, ... capped at 6h
+func Backoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	d := baseBackoff << min(attempts-1, 20)
+	return min(d, maxBackoff)
+}
+
+// Sender POSTs signed deliveries
+type Sender struct {
+	Client *http.Client
+}
+
+// Send delivers body to url and returns the response status. Any non-2xx status is an error.
+func (s Sender) Send(ctx context.Context, url, secret, deliveryID, event string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Vaultify-Webhook/1.0")
+	req.Header.Set(EventHeader, event)
+	req.Header.Set(DeliveryHeader, deliveryID)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(secret, timestamp, body))
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver returned %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,124 @@
+package webhook
+
+import (
+	"fmt"
+	"time"
+)
+
+// event types delivered to subscribers
+const (
+	SecretCreated        = "secret.created"
+	SecretUpdated        = "secret.updated"
+	SecretRolledBack     = "secret.rolled_back"
+	SecretPromoted       = "secret.promoted"
+	SecretRotated        = "secret.rotated"
+	SecretRotationFailed = "secret.rotation_failed"
+	SecretMoved          = "secret.moved"
+	SecretCopied         = "secret.copied"
+	SecretShared         = "secret.shared"
+	SecretExpired        = "secret.expired"
+	ShareExpired         = "share.expired"
+	ShareUpdated         = "share.updated"
+	ShareRevoked         = "share.revoked"
+	HMACFailure          = "secret.hmac_failure"
+	AccessRequested      = "access.requested"
+	AccessApproved       = "access.approved"
+	AccessDenied         = "access.denied"
+	QuorumRequested      = "quorum.requested"
+	QuorumApproved       = "quorum.approved"
+	BreakGlassUsed       = "break_glass.used"
+)
+
+// EventTypes lists every event type a subscription can ask for
+var EventTypes = []string{
+	SecretCreated, SecretUpdated, SecretRolledBack, SecretPromoted, SecretRotated, SecretRotationFailed,
+	SecretMoved, SecretCopied, SecretShared, SecretExpired, ShareExpired, ShareUpdated, ShareRevoked,
+	HMACFailure, AccessRequested, AccessApproved, AccessDenied, QuorumRequested, QuorumApproved,
+	BreakGlassUsed,
+}
+
+// hmacFailureReason is the audit reason recorded when a stored signature does not verify
+const hmacFailureReason = "invalid HMAC signature"
+
+// EventForAudit maps an audit entry to the webhook event it represents, if any. Reads are not
+// delivered; failed actions only are when they point at tampering or a failed rotation.
+func EventForAudit(action string, success bool, reason string) (string, bool) {
+	if !success {
+		switch {
+		case reason == hmacFailureReason:
+			return HMACFailure, true
+		case action == "rotate_secret":
+			return SecretRotationFailed, true
+		}
+		return "", false
+	}
+
+	switch action {
+	case "create_secret":
+		return SecretCreated, true
+	case "update_secret":
+		return SecretUpdated, true
+	case "rollback_secret":
+		return SecretRolledBack, true
+	case "promote_secret":
+		return SecretPromoted, true
+	case "rotate_secret":
+		return SecretRotated, true
+	case "move_secret":
+		return SecretMoved, true
+	case "copy_secret":
+		return SecretCopied, true
+	case "share_secret":
+		return SecretShared, true
+	case "expire_secret":
+		return SecretExpired, true
+	case "expire_share":
+		return ShareExpired, true
+	case "update_share":
+		return ShareUpdated, true
+	case "revoke_share":
+		return ShareRevoked, true
+	case "request_access":
+		return AccessRequested, true
+	case "approve_access_request":
+		return AccessApproved, true
+	case "deny_access_request":
+		return AccessDenied, true
+	case "request_quorum_read":
+		return QuorumRequested, true
+	case "approve_quorum_read":
+		return QuorumApproved, true
+	case "break_glass":
+		return BreakGlassUsed, true
+	}
+	return "", false
+}
+
+// ValidateEventTypes rejects unknown event types
+func ValidateEventTypes(types []string) error {
+	for _, t := range types {
+		known := false
+		for _, e := range EventTypes {
+			if t == e {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown event type %q", t)
+		}
+	}
+	return nil
+}
+
+// Payload is the JSON body of a delivery. It never contains secret values. ID identifies the
+// event and stays the same when a delivery is retried or replayed, so receivers can deduplicate.
+type Payload struct {
+	ID         string    `json:"id"`
+	Event      string    `json:"event"`
+	Path       string    `json:"path"`
+	Version    int32     `json:"version,omitempty"`
+	Actor      string    `json:"actor"`
+	Detail     string    `json:"detail,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
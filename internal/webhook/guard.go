@@ -0,0 +1,135 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress is returned for receivers on loopback, private, link-local and other
+// internal addresses that are not on the allowlist
+var ErrForbiddenAddress = errors.New("receivers may not be on internal addresses")
+
+// internalNetworks are internal ranges not covered by the netip.Addr predicates
+var internalNetworks = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+}
+
+// Guard decides which addresses outbound requests to user-supplied URLs may reach. Internal
+// addresses are refused unless they are in one of the allowed networks.
+type Guard struct {
+	allowed []netip.Prefix
+}
+
+// NewGuard parses a comma separated list of networks or single addresses, e.g.
+// 10.0.5.0/24,192.168.1.10, that requests may reach even though they are internal
+func NewGuard(allowlist string) (*Guard, error) {
+	g := &Guard{}
+	for _, item := range strings.Split(allowlist, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(item)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(item)
+			if addrErr != nil {
+				return nil, fmt.Errorf("invalid network %q", item)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		g.allowed = append(g.allowed, prefix.Masked())
+	}
+	return g, nil
+}
+
+// Allowed reports whether requests may go to ip
+func (g *Guard) Allowed(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	for _, prefix := range g.allowed {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, prefix := range internalNetworks {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckURL checks that rawURL is an absolute http or https URL whose host only resolves to
+// allowed addresses. Clients check again on every connection, since DNS can change in between.
+func (g *Guard) CheckURL(ctx context.Context, rawURL string) error {
+	target, err := url.Parse(rawURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return errors.New("url must be an absolute http or https URL")
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", target.Hostname())
+	if err != nil {
+		return fmt.Errorf("cannot resolve %s", target.Hostname())
+	}
+	for _, addr := range addrs {
+		if !g.Allowed(addr) {
+			return ErrForbiddenAddress
+		}
+	}
+	return nil
+}
+
+// Client returns an HTTP client that refuses to connect to addresses the guard does not allow.
+// The check runs on the resolved address of each connection. Redirects are not followed, and
+// proxies from the environment are not used, since either would bypass the check.
+func (g *Guard) Client() *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   g.control,
+	}
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func (g *Guard) control(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !g.Allowed(addr) {
+		return ErrForbiddenAddress
+	}
+	return nil
+}
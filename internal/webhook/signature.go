@@ -0,0 +1,41 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+)
+
+// headers sent with every delivery
+const (
+	SignatureHeader = "X-Vaultify-Signature"
+	TimestampHeader = "X-Vaultify-Timestamp"
+	EventHeader     = "X-Vaultify-Event"
+	DeliveryHeader  = "X-Vaultify-Delivery"
+)
+
+// NewSecret returns a random signing secret for a subscription
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// Sign returns the signature header value for a delivery: sha256=<hex HMAC-SHA256 of
+// "<timestamp>.<body>">. Covering the timestamp lets receivers reject old, replayed requests.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature header value in constant time
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
@@ -0,0 +1,150 @@
+package webhook_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pixperk/vaultify/internal/webhook"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSenderSignsDeliveries(t *testing.T) {
+	secret, err := webhook.NewSecret()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(secret, "whsec_"))
+
+	body := []byte(`{"event":"secret.updated","path":"alice@example.com/db"}`)
+
+	var verified bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		timestamp, err := strconv.ParseInt(r.Header.Get(webhook.TimestampHeader), 10, 64)
+		require.NoError(t, err)
+
+		require.Equal(t, webhook.SecretUpdated, r.Header.Get(webhook.EventHeader))
+		require.Equal(t, "delivery-1", r.Header.Get(webhook.DeliveryHeader))
+		verified = webhook.Verify(secret, timestamp, got, r.Header.Get(webhook.SignatureHeader))
+		require.False(t, webhook.Verify("whsec_other", timestamp, got, r.Header.Get(webhook.SignatureHeader)))
+	}))
+	defer srv.Close()
+
+	status, err := webhook.Sender{}.Send(context.Background(), srv.URL, secret, "delivery-1", webhook.SecretUpdated, body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, status)
+	require.True(t, verified)
+}
+
+func TestSenderReportsFailures(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	status, err := webhook.Sender{}.Send(context.Background(), srv.URL, "whsec_x", "d", webhook.SecretCreated, []byte(`{}`))
+	require.Error(t, err)
+	require.Equal(t, http.StatusServiceUnavailable, status)
+}
+
+func TestGuardRefusesInternalAddresses(t *testing.T) {
+	guard, err := webhook.NewGuard("")
+	require.NoError(t, err)
+
+	for _, addr := range []string{"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "100.64.0.1", "0.0.0.0", "::1", "fe80::1", "fd00::1", "::ffff:127.0.0.1"} {
+		require.False(t, guard.Allowed(netip.MustParseAddr(addr)), addr)
+	}
+	require.True(t, guard.Allowed(netip.MustParseAddr("93.184.216.34")))
+
+	require.ErrorIs(t, guard.CheckURL(context.Background(), "http://169.254.169.254/latest/meta-data"), webhook.ErrForbiddenAddress)
+	require.Error(t, guard.CheckURL(context.Background(), "ftp://example.com"))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	_, err = webhook.Sender{Client: guard.Client()}.Send(context.Background(), srv.URL, "whsec_x", "d", webhook.SecretCreated, []byte(`{}`))
+	require.ErrorIs(t, err, webhook.ErrForbiddenAddress)
+}
+
+func TestGuardAllowlist(t *testing.T) {
+	_, err := webhook.NewGuard("10.0.0.0/33")
+	require.Error(t, err)
+
+	guard, err := webhook.NewGuard("127.0.0.1, 10.0.5.0/24")
+	require.NoError(t, err)
+	require.True(t, guard.Allowed(netip.MustParseAddr("10.0.5.7")))
+	require.False(t, guard.Allowed(netip.MustParseAddr("10.0.6.7")))
+
+	redirected := false
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirected = true
+	}))
+	defer target.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusFound)
+	}))
+	defer srv.Close()
+
+	// redirects are not followed, so they cannot lead somewhere the check did not see
+	require.NoError(t, guard.CheckURL(context.Background(), srv.URL))
+	status, err := webhook.Sender{Client: guard.Client()}.Send(context.Background(), srv.URL, "whsec_x", "d", webhook.SecretCreated, []byte(`{}`))
+	require.Error(t, err)
+	require.Equal(t, http.StatusFound, status)
+	require.False(t, redirected)
+}
+
+func TestBackoff(t *testing.T) {
+	require.Equal(t, 30*time.Second, webhook.Backoff(1))
+	require.Equal(t, time.Minute, webhook.Backoff(2))
+	require.Equal(t, 4*time.Minute, webhook.Backoff(4))
+	require.Equal(t, 6*time.Hour, webhook.Backoff(50))
+}
+
+func TestEventForAudit(t *testing.T) {
+	event, ok := webhook.EventForAudit("update_secret", true, "")
+	require.True(t, ok)
+	require.Equal(t, webhook.SecretUpdated, event)
+
+	event, ok = webhook.EventForAudit("read_secret", false, "invalid HMAC signature")
+	require.True(t, ok)
+	require.Equal(t, webhook.HMACFailure, event)
+
+	event, ok = webhook.EventForAudit("rotate_secret", false, "scheduled: hook failed")
+	require.True(t, ok)
+	require.Equal(t, webhook.SecretRotationFailed, event)
+
+	event, ok = webhook.EventForAudit("revoke_share", true, "read access for bob@example.com revoked")
+	require.True(t, ok)
+	require.Equal(t, webhook.ShareRevoked, event)
+
+	event, ok = webhook.EventForAudit("approve_access_request", true, "read for bob@example.com until 2026-01-02T15:04:05Z")
+	require.True(t, ok)
+	require.Equal(t, webhook.AccessApproved, event)
+
+	event, ok = webhook.EventForAudit("request_quorum_read", true, "quorum request 1 needs 2 approvals within 1h0m0s")
+	require.True(t, ok)
+	require.Equal(t, webhook.QuorumRequested, event)
+
+	_, ok = webhook.EventForAudit("use_quorum_grant", true, "quorum request 1")
+	require.False(t, ok)
+
+	event, ok = webhook.EventForAudit("break_glass", true, "session 1 until 2026-01-02T15:04:05Z: database down")
+	require.True(t, ok)
+	require.Equal(t, webhook.BreakGlassUsed, event)
+
+	_, ok = webhook.EventForAudit("read_secret", true, "")
+	require.False(t, ok)
+	_, ok = webhook.EventForAudit("update_secret", false, "something else")
+	require.False(t, ok)
+
+	require.NoError(t, webhook.ValidateEventTypes([]string{webhook.SecretShared, webhook.ShareExpired}))
+	require.Error(t, webhook.ValidateEventTypes([]string{"secret.read"}))
+}
@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// AccessRequest asks the approvers of a path for a time-limited share
+type AccessRequest struct {
+	ID             string     `json:"id"`
+	RequesterEmail string     `json:"requester_email"`
+	Path           string     `json:"path"`
+	Permission     string     `json:"permission"`
+	Justification  string     `json:"justification"`
+	DurationSecs   int64      `json:"duration_secs"`
+	Status         string     `json:"status"`
+	DecidedBy      string     `json:"decided_by,omitempty"`
+	DecisionReason string     `json:"decision_reason,omitempty"`
+	DecidedAt      *time.Time `json:"decided_at,omitempty"`
+	SharedUntil    *time.Time `json:"shared_until,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// RequestAccessRequest is the input of RequestAccess
+type RequestAccessRequest struct {
+	// Path is a secret, or a folder ending in /*
+	Path          string `json:"path"`
+	Permission    string `json:"permission"`
+	Justification string `json:"justification"`
+	// Duration is how long the share should last once approved, in whole seconds
+	Duration time.Duration `json:"-"`
+}
+
+// RequestAccess asks for read or write access to a path the caller cannot reach
+func (c *Client) RequestAccess(ctx context.Context, req RequestAccessRequest) (*AccessRequest, error) {
+	body := struct {
+		RequestAccessRequest
+		DurationSecs int64 `json:"duration_secs"`
+	}{req, int64(req.Duration / time.Second)}
+	var request AccessRequest
+	if err := c.do(ctx, http.MethodPost, "/access-requests", nil, body, &request); err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// AccessRequests returns the caller's own requests, newest first, only those with status
+// when it is set
+func (c *Client) AccessRequests(ctx context.Context, status string) ([]AccessRequest, error) {
+	query := url.Values{}
+	if status != "" {
+		query.Set("status", status)
+	}
+	return c.accessRequests(ctx, "/access-requests", query)
+}
+
+// PendingAccessRequests returns the pending requests of other users that the caller can decide
+func (c *Client) PendingAccessRequests(ctx context.Context) ([]AccessRequest, error) {
+	return c.accessRequests(ctx, "/access-requests/pending", nil)
+}
+
+func (c *Client) accessRequests(ctx context.Context, path string, query url.Values) ([]AccessRequest, error) {
+	var resp struct {
+		Requests []AccessRequest `json:"requests"`
+	}
+	if err := c.do(ctx, http.MethodGet, path, query, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Requests, nil
+}
+
+// ApproveAccessRequest shares the requested path with the requester. A non-zero duration
+// shortens the requested one.
+func (c *Client) ApproveAccessRequest(ctx context.Context, id, reason string, duration time.Duration) (*AccessRequest, error) {
+	body := struct {
+		Reason       string `json:"reason,omitempty"`
+		DurationSecs int64  `json:"duration_secs,omitempty"`
+	}{reason, int64(duration / time.Second)}
+	return c.decideAccessRequest(ctx, id, "approve", body)
+}
+
+// DenyAccessRequest rejects a request, with a reason shown to the requester
+func (c *Client) DenyAccessRequest(ctx context.Context, id, reason string) (*AccessRequest, error) {
+	return c.decideAccessRequest(ctx, id, "deny", map[string]string{"reason": reason})
+}
+
+func (c *Client) decideAccessRequest(ctx context.Context, id, decision string, body any) (*AccessRequest, error) {
+	var request AccessRequest
+	if err := c.do(ctx, http.MethodPost, "/access-requests/"+url.PathEscape(id)+"/"+decision, nil, body, &request); err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// CancelAccessRequest withdraws one of the caller's pending requests
+func (c *Client) CancelAccessRequest(ctx context.Context, id string) (*AccessRequest, error) {
+	var request AccessRequest
+	if err := c.do(ctx, http.MethodDelete, "/access-requests/"+url.PathEscape(id), nil, nil, &request); err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
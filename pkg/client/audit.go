@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// AuditQuery filters the caller's audit log. Zero values do not filter.
+type AuditQuery struct {
+	Action  string
+	Path    string
+	Version int32
+	Success *bool
+	// Flagged keeps only entries flagged (true) or not flagged (false) for break-glass review
+	Flagged *bool
+	From    time.Time
+	To      time.Time
+	// Limit defaults to 50 on the server
+	Limit  int
+	Offset int
+}
+
+// AuditLog is an audit log entry
+type AuditLog struct {
+	ID              string    `json:"id"`
+	UserEmail       string    `json:"user_email"`
+	Action          string    `json:"action"`
+	ResourcePath    string    `json:"resource_path"`
+	ResourceVersion int32     `json:"resource_version"`
+	Success         bool      `json:"success"`
+	Reason          *string   `json:"reason,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	// BreakGlassSessionID is set on entries written during break-glass access
+	BreakGlassSessionID string `json:"break_glass_session_id,omitempty"`
+}
+
+// AuditLogs returns the caller's audit log entries, newest first
+func (c *Client) AuditLogs(ctx context.Context, q AuditQuery) ([]AuditLog, error) {
+	return c.auditLogs(ctx, "/audit", q.values())
+}
+
+func (q AuditQuery) values() url.Values {
+	query := url.Values{}
+	if q.Action != "" {
+		query.Set("action", q.Action)
+	}
+	if q.Path != "" {
+		query.Set("path", q.Path)
+	}
+	if q.Version > 0 {
+		query.Set("version", strconv.Itoa(int(q.Version)))
+	}
+	if q.Success != nil {
+		query.Set("success", strconv.FormatBool(*q.Success))
+	}
+	if q.Flagged != nil {
+		query.Set("flagged", strconv.FormatBool(*q.Flagged))
+	}
+	if !q.From.IsZero() {
+		query.Set("from", q.From.Format(time.RFC3339))
+	}
+	if !q.To.IsZero() {
+		query.Set("to", q.To.Format(time.RFC3339))
+	}
+	if q.Limit > 0 {
+		query.Set("limit", strconv.Itoa(q.Limit))
+	}
+	if q.Offset > 0 {
+		query.Set("offset", strconv.Itoa(q.Offset))
+	}
+	return query
+}
+
+func (c *Client) auditLogs(ctx context.Context, path string, query url.Values) ([]AuditLog, error) {
+	var resp struct {
+		Logs []AuditLog `json:"logs"`
+	}
+	if err := c.do(ctx, http.MethodGet, path, query, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Logs, nil
+}
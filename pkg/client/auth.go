@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// User is a Vaultify account
+type User struct {
+	Email string `json:"email"`
+	Name  string `json:"name"`
+	// Role is the system role: "user", "auditor" or "admin"
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LoginResponse carries the access token issued by Login
+type LoginResponse struct {
+	AccessToken string `json:"access_token"`
+	User        User   `json:"user"`
+}
+
+// SignUp registers a new user
+func (c *Client) SignUp(ctx context.Context, name, email, password string) (*User, error) {
+	req := map[string]string{"name": name, "email": email, "password": password}
+	var user User
+	if err := c.do(ctx, http.MethodPost, "/sign-up", nil, req, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Login exchanges credentials for an access token, which authenticates all later requests.
+// Naming policies limits the token to what they grant. The credentials are kept so the token
+// can be renewed once it expires.
+func (c *Client) Login(ctx context.Context, email, password string, policies ...string) (*LoginResponse, error) {
+	req := map[string]any{"email": email, "password": password}
+	if len(policies) > 0 {
+		req["policies"] = policies
+	}
+	var resp LoginResponse
+	if err := c.do(ctx, http.MethodPost, "/login", nil, req, &resp); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.token = resp.AccessToken
+	c.email = email
+	c.password = password
+	c.policies = policies
+	c.mu.Unlock()
+	return &resp, nil
+}
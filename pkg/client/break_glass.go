@@ -0,0 +1,137 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// BreakGlassRule designates a secret or folder that an emergency group may open during incidents
+type BreakGlassRule struct {
+	// Path is a secret, or a folder ending in /*
+	Path            string    `json:"path"`
+	Group           string    `json:"group"`
+	MaxDurationSecs int64     `json:"max_duration_secs"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// BreakGlassSession is one use of break-glass access
+type BreakGlassSession struct {
+	ID          string     `json:"id"`
+	UserEmail   string     `json:"user_email"`
+	Path        string     `json:"path"`
+	Reason      string     `json:"reason"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	ReviewedBy  string     `json:"reviewed_by,omitempty"`
+	ReviewNotes string     `json:"review_notes,omitempty"`
+	ReviewedAt  *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	// AuditLogs are the entries flagged with the session, returned by BreakGlassSession only
+	AuditLogs []AuditLog `json:"audit_logs,omitempty"`
+}
+
+// SetBreakGlassRuleRequest is the input of SetBreakGlassRule
+type SetBreakGlassRuleRequest struct {
+	// Path is a secret, or a folder ending in /*
+	Path  string
+	Group string
+	// MaxDuration caps each use; zero means the server default
+	MaxDuration time.Duration
+}
+
+// BreakGlassRequest is the input of BreakGlass
+type BreakGlassRequest struct {
+	Path   string
+	Reason string
+	// Duration shortens the access below the rule's maximum when set
+	Duration time.Duration
+}
+
+// SetBreakGlassRule lets members of an emergency group open a secret or folder the caller can share
+func (c *Client) SetBreakGlassRule(ctx context.Context, req SetBreakGlassRuleRequest) (*BreakGlassRule, error) {
+	body := struct {
+		Group           string `json:"group"`
+		MaxDurationSecs int64  `json:"max_duration_secs,omitempty"`
+	}{req.Group, int64(req.MaxDuration / time.Second)}
+	var rule BreakGlassRule
+	if err := c.do(ctx, http.MethodPut, "/break-glass/rules/"+secretPath(req.Path), nil, body, &rule); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// RemoveBreakGlassRule stops the emergency group from opening a secret or folder
+func (c *Client) RemoveBreakGlassRule(ctx context.Context, path string) error {
+	return c.do(ctx, http.MethodDelete, "/break-glass/rules/"+secretPath(path), nil, nil, nil)
+}
+
+// BreakGlassRules returns the rules the caller set up or can use
+func (c *Client) BreakGlassRules(ctx context.Context) ([]BreakGlassRule, error) {
+	var resp struct {
+		Rules []BreakGlassRule `json:"rules"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/break-glass/rules", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Rules, nil
+}
+
+// BreakGlass grants the caller short-lived read access to a secret on a break-glass path.
+// The owner and security are notified, and the session is flagged for review.
+func (c *Client) BreakGlass(ctx context.Context, req BreakGlassRequest) (*BreakGlassSession, error) {
+	body := struct {
+		Path         string `json:"path"`
+		Reason       string `json:"reason"`
+		DurationSecs int64  `json:"duration_secs,omitempty"`
+	}{req.Path, req.Reason, int64(req.Duration / time.Second)}
+	var session BreakGlassSession
+	if err := c.do(ctx, http.MethodPost, "/break-glass", nil, body, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// BreakGlassSessions returns the caller's own break-glass sessions, newest first
+func (c *Client) BreakGlassSessions(ctx context.Context) ([]BreakGlassSession, error) {
+	return c.breakGlassSessions(ctx, "/break-glass/sessions", nil)
+}
+
+// AllBreakGlassSessions returns every break-glass session, newest first, only reviewed or
+// unreviewed ones when reviewed is set. Admins and auditors only.
+func (c *Client) AllBreakGlassSessions(ctx context.Context, reviewed *bool) ([]BreakGlassSession, error) {
+	query := url.Values{}
+	if reviewed != nil {
+		query.Set("reviewed", strconv.FormatBool(*reviewed))
+	}
+	return c.breakGlassSessions(ctx, "/sys/break-glass", query)
+}
+
+func (c *Client) breakGlassSessions(ctx context.Context, path string, query url.Values) ([]BreakGlassSession, error) {
+	var resp struct {
+		Sessions []BreakGlassSession `json:"sessions"`
+	}
+	if err := c.do(ctx, http.MethodGet, path, query, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Sessions, nil
+}
+
+// BreakGlassSession returns a session with its flagged audit entries. Admins and auditors only.
+func (c *Client) BreakGlassSession(ctx context.Context, id string) (*BreakGlassSession, error) {
+	var session BreakGlassSession
+	if err := c.do(ctx, http.MethodGet, "/sys/break-glass/"+url.PathEscape(id), nil, nil, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ReviewBreakGlassSession signs off the review of another user's session. Admins and auditors only.
+func (c *Client) ReviewBreakGlassSession(ctx context.Context, id, notes string) (*BreakGlassSession, error) {
+	var session BreakGlassSession
+	if err := c.do(ctx, http.MethodPost, "/sys/break-glass/"+url.PathEscape(id)+"/review", nil, map[string]string{"notes": notes}, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
@@ -0,0 +1,282 @@
+// Package client is a Go client for the Vaultify REST API.
+//
+//	c, err := client.New("http://localhost:9090")
+//	if _, err = c.Login(ctx, email, password); err != nil { ... }
+//	secret, err := c.GetSecret(ctx, client.GetSecretRequest{Path: "db/password"})
+//
+// Requests rejected by the rate limiter are retried with backoff, and an expired access
+// token is renewed once per request with the credentials of the last Login.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	apiPrefix = "/api/v1"
+
+	defaultMaxRetries = 3
+	defaultRetryWait  = 500 * time.Millisecond
+	maxRetryWait      = 10 * time.Second
+)
+
+// Client calls the Vaultify API. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	maxRetries int
+	retryWait  time.Duration
+
+	mu       sync.Mutex
+	token    string
+	email    string
+	password string
+	policies []string
+
+	// renewMu makes concurrent requests that find the token expired share one login
+	renewMu sync.Mutex
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests, e.g. to configure timeouts or TLS
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithToken starts the client with an access token obtained elsewhere
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithCredentials lets the client log in on its own whenever the access token is missing or expired
+func WithCredentials(email, password string) Option {
+	return func(c *Client) {
+		c.email = email
+		c.password = password
+	}
+}
+
+// WithRetries sets how often a rate limited request is retried and the initial wait between
+// attempts, which doubles on every retry unless the server sends Retry-After. Zero disables retries.
+func WithRetries(maxRetries int, wait time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.retryWait = wait
+	}
+}
+
+// New returns a client for the Vaultify server at baseURL, e.g. http://localhost:9090
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q: must be an http or https URL", baseURL)
+	}
+
+	c := &Client{
+		baseURL:    strings.TrimSuffix(u.String(), "/") + apiPrefix,
+		httpClient: http.DefaultClient,
+		maxRetries: defaultMaxRetries,
+		retryWait:  defaultRetryWait,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Token returns the current access token, e.g. to store it between runs
+func (c *Client) Token() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+func (c *Client) setToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = token
+}
+
+func (c *Client) credentials() (string, string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.email, c.password
+}
+
+func (c *Client) tokenPolicies() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.policies
+}
+
+// renew logs in again unless another request already replaced the expired token
+func (c *Client) renew(ctx context.Context, expired string) error {
+	c.renewMu.Lock()
+	defer c.renewMu.Unlock()
+
+	if c.Token() != expired {
+		return nil
+	}
+	email, password := c.credentials()
+	_, err := c.Login(ctx, email, password, c.tokenPolicies()...)
+	return err
+}
+
+// do sends a JSON request to the API and decodes the response into out
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	resp, err := c.open(ctx, method, path, query, payload)
+	if err != nil {
+		return err
+	}
+	return decode(resp, out)
+}
+
+// open sends a request and returns the response of the last attempt. Requests answered with
+// 429 are retried, and a 401 on an authenticated request renews the token and retries once.
+func (c *Client) open(ctx context.Context, method, path string, query url.Values, payload []byte) (*http.Response, error) {
+	authenticated := path != "/login" && path != "/sign-up"
+	renewed := false
+	for attempt := 0; ; attempt++ {
+		token := c.Token()
+		if authenticated && token == "" {
+			if email, _ := c.credentials(); email == "" {
+				return nil, &APIError{StatusCode: http.StatusUnauthorized, Message: "not logged in"}
+			}
+			if err := c.renew(ctx, token); err != nil {
+				return nil, err
+			}
+			token = c.Token()
+		}
+
+		resp, err := c.send(ctx, method, path, query, payload, token, authenticated)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < c.maxRetries {
+			wait := retryAfter(resp.Header.Get("Retry-After"), c.retryWait<<attempt)
+			drain(resp)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(wait):
+			}
+			continue
+		}
+
+		if resp.StatusCode == http.StatusUnauthorized && authenticated && !renewed {
+			if email, _ := c.credentials(); email != "" {
+				drain(resp)
+				renewed = true
+				if err := c.renew(ctx, token); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+
+		return resp, nil
+	}
+}
+
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, payload []byte, token string, authenticated bool) (*http.Response, error) {
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if authenticated {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return c.httpClient.Do(req)
+}
+
+// decode reads a response, turning error statuses into an APIError
+func decode(resp *http.Response, out any) error {
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		var body struct {
+			Error string `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err == nil && body.Error != "" {
+			apiErr.Message = body.Error
+		} else {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		return apiErr
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+func drain(resp *http.Response) {
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+}
+
+// retryAfter returns the wait asked for by a Retry-After header, falling back to backoff
+func retryAfter(header string, backoff time.Duration) time.Duration {
+	if secs, err := strconv.Atoi(header); err == nil && secs >= 0 {
+		return min(time.Duration(secs)*time.Second, maxRetryWait)
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		return min(max(time.Until(at), 0), maxRetryWait)
+	}
+	return min(backoff, maxRetryWait)
+}
+
+// secretPath escapes each segment of a secret path for use in a URL
+func secretPath(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,199 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pixperk/vaultify/pkg/client"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc, opts ...client.Option) *client.Client {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	opts = append([]client.Option{client.WithRetries(3, time.Millisecond)}, opts...)
+	c, err := client.New(srv.URL, opts...)
+	require.NoError(t, err)
+	return c
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func TestNewRejectsInvalidURL(t *testing.T) {
+	_, err := client.New("localhost:9090")
+	require.Error(t, err)
+}
+
+func TestRetriesRateLimitedRequests(t *testing.T) {
+	var calls atomic.Int32
+	var requested atomic.Value
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			writeJSON(w, http.StatusTooManyRequests, map[string]string{"error": "rate limit exceeded"})
+			return
+		}
+		requested.Store(r.URL.RequestURI())
+		writeJSON(w, http.StatusOK, map[string]any{"path": "alice@example.com/db/password", "environment": "staging", "version": 2, "decrypted_value": "s3cret"})
+	}, client.WithToken("token"))
+
+	secret, err := c.GetSecret(context.Background(), client.GetSecretRequest{Path: "alice@example.com/db/password", Environment: "staging"})
+	require.NoError(t, err)
+	require.Equal(t, "s3cret", secret.Value)
+	require.EqualValues(t, 2, secret.Version)
+	require.EqualValues(t, 3, calls.Load())
+	require.Equal(t, "/api/v1/secrets/alice@example.com/db/password?env=staging", requested.Load())
+}
+
+func TestGivesUpAfterMaxRetries(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		writeJSON(w, http.StatusTooManyRequests, map[string]string{"error": "rate limit exceeded"})
+	}, client.WithToken("token"))
+
+	_, err := c.GetSecret(context.Background(), client.GetSecretRequest{Path: "a/b"})
+	require.ErrorIs(t, err, client.ErrRateLimited)
+	require.EqualValues(t, 4, calls.Load())
+}
+
+func TestRenewsExpiredToken(t *testing.T) {
+	var logins atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/login":
+			logins.Add(1)
+			writeJSON(w, http.StatusOK, map[string]any{"access_token": "fresh", "user": map[string]string{"email": "alice@example.com"}})
+		default:
+			if r.Header.Get("Authorization") != "Bearer fresh" {
+				writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "token has expired"})
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]any{"logs": []map[string]any{{"action": "create_secret"}}})
+		}
+	}, client.WithToken("stale"), client.WithCredentials("alice@example.com", "password"))
+
+	logs, err := c.AuditLogs(context.Background(), client.AuditQuery{})
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	require.Equal(t, "fresh", c.Token())
+	require.EqualValues(t, 1, logins.Load())
+}
+
+func TestUnauthorizedWithoutCredentials(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "token has expired"})
+	}, client.WithToken("stale"))
+
+	_, err := c.AuditLogs(context.Background(), client.AuditQuery{})
+	require.ErrorIs(t, err, client.ErrUnauthorized)
+
+	var apiErr *client.APIError
+	require.True(t, errors.As(err, &apiErr))
+	require.Equal(t, "token has expired", apiErr.Message)
+}
+
+func TestErrorsMapToStatus(t *testing.T) {
+	status := map[string]int{
+		"/api/v1/secrets/missing": http.StatusNotFound,
+		"/api/v1/secrets/share":   http.StatusConflict,
+	}
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if code, ok := status[r.URL.Path]; ok {
+			writeJSON(w, code, map[string]string{"error": "nope"})
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+	}, client.WithToken("token"))
+
+	ctx := context.Background()
+	_, err := c.GetSecret(ctx, client.GetSecretRequest{Path: "missing"})
+	require.ErrorIs(t, err, client.ErrNotFound)
+	require.NotErrorIs(t, err, client.ErrForbidden)
+
+	_, err = c.ShareSecret(ctx, client.ShareSecretRequest{Path: "a", TargetEmail: "b@example.com", Permission: "read"})
+	require.ErrorIs(t, err, client.ErrConflict)
+
+	// bodies without an error field fall back to the status text
+	_, err = c.UpdateSecret(ctx, client.UpdateSecretRequest{Path: "a", Value: "v"})
+	require.ErrorIs(t, err, client.ErrForbidden)
+	require.Contains(t, err.Error(), "Forbidden")
+}
+
+func TestGetSecretAwaitingQuorum(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusAccepted, map[string]any{"id": "1c9f0e6e-7f43-4c1a-9a52-5a4a1d0f6a10", "path": "alice@example.com/prod/root-key", "required_approvals": 2, "approvals": []string{}, "status": "pending"})
+	}, client.WithToken("token"))
+
+	_, err := c.GetSecret(context.Background(), client.GetSecretRequest{Path: "alice@example.com/prod/root-key"})
+	require.ErrorIs(t, err, client.ErrQuorumPending)
+	var pending *client.QuorumPendingError
+	require.ErrorAs(t, err, &pending)
+	require.Equal(t, "1c9f0e6e-7f43-4c1a-9a52-5a4a1d0f6a10", pending.Request.ID)
+	require.EqualValues(t, 2, pending.Request.RequiredApprovals)
+}
+
+func TestNotLoggedIn(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("no request expected")
+	})
+	_, err := c.GetSecret(context.Background(), client.GetSecretRequest{Path: "a"})
+	require.ErrorIs(t, err, client.ErrUnauthorized)
+}
+
+func TestContextCancelsRetries(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "5")
+		writeJSON(w, http.StatusTooManyRequests, map[string]string{"error": "rate limit exceeded"})
+	}, client.WithToken("token"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := c.GetSecret(ctx, client.GetSecretRequest{Path: "a"})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestWatch(t *testing.T) {
+	var requested atomic.Value
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requested.Store(r.URL.RequestURI())
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": keepalive\n\n")
+		fmt.Fprint(w, "event:version_changed\ndata:{\"type\":\"version_changed\",\"path\":\"alice@example.com/db/password\",\"environment\":\"staging\",\"version\":3,\"at\":\"2025-01-01T00:00:00Z\"}\n\n")
+		fmt.Fprint(w, "event:reset\ndata:{\"reason\":\"watcher fell behind\"}\n\n")
+	}, client.WithToken("token"))
+
+	var got []client.WatchEvent
+	err := c.Watch(context.Background(), client.WatchRequest{Paths: []string{"alice@example.com/db/password"}, Environment: "staging"}, func(e client.WatchEvent) error {
+		got = append(got, e)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "/api/v1/watch?env=staging&path=alice%40example.com%2Fdb%2Fpassword", requested.Load())
+	require.Len(t, got, 2)
+	require.Equal(t, client.EventVersionChanged, got[0].Type)
+	require.Equal(t, "alice@example.com/db/password", got[0].Path)
+	require.EqualValues(t, 3, got[0].Version)
+	require.Equal(t, client.EventReset, got[1].Type)
+}
+
+func TestWatchRejected(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "at least one path or a prefix is required"})
+	}, client.WithToken("token"))
+
+	err := c.Watch(context.Background(), client.WatchRequest{}, func(client.WatchEvent) error { return nil })
+	require.ErrorIs(t, err, client.ErrBadRequest)
+}
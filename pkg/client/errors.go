@@ -0,0 +1,47 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Errors matched by APIError via errors.Is, so callers need not compare status codes
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrRateLimited  = errors.New("rate limited")
+)
+
+// APIError is a non-2xx response from the Vaultify API
+type APIError struct {
+	StatusCode int
+	// Message is the "error" field of the response body, or the status text when there is none
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("vaultify: %d %s", e.StatusCode, e.Message)
+}
+
+// Is reports whether the error's status code corresponds to target
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
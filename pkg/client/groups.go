@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Group is a set of users that secrets can be shared with
+type Group struct {
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+	// Role is the caller's role in the group, "owner" or "member"
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Member is a user's membership in a group
+type Member struct {
+	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateGroup creates a group owned by the caller
+func (c *Client) CreateGroup(ctx context.Context, slug, name string) (*Group, error) {
+	body := map[string]string{"slug": slug, "name": name}
+	var group Group
+	if err := c.do(ctx, http.MethodPost, "/groups", nil, body, &group); err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// Groups returns the groups the caller belongs to
+func (c *Client) Groups(ctx context.Context) ([]Group, error) {
+	var groups []Group
+	if err := c.do(ctx, http.MethodGet, "/groups", nil, nil, &groups); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// GroupMembers returns the members of a group the caller belongs to
+func (c *Client) GroupMembers(ctx context.Context, group string) ([]Member, error) {
+	var members []Member
+	if err := c.do(ctx, http.MethodGet, "/groups/"+url.PathEscape(group)+"/members", nil, nil, &members); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// AddGroupMember adds a user to a group the caller owns, or changes their role
+func (c *Client) AddGroupMember(ctx context.Context, group, email, role string) (*Member, error) {
+	body := map[string]string{"email": email, "role": role}
+	var member Member
+	if err := c.do(ctx, http.MethodPost, "/groups/"+url.PathEscape(group)+"/members", nil, body, &member); err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// RemoveGroupMember removes a user from a group. Owners can remove anyone; members can
+// remove themselves.
+func (c *Client) RemoveGroupMember(ctx context.Context, group, email string) error {
+	return c.do(ctx, http.MethodDelete, "/groups/"+url.PathEscape(group)+"/members/"+url.PathEscape(email), nil, nil, nil)
+}
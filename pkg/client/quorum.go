@@ -0,0 +1,128 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ErrQuorumPending is matched by QuorumPendingError via errors.Is
+var ErrQuorumPending = errors.New("read awaits approvals")
+
+// QuorumPendingError is returned by GetSecret for a secret under the two-person rule while the
+// caller holds no approved read. Request is the request the read waits on.
+type QuorumPendingError struct {
+	Request *QuorumRequest
+}
+
+func (e *QuorumPendingError) Error() string {
+	return fmt.Sprintf("vaultify: read of %s awaits %d approvals (quorum request %s)", e.Request.Path, e.Request.RequiredApprovals, e.Request.ID)
+}
+
+// Is reports whether target is ErrQuorumPending
+func (e *QuorumPendingError) Is(target error) bool {
+	return target == ErrQuorumPending
+}
+
+// Quorum is the two-person rule of a secret
+type Quorum struct {
+	Path        string    `json:"path"`
+	Approvals   int32     `json:"approvals"`
+	WindowSecs  int64     `json:"window_secs"`
+	ReadTTLSecs int64     `json:"read_ttl_secs"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// QuorumRequest is a read of a secret under the two-person rule waiting for, or holding, approvals
+type QuorumRequest struct {
+	ID                string     `json:"id"`
+	Path              string     `json:"path"`
+	Environment       string     `json:"environment"`
+	RequesterEmail    string     `json:"requester_email"`
+	RequiredApprovals int32      `json:"required_approvals"`
+	Approvals         []string   `json:"approvals"`
+	Status            string     `json:"status"`
+	ExpiresAt         time.Time  `json:"expires_at"`
+	GrantedUntil      *time.Time `json:"granted_until,omitempty"`
+	ConsumedAt        *time.Time `json:"consumed_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+// SetQuorumRequest is the input of SetQuorum
+type SetQuorumRequest struct {
+	Path      string
+	Approvals int32
+	// Window is how long approvals are collected; zero means the server default
+	Window time.Duration
+	// ReadTTL is how long the approved read stays usable; zero means the server default
+	ReadTTL time.Duration
+}
+
+// Quorum returns the two-person rule of a secret
+func (c *Client) Quorum(ctx context.Context, path string) (*Quorum, error) {
+	var quorum Quorum
+	if err := c.do(ctx, http.MethodGet, "/quorum/"+secretPath(path), nil, nil, &quorum); err != nil {
+		return nil, err
+	}
+	return &quorum, nil
+}
+
+// SetQuorum makes every read of a secret wait for approvals by other users who can read it
+func (c *Client) SetQuorum(ctx context.Context, req SetQuorumRequest) (*Quorum, error) {
+	body := struct {
+		Approvals   int32 `json:"approvals"`
+		WindowSecs  int64 `json:"window_secs,omitempty"`
+		ReadTTLSecs int64 `json:"read_ttl_secs,omitempty"`
+	}{req.Approvals, int64(req.Window / time.Second), int64(req.ReadTTL / time.Second)}
+	var quorum Quorum
+	if err := c.do(ctx, http.MethodPut, "/quorum/"+secretPath(req.Path), nil, body, &quorum); err != nil {
+		return nil, err
+	}
+	return &quorum, nil
+}
+
+// RemoveQuorum lifts the two-person rule from a secret
+func (c *Client) RemoveQuorum(ctx context.Context, path string) error {
+	return c.do(ctx, http.MethodDelete, "/quorum/"+secretPath(path), nil, nil, nil)
+}
+
+// QuorumRequests returns the caller's own quorum requests, newest first
+func (c *Client) QuorumRequests(ctx context.Context) ([]QuorumRequest, error) {
+	return c.quorumRequests(ctx, "/quorum-requests")
+}
+
+// PendingQuorumRequests returns the open requests of other users that the caller can approve
+func (c *Client) PendingQuorumRequests(ctx context.Context) ([]QuorumRequest, error) {
+	return c.quorumRequests(ctx, "/quorum-requests/pending")
+}
+
+func (c *Client) quorumRequests(ctx context.Context, path string) ([]QuorumRequest, error) {
+	var resp struct {
+		Requests []QuorumRequest `json:"requests"`
+	}
+	if err := c.do(ctx, http.MethodGet, path, nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Requests, nil
+}
+
+// QuorumRequest returns one quorum request
+func (c *Client) QuorumRequest(ctx context.Context, id string) (*QuorumRequest, error) {
+	var request QuorumRequest
+	if err := c.do(ctx, http.MethodGet, "/quorum-requests/"+url.PathEscape(id), nil, nil, &request); err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// ApproveQuorumRequest adds the caller's approval to another user's read
+func (c *Client) ApproveQuorumRequest(ctx context.Context, id string) (*QuorumRequest, error) {
+	var request QuorumRequest
+	if err := c.do(ctx, http.MethodPost, "/quorum-requests/"+url.PathEscape(id)+"/approve", nil, nil, &request); err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
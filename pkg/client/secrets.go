@@ -0,0 +1,364 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// CreateSecretRequest creates a secret. Paths are relative to the caller's namespace unless
+// they name a team, as in org/<org>/<team>/<name>.
+type CreateSecretRequest struct {
+	Path  string
+	Value string
+	// TTL removes the secret once it passes; zero keeps it
+	TTL time.Duration
+	// Environment of the first value; the server's default environment when empty
+	Environment string
+}
+
+// Secret is a stored version of a secret. Its value is only included encrypted.
+type Secret struct {
+	Path           string `json:"path"`
+	Environment    string `json:"environment"`
+	Version        int32  `json:"version"`
+	EncryptedValue []byte `json:"encrypted_value"`
+	Nonce          []byte `json:"nonce"`
+}
+
+// GetSecretRequest reads a secret
+type GetSecretRequest struct {
+	Path        string
+	Environment string
+	// Version to read; zero reads the latest
+	Version int32
+	// Resolve expands references to other secrets in the value
+	Resolve bool
+}
+
+// SecretValue is a decrypted secret
+type SecretValue struct {
+	Path        string `json:"path"`
+	Environment string `json:"environment"`
+	Version     int32  `json:"version"`
+	Value       string `json:"decrypted_value"`
+}
+
+// UpdateSecretRequest writes a new version of a secret
+type UpdateSecretRequest struct {
+	Path        string
+	Environment string
+	Value       string
+}
+
+// RollbackSecretRequest writes a copy of an earlier version as the latest version
+type RollbackSecretRequest struct {
+	Path        string
+	Environment string
+	Version     int32
+}
+
+// Rollback is the result of a rollback
+type Rollback struct {
+	Path            string `json:"path"`
+	ExistingVersion int32  `json:"existing_version"`
+	ToVersion       int32  `json:"to_version"`
+	NewVersion      int32  `json:"new_version"`
+}
+
+// ShareSecretRequest grants another user or a group access to a secret the caller owns. A
+// Path ending in /* shares every secret below that folder.
+type ShareSecretRequest struct {
+	Path string
+	// TargetEmail or TargetGroup names who the share is for
+	TargetEmail string
+	TargetGroup string
+	// Permission is either "read" or "write"
+	Permission string
+	// TTL ends the share once it passes; zero keeps it until revoked
+	TTL time.Duration
+}
+
+// Share is a granted share
+type Share struct {
+	Path        string     `json:"path"`
+	Permission  string     `json:"permission"`
+	OwnerEmail  string     `json:"owner_email"`
+	TargetEmail string     `json:"target_email,omitempty"`
+	TargetGroup string     `json:"target_group,omitempty"`
+	SharedUntil *time.Time `json:"shared_until,omitempty"`
+	CreatedAt   time.Time  `json:"created_at,omitempty"`
+}
+
+// UpdateShareRequest changes an existing share. An empty Permission keeps the current one.
+type UpdateShareRequest struct {
+	Path string
+	// TargetEmail or TargetGroup names whose share to change
+	TargetEmail string
+	TargetGroup string
+	Permission  string
+	// TTL restarts the expiry from now, a zero TTL removes it and nil keeps it
+	TTL *time.Duration
+}
+
+func envQuery(env string) url.Values {
+	if env == "" {
+		return nil
+	}
+	return url.Values{"env": {env}}
+}
+
+// CreateSecret encrypts and stores a new secret
+func (c *Client) CreateSecret(ctx context.Context, req CreateSecretRequest) (*Secret, error) {
+	body := struct {
+		Path        string `json:"path"`
+		Value       string `json:"value"`
+		TTLSeconds  int64  `json:"ttl_seconds,omitempty"`
+		Environment string `json:"environment,omitempty"`
+	}{req.Path, req.Value, int64(req.TTL / time.Second), req.Environment}
+
+	var secret Secret
+	if err := c.do(ctx, http.MethodPost, "/secrets/", nil, body, &secret); err != nil {
+		return nil, err
+	}
+	return &secret, nil
+}
+
+// GetSecret returns the decrypted value of a secret. For a secret under the two-person rule
+// it returns a *QuorumPendingError until the caller's read is approved.
+func (c *Client) GetSecret(ctx context.Context, req GetSecretRequest) (*SecretValue, error) {
+	query := url.Values{}
+	if req.Environment != "" {
+		query.Set("env", req.Environment)
+	}
+	if req.Version > 0 {
+		query.Set("version", strconv.Itoa(int(req.Version)))
+	}
+	if req.Resolve {
+		query.Set("resolve", "true")
+	}
+
+	resp, err := c.open(ctx, http.MethodGet, "/secrets/"+secretPath(req.Path), query, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusAccepted {
+		var request QuorumRequest
+		if err := decode(resp, &request); err != nil {
+			return nil, err
+		}
+		return nil, &QuorumPendingError{Request: &request}
+	}
+
+	var secret SecretValue
+	if err := decode(resp, &secret); err != nil {
+		return nil, err
+	}
+	return &secret, nil
+}
+
+// UpdateSecret writes a new version of a secret
+func (c *Client) UpdateSecret(ctx context.Context, req UpdateSecretRequest) (*Secret, error) {
+	body := map[string]string{"value": req.Value}
+	var secret Secret
+	if err := c.do(ctx, http.MethodPut, "/secrets/"+secretPath(req.Path), envQuery(req.Environment), body, &secret); err != nil {
+		return nil, err
+	}
+	return &secret, nil
+}
+
+// RollbackSecret restores an earlier version of a secret as a new version
+func (c *Client) RollbackSecret(ctx context.Context, req RollbackSecretRequest) (*Rollback, error) {
+	body := map[string]int32{"version": req.Version}
+	var rollback Rollback
+	if err := c.do(ctx, http.MethodPost, "/secrets/rollback/"+secretPath(req.Path), envQuery(req.Environment), body, &rollback); err != nil {
+		return nil, err
+	}
+	return &rollback, nil
+}
+
+// ShareSecret shares a secret with another user
+func (c *Client) ShareSecret(ctx context.Context, req ShareSecretRequest) (*Share, error) {
+	body := struct {
+		Path         string `json:"path"`
+		TargetEmail  string `json:"target_email,omitempty"`
+		TargetGroup  string `json:"target_group,omitempty"`
+		Permission   string `json:"permission"`
+		ShareTTLSecs int    `json:"share_ttl_secs,omitempty"`
+	}{req.Path, req.TargetEmail, req.TargetGroup, req.Permission, int(req.TTL / time.Second)}
+
+	var share Share
+	if err := c.do(ctx, http.MethodPost, "/secrets/share", nil, body, &share); err != nil {
+		return nil, err
+	}
+	return &share, nil
+}
+
+// UpdateShare changes the permission or expiry of a share of a secret the caller owns
+func (c *Client) UpdateShare(ctx context.Context, req UpdateShareRequest) (*Share, error) {
+	body := struct {
+		TargetEmail  string `json:"target_email,omitempty"`
+		TargetGroup  string `json:"target_group,omitempty"`
+		Permission   string `json:"permission,omitempty"`
+		ShareTTLSecs *int   `json:"share_ttl_secs,omitempty"`
+	}{TargetEmail: req.TargetEmail, TargetGroup: req.TargetGroup, Permission: req.Permission}
+	if req.TTL != nil {
+		secs := int(*req.TTL / time.Second)
+		body.ShareTTLSecs = &secs
+	}
+
+	var share Share
+	if err := c.do(ctx, http.MethodPatch, "/shares/"+secretPath(req.Path), nil, body, &share); err != nil {
+		return nil, err
+	}
+	return &share, nil
+}
+
+// RevokeShare removes the share of a secret the caller owns with targetEmail
+func (c *Client) RevokeShare(ctx context.Context, path, targetEmail string) error {
+	query := url.Values{"target_email": {targetEmail}}
+	return c.do(ctx, http.MethodDelete, "/shares/"+secretPath(path), query, nil, nil)
+}
+
+// RevokeGroupShare removes the share of a secret the caller owns with a group
+func (c *Client) RevokeGroupShare(ctx context.Context, path, group string) error {
+	query := url.Values{"target_group": {group}}
+	return c.do(ctx, http.MethodDelete, "/shares/"+secretPath(path), query, nil, nil)
+}
+
+// RevokeAllShares removes every share of a secret the caller owns and returns who lost
+// access: users by email and groups as group:<slug>
+func (c *Client) RevokeAllShares(ctx context.Context, path string) ([]string, error) {
+	var resp struct {
+		Revoked []string `json:"revoked"`
+	}
+	query := url.Values{"all": {"true"}}
+	if err := c.do(ctx, http.MethodDelete, "/shares/"+secretPath(path), query, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Revoked, nil
+}
+
+// SharedWithMe returns the active shares other users granted to the caller. An empty prefix
+// or permission does not filter.
+func (c *Client) SharedWithMe(ctx context.Context, prefix, permission string) ([]Share, error) {
+	query := url.Values{}
+	if prefix != "" {
+		query.Set("prefix", prefix)
+	}
+	if permission != "" {
+		query.Set("permission", permission)
+	}
+
+	var resp struct {
+		Shares []Share `json:"shares"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/shared-with-me", query, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Shares, nil
+}
+
+// Sources of an Access entry
+const (
+	AccessOwner = "owner"
+	AccessTeam  = "team"
+	AccessShare = "share"
+)
+
+// Access is one reason a user can read or write a secret
+type Access struct {
+	// Email is the user, or Group the group whose members have the access
+	Email      string `json:"email,omitempty"`
+	Group      string `json:"group,omitempty"`
+	Permission string `json:"permission"`
+	Source     string `json:"source"`
+	// Role is the effective team role when Source is AccessTeam
+	Role        string     `json:"role,omitempty"`
+	SharedUntil *time.Time `json:"shared_until,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+}
+
+// WhoHasAccess lists everyone who can access a secret the caller owns, with an entry per
+// source of access. An empty permission does not filter.
+func (c *Client) WhoHasAccess(ctx context.Context, path, permission string) ([]Access, error) {
+	var query url.Values
+	if permission != "" {
+		query = url.Values{"permission": {permission}}
+	}
+
+	var resp struct {
+		Access []Access `json:"access"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/access/"+secretPath(path), query, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Access, nil
+}
+
+// Capabilities is what the caller may do on a path and the policies that contributed
+type Capabilities struct {
+	Path         string   `json:"path"`
+	Capabilities []string `json:"capabilities"`
+	Policies     []string `json:"policies"`
+	// TokenPolicies are the policies the token is limited to, if any
+	TokenPolicies []string `json:"token_policies,omitempty"`
+}
+
+// Capabilities lists what the caller may do on path, e.g. read, update or share. The path
+// does not need to exist.
+func (c *Client) Capabilities(ctx context.Context, path string) (*Capabilities, error) {
+	var resp Capabilities
+	if err := c.do(ctx, http.MethodGet, "/capabilities/"+secretPath(path), nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// SecretInfo describes a secret without its value
+type SecretInfo struct {
+	Path      string     `json:"path"`
+	Version   int32      `json:"version"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// ListSecrets returns the readable secrets under prefix with their latest version in env. An
+// empty prefix lists the caller's own namespace.
+func (c *Client) ListSecrets(ctx context.Context, prefix, env string) ([]SecretInfo, error) {
+	query := url.Values{}
+	if prefix != "" {
+		query.Set("prefix", prefix)
+	}
+	if env != "" {
+		query.Set("env", env)
+	}
+
+	var resp struct {
+		Secrets []SecretInfo `json:"secrets"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/list", query, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Secrets, nil
+}
+
+// SecretVersion describes one version of a secret
+type SecretVersion struct {
+	Version   int32     `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	CreatedBy string    `json:"created_by,omitempty"`
+}
+
+// History returns the versions of a secret in env, newest first
+func (c *Client) History(ctx context.Context, path, env string) ([]SecretVersion, error) {
+	var resp struct {
+		Versions []SecretVersion `json:"versions"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/history/"+secretPath(path), envQuery(env), nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Versions, nil
+}
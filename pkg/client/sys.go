@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// HMACKey is the key new secret versions are signed with
+type HMACKey struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CacheStats are the counters of one of a server's in-memory caches
+type CacheStats struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+	Size      int    `json:"size"`
+	Capacity  int    `json:"capacity"`
+}
+
+// SystemStatus is an overview of a Vaultify deployment
+type SystemStatus struct {
+	Counts struct {
+		Users          int64 `json:"users"`
+		Secrets        int64 `json:"secrets"`
+		SecretVersions int64 `json:"secret_versions"`
+		SharingRules   int64 `json:"sharing_rules"`
+		Groups         int64 `json:"groups"`
+		Organizations  int64 `json:"organizations"`
+		AuditLogs      int64 `json:"audit_logs"`
+	} `json:"counts"`
+	HMACKey      *HMACKey `json:"hmac_key,omitempty"`
+	Environments []string `json:"environments"`
+	Policies     []string `json:"policies"`
+	Cache        struct {
+		Secrets    CacheStats `json:"secrets"`
+		HMACKeys   CacheStats `json:"hmac_keys"`
+		ReadAccess CacheStats `json:"read_access"`
+	} `json:"cache"`
+	GRPC bool `json:"grpc"`
+}
+
+// Users lists every user with their system role. Admins only.
+func (c *Client) Users(ctx context.Context, limit, offset int) ([]User, error) {
+	query := url.Values{}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	if offset > 0 {
+		query.Set("offset", strconv.Itoa(offset))
+	}
+	var resp struct {
+		Users []User `json:"users"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/sys/users", query, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Users, nil
+}
+
+// SetUserRole sets a user's system role to "user", "auditor" or "admin". Admins only.
+func (c *Client) SetUserRole(ctx context.Context, email, role string) (*User, error) {
+	body := map[string]string{"role": role}
+	var user User
+	if err := c.do(ctx, http.MethodPut, "/sys/users/"+url.PathEscape(email)+"/role", nil, body, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// RotateHMACKey replaces the active HMAC key right away. Admins only.
+func (c *Client) RotateHMACKey(ctx context.Context) (*HMACKey, error) {
+	var key HMACKey
+	if err := c.do(ctx, http.MethodPost, "/sys/hmac/rotate", nil, nil, &key); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// SystemStatus returns counts, the active HMAC key, environments, policies and cache
+// statistics. Admins only.
+func (c *Client) SystemStatus(ctx context.Context) (*SystemStatus, error) {
+	var status SystemStatus
+	if err := c.do(ctx, http.MethodGet, "/sys/status", nil, nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// SearchAuditLogs returns the audit log entries of every user, or only of user when it is
+// set. Admins and auditors only.
+func (c *Client) SearchAuditLogs(ctx context.Context, user string, q AuditQuery) ([]AuditLog, error) {
+	query := q.values()
+	if user != "" {
+		query.Set("user", user)
+	}
+	return c.auditLogs(ctx, "/sys/audit", query)
+}
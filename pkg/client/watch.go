@@ -0,0 +1,99 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Event types sent by Watch
+const (
+	EventVersionChanged = "version_changed"
+	EventDeleted        = "deleted"
+	EventExpired        = "expired"
+	// EventReset means events may have been missed; re-read the watched secrets
+	EventReset = "reset"
+)
+
+// WatchRequest selects the secrets to watch: the listed paths, every secret under Prefix, or both
+type WatchRequest struct {
+	Paths  []string
+	Prefix string
+	// Environment limits events to one environment; empty watches all of them
+	Environment string
+}
+
+// WatchEvent tells that a watched secret changed. It never carries the value.
+type WatchEvent struct {
+	Type        string    `json:"type"`
+	Path        string    `json:"path"`
+	Environment string    `json:"environment,omitempty"`
+	Version     int32     `json:"version,omitempty"`
+	At          time.Time `json:"at"`
+}
+
+// Watch streams change events for the secrets in req to fn until ctx is done, fn returns an
+// error or the server ends the stream. The server ends it after a reset event when the watcher
+// fell behind; in that case Watch returns nil and callers that keep watching call it again.
+func (c *Client) Watch(ctx context.Context, req WatchRequest, fn func(WatchEvent) error) error {
+	query := url.Values{}
+	for _, path := range req.Paths {
+		query.Add("path", path)
+	}
+	if req.Prefix != "" {
+		query.Set("prefix", req.Prefix)
+	}
+	if req.Environment != "" {
+		query.Set("env", req.Environment)
+	}
+
+	resp, err := c.open(ctx, http.MethodGet, "/watch", query, nil)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return decode(resp, nil)
+	}
+	defer resp.Body.Close()
+
+	// Server-Sent Events: "event:" and "data:" lines, a blank line ends an event and lines
+	// starting with ":" are keepalives
+	var eventType, data string
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case line == "":
+			if eventType == "" && data == "" {
+				continue
+			}
+			e := WatchEvent{Type: eventType}
+			if eventType != EventReset && data != "" {
+				if err := json.Unmarshal([]byte(data), &e); err != nil {
+					return fmt.Errorf("invalid watch event: %w", err)
+				}
+			}
+			if e.Type == "" {
+				e.Type = eventType
+			}
+			eventType, data = "", ""
+			if err := fn(e); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, ":"):
+		case strings.HasPrefix(line, "event:"):
+			eventType = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data += strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		}
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return sc.Err()
+}